@@ -336,8 +336,15 @@ func ConnectSimpleStreams(uri string, args *ConnectionArgs) (ImageServer, error)
 // ConnectOCI lets you connect to a remote OCI image registry over HTTPs.
 //
 // Unless the remote server is trusted by the system CA, the remote certificate must be provided (TLSServerCert).
-func ConnectOCI(uri string, args *ConnectionArgs) (ImageServer, error) {
-	logger.Debug("Connecting to a remote OCI server", logger.Ctx{"URL": uri})
+func ConnectOCI(uri string, args *ConnectionArgs) (OCIServer, error) {
+	// Don't log the credentials.
+	logURL := uri
+	u, err := url.Parse(uri)
+	if err == nil {
+		logURL = u.Redacted()
+	}
+
+	logger.Debug("Connecting to a remote OCI server", logger.Ctx{"URL": logURL})
 
 	// Cleanup URL
 	uri = strings.TrimSuffix(uri, "/")
@@ -353,9 +360,11 @@ func ConnectOCI(uri string, args *ConnectionArgs) (ImageServer, error) {
 		httpUserAgent:   args.UserAgent,
 		httpCertificate: args.TLSServerCert,
 
-		cache:    map[string]ociInfo{},
-		errors:   map[string]error{},
-		tempPath: args.TempPath,
+		cache:     map[string]ociInfo{},
+		errors:    map[string]error{},
+		tokens:    map[string]string{},
+		cachePath: args.CachePath,
+		tempPath:  args.TempPath,
 	}
 
 	// Setup the HTTP client
@@ -446,6 +446,54 @@ func (r *ProtocolIncus) GetInstancesFullAllProjectsWithFilter(instanceType api.I
 	return instances, nil
 }
 
+// GetInstancesFullWithInventory returns a filtered list of instances including snapshots, backups, state and
+// the software inventory of the running virtual machines.
+func (r *ProtocolIncus) GetInstancesFullWithInventory(instanceType api.InstanceType, filters []string) ([]api.InstanceFull, error) {
+	return r.getInstancesFullWithInventory(instanceType, filters, false)
+}
+
+// GetInstancesFullAllProjectsWithInventory returns a filtered list of instances including snapshots, backups,
+// state and the software inventory of the running virtual machines from all projects.
+func (r *ProtocolIncus) GetInstancesFullAllProjectsWithInventory(instanceType api.InstanceType, filters []string) ([]api.InstanceFull, error) {
+	if !r.HasExtension("instance_all_projects") {
+		return nil, errors.New("The server is missing the required \"instance_all_projects\" API extension")
+	}
+
+	return r.getInstancesFullWithInventory(instanceType, filters, true)
+}
+
+func (r *ProtocolIncus) getInstancesFullWithInventory(instanceType api.InstanceType, filters []string, allProjects bool) ([]api.InstanceFull, error) {
+	if !r.HasExtension("instance_inventory") {
+		return nil, errors.New("The server is missing the required \"instance_inventory\" API extension")
+	}
+
+	instances := []api.InstanceFull{}
+
+	path, v, err := r.instanceTypeToPath(instanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Set("recursion", "2")
+	v.Set("inventory", "true")
+
+	if allProjects {
+		v.Set("all-projects", "true")
+	}
+
+	if len(filters) > 0 {
+		v.Set("filter", parseFilters(filters))
+	}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s?%s", path, v.Encode()), nil, "", &instances)
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
 // GetInstance returns the instance entry for the provided name.
 func (r *ProtocolIncus) GetInstance(name string) (*api.Instance, string, error) {
 	instance := api.Instance{}
@@ -2239,6 +2287,61 @@ func (r *ProtocolIncus) UpdateInstanceState(name string, state api.InstanceState
 	return op, nil
 }
 
+// UpdateInstanceAccessCredentials sets the password and/or adds SSH keys for a user inside of a running instance.
+func (r *ProtocolIncus) UpdateInstanceAccessCredentials(name string, credentials api.InstanceAccessCredentialsPost) error {
+	if !r.HasExtension("instance_access_credentials") {
+		return errors.New("The server is missing the required \"instance_access_credentials\" API extension")
+	}
+
+	_, _, err := r.query("POST", fmt.Sprintf("/instances/%s/access/credentials", url.PathEscape(name)), credentials, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetInstanceInventory returns the software inventory of the provided instance.
+func (r *ProtocolIncus) GetInstanceInventory(name string) (*api.InstanceInventory, error) {
+	return r.GetInstanceInventoryWithFilter(name, nil)
+}
+
+// GetInstanceInventoryWithFilter returns the software inventory of the provided instance, only keeping the
+// packages, users and services matching the given filters.
+func (r *ProtocolIncus) GetInstanceInventoryWithFilter(name string, filters []string) (*api.InstanceInventory, error) {
+	var uri string
+
+	if r.IsAgent() {
+		uri = "/inventory"
+	} else {
+		if !r.HasExtension("instance_inventory") {
+			return nil, errors.New("The server is missing the required \"instance_inventory\" API extension")
+		}
+
+		path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+		if err != nil {
+			return nil, err
+		}
+
+		uri = fmt.Sprintf("%s/%s/inventory", path, url.PathEscape(name))
+		if len(filters) > 0 {
+			v := url.Values{}
+			v.Set("filter", parseFilters(filters))
+			uri += "?" + v.Encode()
+		}
+	}
+
+	inventory := api.InstanceInventory{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", uri, nil, "", &inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inventory, nil
+}
+
 // GetInstanceAccess returns an Access entry for the provided instance name.
 func (r *ProtocolIncus) GetInstanceAccess(name string) (api.Access, error) {
 	access := api.Access{}
@@ -2891,6 +2994,10 @@ func (r *ProtocolIncus) CreateInstanceBackup(instanceName string, backup api.Ins
 		return nil, errors.New("The server is missing the required \"container_backup\" API extension")
 	}
 
+	if backup.Since != "" && !r.HasExtension("backup_incremental") {
+		return nil, errors.New("The server is missing the required \"backup_incremental\" API extension")
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/backups", path, url.PathEscape(instanceName)), backup, "")
 	if err != nil {
@@ -3015,6 +3122,10 @@ func (r *ProtocolIncus) CreateInstanceBackupStream(instanceName string, backup a
 		return errors.New("The server is missing the required \"direct_backup\" API extension")
 	}
 
+	if backup.Since != "" && !r.HasExtension("backup_incremental") {
+		return errors.New("The server is missing the required \"backup_incremental\" API extension")
+	}
+
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
 	if err != nil {
 		return err
@@ -151,6 +151,23 @@ func (r *ProtocolIncus) GetNetworkState(name string) (*api.NetworkState, error)
 	return &state, nil
 }
 
+// GetNetworkBGPState returns the state of the BGP peers and prefixes of the network.
+func (r *ProtocolIncus) GetNetworkBGPState(name string) (*api.NetworkBGPState, error) {
+	if !r.HasExtension("network_bgp_state") {
+		return nil, errors.New("The server is missing the required \"network_bgp_state\" API extension")
+	}
+
+	state := api.NetworkBGPState{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/bgp", url.PathEscape(name)), nil, "", &state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
 // CreateNetwork defines a new network using the provided Network struct.
 func (r *ProtocolIncus) CreateNetwork(network api.NetworksPost) error {
 	if !r.HasExtension("network") {
@@ -992,6 +992,10 @@ func (r *ProtocolIncus) CreateStorageVolumeBackup(pool string, volName string, b
 		return nil, errors.New("The server is missing the required \"custom_volume_backup\" API extension")
 	}
 
+	if backup.Since != "" && !r.HasExtension("backup_incremental") {
+		return nil, errors.New("The server is missing the required \"backup_incremental\" API extension")
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/custom/%s/backups", url.PathEscape(pool), url.PathEscape(volName)), backup, "")
 	if err != nil {
@@ -1104,6 +1108,10 @@ func (r *ProtocolIncus) CreateStorageVolumeBackupStream(pool string, volName str
 		return errors.New("The server is missing the required \"direct_backup\" API extension")
 	}
 
+	if backup.Since != "" && !r.HasExtension("backup_incremental") {
+		return errors.New("The server is missing the required \"backup_incremental\" API extension")
+	}
+
 	// Build the URL
 	uri := fmt.Sprintf("%s/1.0/storage-pools/%s/volumes/custom/%s/backups", r.httpBaseURL.String(), url.PathEscape(pool), url.PathEscape(volName))
 	if r.project != "" {
@@ -70,6 +70,14 @@ type ImageServer interface {
 	ExportImage(fingerprint string, image api.ImageExportPost) (Operation, error)
 }
 
+// The OCIServer type represents an OCI image registry.
+type OCIServer interface {
+	ImageServer
+
+	// Image push functions
+	PushOCIImage(layoutPath string, name string, progressHandler func(progress ioprogress.ProgressData)) (digest string, err error)
+}
+
 // The InstanceServer type represents a full featured Incus server.
 type InstanceServer interface {
 	ImageServer
@@ -107,6 +115,8 @@ type InstanceServer interface {
 	GetInstancesFullWithFilter(instanceType api.InstanceType, filters []string) (instances []api.InstanceFull, err error)
 	GetInstancesAllProjectsWithFilter(instanceType api.InstanceType, filters []string) (instances []api.Instance, err error)
 	GetInstancesFullAllProjectsWithFilter(instanceType api.InstanceType, filters []string) (instances []api.InstanceFull, err error)
+	GetInstancesFullWithInventory(instanceType api.InstanceType, filters []string) (instances []api.InstanceFull, err error)
+	GetInstancesFullAllProjectsWithInventory(instanceType api.InstanceType, filters []string) (instances []api.InstanceFull, err error)
 	GetInstance(name string) (instance *api.Instance, ETag string, err error)
 	GetInstanceFull(name string) (instance *api.InstanceFull, ETag string, err error)
 	CreateInstance(instance api.InstancesPost) (op Operation, err error)
@@ -160,6 +170,10 @@ type InstanceServer interface {
 	UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (op Operation, err error)
 
 	GetInstanceAccess(name string) (access api.Access, err error)
+	UpdateInstanceAccessCredentials(name string, credentials api.InstanceAccessCredentialsPost) (err error)
+
+	GetInstanceInventory(name string) (inventory *api.InstanceInventory, err error)
+	GetInstanceInventoryWithFilter(name string, filters []string) (inventory *api.InstanceInventory, err error)
 
 	GetInstanceLogfiles(name string) (logfiles []string, err error)
 	GetInstanceLogfile(name string, filename string) (content io.ReadCloser, err error)
@@ -206,6 +220,7 @@ type InstanceServer interface {
 	GetNetwork(name string) (network *api.Network, ETag string, err error)
 	GetNetworkLeases(name string) (leases []api.NetworkLease, err error)
 	GetNetworkState(name string) (state *api.NetworkState, err error)
+	GetNetworkBGPState(name string) (state *api.NetworkBGPState, err error)
 	CreateNetwork(network api.NetworksPost) (err error)
 	UpdateNetwork(name string, network api.NetworkPut, ETag string) (err error)
 	RenameNetwork(name string, network api.NetworkPost) (err error)
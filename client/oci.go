@@ -3,6 +3,7 @@ package incus
 import (
 	"errors"
 	"net/http"
+	"sync"
 )
 
 // ProtocolOCI implements an OCI registry API client.
@@ -18,7 +19,12 @@ type ProtocolOCI struct {
 	// Error tracking for images.
 	errors map[string]error
 
-	tempPath string
+	// Registry authorization by token scope.
+	tokens     map[string]string
+	tokensLock sync.Mutex
+
+	cachePath string
+	tempPath  string
 }
 
 // Disconnect is a no-op for OCI.
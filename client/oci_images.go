@@ -4,19 +4,19 @@ import (
 	"compress/gzip"
 	"context"
 	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
-	"net/url"
+	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
 	"time"
 
+	"github.com/opencontainers/go-digest"
+	ociImageSpecs "github.com/opencontainers/image-spec/specs-go"
+	ociImage "github.com/opencontainers/image-spec/specs-go/v1"
+
 	"github.com/lxc/incus/v7/shared/api"
 	"github.com/lxc/incus/v7/shared/ioprogress"
 	"github.com/lxc/incus/v7/shared/logger"
@@ -28,29 +28,13 @@ import (
 
 type ociInfo struct {
 	Alias        string
-	Name         string    `json:"Name"`
-	Digest       string    `json:"Digest"`
-	Created      time.Time `json:"Created"`
-	Architecture string    `json:"Architecture"`
-	Layers       []string  `json:"Layers"`
-	LayersData   []struct {
-		Size int64 `json:"Size"`
-	} `json:"LayersData"`
-}
-
-// Get the proxy host value.
-func (r *ProtocolOCI) getProxyHost() (*url.URL, error) {
-	req, err := http.NewRequest("GET", r.httpHost, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	proxy, err := r.http.Transport.(*http.Transport).Proxy(req)
-	if err != nil {
-		return nil, err
-	}
-
-	return proxy, nil
+	Name         string
+	Repository   string
+	Digest       string
+	Created      time.Time
+	Architecture string
+	Manifest     *ociImage.Manifest
+	Config       []byte
 }
 
 // Image handling functions
@@ -84,11 +68,6 @@ func (r *ProtocolOCI) GetImagesWithFilter(_ []string) ([]api.Image, error) {
 func (r *ProtocolOCI) GetImage(fingerprint string) (*api.Image, string, error) {
 	info, ok := r.cache[fingerprint]
 	if !ok {
-		_, err := exec.LookPath("skopeo")
-		if err != nil {
-			return nil, "", errors.New("OCI container handling requires \"skopeo\" be present on the system")
-		}
-
 		err, ok := r.errors[fingerprint]
 		if ok {
 			return nil, "", err
@@ -118,7 +97,7 @@ func (r *ProtocolOCI) GetImage(fingerprint string) (*api.Image, string, error) {
 	}
 
 	var size int64
-	for _, layer := range info.LayersData {
+	for _, layer := range info.Manifest.Layers {
 		size += layer.Size
 	}
 
@@ -134,11 +113,6 @@ func (r *ProtocolOCI) GetImageFile(fingerprint string, req ImageFileRequest) (*I
 	// Get the cached entry.
 	info, ok := r.cache[fingerprint]
 	if !ok {
-		_, err := exec.LookPath("skopeo")
-		if err != nil {
-			return nil, errors.New("OCI container handling requires \"skopeo\" be present on the system")
-		}
-
 		err, ok := r.errors[fingerprint]
 		if ok {
 			return nil, err
@@ -164,29 +138,21 @@ func (r *ProtocolOCI) GetImageFile(fingerprint string, req ImageFileRequest) (*I
 
 	defer func() { _ = os.RemoveAll(ociPath) }()
 
-	err = os.Mkdir(filepath.Join(ociPath, "oci"), 0o700)
-	if err != nil {
-		return nil, err
-	}
-
 	err = os.Mkdir(filepath.Join(ociPath, "image"), 0o700)
 	if err != nil {
 		return nil, err
 	}
 
-	// Copy the image.
+	// Retrieve the image.
 	if req.ProgressHandler != nil {
 		req.ProgressHandler(ioprogress.ProgressData{Text: "Retrieving OCI image from registry"})
 	}
 
 	imageTag := "latest"
 
-	stdout, err := r.runSkopeo(
-		"copy", info.Alias,
-		"--remove-signatures",
-		fmt.Sprintf("oci:%s:%s", filepath.Join(ociPath, "oci"), imageTag))
+	err = r.writeImageLayout(ctx, &info, filepath.Join(ociPath, "oci"), imageTag, req.ProgressHandler)
 	if err != nil {
-		logger.Debug("Error copying remote image to local", logger.Ctx{"image": info.Alias, "stdout": stdout, "stderr": err})
+		logger.Debug("Error retrieving OCI image", logger.Ctx{"image": info.Alias, "err": err})
 		return nil, err
 	}
 
@@ -331,111 +297,16 @@ func (r *ProtocolOCI) GetImageAliasNames() ([]string, error) {
 	return nil, errors.New("Can't list image aliases from OCI registry")
 }
 
-func (r *ProtocolOCI) runSkopeo(action string, image string, args ...string) (string, error) {
-	// Parse and mangle the server URL.
-	uri, err := url.Parse(r.httpHost)
-	if err != nil {
-		return "", err
-	}
-
-	// Get proxy details.
-	proxy, err := r.getProxyHost()
-	if err != nil {
-		return "", err
-	}
-
-	var env []string
-	if proxy != nil {
-		env = []string{
-			fmt.Sprintf("HTTPS_PROXY=%s", proxy),
-			fmt.Sprintf("HTTP_PROXY=%s", proxy),
-		}
-	}
-
-	// Handle authentication.
-	if uri.User != nil {
-		creds, err := json.Marshal(map[string]any{
-			"auths": map[string]any{
-				uri.Scheme + "://" + uri.Host: map[string]string{
-					"auth": base64.StdEncoding.EncodeToString([]byte(uri.User.String())),
-				},
-			},
-		})
-		if err != nil {
-			return "", err
-		}
-
-		authFile, err := os.CreateTemp(r.tempPath, "incus_client_auth_")
-		if err != nil {
-			return "", err
-		}
-
-		defer authFile.Close()
-		defer os.Remove(authFile.Name())
-
-		err = authFile.Chmod(0o600)
-		if err != nil {
-			return "", err
-		}
-
-		_, err = fmt.Fprintf(authFile, "%s", creds)
-		if err != nil {
-			return "", err
-		}
-
-		uri.User = nil
-
-		args = append(args, fmt.Sprintf("--authfile=%s", authFile.Name()))
-	}
-
-	// Prepare the arguments.
-	uri.Scheme = "docker"
-	args = append([]string{"--insecure-policy", action, fmt.Sprintf("%s/%s", uri.String(), image)}, args...)
-
-	// Get the image information from skopeo.
-	stdout, _, err := subprocess.RunCommandSplit(
-		context.TODO(),
-		env,
-		nil,
-		"skopeo",
-		args...)
-	if err != nil {
-		return "", err
-	}
-
-	return stdout, nil
-}
-
 // GetImageAlias returns an existing alias as an ImageAliasesEntry struct.
 func (r *ProtocolOCI) GetImageAlias(name string) (*api.ImageAliasesEntry, string, error) {
-	// If image name is "IMAGE:TAG@HASH", drop ":TAG" so that skopeo uses the pinned hash instead.
-	imageWithoutHash, hash, hasHash := strings.Cut(name, "@")
-	if hasHash {
-		imageWithoutTag, _, _ := strings.Cut(imageWithoutHash, ":")
-		name = fmt.Sprintf("%s@%s", imageWithoutTag, hash)
-	}
-
-	// Get the image information from skopeo.
-	stdout, err := r.runSkopeo("inspect", name)
-	if err != nil {
-		logger.Debug("Error getting image alias", logger.Ctx{"name": name, "stdout": stdout, "stderr": err})
-		r.errors[name] = err
-
-		return nil, "", err
-	}
-
-	// Parse the image info.
-	var info ociInfo
-	err = json.Unmarshal([]byte(stdout), &info)
+	info, err := r.inspectImage(context.TODO(), name)
 	if err != nil {
+		logger.Debug("Error getting image alias", logger.Ctx{"name": name, "err": err})
 		r.errors[name] = err
 
 		return nil, "", err
 	}
 
-	info.Alias = name
-	info.Digest = r.computeFingerprint(info.Layers)
-
 	archID, err := osarch.ArchitectureID(info.Architecture)
 	if err != nil {
 		r.errors[name] = err
@@ -453,7 +324,7 @@ func (r *ProtocolOCI) GetImageAlias(name string) (*api.ImageAliasesEntry, string
 	info.Architecture = archName
 
 	// Store it in the cache.
-	r.cache[info.Digest] = info
+	r.cache[info.Digest] = *info
 
 	// Prepare the alias entry.
 	alias := api.ImageAliasesEntry{
@@ -509,3 +380,258 @@ func (r *ProtocolOCI) computeFingerprint(layers []string) string {
 
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
+
+// inspectImage resolves an image name to the manifest and config of the image for the local architecture.
+func (r *ProtocolOCI) inspectImage(ctx context.Context, name string) (*ociInfo, error) {
+	ref, err := r.parseReference(name)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := r.resolveManifest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	configData, err := r.getBlob(ctx, ref.Repository, manifest.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	var config ociImage.Image
+	err = json.Unmarshal(configData, &config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing image config: %w", err)
+	}
+
+	_, registryName, _, err := r.ociRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	layers := make([]string, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		layers = append(layers, layer.Digest.String())
+	}
+
+	info := &ociInfo{
+		Alias:        name,
+		Name:         registryName + "/" + ref.Repository,
+		Repository:   ref.Repository,
+		Digest:       r.computeFingerprint(layers),
+		Architecture: config.Architecture,
+		Manifest:     manifest,
+		Config:       configData,
+	}
+
+	if config.Created != nil {
+		info.Created = *config.Created
+	}
+
+	return info, nil
+}
+
+// writeImageLayout retrieves an image into an OCI image layout at layoutPath, under the given tag.
+//
+// The layers are kept in the blob store of the cache directory if set, so that layers shared between images or
+// versions of an image are only downloaded once. Docker manifests are converted to OCI manifests.
+func (r *ProtocolOCI) writeImageLayout(ctx context.Context, info *ociInfo, layoutPath string, tag string, progressHandler func(ioprogress.ProgressData)) error {
+	storePath := layoutPath
+	if r.cachePath != "" {
+		storePath = filepath.Join(r.cachePath, "oci")
+	}
+
+	err := os.MkdirAll(filepath.Join(layoutPath, "blobs", "sha256"), 0o700)
+	if err != nil {
+		return err
+	}
+
+	manifest := ociImage.Manifest{
+		Versioned: ociImageSpecs.Versioned{SchemaVersion: 2},
+		MediaType: ociImage.MediaTypeImageManifest,
+		Config: ociImage.Descriptor{
+			MediaType: ociImage.MediaTypeImageConfig,
+			Digest:    info.Manifest.Config.Digest,
+			Size:      int64(len(info.Config)),
+		},
+		Annotations: info.Manifest.Annotations,
+	}
+
+	for i, layer := range info.Manifest.Layers {
+		switch layer.MediaType {
+		case ociMediaTypeDockerLayerGzip:
+			layer.MediaType = ociImage.MediaTypeImageLayerGzip
+		case ociImage.MediaTypeImageLayer, ociImage.MediaTypeImageLayerGzip, ociImage.MediaTypeImageLayerZstd:
+		default:
+			return fmt.Errorf("Unsupported layer type %q", layer.MediaType)
+		}
+
+		var progress func(int64, int64)
+		if progressHandler != nil {
+			progress = func(percent int64, speed int64) {
+				progressHandler(ioprogress.ProgressData{Text: fmt.Sprintf("Retrieving layer %d/%d: %d%% (%s/s)", i+1, len(info.Manifest.Layers), percent, units.GetByteSizeString(speed, 2))})
+			}
+		}
+
+		err = r.downloadBlob(ctx, info.Repository, layer, storePath, progress)
+		if err != nil {
+			return err
+		}
+
+		if storePath != layoutPath {
+			err = os.MkdirAll(filepath.Dir(ociBlobPath(layoutPath, layer.Digest)), 0o700)
+			if err != nil {
+				return err
+			}
+
+			err = os.Symlink(ociBlobPath(storePath, layer.Digest), ociBlobPath(layoutPath, layer.Digest))
+			if err != nil && !errors.Is(err, fs.ErrExist) {
+				return err
+			}
+		}
+
+		manifest.Layers = append(manifest.Layers, ociImage.Descriptor{
+			MediaType: layer.MediaType,
+			Digest:    layer.Digest,
+			Size:      layer.Size,
+		})
+	}
+
+	if storePath != layoutPath {
+		err = pruneBlobs(storePath)
+		if err != nil {
+			logger.Warn("Failed pruning OCI blob store", logger.Ctx{"path": storePath, "err": err})
+		}
+	}
+
+	// Write the config, manifest, index and layout marker.
+	err = os.MkdirAll(filepath.Dir(ociBlobPath(layoutPath, manifest.Config.Digest)), 0o700)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(ociBlobPath(layoutPath, manifest.Config.Digest), info.Config, 0o600)
+	if err != nil {
+		return err
+	}
+
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	manifestDigest := digest.FromBytes(manifestData)
+	err = os.WriteFile(ociBlobPath(layoutPath, manifestDigest), manifestData, 0o600)
+	if err != nil {
+		return err
+	}
+
+	index := ociImage.Index{
+		Versioned: ociImageSpecs.Versioned{SchemaVersion: 2},
+		MediaType: ociImage.MediaTypeImageIndex,
+		Manifests: []ociImage.Descriptor{{
+			MediaType:   ociImage.MediaTypeImageManifest,
+			Digest:      manifestDigest,
+			Size:        int64(len(manifestData)),
+			Annotations: map[string]string{ociImage.AnnotationRefName: tag},
+		}},
+	}
+
+	indexData, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(layoutPath, ociImage.ImageIndexFile), indexData, 0o600)
+	if err != nil {
+		return err
+	}
+
+	layoutData, err := json.Marshal(ociImage.ImageLayout{Version: ociImage.ImageLayoutVersion})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(layoutPath, ociImage.ImageLayoutFile), layoutData, 0o600)
+}
+
+// PushOCIImage uploads the image of the OCI image layout at layoutPath to the registry, under a name of the form
+// "<repository>[:<tag>]", and returns the digest of its manifest.
+func (r *ProtocolOCI) PushOCIImage(layoutPath string, name string, progressHandler func(ioprogress.ProgressData)) (string, error) {
+	ctx := context.Background()
+
+	ref, err := r.parseReference(name)
+	if err != nil {
+		return "", err
+	}
+
+	// Load the manifest of the image.
+	indexData, err := os.ReadFile(filepath.Join(layoutPath, ociImage.ImageIndexFile))
+	if err != nil {
+		return "", err
+	}
+
+	var index ociImage.Index
+	err = json.Unmarshal(indexData, &index)
+	if err != nil {
+		return "", fmt.Errorf("Failed parsing image index: %w", err)
+	}
+
+	if len(index.Manifests) != 1 || index.Manifests[0].MediaType != ociImage.MediaTypeImageManifest {
+		return "", errors.New("The image layout must contain a single image manifest")
+	}
+
+	manifestDigest := index.Manifests[0].Digest
+	err = manifestDigest.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	manifestData, err := os.ReadFile(ociBlobPath(layoutPath, manifestDigest))
+	if err != nil {
+		return "", err
+	}
+
+	var manifest ociImage.Manifest
+	err = json.Unmarshal(manifestData, &manifest)
+	if err != nil {
+		return "", fmt.Errorf("Failed parsing image manifest: %w", err)
+	}
+
+	// Upload the layers and the config before the manifest referencing them.
+	for i, layer := range manifest.Layers {
+		err = layer.Digest.Validate()
+		if err != nil {
+			return "", err
+		}
+
+		var progress func(int64, int64)
+		if progressHandler != nil {
+			progress = func(percent int64, speed int64) {
+				progressHandler(ioprogress.ProgressData{Text: fmt.Sprintf("Pushing layer %d/%d: %d%% (%s/s)", i+1, len(manifest.Layers), percent, units.GetByteSizeString(speed, 2))})
+			}
+		}
+
+		err = r.uploadBlob(ctx, ref.Repository, layer, ociBlobPath(layoutPath, layer.Digest), progress)
+		if err != nil {
+			return "", fmt.Errorf("Failed pushing layer %q: %w", layer.Digest, err)
+		}
+	}
+
+	err = manifest.Config.Digest.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	err = r.uploadBlob(ctx, ref.Repository, manifest.Config, ociBlobPath(layoutPath, manifest.Config.Digest), nil)
+	if err != nil {
+		return "", fmt.Errorf("Failed pushing image config: %w", err)
+	}
+
+	err = r.putManifest(ctx, ref.Repository, ref.Reference, ociImage.MediaTypeImageManifest, manifestData)
+	if err != nil {
+		return "", fmt.Errorf("Failed pushing image manifest: %w", err)
+	}
+
+	return manifestDigest.String(), nil
+}
@@ -0,0 +1,813 @@
+package incus
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ociImage "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/lxc/incus/v7/shared/ioprogress"
+	"github.com/lxc/incus/v7/shared/osarch"
+)
+
+// Docker media types, which registries still commonly serve instead of their OCI equivalents.
+const (
+	ociMediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	ociMediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	ociMediaTypeDockerLayerGzip    = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+)
+
+// ociMaxDocumentSize is the maximum size of the manifests and configs fetched from a registry.
+const ociMaxDocumentSize = 16 * 1024 * 1024
+
+// ociBlobExpiry is how long unused blobs are kept in the local blob store.
+const ociBlobExpiry = 7 * 24 * time.Hour
+
+// ociBlobRetries is how many times an interrupted blob download is resumed.
+const ociBlobRetries = 3
+
+// ociBlobLocks serializes the downloads of the same blob within the process.
+var ociBlobLocks sync.Map
+
+// ociReference is a reference to an image in a registry.
+type ociReference struct {
+	// Repository is the repository path on the registry, for example "library/alpine".
+	Repository string
+
+	// Reference is the tag or digest of the image.
+	Reference string
+}
+
+// ociRegistry returns the URL of the registry API, its name and the repository prefix of the remote.
+func (r *ProtocolOCI) ociRegistry() (*url.URL, string, string, error) {
+	u, err := url.Parse(r.httpHost)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	name := u.Host
+	prefix := strings.Trim(u.Path, "/")
+
+	// The Docker Hub API is served from a different host than its web interface.
+	if name == "docker.io" || name == "index.docker.io" || name == "registry-1.docker.io" {
+		name = "docker.io"
+		u.Host = "registry-1.docker.io"
+	}
+
+	return &url.URL{Scheme: u.Scheme, Host: u.Host}, name, prefix, nil
+}
+
+// parseReference parses an image name of the form "<repository>[:<tag>][@<digest>]".
+// The digest takes precedence over the tag and the tag defaults to "latest".
+func (r *ProtocolOCI) parseReference(name string) (*ociReference, error) {
+	_, registryName, prefix, err := r.ociRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	repository, reference, hasDigest := strings.Cut(name, "@")
+	if hasDigest {
+		err := digest.Digest(reference).Validate()
+		if err != nil {
+			return nil, fmt.Errorf("Invalid image digest %q: %w", reference, err)
+		}
+	}
+
+	// Split the tag from the last path component.
+	idx := strings.LastIndex(repository, ":")
+	if idx > strings.LastIndex(repository, "/") {
+		if !hasDigest {
+			reference = repository[idx+1:]
+		}
+
+		repository = repository[:idx]
+	}
+
+	if reference == "" {
+		reference = "latest"
+	}
+
+	if prefix != "" {
+		repository = prefix + "/" + repository
+	}
+
+	// Official Docker Hub images live in the "library" namespace.
+	if registryName == "docker.io" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	if repository == "" || repository != strings.ToLower(repository) {
+		return nil, fmt.Errorf("Invalid image name %q", name)
+	}
+
+	return &ociReference{Repository: repository, Reference: reference}, nil
+}
+
+// ociDo performs a registry request, authenticating as requested by the registry.
+// The actions are those of the token scope requested for the repository, for example "pull".
+func (r *ProtocolOCI) ociDo(req *http.Request, repository string, actions string) (*http.Response, error) {
+	scope := fmt.Sprintf("repository:%s:%s", repository, actions)
+
+	// Registries commonly redirect blob downloads to storage services which reject the registry
+	// credentials. Go only forwards them on redirects to the same host, unlike our own redirect policy.
+	client := *r.http
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("Stopped after 10 redirects")
+		}
+
+		return nil
+	}
+
+	if r.httpUserAgent != "" {
+		req.Header.Set("User-Agent", r.httpUserAgent)
+	}
+
+	r.tokensLock.Lock()
+	auth, ok := r.tokens[scope]
+	r.tokensLock.Unlock()
+
+	if ok {
+		req.Header.Set("Authorization", auth)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	// Authenticate and retry.
+	challenge := resp.Header.Get("WWW-Authenticate")
+	_ = resp.Body.Close()
+
+	auth, err = r.ociAuthenticate(req.Context(), challenge, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	r.tokensLock.Lock()
+	r.tokens[scope] = auth
+	r.tokensLock.Unlock()
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	retry.Header.Set("Authorization", auth)
+
+	return client.Do(retry)
+}
+
+// ociAuthenticate answers a WWW-Authenticate challenge and returns the value of the Authorization header to use.
+// Bearer tokens are requested from the token service of the registry, with the remote credentials if any.
+func (r *ProtocolOCI) ociAuthenticate(ctx context.Context, challenge string, scope string) (string, error) {
+	u, err := url.Parse(r.httpHost)
+	if err != nil {
+		return "", err
+	}
+
+	scheme, params := parseAuthChallenge(challenge)
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if u.User == nil {
+			return "", errors.New("The registry requires credentials")
+		}
+
+		password, _ := u.User.Password()
+
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(u.User.Username()+":"+password)), nil
+	case "bearer":
+		realm, err := url.Parse(params["realm"])
+		if err != nil || realm.Host == "" {
+			return "", fmt.Errorf("Invalid authentication realm %q", params["realm"])
+		}
+
+		if params["scope"] != "" {
+			scope = params["scope"]
+		}
+
+		query := realm.Query()
+		query.Set("scope", scope)
+		if params["service"] != "" {
+			query.Set("service", params["service"])
+		}
+
+		realm.RawQuery = query.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+		if err != nil {
+			return "", err
+		}
+
+		if u.User != nil {
+			password, _ := u.User.Password()
+			req.SetBasicAuth(u.User.Username(), password)
+		}
+
+		resp, err := r.DoHTTP(req)
+		if err != nil {
+			return "", fmt.Errorf("Failed getting registry token: %w", err)
+		}
+
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("Failed getting registry token: %s", resp.Status)
+		}
+
+		var token struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+
+		err = json.NewDecoder(io.LimitReader(resp.Body, ociMaxDocumentSize)).Decode(&token)
+		if err != nil {
+			return "", fmt.Errorf("Failed parsing registry token: %w", err)
+		}
+
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+
+		if token.Token == "" {
+			return "", errors.New("The registry didn't return a token")
+		}
+
+		return "Bearer " + token.Token, nil
+	}
+
+	return "", fmt.Errorf("Unsupported registry authentication scheme %q", scheme)
+}
+
+// parseAuthChallenge parses a WWW-Authenticate header of the form `<scheme> <key>="<value>",...`.
+func parseAuthChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+
+	for rest != "" {
+		var key, value string
+
+		rest = strings.TrimLeft(rest, " ,")
+		key, rest, _ = strings.Cut(rest, "=")
+		if strings.HasPrefix(rest, `"`) {
+			// Quoted values may contain commas and escaped characters.
+			var b strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+
+				b.WriteByte(rest[i])
+			}
+
+			value = b.String()
+			rest = rest[min(i+1, len(rest)):]
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key != "" {
+			params[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return scheme, params
+}
+
+// ociResponseError returns an error describing a failed registry response.
+func ociResponseError(resp *http.Response) error {
+	var body struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	err := json.NewDecoder(io.LimitReader(resp.Body, ociMaxDocumentSize)).Decode(&body)
+	if err == nil && len(body.Errors) > 0 {
+		return fmt.Errorf("Registry returned %s: %s (%s)", resp.Status, body.Errors[0].Message, body.Errors[0].Code)
+	}
+
+	return fmt.Errorf("Registry returned %s", resp.Status)
+}
+
+// getManifest fetches a manifest or an index, verifying its digest if requested by digest.
+func (r *ProtocolOCI) getManifest(ctx context.Context, repository string, reference string) (string, []byte, error) {
+	registryURL, _, _, err := r.ociRegistry()
+	if err != nil {
+		return "", nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, registryURL.JoinPath("v2", repository, "manifests", reference).String(), nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	req.Header.Set("Accept", strings.Join([]string{
+		ociImage.MediaTypeImageIndex,
+		ociImage.MediaTypeImageManifest,
+		ociMediaTypeDockerManifestList,
+		ociMediaTypeDockerManifest,
+	}, ", "))
+
+	resp, err := r.ociDo(req, repository, "pull")
+	if err != nil {
+		return "", nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, ociResponseError(resp)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, ociMaxDocumentSize))
+	if err != nil {
+		return "", nil, err
+	}
+
+	dgst := digest.Digest(reference)
+	if dgst.Validate() == nil && dgst.Algorithm().FromBytes(data) != dgst {
+		return "", nil, fmt.Errorf("Manifest %q doesn't match its digest", reference)
+	}
+
+	// Fall back to the media type of the document itself.
+	mediaType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	var doc struct {
+		MediaType string `json:"mediaType"`
+	}
+
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed parsing manifest: %w", err)
+	}
+
+	if doc.MediaType != "" {
+		mediaType = doc.MediaType
+	}
+
+	return strings.TrimSpace(mediaType), data, nil
+}
+
+// selectManifest returns the manifest of an index matching the local architecture.
+// Manifests with the exact variant are preferred over those without variant.
+func selectManifest(index *ociImage.Index) (*ociImage.Descriptor, error) {
+	archID, err := osarch.ArchitectureGetLocalID()
+	if err != nil {
+		return nil, err
+	}
+
+	arch, variant, err := osarch.ArchitectureOCIPlatform(archID)
+	if err != nil {
+		return nil, err
+	}
+
+	var match *ociImage.Descriptor
+	for i, m := range index.Manifests {
+		if m.Platform == nil || m.Platform.OS != "linux" || m.Platform.Architecture != arch {
+			continue
+		}
+
+		if m.Platform.Variant == variant {
+			return &index.Manifests[i], nil
+		}
+
+		if m.Platform.Variant == "" && match == nil {
+			match = &index.Manifests[i]
+		}
+	}
+
+	if match == nil {
+		archName, _ := osarch.ArchitectureName(archID)
+		return nil, fmt.Errorf("The image isn't available for %q", archName)
+	}
+
+	return match, nil
+}
+
+// resolveManifest returns the image manifest for a reference, selecting the manifest of the local architecture from
+// multi-architecture images.
+func (r *ProtocolOCI) resolveManifest(ctx context.Context, ref *ociReference) (*ociImage.Manifest, error) {
+	mediaType, data, err := r.getManifest(ctx, ref.Repository, ref.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	if mediaType == ociImage.MediaTypeImageIndex || mediaType == ociMediaTypeDockerManifestList {
+		var index ociImage.Index
+		err = json.Unmarshal(data, &index)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing image index: %w", err)
+		}
+
+		desc, err := selectManifest(&index)
+		if err != nil {
+			return nil, err
+		}
+
+		mediaType, data, err = r.getManifest(ctx, ref.Repository, desc.Digest.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if mediaType != ociImage.MediaTypeImageManifest && mediaType != ociMediaTypeDockerManifest {
+		return nil, fmt.Errorf("Unsupported manifest type %q", mediaType)
+	}
+
+	var manifest ociImage.Manifest
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing image manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// getBlob fetches a small blob, such as an image config, into memory.
+func (r *ProtocolOCI) getBlob(ctx context.Context, repository string, desc ociImage.Descriptor) ([]byte, error) {
+	err := desc.Digest.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if desc.Size > ociMaxDocumentSize {
+		return nil, fmt.Errorf("Blob %q is too large", desc.Digest)
+	}
+
+	registryURL, _, _, err := r.ociRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, registryURL.JoinPath("v2", repository, "blobs", desc.Digest.String()).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.ociDo(req, repository, "pull")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ociResponseError(resp)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, ociMaxDocumentSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if desc.Digest.Algorithm().FromBytes(data) != desc.Digest {
+		return nil, fmt.Errorf("Blob %q doesn't match its digest", desc.Digest)
+	}
+
+	return data, nil
+}
+
+// ociBlobPath returns the path of a blob in an OCI image layout.
+func ociBlobPath(layoutPath string, dgst digest.Digest) string {
+	return filepath.Join(layoutPath, "blobs", dgst.Algorithm().String(), dgst.Encoded())
+}
+
+// downloadBlob downloads a blob into the image layout at layoutPath, unless it's already present.
+//
+// The data is written to a partial file which is only moved in place once its digest is verified.
+// Interrupted downloads are resumed from the partial file, including on later attempts.
+func (r *ProtocolOCI) downloadBlob(ctx context.Context, repository string, desc ociImage.Descriptor, layoutPath string, progress func(int64, int64)) error {
+	err := desc.Digest.Validate()
+	if err != nil {
+		return err
+	}
+
+	blobPath := ociBlobPath(layoutPath, desc.Digest)
+
+	lock, _ := ociBlobLocks.LoadOrStore(blobPath, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	// Reuse the blob if already present, refreshing its last use time.
+	_, err = os.Stat(blobPath)
+	if err == nil {
+		now := time.Now()
+		return os.Chtimes(blobPath, now, now)
+	}
+
+	err = os.MkdirAll(filepath.Dir(blobPath), 0o700)
+	if err != nil {
+		return err
+	}
+
+	partialPath := blobPath + ".partial"
+	for attempt := 1; ; attempt++ {
+		err = r.downloadBlobPartial(ctx, repository, desc, partialPath, progress)
+		if err == nil {
+			break
+		}
+
+		if attempt >= ociBlobRetries || ctx.Err() != nil {
+			return fmt.Errorf("Failed downloading blob %q: %w", desc.Digest, err)
+		}
+	}
+
+	return os.Rename(partialPath, blobPath)
+}
+
+// downloadBlobPartial downloads the remainder of a blob into partialPath and verifies it.
+func (r *ProtocolOCI) downloadBlobPartial(ctx context.Context, repository string, desc ociImage.Descriptor, partialPath string, progress func(int64, int64)) error {
+	f, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	// Hash the data already retrieved.
+	verifier := desc.Digest.Algorithm().Hash()
+	offset, err := io.Copy(verifier, f)
+	if err != nil {
+		return err
+	}
+
+	if offset >= desc.Size {
+		offset = 0
+	}
+
+	registryURL, _, _, err := r.ociRegistry()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, registryURL.JoinPath("v2", repository, "blobs", desc.Digest.String()).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := r.ociDo(req, repository, "pull")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if offset == 0 {
+			return errors.New("Unexpected partial content")
+		}
+	case http.StatusOK:
+		// The registry doesn't support ranges, start over.
+		offset = 0
+	default:
+		return ociResponseError(resp)
+	}
+
+	if offset == 0 {
+		verifier.Reset()
+
+		err = f.Truncate(0)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	var body io.ReadCloser = resp.Body
+	if progress != nil {
+		body = &ioprogress.ProgressReader{
+			ReadCloser: resp.Body,
+			Tracker: &ioprogress.ProgressTracker{
+				Length:  desc.Size - offset,
+				Handler: progress,
+			},
+		}
+	}
+
+	written, err := io.Copy(io.MultiWriter(f, verifier), io.LimitReader(body, desc.Size-offset))
+	if err != nil {
+		return err
+	}
+
+	if offset+written != desc.Size || digest.NewDigest(desc.Digest.Algorithm(), verifier) != desc.Digest {
+		// Start over on the next attempt.
+		_ = f.Truncate(0)
+		return errors.New("Downloaded data doesn't match the digest")
+	}
+
+	return f.Close()
+}
+
+// hasBlob returns whether the repository already has a blob.
+func (r *ProtocolOCI) hasBlob(ctx context.Context, repository string, dgst digest.Digest) (bool, error) {
+	registryURL, _, _, err := r.ociRegistry()
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, registryURL.JoinPath("v2", repository, "blobs", dgst.String()).String(), nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := r.ociDo(req, repository, "pull,push")
+	if err != nil {
+		return false, err
+	}
+
+	_ = resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+
+	return false, fmt.Errorf("Registry returned %s", resp.Status)
+}
+
+// uploadBlob uploads the blob stored at path into the repository, unless the repository already has it.
+func (r *ProtocolOCI) uploadBlob(ctx context.Context, repository string, desc ociImage.Descriptor, path string, progress func(int64, int64)) error {
+	exists, err := r.hasBlob(ctx, repository, desc.Digest)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	registryURL, _, _, err := r.ociRegistry()
+	if err != nil {
+		return err
+	}
+
+	// Start the upload.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, registryURL.JoinPath("v2", repository, "blobs", "uploads").String()+"/", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.ociDo(req, repository, "pull,push")
+	if err != nil {
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("Failed starting upload of blob %q: Registry returned %s", desc.Digest, resp.Status)
+	}
+
+	uploadURL, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("Invalid upload location: %w", err)
+	}
+
+	query := uploadURL.Query()
+	query.Set("digest", desc.Digest.String())
+	uploadURL.RawQuery = query.Encode()
+
+	// Upload the data in a single request.
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	var body io.ReadCloser = f
+	if progress != nil {
+		body = &ioprogress.ProgressReader{
+			ReadCloser: f,
+			Tracker: &ioprogress.ProgressTracker{
+				Length:  desc.Size,
+				Handler: progress,
+			},
+		}
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodPut, uploadURL.String(), body)
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = desc.Size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}
+
+	resp, err = r.ociDo(req, repository, "pull,push")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		return ociResponseError(resp)
+	}
+
+	return nil
+}
+
+// putManifest uploads a manifest into the repository under the given tag or digest.
+func (r *ProtocolOCI) putManifest(ctx context.Context, repository string, reference string, mediaType string, data []byte) error {
+	registryURL, _, _, err := r.ociRegistry()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, registryURL.JoinPath("v2", repository, "manifests", reference).String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", mediaType)
+
+	resp, err := r.ociDo(req, repository, "pull,push")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		return ociResponseError(resp)
+	}
+
+	return nil
+}
+
+// pruneBlobs removes the blobs of the image layout at layoutPath which weren't used recently.
+func pruneBlobs(layoutPath string) error {
+	return filepath.WalkDir(filepath.Join(layoutPath, "blobs"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if time.Since(info.ModTime()) < ociBlobExpiry {
+			return nil
+		}
+
+		// Skip the blobs being downloaded.
+		lock, _ := ociBlobLocks.LoadOrStore(strings.TrimSuffix(path, ".partial"), &sync.Mutex{})
+		if !lock.(*sync.Mutex).TryLock() {
+			return nil
+		}
+
+		defer lock.(*sync.Mutex).Unlock()
+
+		return os.Remove(path)
+	})
+}
@@ -0,0 +1,425 @@
+package incus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ociImage "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v7/shared/osarch"
+)
+
+// newTestOCI returns a registry client for a test server.
+func newTestOCI(srv *httptest.Server) *ProtocolOCI {
+	return &ProtocolOCI{
+		http:     srv.Client(),
+		httpHost: srv.URL,
+		cache:    map[string]ociInfo{},
+		errors:   map[string]error{},
+		tokens:   map[string]string{},
+	}
+}
+
+// localOCIPlatform returns the OCI architecture and variant of the local machine.
+func localOCIPlatform(t *testing.T) (string, string) {
+	archID, err := osarch.ArchitectureGetLocalID()
+	require.NoError(t, err)
+
+	arch, variant, err := osarch.ArchitectureOCIPlatform(archID)
+	require.NoError(t, err)
+
+	return arch, variant
+}
+
+func TestParseAuthChallenge(t *testing.T) {
+	tests := []struct {
+		challenge string
+		scheme    string
+		params    map[string]string
+	}{
+		{
+			challenge: `Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/alpine:pull"`,
+			scheme:    "Bearer",
+			params:    map[string]string{"realm": "https://auth.docker.io/token", "service": "registry.docker.io", "scope": "repository:library/alpine:pull"},
+		},
+		{
+			challenge: `Basic realm="Registry Realm"`,
+			scheme:    "Basic",
+			params:    map[string]string{"realm": "Registry Realm"},
+		},
+		{
+			challenge: `Bearer realm="https://ghcr.io/token", Scope="repository:user/image:pull,push", error="a \"quoted\" value"`,
+			scheme:    "Bearer",
+			params:    map[string]string{"realm": "https://ghcr.io/token", "scope": "repository:user/image:pull,push", "error": `a "quoted" value`},
+		},
+		{
+			challenge: `Bearer realm=https://registry.example.net/token, service=registry`,
+			scheme:    "Bearer",
+			params:    map[string]string{"realm": "https://registry.example.net/token", "service": "registry"},
+		},
+		{
+			challenge: `Bearer realm="unterminated`,
+			scheme:    "Bearer",
+			params:    map[string]string{"realm": "unterminated"},
+		},
+		{
+			challenge: "Bearer",
+			scheme:    "Bearer",
+			params:    map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.challenge, func(t *testing.T) {
+			scheme, params := parseAuthChallenge(tt.challenge)
+			assert.Equal(t, tt.scheme, scheme)
+			assert.Equal(t, tt.params, params)
+		})
+	}
+}
+
+func TestSelectManifest(t *testing.T) {
+	arch, variant := localOCIPlatform(t)
+
+	otherArch := "s390x"
+	if arch == otherArch {
+		otherArch = "ppc64le"
+	}
+
+	descriptor := func(os string, arch string, variant string) ociImage.Descriptor {
+		return ociImage.Descriptor{
+			Digest:   digest.FromString(os + arch + variant),
+			Platform: &ociImage.Platform{OS: os, Architecture: arch, Variant: variant},
+		}
+	}
+
+	// Only Linux manifests of the local architecture are considered.
+	index := &ociImage.Index{Manifests: []ociImage.Descriptor{
+		{Digest: digest.FromString("attestation")},
+		descriptor("linux", otherArch, ""),
+		descriptor("windows", arch, variant),
+		descriptor("linux", arch, variant),
+	}}
+
+	desc, err := selectManifest(index)
+	require.NoError(t, err)
+	assert.Equal(t, index.Manifests[3].Digest, desc.Digest)
+
+	// The exact variant is preferred, then no variant, and other variants never match.
+	index = &ociImage.Index{Manifests: []ociImage.Descriptor{
+		descriptor("linux", arch, "v0"),
+		descriptor("linux", arch, ""),
+		descriptor("linux", arch, variant),
+	}}
+
+	desc, err = selectManifest(index)
+	require.NoError(t, err)
+	if variant == "" {
+		assert.Equal(t, index.Manifests[1].Digest, desc.Digest)
+	} else {
+		assert.Equal(t, index.Manifests[2].Digest, desc.Digest)
+	}
+
+	index = &ociImage.Index{Manifests: []ociImage.Descriptor{
+		descriptor("linux", arch, "v0"),
+		descriptor("linux", arch, ""),
+	}}
+
+	desc, err = selectManifest(index)
+	require.NoError(t, err)
+	assert.Equal(t, index.Manifests[1].Digest, desc.Digest)
+
+	// Missing architecture.
+	_, err = selectManifest(&ociImage.Index{Manifests: []ociImage.Descriptor{descriptor("linux", otherArch, ""), descriptor("linux", arch, "v0")}})
+	assert.Error(t, err)
+}
+
+// Test retrieving a Docker image through a registry requiring a token, and its conversion to an OCI image layout.
+func TestWriteImageLayoutDocker(t *testing.T) {
+	arch, variant := localOCIPlatform(t)
+
+	layer := []byte("layer data")
+	layerDigest := digest.FromBytes(layer)
+
+	config, err := json.Marshal(ociImage.Image{Platform: ociImage.Platform{OS: "linux", Architecture: arch, Variant: variant}})
+	require.NoError(t, err)
+
+	configDigest := digest.FromBytes(config)
+
+	manifest, err := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     ociMediaTypeDockerManifest,
+		"config":        map[string]any{"mediaType": "application/vnd.docker.container.image.v1+json", "digest": configDigest, "size": len(config)},
+		"layers":        []map[string]any{{"mediaType": ociMediaTypeDockerLayerGzip, "digest": layerDigest, "size": len(layer)}},
+	})
+	require.NoError(t, err)
+
+	manifestDigest := digest.FromBytes(manifest)
+
+	list, err := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     ociMediaTypeDockerManifestList,
+		"manifests": []map[string]any{{
+			"mediaType": ociMediaTypeDockerManifest,
+			"digest":    manifestDigest,
+			"size":      len(manifest),
+			"platform":  map[string]string{"os": "linux", "architecture": arch, "variant": variant},
+		}},
+	})
+	require.NoError(t, err)
+
+	var tokenRequests atomic.Int64
+
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests.Add(1)
+
+		if r.URL.Query().Get("scope") != "repository:test/image:pull" || r.URL.Query().Get("service") != "registry.test" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"access_token": "secret"}`))
+	})
+
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry.test"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/test/image/manifests/latest":
+			w.Header().Set("Content-Type", ociMediaTypeDockerManifestList)
+			_, _ = w.Write(list)
+		case "/v2/test/image/manifests/" + manifestDigest.String():
+			w.Header().Set("Content-Type", ociMediaTypeDockerManifest)
+			_, _ = w.Write(manifest)
+		case "/v2/test/image/blobs/" + configDigest.String():
+			_, _ = w.Write(config)
+		case "/v2/test/image/blobs/" + layerDigest.String():
+			_, _ = w.Write(layer)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	r := newTestOCI(srv)
+	r.cachePath = t.TempDir()
+
+	info, err := r.inspectImage(context.Background(), "test/image")
+	require.NoError(t, err)
+	assert.Equal(t, arch, info.Architecture)
+	assert.Equal(t, "test/image", info.Repository)
+
+	layoutPath := t.TempDir()
+	err = r.writeImageLayout(context.Background(), info, layoutPath, "latest", nil)
+	require.NoError(t, err)
+
+	// A single token was requested for the repository.
+	assert.Equal(t, int64(1), tokenRequests.Load())
+
+	// The layout has an OCI manifest.
+	var index ociImage.Index
+	data, err := os.ReadFile(filepath.Join(layoutPath, ociImage.ImageIndexFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &index))
+	require.Len(t, index.Manifests, 1)
+	assert.Equal(t, "latest", index.Manifests[0].Annotations[ociImage.AnnotationRefName])
+
+	var layoutManifest ociImage.Manifest
+	data, err = os.ReadFile(ociBlobPath(layoutPath, index.Manifests[0].Digest))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &layoutManifest))
+	assert.Equal(t, ociImage.MediaTypeImageManifest, layoutManifest.MediaType)
+	assert.Equal(t, ociImage.MediaTypeImageConfig, layoutManifest.Config.MediaType)
+	assert.Equal(t, configDigest, layoutManifest.Config.Digest)
+	require.Len(t, layoutManifest.Layers, 1)
+	assert.Equal(t, ociImage.Descriptor{MediaType: ociImage.MediaTypeImageLayerGzip, Digest: layerDigest, Size: int64(len(layer))}, layoutManifest.Layers[0])
+
+	// The layer is linked from the blob store of the cache.
+	data, err = os.ReadFile(ociBlobPath(layoutPath, layerDigest))
+	require.NoError(t, err)
+	assert.Equal(t, layer, data)
+
+	_, err = os.Stat(ociBlobPath(filepath.Join(r.cachePath, "oci"), layerDigest))
+	assert.NoError(t, err)
+
+	data, err = os.ReadFile(ociBlobPath(layoutPath, configDigest))
+	require.NoError(t, err)
+	assert.Equal(t, config, data)
+
+	// Concurrent requests share the token.
+	wg := sync.WaitGroup{}
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			data, err := r.getBlob(context.Background(), "test/image", ociImage.Descriptor{Digest: configDigest, Size: int64(len(config))})
+			assert.NoError(t, err)
+			assert.Equal(t, config, data)
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, int64(1), tokenRequests.Load())
+}
+
+// Test resuming blob downloads, from registries supporting ranges or not.
+func TestDownloadBlobPartial(t *testing.T) {
+	blob := make([]byte, 64*1024)
+	_, err := rand.Read(blob)
+	require.NoError(t, err)
+
+	desc := ociImage.Descriptor{Digest: digest.FromBytes(blob), Size: int64(len(blob))}
+
+	for _, ranges := range []bool{true, false} {
+		t.Run(fmt.Sprintf("ranges=%v", ranges), func(t *testing.T) {
+			var gotRanges []string
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v2/test/image/blobs/"+desc.Digest.String() {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				gotRanges = append(gotRanges, r.Header.Get("Range"))
+
+				var offset int
+				_, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &offset)
+				if !ranges || err != nil {
+					_, _ = w.Write(blob)
+					return
+				}
+
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(blob)-1, len(blob)))
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write(blob[offset:])
+			}))
+
+			defer srv.Close()
+
+			r := newTestOCI(srv)
+			partialPath := filepath.Join(t.TempDir(), "blob.partial")
+
+			// Resume an interrupted download.
+			err := os.WriteFile(partialPath, blob[:1000], 0o600)
+			require.NoError(t, err)
+
+			err = r.downloadBlobPartial(context.Background(), "test/image", desc, partialPath, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"bytes=1000-"}, gotRanges)
+
+			data, err := os.ReadFile(partialPath)
+			require.NoError(t, err)
+			assert.Equal(t, blob, data)
+
+			// Corrupted partial data is detected when resuming and the next attempt starts over.
+			corrupted := append([]byte{}, blob[:1000]...)
+			corrupted[0]++
+
+			err = os.WriteFile(partialPath, corrupted, 0o600)
+			require.NoError(t, err)
+
+			gotRanges = nil
+			err = r.downloadBlobPartial(context.Background(), "test/image", desc, partialPath, nil)
+			if ranges {
+				require.Error(t, err)
+
+				data, err = os.ReadFile(partialPath)
+				require.NoError(t, err)
+				assert.Empty(t, data)
+
+				err = r.downloadBlobPartial(context.Background(), "test/image", desc, partialPath, nil)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"bytes=1000-", ""}, gotRanges)
+			} else {
+				// Registries ignoring the range send the whole blob again.
+				require.NoError(t, err)
+			}
+
+			data, err = os.ReadFile(partialPath)
+			require.NoError(t, err)
+			assert.Equal(t, blob, data)
+
+			// The whole blob gets stored through downloadBlob, retrying as needed.
+			layoutPath := t.TempDir()
+			err = os.MkdirAll(filepath.Dir(ociBlobPath(layoutPath, desc.Digest)), 0o700)
+			require.NoError(t, err)
+
+			err = os.WriteFile(ociBlobPath(layoutPath, desc.Digest)+".partial", corrupted, 0o600)
+			require.NoError(t, err)
+
+			err = r.downloadBlob(context.Background(), "test/image", desc, layoutPath, nil)
+			require.NoError(t, err)
+
+			data, err = os.ReadFile(ociBlobPath(layoutPath, desc.Digest))
+			require.NoError(t, err)
+			assert.Equal(t, blob, data)
+
+			_, err = os.Stat(ociBlobPath(layoutPath, desc.Digest) + ".partial")
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestPruneBlobs(t *testing.T) {
+	layoutPath := t.TempDir()
+	blobsPath := filepath.Join(layoutPath, "blobs", "sha256")
+	require.NoError(t, os.MkdirAll(blobsPath, 0o700))
+
+	old := time.Now().Add(-ociBlobExpiry - time.Hour)
+	for name, mtime := range map[string]time.Time{"old": old, "recent": time.Now(), "downloading.partial": old} {
+		path := filepath.Join(blobsPath, name)
+		require.NoError(t, os.WriteFile(path, []byte(name), 0o600))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	// Hold the lock of the blob being downloaded.
+	lock, _ := ociBlobLocks.LoadOrStore(filepath.Join(blobsPath, "downloading"), &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+
+	err := pruneBlobs(layoutPath)
+	lock.(*sync.Mutex).Unlock()
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(blobsPath)
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	assert.Equal(t, []string{"downloading.partial", "recent"}, names)
+
+	// Stale partial downloads are removed too.
+	require.NoError(t, pruneBlobs(layoutPath))
+
+	_, err = os.Stat(filepath.Join(blobsPath, "downloading.partial"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Layouts without blobs are fine.
+	assert.NoError(t, pruneBlobs(t.TempDir()))
+	assert.NoError(t, pruneBlobs(filepath.Join(t.TempDir(), "missing")))
+}
@@ -28,6 +28,8 @@ var api10 = []APIEndpoint{
 	api10Cmd,
 	execCmd,
 	eventsCmd,
+	freezeCmd,
+	inventoryCmd,
 	metricsCmd,
 	operationsCmd,
 	operationCmd,
@@ -35,6 +37,7 @@ var api10 = []APIEndpoint{
 	operationWait,
 	sftpCmd,
 	stateCmd,
+	thawCmd,
 }
 
 func api10Get(d *Daemon, r *http.Request) response.Response {
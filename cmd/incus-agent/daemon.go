@@ -2,6 +2,7 @@ package main
 
 import (
 	"sync"
+	"time"
 
 	"github.com/lxc/incus/v7/internal/server/events"
 )
@@ -28,6 +29,12 @@ type Daemon struct {
 	DevIncusRunning bool
 	DevIncusMu      sync.Mutex
 	DevIncusEnabled bool
+
+	// File system freeze state.
+	freezeMu          sync.Mutex
+	freezeMountpoints []string
+	freezeTimer       *time.Timer
+	freezeExpired     bool
 }
 
 // newDaemon returns a new Daemon object with the given configuration.
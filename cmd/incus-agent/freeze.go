@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/server/response"
+	agentAPI "github.com/lxc/incus/v7/shared/api/agent"
+	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/subprocess"
+)
+
+// freezeDefaultTimeout is the time after which frozen file systems are automatically thawed if not requested otherwise.
+const freezeDefaultTimeout = 5 * time.Minute
+
+var freezeCmd = APIEndpoint{
+	Name: "freeze",
+	Path: "freeze",
+
+	Post: APIEndpointAction{Handler: freezePost},
+}
+
+var thawCmd = APIEndpoint{
+	Name: "thaw",
+	Path: "thaw",
+
+	Post: APIEndpointAction{Handler: thawPost},
+}
+
+func freezePost(d *Daemon, r *http.Request) response.Response {
+	if d.Features != nil && !d.Features["freeze"] {
+		return response.Forbidden(errors.New("File system freezing is disabled by configuration"))
+	}
+
+	if !osFreezeSupported {
+		return response.NotImplemented(nil)
+	}
+
+	req := agentAPI.FreezePost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	timeout := freezeDefaultTimeout
+	if req.Timeout > 0 {
+		timeout = time.Duration(req.Timeout) * time.Second
+	}
+
+	d.freezeMu.Lock()
+	defer d.freezeMu.Unlock()
+
+	if d.freezeTimer != nil {
+		return response.Conflict(errors.New("File systems are already frozen"))
+	}
+
+	// Run the pre-freeze hooks, undoing those which already ran on failure.
+	hooks, err := freezeHooks()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	for i, hook := range hooks {
+		err = freezeRunHook(r.Context(), hook, "freeze")
+		if err != nil {
+			freezeRunThawHooks(hooks[:i])
+			return response.SmartError(err)
+		}
+	}
+
+	// Freeze the file systems.
+	mountpoints, err := osFreezeFilesystems()
+	if err != nil {
+		freezeRunThawHooks(hooks)
+		return response.SmartError(err)
+	}
+
+	logger.Info("Froze file systems", logger.Ctx{"mountpoints": mountpoints, "timeout": timeout})
+
+	// Make sure the guest doesn't stay frozen if the host never comes back.
+	var timer *time.Timer
+	timer = time.AfterFunc(timeout, func() {
+		d.freezeMu.Lock()
+		defer d.freezeMu.Unlock()
+
+		// Skip if thawed (and possibly frozen again) in the mean time.
+		if d.freezeTimer != timer {
+			return
+		}
+
+		logger.Warn("File systems were frozen for too long, thawing", logger.Ctx{"timeout": timeout})
+		_ = thaw(d)
+		d.freezeExpired = true
+	})
+
+	d.freezeMountpoints = mountpoints
+	d.freezeTimer = timer
+	d.freezeExpired = false
+
+	return response.EmptySyncResponse
+}
+
+func thawPost(d *Daemon, r *http.Request) response.Response {
+	if d.Features != nil && !d.Features["freeze"] {
+		return response.Forbidden(errors.New("File system freezing is disabled by configuration"))
+	}
+
+	d.freezeMu.Lock()
+	defer d.freezeMu.Unlock()
+
+	if d.freezeTimer == nil {
+		// Let the host know that whatever it did while the file systems were meant to be frozen may not be consistent.
+		if d.freezeExpired {
+			d.freezeExpired = false
+			return response.Conflict(errors.New("File systems were automatically thawed"))
+		}
+
+		return response.BadRequest(errors.New("File systems aren't frozen"))
+	}
+
+	d.freezeTimer.Stop()
+
+	err := thaw(d)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// thaw thaws the file systems and runs the post-thaw hooks. Must be called with freezeMu held.
+func thaw(d *Daemon) error {
+	if d.freezeTimer == nil {
+		return nil
+	}
+
+	err := osThawFilesystems(d.freezeMountpoints)
+	if err != nil {
+		logger.Error("Failed thawing file systems", logger.Ctx{"err": err})
+	} else {
+		logger.Info("Thawed file systems", logger.Ctx{"mountpoints": d.freezeMountpoints})
+	}
+
+	d.freezeMountpoints = nil
+	d.freezeTimer = nil
+
+	hooks, hookErr := freezeHooks()
+	if hookErr != nil {
+		logger.Error("Failed listing freeze hooks", logger.Ctx{"err": hookErr})
+	} else {
+		freezeRunThawHooks(hooks)
+	}
+
+	return err
+}
+
+// freezeHooks returns the executable hooks found in the freeze hooks directory, in the order they must be
+// run before freezing.
+func freezeHooks() ([]string, error) {
+	entries, err := os.ReadDir(osFreezeHooksPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Failed to read freeze hooks directory: %w", err)
+	}
+
+	hooks := []string{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+
+		hooks = append(hooks, filepath.Join(osFreezeHooksPath, entry.Name()))
+	}
+
+	return hooks, nil
+}
+
+// freezeRunHook runs a single freeze hook with the given action ("freeze" or "thaw").
+func freezeRunHook(ctx context.Context, hook string, action string) error {
+	output, err := subprocess.RunCommandContext(ctx, hook, action)
+	if err != nil {
+		return fmt.Errorf("Failed running %s hook %q: %w", action, hook, err)
+	}
+
+	logger.Debug("Ran freeze hook", logger.Ctx{"hook": hook, "action": action, "output": output})
+
+	return nil
+}
+
+// freezeRunThawHooks runs the given hooks with the "thaw" action in reverse order, logging any failure.
+func freezeRunThawHooks(hooks []string) {
+	for _, hook := range slices.Backward(hooks) {
+		err := freezeRunHook(context.Background(), hook, "thaw")
+		if err != nil {
+			logger.Error("Failed running thaw hook", logger.Ctx{"hook": hook, "err": err})
+		}
+	}
+}
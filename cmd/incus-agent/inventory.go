@@ -0,0 +1,28 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/lxc/incus/v7/internal/server/response"
+)
+
+var inventoryCmd = APIEndpoint{
+	Name: "inventory",
+	Path: "inventory",
+
+	Get: APIEndpointAction{Handler: inventoryGet},
+}
+
+func inventoryGet(d *Daemon, r *http.Request) response.Response {
+	if d.Features != nil && !d.Features["inventory"] {
+		return response.Forbidden(errors.New("Guest inventory reporting is disabled by configuration"))
+	}
+
+	inventory, err := osGetInventory(r.Context())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, inventory)
+}
@@ -4,10 +4,12 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"strconv"
@@ -174,3 +176,7 @@ func osGetListener(port int64) (net.Listener, error) {
 
 	return l, nil
 }
+
+func osGetInventory(_ context.Context) (*api.InstanceInventory, error) {
+	return nil, api.StatusErrorf(http.StatusNotImplemented, "Guest inventory isn't supported on this operating system")
+}
@@ -25,6 +25,8 @@ var (
 	osShutdownSignal   = os.Interrupt
 	osMetricsSupported = true
 	osGuestAPISupport  = false
+	osFreezeSupported  = false
+	osFreezeHooksPath  = ""
 )
 
 func osLoadModules() error {
@@ -271,3 +273,13 @@ func osReconfigureNetworkInterfaces() {
 func osExecWrapper(ctx context.Context, pty io.ReadWriteCloser) io.ReadWriteCloser {
 	return pty
 }
+
+func osFreezeFilesystems() ([]string, error) {
+	// File system freezing isn't currently supported.
+	return nil, nil
+}
+
+func osThawFilesystems(mountpoints []string) error {
+	// File system freezing isn't currently supported.
+	return nil
+}
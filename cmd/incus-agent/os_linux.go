@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/mdlayher/vsock"
+	"github.com/shirou/gopsutil/v4/host"
 	"github.com/shirou/gopsutil/v4/process"
 	"golang.org/x/sys/unix"
 
@@ -51,10 +52,21 @@ var (
 	osBaseWorkingDirectory = "/"
 	osMetricsSupported     = true
 	osGuestAPISupport      = true
+	osFreezeSupported      = true
+	osFreezeHooksPath      = "/etc/incus-agent/freeze.d"
 	osAgentConfigPath      = "/etc/incus-agent.yml"
 	osVioSerialPath        = "/dev/virtio-ports/org.linuxcontainers.incus"
 )
 
+// osMountPathUnescaper reverts the escaping of mount paths in /proc/self/mounts.
+var osMountPathUnescaper = strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+
+// File system freeze ioctls (_IOWR('X', 119, int) and _IOWR('X', 120, int)).
+const (
+	osFIFREEZE = 0xC0045877
+	osFITHAW   = 0xC0045878
+)
+
 func runService(name string, agentCmd *cmdAgent) error {
 	return errors.New("Not implemented.")
 }
@@ -893,3 +905,292 @@ func osSetEnv(post *api.InstanceExecPost, env map[string]string) {
 		}
 	}
 }
+
+func osFreezeFilesystems() ([]string, error) {
+	mounts, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read /proc/self/mounts: %w", err)
+	}
+
+	mountpoints := osFreezeMountpoints(mounts)
+
+	// Freeze in reverse mount order so that nested mounts are frozen before their parents.
+	frozen := []string{}
+	for _, mountpoint := range slices.Backward(mountpoints) {
+		err := osFreezeFilesystem(mountpoint, osFIFREEZE)
+		if err != nil {
+			// Skip file systems which don't support freezing.
+			if errors.Is(err, unix.EOPNOTSUPP) {
+				continue
+			}
+
+			_ = osThawFilesystems(frozen)
+			return nil, fmt.Errorf("Failed to freeze %q: %w", mountpoint, err)
+		}
+
+		frozen = append(frozen, mountpoint)
+	}
+
+	return frozen, nil
+}
+
+// osFreezeMountpoints returns the mount points to freeze from the content of /proc/self/mounts, in mount order.
+// Only writable file systems backed by a block device are considered, each of them only once.
+func osFreezeMountpoints(mounts []byte) []string {
+	mountpoints := []string{}
+	devices := map[string]bool{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(mounts))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+
+		if !strings.HasPrefix(fields[0], "/dev/") || devices[fields[0]] || slices.Contains(strings.Split(fields[3], ","), "ro") {
+			continue
+		}
+
+		devices[fields[0]] = true
+		mountpoints = append(mountpoints, osMountPathUnescaper.Replace(fields[1]))
+	}
+
+	return mountpoints
+}
+
+func osThawFilesystems(mountpoints []string) error {
+	var errs []error
+
+	// Thaw in the reverse order of freezing.
+	for _, mountpoint := range slices.Backward(mountpoints) {
+		err := osFreezeFilesystem(mountpoint, osFITHAW)
+		if err != nil && !errors.Is(err, unix.EINVAL) {
+			errs = append(errs, fmt.Errorf("Failed to thaw %q: %w", mountpoint, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// osFreezeFilesystem runs a freeze or thaw ioctl against a mount point.
+func osFreezeFilesystem(mountpoint string, request uint) error {
+	f, err := os.Open(mountpoint)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	return unix.IoctlSetInt(int(f.Fd()), request, 0)
+}
+
+func osGetInventory(ctx context.Context) (*api.InstanceInventory, error) {
+	inventory := &api.InstanceInventory{
+		OSRelease: map[string]string{},
+		Packages:  []api.InstanceInventoryPackage{},
+		Users:     []api.InstanceInventoryUser{},
+		Services:  []api.InstanceInventoryService{},
+	}
+
+	// Get the OS release.
+	osRelease, err := osarch.GetOSRelease()
+	if err == nil {
+		inventory.OSRelease = osRelease
+	}
+
+	// Get the packages from all the package managers found in the guest.
+	for _, getPackages := range []func(ctx context.Context) ([]api.InstanceInventoryPackage, error){osGetDpkgPackages, osGetRpmPackages, osGetApkPackages} {
+		packages, err := getPackages(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		inventory.Packages = append(inventory.Packages, packages...)
+	}
+
+	// Get the logged-in users.
+	users, err := host.UsersWithContext(ctx)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("Failed to get logged-in users: %w", err)
+	}
+
+	for _, user := range users {
+		inventory.Users = append(inventory.Users, api.InstanceInventoryUser{
+			Name:      user.User,
+			Terminal:  user.Terminal,
+			Host:      user.Host,
+			StartedAt: time.Unix(int64(user.Started), 0).UTC(),
+		})
+	}
+
+	// Get the services.
+	inventory.Services, err = osGetSystemdServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return inventory, nil
+}
+
+// osGetDpkgPackages returns the packages installed through dpkg.
+func osGetDpkgPackages(_ context.Context) ([]api.InstanceInventoryPackage, error) {
+	content, err := os.ReadFile("/var/lib/dpkg/status")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Failed to read dpkg status: %w", err)
+	}
+
+	return osParseDpkgStatus(string(content)), nil
+}
+
+// osParseDpkgStatus returns the installed packages listed in a dpkg status file.
+func osParseDpkgStatus(content string) []api.InstanceInventoryPackage {
+	packages := []api.InstanceInventoryPackage{}
+
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		fields := map[string]string{}
+		for _, line := range strings.Split(paragraph, "\n") {
+			key, value, found := strings.Cut(line, ": ")
+			if !found || strings.HasPrefix(key, " ") {
+				continue
+			}
+
+			fields[key] = value
+		}
+
+		if fields["Package"] == "" || !strings.HasSuffix(fields["Status"], " installed") {
+			continue
+		}
+
+		packages = append(packages, api.InstanceInventoryPackage{
+			Name:         fields["Package"],
+			Version:      fields["Version"],
+			Architecture: fields["Architecture"],
+			Source:       "dpkg",
+		})
+	}
+
+	return packages
+}
+
+// osGetRpmPackages returns the packages installed through rpm.
+func osGetRpmPackages(ctx context.Context) ([]api.InstanceInventoryPackage, error) {
+	_, err := exec.LookPath("rpm")
+	if err != nil {
+		return nil, nil
+	}
+
+	output, err := subprocess.RunCommandContext(ctx, "rpm", "-qa", "--queryformat", `%{NAME}\t%|EPOCH?{%{EPOCH}:}:{}|%{VERSION}-%{RELEASE}\t%{ARCH}\n`)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list rpm packages: %w", err)
+	}
+
+	return osParseRpmPackages(output), nil
+}
+
+// osParseRpmPackages returns the packages listed by rpm, one tab-separated name, version and architecture per line.
+func osParseRpmPackages(output string) []api.InstanceInventoryPackage {
+	packages := []api.InstanceInventoryPackage{}
+
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || fields[0] == "gpg-pubkey" {
+			continue
+		}
+
+		packages = append(packages, api.InstanceInventoryPackage{
+			Name:         fields[0],
+			Version:      fields[1],
+			Architecture: fields[2],
+			Source:       "rpm",
+		})
+	}
+
+	return packages
+}
+
+// osGetApkPackages returns the packages installed through apk.
+func osGetApkPackages(_ context.Context) ([]api.InstanceInventoryPackage, error) {
+	content, err := os.ReadFile("/lib/apk/db/installed")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Failed to read apk database: %w", err)
+	}
+
+	return osParseApkInstalled(string(content)), nil
+}
+
+// osParseApkInstalled returns the packages listed in an apk installed database.
+func osParseApkInstalled(content string) []api.InstanceInventoryPackage {
+	packages := []api.InstanceInventoryPackage{}
+
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		pkg := api.InstanceInventoryPackage{Source: "apk"}
+
+		for _, line := range strings.Split(paragraph, "\n") {
+			key, value, found := strings.Cut(line, ":")
+			if !found {
+				continue
+			}
+
+			switch key {
+			case "P":
+				pkg.Name = value
+			case "V":
+				pkg.Version = value
+			case "A":
+				pkg.Architecture = value
+			}
+		}
+
+		if pkg.Name == "" {
+			continue
+		}
+
+		packages = append(packages, pkg)
+	}
+
+	return packages
+}
+
+// osGetSystemdServices returns the services known to systemd.
+func osGetSystemdServices(ctx context.Context) ([]api.InstanceInventoryService, error) {
+	_, err := exec.LookPath("systemctl")
+	if err != nil {
+		return []api.InstanceInventoryService{}, nil
+	}
+
+	output, err := subprocess.RunCommandContext(ctx, "systemctl", "list-units", "--type=service", "--all", "--plain", "--no-legend", "--no-pager")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list services: %w", err)
+	}
+
+	return osParseSystemdServices(output), nil
+}
+
+// osParseSystemdServices returns the loaded services listed by systemctl list-units.
+func osParseSystemdServices(output string) []api.InstanceInventoryService {
+	services := []api.InstanceInventoryService{}
+
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		// Format is: UNIT LOAD ACTIVE SUB DESCRIPTION.
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[1] != "loaded" {
+			continue
+		}
+
+		services = append(services, api.InstanceInventoryService{
+			Name:        fields[0],
+			Description: strings.Join(fields[4:], " "),
+			State:       fields[3],
+		})
+	}
+
+	return services
+}
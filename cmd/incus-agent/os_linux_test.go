@@ -0,0 +1,152 @@
+//go:build linux
+
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+func TestOSFreezeMountpoints(t *testing.T) {
+	mounts := `sysfs /sys sysfs rw,nosuid,nodev,noexec,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+/dev/sda2 / ext4 rw,relatime 0 0
+tmpfs /run tmpfs rw,nosuid,nodev,size=402020k,mode=755 0 0
+/dev/sda1 /boot/efi vfat rw,relatime,fmask=0077,dmask=0077 0 0
+/dev/sdb1 /srv/my\040data xfs rw,relatime 0 0
+/dev/sdb1 /var/lib/bind xfs rw,relatime 0 0
+/dev/sdc1 /mnt/backup ext4 ro,relatime 0 0
+/dev/sdd1 /mnt/tab\011and\134slash ext4 rw 0 0
+/dev/loop0 /snap/core/1 squashfs ro,nodev,relatime 0 0
+lxd_agent /run/incus_agent virtiofs rw,relatime 0 0
+/dev/sde1 /short
+`
+
+	got := osFreezeMountpoints([]byte(mounts))
+	want := []string{"/", "/boot/efi", "/srv/my data", "/mnt/tab\tand\\slash"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Expected %q, got %q", want, got)
+	}
+
+	if len(osFreezeMountpoints(nil)) != 0 {
+		t.Fatal("Unexpected mount points without any mount")
+	}
+}
+
+func TestOSParseDpkgStatus(t *testing.T) {
+	status := `Package: openssl
+Status: install ok installed
+Priority: optional
+Architecture: amd64
+Version: 3.0.14-1~deb12u2
+Description: Secure Sockets Layer toolkit
+ This package contains the openssl binary.
+ Version: not a field
+
+Package: removed-pkg
+Status: deinstall ok config-files
+Architecture: amd64
+Version: 1.0-1
+
+Package: bash
+Status: install ok installed
+Architecture: amd64
+Version: 5.2.15-2+b7
+
+Status: install ok installed
+Version: 1.0
+`
+
+	got := osParseDpkgStatus(status)
+	want := []api.InstanceInventoryPackage{
+		{Name: "openssl", Version: "3.0.14-1~deb12u2", Architecture: "amd64", Source: "dpkg"},
+		{Name: "bash", Version: "5.2.15-2+b7", Architecture: "amd64", Source: "dpkg"},
+	}
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+
+	if len(osParseDpkgStatus("")) != 0 {
+		t.Fatal("Unexpected packages in an empty status file")
+	}
+}
+
+func TestOSParseRpmPackages(t *testing.T) {
+	output := "bash\t5.2.26-3.fc40\tx86_64\n" +
+		"openssl\t1:3.2.2-3.fc40\tx86_64\n" +
+		"gpg-pubkey\ta15b79cc-63d04c2c\t(none)\n" +
+		"broken line\n"
+
+	got := osParseRpmPackages(output)
+	want := []api.InstanceInventoryPackage{
+		{Name: "bash", Version: "5.2.26-3.fc40", Architecture: "x86_64", Source: "rpm"},
+		{Name: "openssl", Version: "1:3.2.2-3.fc40", Architecture: "x86_64", Source: "rpm"},
+	}
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+
+	if len(osParseRpmPackages("")) != 0 {
+		t.Fatal("Unexpected packages without any output")
+	}
+}
+
+func TestOSParseApkInstalled(t *testing.T) {
+	installed := `C:Q1abcdef=
+P:musl
+V:1.2.5-r0
+A:x86_64
+S:407278
+T:the musl c library (libc) implementation
+F:lib
+R:ld-musl-x86_64.so.1
+
+C:Q1123456=
+P:busybox
+V:1.36.1-r29
+A:x86_64
+
+C:Q1nopackage=
+V:1.0-r0
+`
+
+	got := osParseApkInstalled(installed)
+	want := []api.InstanceInventoryPackage{
+		{Name: "musl", Version: "1.2.5-r0", Architecture: "x86_64", Source: "apk"},
+		{Name: "busybox", Version: "1.36.1-r29", Architecture: "x86_64", Source: "apk"},
+	}
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestOSParseSystemdServices(t *testing.T) {
+	output := `cron.service                 loaded    active   running Regular background program processing daemon
+incus-agent.service          loaded    active   running Incus - agent
+ssh.service                  loaded    failed   failed  OpenBSD Secure Shell server
+auditd.service               not-found inactive dead    auditd.service
+systemd-fsck-root.service    loaded    inactive dead
+short.service                loaded
+`
+
+	got := osParseSystemdServices(output)
+	want := []api.InstanceInventoryService{
+		{Name: "cron.service", Description: "Regular background program processing daemon", State: "running"},
+		{Name: "incus-agent.service", Description: "Incus - agent", State: "running"},
+		{Name: "ssh.service", Description: "OpenBSD Secure Shell server", State: "failed"},
+		{Name: "systemd-fsck-root.service", Description: "", State: "dead"},
+	}
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+
+	if len(osParseSystemdServices("")) != 0 {
+		t.Fatal("Unexpected services without any output")
+	}
+}
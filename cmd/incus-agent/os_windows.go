@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unsafe"
 
 	"github.com/FuturFusion/vsock"
 	"github.com/shirou/gopsutil/v4/disk"
@@ -22,6 +23,7 @@ import (
 	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/debug"
 	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
 
 	"github.com/lxc/incus/v7/internal/ports"
 	"github.com/lxc/incus/v7/internal/server/metrics"
@@ -310,3 +312,195 @@ func osSetEnv(post *api.InstanceExecPost, env map[string]string) {
 		post.Cwd = system32
 	}
 }
+
+func osGetInventory(ctx context.Context) (*api.InstanceInventory, error) {
+	inventory := &api.InstanceInventory{
+		OSRelease: map[string]string{},
+		Packages:  []api.InstanceInventoryPackage{},
+		Users:     []api.InstanceInventoryUser{},
+		Services:  []api.InstanceInventoryService{},
+	}
+
+	// Get the OS release from the registry.
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows NT\CurrentVersion`, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open the Windows version registry key: %w", err)
+	}
+
+	for _, name := range []string{"ProductName", "EditionID", "DisplayVersion", "CurrentVersion", "CurrentBuild", "InstallationType"} {
+		value, _, err := k.GetStringValue(name)
+		if err == nil {
+			inventory.OSRelease[name] = value
+		}
+	}
+
+	k.Close()
+
+	// Get the installed programs, both native and 32-bit ones.
+	for path, architecture := range map[string]string{
+		`SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`:             runtime.GOARCH,
+		`SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall`: "386",
+	} {
+		packages, err := osGetRegistryPackages(path, architecture)
+		if err != nil {
+			return nil, err
+		}
+
+		inventory.Packages = append(inventory.Packages, packages...)
+	}
+
+	// Get the logged-in users.
+	inventory.Users, err = osGetSessionUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the services.
+	inventory.Services, err = osGetServices()
+	if err != nil {
+		return nil, err
+	}
+
+	return inventory, nil
+}
+
+// osGetRegistryPackages returns the programs registered under the given uninstall registry key.
+func osGetRegistryPackages(path string, architecture string) ([]api.InstanceInventoryPackage, error) {
+	packages := []api.InstanceInventoryPackage{}
+
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return packages, nil
+		}
+
+		return nil, fmt.Errorf("Failed to open registry key %q: %w", path, err)
+	}
+
+	defer k.Close()
+
+	names, err := k.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list registry key %q: %w", path, err)
+	}
+
+	for _, name := range names {
+		sk, err := registry.OpenKey(k, name, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+
+		displayName, _, err := sk.GetStringValue("DisplayName")
+		if err != nil || displayName == "" {
+			sk.Close()
+			continue
+		}
+
+		displayVersion, _, _ := sk.GetStringValue("DisplayVersion")
+		sk.Close()
+
+		packages = append(packages, api.InstanceInventoryPackage{
+			Name:         displayName,
+			Version:      displayVersion,
+			Architecture: architecture,
+			Source:       "registry",
+		})
+	}
+
+	return packages, nil
+}
+
+// osGetSessionUsers returns the users logged into a Windows session.
+func osGetSessionUsers() ([]api.InstanceInventoryUser, error) {
+	users := []api.InstanceInventoryUser{}
+
+	var sessions *windows.WTS_SESSION_INFO
+	var count uint32
+
+	err := windows.WTSEnumerateSessions(0, 0, 1, &sessions, &count)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list sessions: %w", err)
+	}
+
+	defer windows.WTSFreeMemory(uintptr(unsafe.Pointer(sessions)))
+
+	for _, session := range unsafe.Slice(sessions, count) {
+		var token windows.Token
+
+		// Sessions without a user (services, listeners) don't have a token.
+		err := windows.WTSQueryUserToken(session.SessionID, &token)
+		if err != nil {
+			continue
+		}
+
+		tokenUser, err := token.GetTokenUser()
+		token.Close()
+		if err != nil {
+			continue
+		}
+
+		account, domain, _, err := tokenUser.User.Sid.LookupAccount("")
+		if err != nil {
+			continue
+		}
+
+		users = append(users, api.InstanceInventoryUser{
+			Name:     fmt.Sprintf(`%s\%s`, domain, account),
+			Terminal: windows.UTF16PtrToString(session.WindowStationName),
+		})
+	}
+
+	return users, nil
+}
+
+// osGetServices returns the services known to the service control manager.
+func osGetServices() ([]api.InstanceInventoryService, error) {
+	services := []api.InstanceInventoryService{}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to the service manager: %w", err)
+	}
+
+	defer func() { _ = m.Disconnect() }()
+
+	names, err := m.ListServices()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list services: %w", err)
+	}
+
+	states := map[svc.State]string{
+		svc.Stopped:         "stopped",
+		svc.StartPending:    "start-pending",
+		svc.StopPending:     "stop-pending",
+		svc.Running:         "running",
+		svc.ContinuePending: "continue-pending",
+		svc.PausePending:    "pause-pending",
+		svc.Paused:          "paused",
+	}
+
+	for _, name := range names {
+		s, err := m.OpenService(name)
+		if err != nil {
+			continue
+		}
+
+		service := api.InstanceInventoryService{Name: name}
+
+		config, err := s.Config()
+		if err == nil {
+			service.Description = config.DisplayName
+		}
+
+		status, err := s.Query()
+		if err == nil {
+			service.State = states[status.State]
+		}
+
+		_ = s.Close()
+
+		services = append(services, service)
+	}
+
+	return services, nil
+}
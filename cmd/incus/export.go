@@ -28,6 +28,7 @@ type cmdExport struct {
 	flagOptimizedStorage     bool
 	flagCompressionAlgorithm string
 	flagForce                bool
+	flagSince                string
 }
 
 var cmdExportUsage = u.Usage{u.Instance.Remote(), u.Target(u.File).Optional()}
@@ -43,7 +44,10 @@ func (c *cmdExport) command() *cobra.Command {
 	Download a backup tarball of the u1 instance.
 
 incus export u1 -
-	Download a backup tarball with it written to the standard output.`))
+	Download a backup tarball with it written to the standard output.
+
+incus export v1 v1-delta0.tar.gz --since bitmap0
+	Download an incremental backup of the v1 virtual machine containing only the blocks changed since bitmap0.`))
 
 	cmd.RunE = c.run
 	cli.AddBoolFlag(cmd.Flags(), &c.flagInstanceOnly, "instance-only", i18n.G("Whether or not to only backup the instance (without snapshots)"))
@@ -51,6 +55,7 @@ incus export u1 -
 	cli.AddBoolFlag(cmd.Flags(), &c.flagOptimizedStorage, "optimized-storage", i18n.G("Use storage driver optimized format (can only be restored on a similar pool)"))
 	cli.AddStringFlag(cmd.Flags(), &c.flagCompressionAlgorithm, "compression", "", "", i18n.G("Compression algorithm to use (none for uncompressed)"))
 	cli.AddBoolFlag(cmd.Flags(), &c.flagForce, "force|f", i18n.G("Force overwriting existing backup file"))
+	cli.AddStringFlag(cmd.Flags(), &c.flagSince, "since", "", "", i18n.G("Only export the blocks changed since the given dirty bitmap (incremental backup)"))
 
 	return cmd
 }
@@ -83,6 +88,7 @@ func (c *cmdExport) run(cmd *cobra.Command, args []string) error {
 		RootOnly:             c.flagRootOnly,
 		OptimizedStorage:     c.flagOptimizedStorage,
 		CompressionAlgorithm: c.flagCompressionAlgorithm,
+		Since:                c.flagSince,
 	}
 
 	var getter func(backupReq *incus.BackupFileRequest) error
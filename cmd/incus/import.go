@@ -33,7 +33,10 @@ func (c *cmdImport) command() *cobra.Command {
 		`Import backups of instances including their snapshots.`))
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`incus import backup0.tar.gz
-    Create a new instance using backup0.tar.gz as the source.`))
+    Create a new instance using backup0.tar.gz as the source.
+
+incus import v1-delta0.tar.gz v1
+    Apply the incremental backup v1-delta0.tar.gz on top of the existing (stopped) v1 instance.`))
 
 	cmd.RunE = c.run
 	cli.AddStringFlag(cmd.Flags(), &c.flagStorage, "storage|s", "", "", i18n.G("Storage pool name"))
@@ -29,6 +29,7 @@ type cmdInfo struct {
 	flagShowLog    string
 	flagResources  bool
 	flagTarget     string
+	flagInventory  bool
 }
 
 var cmdInfoUsage = u.Usage{u.Instance.Optional().Remote()}
@@ -43,11 +44,15 @@ func (c *cmdInfo) command() *cobra.Command {
 		`incus info [<remote>:]<instance> [--show-log]
     For instance information.
 
+incus info [<remote>:]<instance> --inventory
+    For the software inventory of a virtual machine.
+
 incus info [<remote>:] [--resources]
     For server information.`))
 
 	cmd.RunE = c.run
 	cli.AddBoolFlag(cmd.Flags(), &c.flagShowAccess, "show-access", i18n.G("Show the instance's access list"))
+	cli.AddBoolFlag(cmd.Flags(), &c.flagInventory, "inventory", i18n.G("Show the software inventory of the instance (packages, users and services)"))
 	cli.AddStringFlag(cmd.Flags(), &c.flagShowLog, "show-log", "", "default", i18n.G("Show the instance's recent log entries"))
 	cli.AddBoolFlag(cmd.Flags(), &c.flagResources, "resources", i18n.G("Show the resources available to the server"))
 	cli.AddStringFlag(cmd.Flags(), &c.flagTarget, "target", "", "", i18n.G("Cluster member name"))
@@ -93,6 +98,22 @@ func (c *cmdInfo) run(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if c.flagInventory {
+		inventory, err := d.GetInstanceInventory(instanceName)
+		if err != nil {
+			return err
+		}
+
+		data, err := yaml.Dump(inventory, yaml.V2)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s", data)
+
+		return nil
+	}
+
 	return c.instanceInfo(d, instanceName, c.flagShowLog)
 }
 
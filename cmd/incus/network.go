@@ -10,6 +10,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"go.yaml.in/yaml/v4"
@@ -50,6 +51,10 @@ func (c *cmdNetwork) command() *cobra.Command {
 	networkAttachProfileCmd := cmdNetworkAttachProfile{global: c.global, network: c}
 	cmd.AddCommand(networkAttachProfileCmd.command())
 
+	// BGP
+	networkBGPCmd := cmdNetworkBGP{global: c.global, network: c}
+	cmd.AddCommand(networkBGPCmd.command())
+
 	// Create
 	networkCreateCmd := cmdNetworkCreate{global: c.global, network: c}
 	cmd.AddCommand(networkCreateCmd.command())
@@ -320,6 +325,126 @@ func (c *cmdNetworkAttachProfile) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// BGP.
+type cmdNetworkBGP struct {
+	global  *cmdGlobal
+	network *cmdNetwork
+}
+
+var cmdNetworkBGPUsage = u.Usage{u.Network.Remote()}
+
+func (c *cmdNetworkBGP) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("bgp", cmdNetworkBGPUsage...)
+	cmd.Short = i18n.G("Show the BGP state of networks")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G(
+		`Show the BGP state of networks
+
+Shows the state of the BGP sessions with the peers used by the network
+and the prefixes which are advertised for it by the server.`))
+
+	cli.AddStringFlag(cmd.Flags(), &c.network.flagTarget, "target", "", "", i18n.G("Cluster member name"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return c.global.cmpNetworks(toComplete)
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkBGP) run(cmd *cobra.Command, args []string) error {
+	parsed, err := cmdNetworkBGPUsage.Parse(c.global.conf, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+
+	// Targeting.
+	if c.network.flagTarget != "" {
+		if !d.IsClustered() {
+			return errors.New(i18n.G("To use --target, the destination remote must be a cluster"))
+		}
+
+		d = d.UseTarget(c.network.flagTarget)
+	}
+
+	state, err := d.GetNetworkBGPState(networkName)
+	if err != nil {
+		return err
+	}
+
+	// Peers.
+	fmt.Println(i18n.G("Peers:"))
+
+	peerData := [][]string{}
+	for _, peer := range state.Peers {
+		asn := ""
+		if peer.ASN > 0 {
+			asn = fmt.Sprintf("%d", peer.ASN)
+		}
+
+		uptime := ""
+		if peer.State == "established" {
+			uptime = (time.Duration(peer.Uptime) * time.Second).String()
+		}
+
+		lastError := peer.LastError
+		if lastError != "" && peer.LastErrorAt != nil {
+			lastError = fmt.Sprintf("%s (%s)", lastError, peer.LastErrorAt.Local().Format(dateLayout))
+		}
+
+		peerData = append(peerData, []string{peer.Name, peer.Address, asn, peer.State, uptime, fmt.Sprintf("%d", peer.ReceivedPrefixes), fmt.Sprintf("%d", peer.AdvertisedPrefixes), peer.BFDState, lastError})
+	}
+
+	peerHeader := []string{
+		i18n.G("NAME"),
+		i18n.G("ADDRESS"),
+		i18n.G("ASN"),
+		i18n.G("STATE"),
+		i18n.G("UPTIME"),
+		i18n.G("RECEIVED"),
+		i18n.G("ADVERTISED"),
+		i18n.G("BFD"),
+		i18n.G("LAST ERROR"),
+	}
+
+	err = cli.RenderTable(os.Stdout, cli.TableFormatTable, peerHeader, peerData, state.Peers)
+	if err != nil {
+		return err
+	}
+
+	// Prefixes.
+	fmt.Println("")
+	fmt.Println(i18n.G("Advertised prefixes:"))
+
+	prefixData := [][]string{}
+	for _, prefix := range state.Prefixes {
+		source := prefix.Source
+		if prefix.Instance != "" {
+			source = fmt.Sprintf("%s (%s)", source, prefix.Instance)
+		}
+
+		prefixData = append(prefixData, []string{prefix.Prefix, prefix.Nexthop, source})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(prefixData))
+
+	prefixHeader := []string{
+		i18n.G("PREFIX"),
+		i18n.G("NEXT-HOP"),
+		i18n.G("SOURCE"),
+	}
+
+	return cli.RenderTable(os.Stdout, cli.TableFormatTable, prefixHeader, prefixData, state.Prefixes)
+}
+
 // Create.
 type cmdNetworkCreate struct {
 	global  *cmdGlobal
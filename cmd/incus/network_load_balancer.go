@@ -1131,6 +1131,8 @@ func (c *cmdNetworkLoadBalancerInfo) command() *cobra.Command {
 	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Get current load-balancer status"))
 	cmd.RunE = c.run
 
+	cli.AddStringFlag(cmd.Flags(), &c.networkLoadBalancer.flagTarget, "target", "", "", i18n.G("Cluster member name"))
+
 	return cmd
 }
 
@@ -1144,6 +1146,11 @@ func (c *cmdNetworkLoadBalancerInfo) run(cmd *cobra.Command, args []string) erro
 	networkName := parsed[0].RemoteObject.String
 	listenAddress := parsed[1].String
 
+	// If a target was specified, get the state as seen by that member.
+	if c.networkLoadBalancer.flagTarget != "" {
+		d = d.UseTarget(c.networkLoadBalancer.flagTarget)
+	}
+
 	// Get the load-balancer state.
 	lbState, err := d.GetNetworkLoadBalancerState(networkName, listenAddress)
 	if err != nil {
@@ -3,6 +3,8 @@ package main
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -31,11 +33,20 @@ type cmdPublish struct {
 
 var cmdPublishUsage = u.Usage{u.MakePath(u.Instance, u.Snapshot.Optional()).Remote(), u.RemoteColonOpt, u.LegacyKV.List(0)}
 
+// OCI registries aren't instance servers, so they can't be parsed as a remote of the publish target.
+var cmdPublishOCIUsage = u.Usage{u.MakePath(u.Instance, u.Snapshot.Optional()).Remote(), u.LegacyKV.List(0)}
+
 func (c *cmdPublish) command() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Use = cli.U("publish", cmdPublishUsage...)
 	cmd.Short = i18n.G("Publish instances as images")
-	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G(`Publish instances as images`))
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G(`Publish instances as images
+
+Application containers can also be published to an OCI registry remote,
+in which case the image names (repository and tag) are set with --alias.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus publish c1 ghcr: --alias org/app:1.0
+    Publish the application container "c1" to the "ghcr" OCI registry remote as "org/app:1.0".`))
 
 	cmd.RunE = c.run
 	cli.AddBoolFlag(cmd.Flags(), &c.flagMakePublic, "public", i18n.G("Make the image public"))
@@ -62,7 +73,22 @@ func (c *cmdPublish) command() *cobra.Command {
 }
 
 func (c *cmdPublish) run(cmd *cobra.Command, args []string) error {
-	parsed, err := cmdPublishUsage.Parse(c.global.conf, cmd, args)
+	// Check whether publishing to an OCI registry.
+	ociRemote := ""
+	if len(args) > 1 {
+		remoteName, rest, err := c.global.conf.ParseRemote(args[1])
+		if err == nil && rest == "" && c.global.conf.Remotes[remoteName].Protocol == "oci" {
+			ociRemote = remoteName
+			args = slices.Delete(slices.Clone(args), 1, 2)
+		}
+	}
+
+	usage := cmdPublishUsage
+	if ociRemote != "" {
+		usage = cmdPublishOCIUsage
+	}
+
+	parsed, err := usage.Parse(c.global.conf, cmd, args)
 	if err != nil {
 		return err
 	}
@@ -70,12 +96,28 @@ func (c *cmdPublish) run(cmd *cobra.Command, args []string) error {
 	srcServer := parsed[0].RemoteServer
 	isSnapshot := !parsed[0].RemoteObject.List[1].Skipped
 	objectName := parsed[0].RemoteObject.String
-	dstServer := parsed[1].RemoteServer
-	keys, err := kvToMap(parsed[2])
+	keys, err := kvToMap(parsed[len(parsed)-1])
 	if err != nil {
 		return err
 	}
 
+	var dstServer incus.InstanceServer
+	if ociRemote == "" {
+		dstServer = parsed[1].RemoteServer
+	} else {
+		if !srcServer.HasExtension("instance_publish_oci") {
+			return errors.New(i18n.G("The server doesn't support publishing instances to OCI registries"))
+		}
+
+		if len(c.flagAliases) == 0 {
+			return errors.New(i18n.G("The image name must be provided with --alias when publishing to an OCI registry"))
+		}
+
+		if len(keys) > 0 {
+			return errors.New(i18n.G("Image properties can't be set when publishing to an OCI registry"))
+		}
+	}
+
 	if !isSnapshot {
 		inst, etag, err := srcServer.GetInstance(objectName)
 		if err != nil {
@@ -153,6 +195,10 @@ func (c *cmdPublish) run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if ociRemote != "" {
+		return c.publishOCI(srcServer, objectName, isSnapshot, ociRemote)
+	}
+
 	// Reformat aliases
 	aliases := []api.ImageAlias{}
 	for _, entry := range c.flagAliases {
@@ -290,3 +336,84 @@ func (c *cmdPublish) run(cmd *cobra.Command, args []string) error {
 	fmt.Printf(i18n.G("Instance published with fingerprint: %s")+"\n", fingerprint)
 	return nil
 }
+
+// publishOCI publishes an application container to an OCI registry remote, under the names given as aliases.
+func (c *cmdPublish) publishOCI(srcServer incus.InstanceServer, objectName string, isSnapshot bool, remoteName string) error {
+	registry, err := c.global.conf.GetImageServer(remoteName)
+	if err != nil {
+		return err
+	}
+
+	info, err := registry.GetConnectionInfo()
+	if err != nil {
+		return err
+	}
+
+	// Pass the credentials of the remote separately from its URL.
+	registryURL, err := url.Parse(info.URL)
+	if err != nil {
+		return errors.New(i18n.G("Invalid registry URL"))
+	}
+
+	target := &api.ImagesPostTarget{
+		Certificate: info.Certificate,
+	}
+
+	if registryURL.User != nil {
+		target.Username = registryURL.User.Username()
+		target.Password, _ = registryURL.User.Password()
+		registryURL.User = nil
+	}
+
+	target.Server = registryURL.String()
+
+	req := api.ImagesPost{
+		Source: &api.ImagesPostSource{
+			Type: "instance",
+			Name: objectName,
+		},
+		Format: "oci",
+		Target: target,
+	}
+
+	if isSnapshot {
+		req.Source.Type = "snapshot"
+	}
+
+	for _, entry := range c.flagAliases {
+		req.Aliases = append(req.Aliases, api.ImageAlias{Name: entry})
+	}
+
+	op, err := srcServer.CreateImage(req, nil)
+	if err != nil {
+		return err
+	}
+
+	// Watch the background operation
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Publishing instance: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	digest, ok := op.Get().Metadata["digest"].(string)
+	if !ok {
+		return errors.New("Bad digest")
+	}
+
+	fmt.Printf(i18n.G("Instance published with digest: %s")+"\n", digest)
+	return nil
+}
@@ -1323,6 +1323,22 @@ func (c *cmdStorageVolumeInfo) run(cmd *cobra.Command, args []string) error {
 		fmt.Printf(i18n.G("Created: %s")+"\n", vol.CreatedAt.Local().Format(dateLayout))
 	}
 
+	// Show the replication state
+	if volState != nil && volState.Replication != nil {
+		fmt.Println("\n" + i18n.G("Replication:"))
+		fmt.Printf("  "+i18n.G("Target: %s")+"\n", volState.Replication.Target)
+
+		if volState.Replication.LastSuccess != nil {
+			fmt.Printf("  "+i18n.G("Last success: %s")+"\n", volState.Replication.LastSuccess.Local().Format(dateLayout))
+			fmt.Printf("  "+i18n.G("Last snapshot: %s")+"\n", volState.Replication.LastSnapshot)
+			fmt.Printf("  "+i18n.G("Lag: %s")+"\n", time.Duration(volState.Replication.Lag)*time.Second)
+		}
+
+		if volState.Replication.LastError != "" {
+			fmt.Printf("  "+i18n.G("Last error: %s")+"\n", volState.Replication.LastError)
+		}
+	}
+
 	// List snapshots
 	firstSnapshot := true
 	if len(volSnapshots) > 0 {
@@ -3633,6 +3649,7 @@ type cmdStorageVolumeExport struct {
 	flagOptimizedStorage     bool
 	flagCompressionAlgorithm string
 	flagForce                bool
+	flagSince                string
 }
 
 var cmdStorageVolumeExportUsage = u.Usage{u.Pool.Remote(), u.Volume, u.Target(u.File).Optional()}
@@ -3648,6 +3665,7 @@ func (c *cmdStorageVolumeExport) command() *cobra.Command {
 	cli.AddStringFlag(cmd.Flags(), &c.flagCompressionAlgorithm, "compression", "", "", i18n.G("Compression algorithm to use (none for uncompressed, ignored for ISO storage volumes)"))
 	cli.AddStringFlag(cmd.Flags(), &c.storage.flagTarget, "target", "", "", i18n.G("Cluster member name"))
 	cli.AddBoolFlag(cmd.Flags(), &c.flagForce, "force|f", i18n.G("Force overwriting existing backup file"))
+	cli.AddStringFlag(cmd.Flags(), &c.flagSince, "since", "", "", i18n.G("Only export the blocks changed since the given dirty bitmap (incremental backup)"))
 	cmd.RunE = c.run
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -3709,6 +3727,7 @@ func (c *cmdStorageVolumeExport) run(cmd *cobra.Command, args []string) error {
 		VolumeOnly:           volumeOnly,
 		OptimizedStorage:     c.flagOptimizedStorage,
 		CompressionAlgorithm: c.flagCompressionAlgorithm,
+		Since:                c.flagSince,
 	}
 
 	var getter func(backupReq *incus.BackupFileRequest) error
@@ -3853,7 +3872,10 @@ func (c *cmdStorageVolumeImport) command() *cobra.Command {
     Create a new custom volume using backup0.tar.gz as the source
 
 incus storage volume import default some-installer.iso installer --type=iso
-    Create a new custom volume storing some-installer.iso for use as a CD-ROM image`))
+    Create a new custom volume storing some-installer.iso for use as a CD-ROM image
+
+incus storage volume import default data-delta0.tar.gz data
+    Apply the incremental backup data-delta0.tar.gz on top of the existing data custom volume`))
 	cli.AddStringFlag(cmd.Flags(), &c.storage.flagTarget, "target", "", "", i18n.G("Cluster member name"))
 	cmd.RunE = c.run
 	cli.AddStringFlag(cmd.Flags(), &c.flagType, "type|t", "", "", i18n.G("Import type, backup or iso (default \"backup\")"))
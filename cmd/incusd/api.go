@@ -392,7 +392,7 @@ func serveLocalBucket(d *Daemon, w http.ResponseWriter, r *http.Request, bucket
 		}
 	}()
 
-	srv := local.NewServer(bucketDir, creds)
+	srv := newLocalBucketServer(bucketDir, bucket.Config, creds)
 
 	// Migrate any data left over from the legacy minio layout, but only
 	// once the request has cleared authentication. This is a no-op once
@@ -72,6 +72,8 @@ var api10 = []APIEndpoint{
 	instanceSnapshotsCmd,
 	instanceStateCmd,
 	instanceAccessCmd,
+	instanceAccessCredentialsCmd,
+	instanceInventoryCmd,
 	instanceDebugMemoryCmd,
 	instanceDebugRepairCmd,
 	eventsCmd,
@@ -84,6 +86,7 @@ var api10 = []APIEndpoint{
 	imageSecretCmd,
 	metadataConfigurationCmd,
 	networkCmd,
+	networkBGPCmd,
 	networkLeasesCmd,
 	networksCmd,
 	networkStateCmd,
@@ -1058,6 +1061,15 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	// Compile and load the instance validation scriptlet.
+	value, ok = clusterChanged["instances.validation.scriptlet"]
+	if ok {
+		err := scriptletLoad.InstanceValidationSet(value)
+		if err != nil {
+			return fmt.Errorf("Failed saving instance validation scriptlet: %w", err)
+		}
+	}
+
 	// Setup the authorization scriptlet.
 	value, ok = clusterChanged["authorization.scriptlet"]
 	if ok {
@@ -1959,6 +1959,53 @@ func clusterValidateConfig(config map[string]string) error {
 		//  defaultdesc: `all`
 		//  shortdesc: Controls how instances are scheduled to run on this member
 		"scheduler.instance": validate.Optional(validate.IsOneOf("all", "group", "manual")),
+
+		// gendoc:generate(entity=cluster, group=cluster, key=maintenance.schedule)
+		// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-and-space-separated list of cron expressions or leave empty to use the maintenance windows of the member's cluster groups.
+		// See {ref}`cluster-maintenance-windows` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Schedule for the start of the member's maintenance windows
+		"maintenance.schedule": validate.Optional(validate.IsCron(nil)),
+
+		// gendoc:generate(entity=cluster, group=cluster, key=maintenance.duration)
+		// Length of each maintenance window (for example `2h` or `90m`).
+		// ---
+		//  type: string
+		//  defaultdesc: `1h`
+		//  shortdesc: Length of the member's maintenance windows
+		"maintenance.duration": validate.Optional(validate.IsMinimumDuration(time.Minute)),
+
+		// gendoc:generate(entity=cluster, group=cluster, key=maintenance.mode)
+		// Evacuation mode used when the maintenance window starts.
+		// Possible values are the same as for the {config:option}`instance-miscellaneous:cluster.evacuate` instance option.
+		// ---
+		//  type: string
+		//  defaultdesc: `auto`
+		//  shortdesc: Evacuation mode used for the member's maintenance windows
+		"maintenance.mode": internalInstance.InstanceConfigKeysAny["cluster.evacuate"],
+
+		// gendoc:generate(entity=cluster, group=cluster, key=volatile.maintenance.window)
+		// Start of the maintenance window for which the member was automatically evacuated (as a Unix timestamp).
+		// ---
+		//  type: string
+		//  shortdesc: Current maintenance window
+		"volatile.maintenance.window": validate.Optional(validate.IsInt64),
+
+		// gendoc:generate(entity=cluster, group=cluster, key=ceph.client_addresses)
+		// Comma-separated list of IP addresses or subnets the member uses to access Ceph.
+		// They are added to the Ceph OSD blocklist when fencing the member, see {ref}`cluster-healing-fencing`.
+		// ---
+		//  type: string
+		//  shortdesc: Addresses used by the member to access Ceph
+		"ceph.client_addresses": validate.Optional(validate.IsListOf(validate.Or(validate.IsNetworkAddress, validate.IsNetwork))),
+
+		// gendoc:generate(entity=cluster, group=cluster, key=volatile.healing.fenced)
+		// Comma-separated list of the storage pools the member was fenced from when healing it.
+		// ---
+		//  type: string
+		//  shortdesc: Storage pools the member is fenced from
+		"volatile.healing.fenced": validate.IsAny,
 	}
 
 	for k, v := range config {
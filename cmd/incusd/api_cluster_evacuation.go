@@ -44,6 +44,9 @@ type (
 	evacuateMigrateFunc func(ctx context.Context, s *state.State, inst instance.Instance, sourceMemberInfo *db.NodeInfo, targetMemberInfo *db.NodeInfo, live bool, startInstance bool, op *operations.Operation) error
 )
 
+// errEvacuateAntiAffinity is returned when the placement anti-affinity rules of an instance leave no cluster member to move it to.
+var errEvacuateAntiAffinity = errors.New("No cluster member satisfies the placement anti-affinity rules")
+
 type evacuateOpts struct {
 	s               *state.State
 	instances       []instance.Instance
@@ -264,24 +267,44 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 		return errors.New("Missing migration callback function")
 	}
 
+	// Healing restarts the instances in order of priority.
+	if opts.mode == "heal" {
+		return healInstances(ctx, opts)
+	}
+
+	err := evacuateInstancesParallel(ctx, opts.instances, opts, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to evacuate instances: %w", err)
+	}
+
+	return nil
+}
+
+// evacuateInstancesParallel evacuates the given instances, limiting the number of evacuations running at the same time.
+// If set, the after function is called once an instance has been evacuated.
+func evacuateInstancesParallel(ctx context.Context, instances []instance.Instance, opts evacuateOpts, after func(ctx context.Context, inst instance.Instance)) error {
 	// Limit the number of concurrent evacuations to run at the same time
 	numParallelEvacs := max(runtime.NumCPU()/16, 1)
 
 	group, groupCtx := errgroup.WithContext(ctx)
 	group.SetLimit(numParallelEvacs)
 
-	for _, inst := range opts.instances {
+	for _, inst := range instances {
 		group.Go(func() error {
-			return evacuateInstancesFunc(groupCtx, inst, opts)
-		})
-	}
+			err := evacuateInstancesFunc(groupCtx, inst, opts)
+			if err != nil {
+				return err
+			}
 
-	err := group.Wait()
-	if err != nil {
-		return fmt.Errorf("Failed to evacuate instances: %w", err)
+			if after != nil {
+				after(groupCtx, inst)
+			}
+
+			return nil
+		})
 	}
 
-	return nil
+	return group.Wait()
 }
 
 func evacuateInstancesFunc(ctx context.Context, inst instance.Instance, opts evacuateOpts) error {
@@ -332,6 +355,19 @@ func evacuateInstancesFunc(ctx context.Context, inst instance.Instance, opts eva
 	// Find a new location for the instance.
 	sourceMemberInfo, targetMemberInfo, err := evacuateClusterSelectTarget(ctx, opts.s, inst)
 	if err != nil {
+		if errors.Is(err, errEvacuateAntiAffinity) {
+			// Fall back to stopping the instance, keeping it on the evacuated member.
+			l.Warn("No migration target satisfies the placement anti-affinity rules, stopping instance")
+
+			if opts.mode != "heal" && opts.stopInstance != nil && inst.IsRunning() {
+				_ = opts.op.ExtendMetadata(map[string]any{"evacuation_progress": fmt.Sprintf("Stopping %q in project %q", inst.Name(), instProject.Name)})
+
+				return opts.stopInstance(inst, "stop")
+			}
+
+			return nil
+		}
+
 		if api.StatusErrorCheck(err, http.StatusNotFound) {
 			// Skip migration if no target is available.
 			l.Warn("No migration target available for instance")
@@ -688,6 +724,18 @@ func evacuateClusterSelectTarget(ctx context.Context, s *state.State, inst insta
 			return err
 		}
 
+		// Apply the placement groups of the instance.
+		if len(candidateMembers) > 0 {
+			candidateMembers, err = instance.PlacementFilterMembers(ctx, tx, inst.Project().Name, inst.Name(), inst.ExpandedConfig(), candidateMembers, false)
+			if err != nil {
+				return err
+			}
+
+			if len(candidateMembers) == 0 {
+				return fmt.Errorf("Instance %q in project %q: %w", inst.Name(), inst.Project().Name, errEvacuateAntiAffinity)
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -750,7 +798,7 @@ func evacuateClusterSelectTarget(ctx context.Context, s *state.State, inst insta
 func autoHealClusterTask(d *Daemon) (task.Func, task.Schedule) {
 	f := func(ctx context.Context) {
 		s := d.State()
-		healingThreshold := s.GlobalConfig.ClusterHealingThreshold()
+		healingThreshold := clusterHealingThreshold(s)
 		if healingThreshold == 0 {
 			return // Skip healing if it's disabled.
 		}
@@ -786,6 +834,16 @@ func autoHealClusterTask(d *Daemon) (task.Func, task.Schedule) {
 			}
 
 			for _, member := range members {
+				// Lift the storage fencing of members which are back online.
+				if member.Config["volatile.healing.fenced"] != "" && !member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+					err := unfenceClusterMember(ctx, s, member)
+					if err != nil {
+						logger.Error("Failed unfencing cluster member", logger.Ctx{"server": member.Name, "err": err})
+					}
+
+					continue
+				}
+
 				// Ignore members which have been evacuated, and those which haven't exceeded the
 				// healing offline trigger threshold.
 				if member.State == db.ClusterMemberStateEvacuated || !member.IsOffline(healingThreshold) {
@@ -812,7 +870,13 @@ func autoHealClusterTask(d *Daemon) (task.Func, task.Schedule) {
 
 		opRun := func(op *operations.Operation) error {
 			for _, member := range offlineMembers {
-				err := healClusterMember(d, op, member.Name)
+				err := fenceClusterMember(ctx, s, member)
+				if err != nil {
+					logger.Error("Failed fencing cluster member, skipping healing", logger.Ctx{"server": member.Name, "err": err})
+					continue
+				}
+
+				err = healClusterMember(d, op, member.Name)
 				if err != nil {
 					logger.Error("Failed healing cluster instances", logger.Ctx{"server": member.Name, "err": err})
 					return err
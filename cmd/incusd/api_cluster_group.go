@@ -5,13 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
 	"github.com/lxc/incus/v7/internal/server/auth"
 	"github.com/lxc/incus/v7/internal/server/db"
 	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
@@ -937,7 +940,42 @@ func clusterGroupDelete(d *Daemon, r *http.Request) response.Response {
 
 // clusterGroupValidate validates the configuration keys/values for cluster groups.
 func clusterGroupValidate(config map[string]string) error {
-	configKeys := map[string]func(value string) error{}
+	configKeys := map[string]func(value string) error{
+		// gendoc:generate(entity=cluster_group, group=common, key=maintenance.schedule)
+		// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`) or a comma-and-space-separated list of cron expressions.
+		// The schedule applies to all members of the group which don't have their own `maintenance.schedule`.
+		// See {ref}`cluster-maintenance-windows` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Schedule for the start of the maintenance windows of the group members
+		"maintenance.schedule": validate.Optional(validate.IsCron(nil)),
+
+		// gendoc:generate(entity=cluster_group, group=common, key=maintenance.duration)
+		// Length of each maintenance window (for example `2h` or `90m`).
+		// ---
+		//  type: string
+		//  defaultdesc: `1h`
+		//  shortdesc: Length of the maintenance windows of the group members
+		"maintenance.duration": validate.Optional(validate.IsMinimumDuration(time.Minute)),
+
+		// gendoc:generate(entity=cluster_group, group=common, key=maintenance.mode)
+		// Evacuation mode used when the maintenance window starts.
+		// Possible values are the same as for the {config:option}`instance-miscellaneous:cluster.evacuate` instance option.
+		// ---
+		//  type: string
+		//  defaultdesc: `auto`
+		//  shortdesc: Evacuation mode used for the maintenance windows of the group members
+		"maintenance.mode": internalInstance.InstanceConfigKeysAny["cluster.evacuate"],
+
+		// gendoc:generate(entity=cluster_group, group=common, key=maintenance.concurrency)
+		// The members of the group take turns, each with its own maintenance window of {config:option}`cluster_group-common:maintenance.duration`.
+		// This sets how many of them are in maintenance at the same time.
+		// ---
+		//  type: integer
+		//  defaultdesc: `1`
+		//  shortdesc: Number of group members in maintenance at the same time
+		"maintenance.concurrency": validate.Optional(validate.IsInRange(1, math.MaxInt32)),
+	}
 
 	// Add architecture keys.
 	for _, arch := range osarch.SupportedArchitectures() {
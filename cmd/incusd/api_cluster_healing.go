@@ -0,0 +1,393 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/internal/server/instance"
+	"github.com/lxc/incus/v7/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v7/internal/server/state"
+	storagePools "github.com/lxc/incus/v7/internal/server/storage"
+	storageDrivers "github.com/lxc/incus/v7/internal/server/storage/drivers"
+	"github.com/lxc/incus/v7/internal/server/task"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/subprocess"
+	"github.com/lxc/incus/v7/shared/util"
+)
+
+// healInstances restarts the instances of an offline cluster member elsewhere, in order of healing priority.
+// Instances sharing the same priority are handled in parallel.
+func healInstances(ctx context.Context, opts evacuateOpts) error {
+	// Group the instances by priority.
+	priorities := map[int][]instance.Instance{}
+	for _, inst := range opts.instances {
+		priority, _ := strconv.Atoi(inst.ExpandedConfig()["cluster.healing.priority"])
+		priorities[priority] = append(priorities[priority], inst)
+	}
+
+	order := make([]int, 0, len(priorities))
+	for priority := range priorities {
+		order = append(order, priority)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(order)))
+
+	// Wait the healing delay if set.
+	healingDelay := func(ctx context.Context, inst instance.Instance) {
+		delay, err := strconv.Atoi(inst.ExpandedConfig()["cluster.healing.delay"])
+		if err == nil && delay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(delay) * time.Second):
+			}
+		}
+	}
+
+	for _, priority := range order {
+		err := evacuateInstancesParallel(ctx, priorities[priority], opts, healingDelay)
+		if err != nil {
+			return fmt.Errorf("Failed to heal instances: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// selfFenceInterval is how often cluster members check whether they need to fence themselves.
+const selfFenceInterval = 5 * time.Second
+
+// fenceClusterMember applies the configured fencing methods to an offline cluster member.
+// Healing must not proceed unless this succeeds.
+func fenceClusterMember(ctx context.Context, s *state.State, member db.NodeInfo) error {
+	methods := s.GlobalConfig.ClusterHealingFencing()
+
+	l := logger.AddContext(logger.Ctx{"server": member.Name, "methods": methods})
+	if len(methods) > 0 {
+		l.Info("Fencing cluster member")
+	}
+
+	for _, method := range methods {
+		switch method {
+		case "command":
+			command := s.GlobalConfig.ClusterHealingFencingCommand()
+			if command == "" {
+				return errors.New("No fencing command configured")
+			}
+
+			cmdCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+			_, err := subprocess.RunCommandContext(cmdCtx, command, member.Name, member.Address)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("Failed running fencing command: %w", err)
+			}
+
+		case "storage":
+			err := fenceClusterMemberStorage(ctx, s, member)
+			if err != nil {
+				return err
+			}
+
+		case "self":
+			// The member fences itself (see autoSelfFenceClusterTask), make sure it had the time to do so.
+			deadline := clusterSelfFenceDeadline(member, s.GlobalConfig.OfflineThreshold())
+			if time.Now().Before(deadline) {
+				return fmt.Errorf("Cluster member may not have fenced itself until %s", deadline.Format(time.RFC3339))
+			}
+		}
+	}
+
+	return nil
+}
+
+// clusterSelfFenceDeadline returns the time by which an offline cluster member has killed its instances.
+// The member does so once it hasn't received a heartbeat for longer than the offline threshold, which it checks
+// every selfFenceInterval.
+func clusterSelfFenceDeadline(member db.NodeInfo, offlineThreshold time.Duration) time.Time {
+	return member.Heartbeat.Add(offlineThreshold + 2*selfFenceInterval)
+}
+
+// fenceClusterMemberStorage prevents an offline cluster member from accessing the remote storage pools used by its instances.
+// The fenced storage pools are recorded in the member's configuration so they can be unfenced once it's back.
+func fenceClusterMemberStorage(ctx context.Context, s *state.State, member db.NodeInfo) error {
+	var dbInstances []dbCluster.Instance
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		dbInstances, err = dbCluster.GetInstances(ctx, tx.Tx(), dbCluster.InstanceFilter{Node: &member.Name})
+		if err != nil {
+			return fmt.Errorf("Failed to get instances: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Get the storage pools used by the instances.
+	poolNames := []string{}
+	for _, dbInst := range dbInstances {
+		inst, err := instance.LoadByProjectAndName(s, dbInst.Project, dbInst.Name)
+		if err != nil {
+			return fmt.Errorf("Failed to load instance: %w", err)
+		}
+
+		poolName, err := inst.StoragePool()
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				continue
+			}
+
+			return err
+		}
+
+		if !slices.Contains(poolNames, poolName) {
+			poolNames = append(poolNames, poolName)
+		}
+	}
+
+	addresses := util.SplitNTrimSpace(member.Config["ceph.client_addresses"], ",", -1, true)
+	fenced := util.SplitNTrimSpace(member.Config["volatile.healing.fenced"], ",", -1, true)
+	fencedCount := len(fenced)
+
+	var fenceErr error
+	for _, poolName := range poolNames {
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			fenceErr = err
+			break
+		}
+
+		// Instances on local storage pools are never healed.
+		if !pool.Driver().Info().Remote {
+			continue
+		}
+
+		err = pool.Driver().FenceMember(member.Name, addresses)
+		if err != nil {
+			if errors.Is(err, storageDrivers.ErrNotSupported) {
+				fenceErr = fmt.Errorf("Storage pool %q doesn't support fencing", poolName)
+			} else {
+				fenceErr = fmt.Errorf("Failed fencing storage pool %q: %w", poolName, err)
+			}
+
+			break
+		}
+
+		if !slices.Contains(fenced, poolName) {
+			fenced = append(fenced, poolName)
+		}
+	}
+
+	// Record the storage pools fenced so far, even if fencing failed on another one.
+	if len(fenced) != fencedCount {
+		err = clusterHealingSetFenced(ctx, s, member.Name, fenced)
+		if err != nil {
+			return errors.Join(fenceErr, err)
+		}
+	}
+
+	return fenceErr
+}
+
+// unfenceClusterMember lifts the storage fencing of a cluster member which is back online.
+func unfenceClusterMember(ctx context.Context, s *state.State, member db.NodeInfo) error {
+	addresses := util.SplitNTrimSpace(member.Config["ceph.client_addresses"], ",", -1, true)
+
+	logger.Info("Unfencing cluster member", logger.Ctx{"server": member.Name})
+
+	var errs []error
+	remaining := []string{}
+	for _, poolName := range util.SplitNTrimSpace(member.Config["volatile.healing.fenced"], ",", -1, true) {
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				continue // The storage pool is gone.
+			}
+
+			remaining = append(remaining, poolName)
+			errs = append(errs, err)
+			continue
+		}
+
+		err = pool.Driver().UnfenceMember(member.Name, addresses)
+		if err != nil {
+			remaining = append(remaining, poolName)
+			errs = append(errs, fmt.Errorf("Failed unfencing storage pool %q: %w", poolName, err))
+		}
+	}
+
+	err := clusterHealingSetFenced(ctx, s, member.Name, remaining)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// clusterHealingSetFenced records the storage pools a cluster member is fenced from.
+func clusterHealingSetFenced(ctx context.Context, s *state.State, name string, poolNames []string) error {
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		member, err := tx.GetNodeByName(ctx, name)
+		if err != nil {
+			return fmt.Errorf("Failed to get cluster member by name: %w", err)
+		}
+
+		config := make(map[string]string, len(member.Config)+1)
+		for k, v := range member.Config {
+			config[k] = v
+		}
+
+		if len(poolNames) == 0 {
+			delete(config, "volatile.healing.fenced")
+		} else {
+			config["volatile.healing.fenced"] = strings.Join(poolNames, ",")
+		}
+
+		return tx.UpdateNodeConfig(ctx, member.ID, config)
+	})
+}
+
+// selfFenceRestart restarts the instances which were fenced while the local member was isolated and are still
+// located on it, returning those which couldn't be loaded and need to be retried.
+func selfFenceRestart(fenced []instance.Instance, serverName string, load func(projectName string, instanceName string) (instance.Instance, error)) []instance.Instance {
+	var retry []instance.Instance
+
+	for _, fencedInst := range fenced {
+		inst, err := load(fencedInst.Project().Name, fencedInst.Name())
+		if err != nil {
+			// The database may not be reachable yet, try again unless the instance is gone.
+			if !api.StatusErrorCheck(err, http.StatusNotFound) {
+				retry = append(retry, fencedInst)
+			}
+
+			continue
+		}
+
+		if inst.Location() != serverName || inst.IsRunning() {
+			continue
+		}
+
+		logger.Info("Contact with the cluster restored, restarting fenced instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+		err = inst.Start(false)
+		if err != nil {
+			logger.Error("Failed restarting fenced instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+		}
+	}
+
+	return retry
+}
+
+// autoSelfFenceClusterTask kills the local instances which may be healed on other cluster members when
+// this member loses contact with the rest of the cluster, and restarts them once contact is restored if they
+// haven't been moved in the mean time.
+func autoSelfFenceClusterTask(d *Daemon) (task.Func, task.Schedule) {
+	// List of local instances using remote storage, refreshed while the member is healthy.
+	var instances []instance.Instance
+	var instancesRefreshed time.Time
+
+	// List of instances which were fenced.
+	var fenced []instance.Instance
+
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		if !s.ServerClustered || s.GlobalConfig.ClusterHealingThreshold() == 0 || !slices.Contains(s.GlobalConfig.ClusterHealingFencing(), "self") {
+			instances = nil
+			instancesRefreshed = time.Time{}
+			return
+		}
+
+		lastHeartbeat := d.lastHeartbeat.Load()
+		if lastHeartbeat == 0 {
+			return // No heartbeat seen yet.
+		}
+
+		// Fence the instances if isolated.
+		if time.Since(time.Unix(0, lastHeartbeat)) > s.GlobalConfig.OfflineThreshold() {
+			for _, inst := range instances {
+				pid := inst.InitPID()
+				if pid <= 0 {
+					continue
+				}
+
+				logger.Warn("Cluster member isolated, fencing instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+				err := unix.Kill(pid, unix.SIGKILL)
+				if err != nil {
+					logger.Error("Failed fencing instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+					continue
+				}
+
+				fenced = append(fenced, inst)
+			}
+
+			instances = nil
+			instancesRefreshed = time.Time{}
+			return
+		}
+
+		// Restart the fenced instances which are still located on this member.
+		fenced = selfFenceRestart(fenced, s.ServerName, func(projectName string, instanceName string) (instance.Instance, error) {
+			return instance.LoadByProjectAndName(s, projectName, instanceName)
+		})
+
+		// Refresh the list of instances to fence.
+		if time.Since(instancesRefreshed) < 30*time.Second {
+			return
+		}
+
+		localInstances, err := instance.LoadNodeAll(s, instancetype.Any)
+		if err != nil {
+			logger.Warn("Failed loading instances for self-fencing", logger.Ctx{"err": err})
+			return
+		}
+
+		instances = instances[:0]
+		for _, inst := range localInstances {
+			if !inst.IsRunning() {
+				continue
+			}
+
+			poolName, err := inst.StoragePool()
+			if err != nil {
+				continue
+			}
+
+			pool, err := storagePools.LoadByName(s, poolName)
+			if err != nil || !pool.Driver().Info().Remote {
+				continue
+			}
+
+			instances = append(instances, inst)
+		}
+
+		instancesRefreshed = time.Now()
+	}
+
+	return f, task.Every(selfFenceInterval)
+}
+
+// clusterHealingThreshold returns the time after which an offline cluster member can be healed.
+// When self-fencing is in use, this leaves enough time for the member to notice it is isolated and fence itself.
+func clusterHealingThreshold(s *state.State) time.Duration {
+	healingThreshold := s.GlobalConfig.ClusterHealingThreshold()
+	if healingThreshold > 0 && slices.Contains(s.GlobalConfig.ClusterHealingFencing(), "self") {
+		healingThreshold += s.GlobalConfig.OfflineThreshold()
+	}
+
+	return healingThreshold
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/server/db"
+	"github.com/lxc/incus/v7/internal/server/instance"
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// healingTestInstance implements the parts of an instance used when restarting fenced instances.
+type healingTestInstance struct {
+	instance.Instance
+
+	name     string
+	location string
+	running  bool
+	started  bool
+}
+
+func (inst *healingTestInstance) Project() api.Project {
+	return api.Project{Name: "default"}
+}
+
+func (inst *healingTestInstance) Name() string {
+	return inst.name
+}
+
+func (inst *healingTestInstance) Location() string {
+	return inst.location
+}
+
+func (inst *healingTestInstance) IsRunning() bool {
+	return inst.running
+}
+
+func (inst *healingTestInstance) Start(stateful bool) error {
+	inst.started = true
+	return nil
+}
+
+func TestClusterSelfFenceDeadline(t *testing.T) {
+	heartbeat := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+
+	deadline := clusterSelfFenceDeadline(db.NodeInfo{Name: "m1", Heartbeat: heartbeat}, 20*time.Second)
+	if !deadline.Equal(heartbeat.Add(30 * time.Second)) {
+		t.Fatalf("Unexpected self-fencing deadline %v", deadline)
+	}
+}
+
+func TestSelfFenceRestart(t *testing.T) {
+	instances := map[string]*healingTestInstance{
+		"local":   {name: "local", location: "m1"},
+		"running": {name: "running", location: "m1", running: true},
+		"moved":   {name: "moved", location: "m2"},
+	}
+
+	var dbErr error
+	load := func(projectName string, instanceName string) (instance.Instance, error) {
+		if dbErr != nil {
+			return nil, dbErr
+		}
+
+		inst, ok := instances[instanceName]
+		if !ok {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Instance not found")
+		}
+
+		return inst, nil
+	}
+
+	fenced := []instance.Instance{
+		&healingTestInstance{name: "local"},
+		&healingTestInstance{name: "running"},
+		&healingTestInstance{name: "moved"},
+		&healingTestInstance{name: "deleted"},
+	}
+
+	// Instances are kept for later when the database isn't reachable.
+	dbErr = errors.New("Database unavailable")
+	retry := selfFenceRestart(fenced, "m1", load)
+	if len(retry) != len(fenced) {
+		t.Fatalf("Expected all instances to be retried, got %d", len(retry))
+	}
+
+	if instances["local"].started {
+		t.Fatal("Instance started without being loaded")
+	}
+
+	// Only the instances still located on the member and stopped are restarted.
+	dbErr = nil
+	retry = selfFenceRestart(retry, "m1", load)
+	if len(retry) != 0 {
+		t.Fatalf("Unexpected instances to retry: %d", len(retry))
+	}
+
+	started := []string{}
+	for name, inst := range instances {
+		if inst.started {
+			started = append(started, name)
+		}
+	}
+
+	if !slices.Equal(started, []string{"local"}) {
+		t.Fatalf("Unexpected restarted instances: %v", started)
+	}
+}
+
+func TestClusterValidateConfigCephClientAddresses(t *testing.T) {
+	for _, value := range []string{"192.0.2.1", "192.0.2.1, 2001:db8::1", "192.0.2.0/24,2001:db8::/64"} {
+		err := clusterValidateConfig(map[string]string{"ceph.client_addresses": value})
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", value, err)
+		}
+	}
+
+	for _, value := range []string{"ceph1.example.net", "192.0.2.1:6789", "192.0.2.1/24"} {
+		err := clusterValidateConfig(map[string]string{"ceph.client_addresses": value})
+		if err == nil {
+			t.Fatalf("Expected an error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adhocore/gronx"
+
+	"github.com/lxc/incus/v7/internal/server/cluster"
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/internal/server/db/operationtype"
+	"github.com/lxc/incus/v7/internal/server/lifecycle"
+	"github.com/lxc/incus/v7/internal/server/operations"
+	"github.com/lxc/incus/v7/internal/server/state"
+	"github.com/lxc/incus/v7/internal/server/task"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// clusterMaintenanceDefaultDuration is the default length of a maintenance window.
+const clusterMaintenanceDefaultDuration = time.Hour
+
+// clusterMaintenanceWindow returns the start of the maintenance window the cluster member is currently in
+// along with the evacuation mode to use. A zero time is returned if the member isn't in a maintenance window.
+//
+// Maintenance windows defined on the member itself take precedence over those defined on its cluster groups.
+// The members of a cluster group take turns, with at most maintenance.concurrency of them in maintenance at
+// the same time. Each of them gets its own window, starting once the windows of the previous members have ended.
+func clusterMaintenanceWindow(member db.NodeInfo, members []db.NodeInfo, groupConfigs map[string]map[string]string, now time.Time) (time.Time, string, error) {
+	type window struct {
+		config map[string]string
+		slot   int
+	}
+
+	var windows []window
+	if member.Config["maintenance.schedule"] != "" {
+		windows = append(windows, window{config: member.Config})
+	} else {
+		for _, group := range member.Groups {
+			config := groupConfigs[group]
+			if config["maintenance.schedule"] != "" {
+				slot, err := clusterMaintenanceSlot(member, members, group, config)
+				if err != nil {
+					return time.Time{}, "", err
+				}
+
+				windows = append(windows, window{config: config, slot: slot})
+			}
+		}
+	}
+
+	var windowStart time.Time
+	var windowMode string
+
+	now = now.Truncate(time.Minute)
+	for _, w := range windows {
+		duration := clusterMaintenanceDefaultDuration
+		if w.config["maintenance.duration"] != "" {
+			var err error
+
+			duration, err = time.ParseDuration(w.config["maintenance.duration"])
+			if err != nil {
+				return time.Time{}, "", fmt.Errorf("Invalid maintenance duration %q: %w", w.config["maintenance.duration"], err)
+			}
+		}
+
+		offset := time.Duration(w.slot) * duration
+
+		// Can be comma+space separated (just commas are valid cron pattern).
+		for _, spec := range strings.Split(strings.ToLower(w.config["maintenance.schedule"]), ", ") {
+			tick, err := gronx.PrevTickBefore(spec, now.Add(-offset), true)
+			if err != nil {
+				return time.Time{}, "", fmt.Errorf("Could not parse cron %q: %w", spec, err)
+			}
+
+			start := tick.Add(offset)
+			if now.Before(start.Add(duration)) && start.After(windowStart) {
+				windowStart = start
+				windowMode = w.config["maintenance.mode"]
+			}
+		}
+	}
+
+	return windowStart, windowMode, nil
+}
+
+// clusterMaintenanceSlot returns the turn of a cluster member within the maintenance windows of a cluster group.
+// Members are ordered by name, skipping those with their own schedule.
+func clusterMaintenanceSlot(member db.NodeInfo, members []db.NodeInfo, group string, config map[string]string) (int, error) {
+	concurrency := 1
+	if config["maintenance.concurrency"] != "" {
+		var err error
+
+		concurrency, err = strconv.Atoi(config["maintenance.concurrency"])
+		if err != nil || concurrency < 1 {
+			return -1, fmt.Errorf("Invalid maintenance concurrency %q", config["maintenance.concurrency"])
+		}
+	}
+
+	names := []string{}
+	for _, m := range members {
+		if m.Config["maintenance.schedule"] != "" || !slices.Contains(m.Groups, group) {
+			continue
+		}
+
+		names = append(names, m.Name)
+	}
+
+	slices.Sort(names)
+
+	index := slices.Index(names, member.Name)
+	if index < 0 {
+		return -1, fmt.Errorf("Cluster member %q isn't part of cluster group %q", member.Name, group)
+	}
+
+	return index / concurrency, nil
+}
+
+// clusterMaintenanceCheck checks that evacuating a cluster member leaves the cluster with enough members to run
+// the instances and, for database members, to keep the database quorum should the member go down.
+func clusterMaintenanceCheck(member db.NodeInfo, members []db.NodeInfo, voters []string, offlineThreshold time.Duration) error {
+	available := 0
+	availableVoters := 0
+	for _, m := range members {
+		if m.Name == member.Name || m.State != db.ClusterMemberStateCreated || m.IsOffline(offlineThreshold) {
+			continue
+		}
+
+		available++
+
+		if slices.Contains(voters, m.Address) {
+			availableVoters++
+		}
+	}
+
+	if available == 0 {
+		return errors.New("No other cluster member is available to run the instances")
+	}
+
+	if slices.Contains(voters, member.Address) && availableVoters <= len(voters)/2 {
+		return errors.New("Not enough other database members are available to keep the quorum")
+	}
+
+	return nil
+}
+
+// clusterMaintenanceSetWindow records the maintenance window handled for a cluster member (or clears it).
+func clusterMaintenanceSetWindow(ctx context.Context, s *state.State, name string, windowStart time.Time) error {
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		member, err := tx.GetNodeByName(ctx, name)
+		if err != nil {
+			return fmt.Errorf("Failed to get cluster member by name: %w", err)
+		}
+
+		config := make(map[string]string, len(member.Config)+1)
+		for k, v := range member.Config {
+			config[k] = v
+		}
+
+		if windowStart.IsZero() {
+			delete(config, "volatile.maintenance.window")
+		} else {
+			config["volatile.maintenance.window"] = strconv.FormatInt(windowStart.Unix(), 10)
+		}
+
+		return tx.UpdateNodeConfig(ctx, member.ID, config)
+	})
+}
+
+// clusterMaintenanceSetState evacuates or restores a cluster member and waits for the operation to complete.
+func clusterMaintenanceSetState(s *state.State, member db.NodeInfo, action string, mode string) error {
+	client, err := cluster.Connect(member.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to cluster member %q: %w", member.Name, err)
+	}
+
+	op, err := client.UpdateClusterMemberState(member.Name, api.ClusterMemberStatePost{Action: action, Mode: mode})
+	if err != nil {
+		return err
+	}
+
+	return op.Wait()
+}
+
+// clusterMaintenanceAction returns the maintenance action needed for a cluster member given its current
+// maintenance window. This is one of "evacuate", "restore", "clear" (maintenance ended while the member was
+// already restored) or an empty string if there's nothing to do.
+func clusterMaintenanceAction(member db.NodeInfo, windowStart time.Time) string {
+	lastWindow := member.Config["volatile.maintenance.window"]
+
+	if !windowStart.IsZero() {
+		// Skip windows which were already handled, the member may have been restored manually since.
+		// Also leave members which are already evacuated (or being restored) alone.
+		if lastWindow == strconv.FormatInt(windowStart.Unix(), 10) || member.State != db.ClusterMemberStateCreated {
+			return ""
+		}
+
+		return "evacuate"
+	}
+
+	// Nothing to do unless the maintenance was started by us.
+	if lastWindow == "" {
+		return ""
+	}
+
+	switch member.State {
+	case db.ClusterMemberStateEvacuated:
+		return "restore"
+	case db.ClusterMemberStateCreated:
+		return "clear"
+	}
+
+	// Evacuation or restore in progress, check again later.
+	return ""
+}
+
+// clusterMaintenanceMember starts or ends the maintenance of a cluster member.
+func clusterMaintenanceMember(ctx context.Context, s *state.State, op *operations.Operation, member db.NodeInfo, windowStart time.Time, mode string) error {
+	l := logger.AddContext(logger.Ctx{"member": member.Name})
+
+	switch clusterMaintenanceAction(member, windowStart) {
+	case "evacuate":
+		// Record the window first so a failed evacuation isn't retried in a loop.
+		err := clusterMaintenanceSetWindow(ctx, s, member.Name, windowStart)
+		if err != nil {
+			return err
+		}
+
+		l.Info("Starting cluster member maintenance", logger.Ctx{"window": windowStart, "mode": mode})
+		_ = op.ExtendMetadata(map[string]any{"maintenance_progress": fmt.Sprintf("Evacuating %q", member.Name)})
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.ClusterMemberMaintenanceStarted.Event(member.Name, op.Requestor(), map[string]any{"window": windowStart, "mode": mode}))
+
+		err = clusterMaintenanceSetState(s, member, "evacuate", mode)
+		if err != nil {
+			return fmt.Errorf("Failed to evacuate cluster member %q: %w", member.Name, err)
+		}
+
+	case "restore":
+		l.Info("Ending cluster member maintenance")
+		_ = op.ExtendMetadata(map[string]any{"maintenance_progress": fmt.Sprintf("Restoring %q", member.Name)})
+
+		err := clusterMaintenanceSetState(s, member, "restore", "")
+		if err != nil {
+			return fmt.Errorf("Failed to restore cluster member %q: %w", member.Name, err)
+		}
+
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.ClusterMemberMaintenanceEnded.Event(member.Name, op.Requestor(), nil))
+
+		return clusterMaintenanceSetWindow(ctx, s, member.Name, time.Time{})
+
+	case "clear":
+		s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.ClusterMemberMaintenanceEnded.Event(member.Name, op.Requestor(), nil))
+
+		return clusterMaintenanceSetWindow(ctx, s, member.Name, time.Time{})
+	}
+
+	return nil
+}
+
+func autoMaintenanceClusterTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		leader, err := s.Cluster.LeaderAddress()
+		if err != nil {
+			if errors.Is(err, cluster.ErrNodeIsNotClustered) {
+				return // Skip maintenance if not clustered.
+			}
+
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if s.LocalConfig.ClusterAddress() != leader {
+			return // Skip maintenance if not cluster leader.
+		}
+
+		var voters []string
+		err = s.DB.Node.Transaction(ctx, func(ctx context.Context, tx *db.NodeTx) error {
+			raftNodes, err := tx.GetRaftNodes(ctx)
+			if err != nil {
+				return fmt.Errorf("Failed loading RAFT nodes: %w", err)
+			}
+
+			for _, raftNode := range raftNodes {
+				if raftNode.Role == db.RaftVoter {
+					voters = append(voters, raftNode.Address)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed loading cluster database members", logger.Ctx{"err": err})
+			return
+		}
+
+		var members []db.NodeInfo
+		groupConfigs := map[string]map[string]string{}
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			members, err = tx.GetNodes(ctx)
+			if err != nil {
+				return fmt.Errorf("Failed getting cluster members: %w", err)
+			}
+
+			groups, err := dbCluster.GetClusterGroups(ctx, tx.Tx())
+			if err != nil {
+				return fmt.Errorf("Failed getting cluster groups: %w", err)
+			}
+
+			for _, group := range groups {
+				groupConfigs[group.Name], err = dbCluster.GetClusterGroupConfig(ctx, tx.Tx(), group.ID)
+				if err != nil {
+					return fmt.Errorf("Failed getting cluster group config: %w", err)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed loading cluster maintenance windows", logger.Ctx{"err": err})
+			return
+		}
+
+		// Find the members whose maintenance needs to be started or ended.
+		type maintenance struct {
+			member      db.NodeInfo
+			windowStart time.Time
+			mode        string
+		}
+
+		// Members whose maintenance ends are handled first, making room for the others.
+		var pending []maintenance
+		var evacuations []maintenance
+		for _, member := range members {
+			if member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+				continue
+			}
+
+			windowStart, mode, err := clusterMaintenanceWindow(member, members, groupConfigs, time.Now())
+			if err != nil {
+				logger.Warn("Failed checking cluster member maintenance window", logger.Ctx{"member": member.Name, "err": err})
+				continue
+			}
+
+			switch clusterMaintenanceAction(member, windowStart) {
+			case "":
+				continue
+			case "evacuate":
+				evacuations = append(evacuations, maintenance{member: member, windowStart: windowStart, mode: mode})
+			default:
+				pending = append(pending, maintenance{member: member, windowStart: windowStart, mode: mode})
+			}
+		}
+
+		pending = append(pending, evacuations...)
+
+		if len(pending) == 0 {
+			return // Skip if there is no maintenance to start or end.
+		}
+
+		opRun := func(op *operations.Operation) error {
+			// Handle one member at a time, keeping track of their new state for the next checks.
+			for _, m := range pending {
+				index := slices.IndexFunc(members, func(member db.NodeInfo) bool { return member.Name == m.member.Name })
+
+				action := clusterMaintenanceAction(m.member, m.windowStart)
+				if action == "evacuate" {
+					err := clusterMaintenanceCheck(m.member, members, voters, s.GlobalConfig.OfflineThreshold())
+					if err != nil {
+						logger.Warn("Skipping cluster member maintenance", logger.Ctx{"member": m.member.Name, "err": err})
+						continue
+					}
+				}
+
+				err := clusterMaintenanceMember(ctx, s, op, m.member, m.windowStart, m.mode)
+				if err != nil {
+					logger.Error("Failed cluster member maintenance", logger.Ctx{"member": m.member.Name, "err": err})
+					continue
+				}
+
+				switch action {
+				case "evacuate":
+					members[index].State = db.ClusterMemberStateEvacuated
+				case "restore":
+					members[index].State = db.ClusterMemberStateCreated
+				}
+			}
+
+			return nil
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterMemberMaintenance, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating cluster member maintenance operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting cluster member maintenance operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed cluster member maintenance", logger.Ctx{"err": err})
+			return
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/server/db"
+)
+
+func TestClusterMaintenanceWindow(t *testing.T) {
+	at := func(day int, hour int, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, time.Local)
+	}
+
+	groupMembers := []db.NodeInfo{
+		{Name: "m3", Groups: []string{"patch"}},
+		{Name: "m1", Groups: []string{"patch"}},
+		{Name: "m2", Groups: []string{"patch", "other"}},
+		{Name: "m0", Groups: []string{"patch"}, Config: map[string]string{"maintenance.schedule": "0 12 * * *"}},
+	}
+
+	member := func(name string) db.NodeInfo {
+		for _, m := range groupMembers {
+			if m.Name == name {
+				return m
+			}
+		}
+
+		t.Fatalf("Unknown member %q", name)
+		return db.NodeInfo{}
+	}
+
+	daily := map[string]string{"maintenance.schedule": "0 2 * * *", "maintenance.duration": "3h", "maintenance.mode": "stop"}
+
+	tests := []struct {
+		name      string
+		member    db.NodeInfo
+		groups    map[string]map[string]string
+		now       time.Time
+		wantStart time.Time
+		wantMode  string
+		wantErr   bool
+	}{
+		{
+			name:   "no schedule",
+			member: db.NodeInfo{Name: "m1", Groups: []string{"patch"}},
+			groups: map[string]map[string]string{"patch": {}},
+			now:    at(16, 3, 0),
+		},
+		{
+			name:      "member schedule",
+			member:    db.NodeInfo{Name: "m9", Config: daily},
+			now:       at(16, 3, 0),
+			wantStart: at(16, 2, 0),
+			wantMode:  "stop",
+		},
+		{
+			name:      "member schedule at the start",
+			member:    db.NodeInfo{Name: "m9", Config: daily},
+			now:       at(16, 2, 0),
+			wantStart: at(16, 2, 0),
+			wantMode:  "stop",
+		},
+		{
+			name:   "member schedule after the end",
+			member: db.NodeInfo{Name: "m9", Config: daily},
+			now:    at(16, 5, 0),
+		},
+		{
+			name:      "default duration",
+			member:    db.NodeInfo{Name: "m9", Config: map[string]string{"maintenance.schedule": "0 2 * * *"}},
+			now:       at(16, 2, 59),
+			wantStart: at(16, 2, 0),
+		},
+		{
+			name:   "default duration after the end",
+			member: db.NodeInfo{Name: "m9", Config: map[string]string{"maintenance.schedule": "0 2 * * *"}},
+			now:    at(16, 3, 0),
+		},
+		{
+			name:      "multiple schedules",
+			member:    db.NodeInfo{Name: "m9", Config: map[string]string{"maintenance.schedule": "0 2 * * *, 0 14 * * *"}},
+			now:       at(16, 14, 30),
+			wantStart: at(16, 14, 0),
+		},
+		{
+			name:      "member schedule takes precedence",
+			member:    member("m0"),
+			groups:    map[string]map[string]string{"patch": daily},
+			now:       at(16, 12, 30),
+			wantStart: at(16, 12, 0),
+		},
+		{
+			name:   "member schedule ignores group schedule",
+			member: member("m0"),
+			groups: map[string]map[string]string{"patch": daily},
+			now:    at(16, 3, 0),
+		},
+		{
+			name:      "first group member",
+			member:    member("m1"),
+			groups:    map[string]map[string]string{"patch": daily},
+			now:       at(16, 3, 0),
+			wantStart: at(16, 2, 0),
+			wantMode:  "stop",
+		},
+		{
+			name:   "first group member after its turn",
+			member: member("m1"),
+			groups: map[string]map[string]string{"patch": daily},
+			now:    at(16, 5, 0),
+		},
+		{
+			name:   "second group member before its turn",
+			member: member("m2"),
+			groups: map[string]map[string]string{"patch": daily},
+			now:    at(16, 3, 0),
+		},
+		{
+			name:      "second group member",
+			member:    member("m2"),
+			groups:    map[string]map[string]string{"patch": daily},
+			now:       at(16, 5, 0),
+			wantStart: at(16, 5, 0),
+			wantMode:  "stop",
+		},
+		{
+			name:      "third group member",
+			member:    member("m3"),
+			groups:    map[string]map[string]string{"patch": daily},
+			now:       at(16, 10, 59),
+			wantStart: at(16, 8, 0),
+			wantMode:  "stop",
+		},
+		{
+			name:   "third group member after its turn",
+			member: member("m3"),
+			groups: map[string]map[string]string{"patch": daily},
+			now:    at(16, 11, 0),
+		},
+		{
+			name:      "group concurrency",
+			member:    member("m2"),
+			groups:    map[string]map[string]string{"patch": {"maintenance.schedule": "0 2 * * *", "maintenance.duration": "3h", "maintenance.concurrency": "2"}},
+			now:       at(16, 3, 0),
+			wantStart: at(16, 2, 0),
+		},
+		{
+			name:      "group concurrency second turn",
+			member:    member("m3"),
+			groups:    map[string]map[string]string{"patch": {"maintenance.schedule": "0 2 * * *", "maintenance.duration": "3h", "maintenance.concurrency": "2"}},
+			now:       at(16, 5, 0),
+			wantStart: at(16, 5, 0),
+		},
+		{
+			name:      "turn past midnight",
+			member:    member("m3"),
+			groups:    map[string]map[string]string{"patch": {"maintenance.schedule": "0 22 * * *", "maintenance.duration": "2h"}},
+			now:       at(17, 2, 30),
+			wantStart: at(17, 2, 0),
+		},
+		{
+			name:      "latest window of multiple groups",
+			member:    member("m2"),
+			groups:    map[string]map[string]string{"patch": daily, "other": {"maintenance.schedule": "0 6 * * *", "maintenance.mode": "migrate"}},
+			now:       at(16, 6, 0),
+			wantStart: at(16, 6, 0),
+			wantMode:  "migrate",
+		},
+		{
+			name:    "invalid duration",
+			member:  db.NodeInfo{Name: "m9", Config: map[string]string{"maintenance.schedule": "0 2 * * *", "maintenance.duration": "forever"}},
+			now:     at(16, 3, 0),
+			wantErr: true,
+		},
+		{
+			name:    "invalid schedule",
+			member:  db.NodeInfo{Name: "m9", Config: map[string]string{"maintenance.schedule": "every night"}},
+			now:     at(16, 3, 0),
+			wantErr: true,
+		},
+		{
+			name:    "invalid concurrency",
+			member:  member("m1"),
+			groups:  map[string]map[string]string{"patch": {"maintenance.schedule": "0 2 * * *", "maintenance.concurrency": "0"}},
+			now:     at(16, 3, 0),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, mode, err := clusterMaintenanceWindow(tt.member, groupMembers, tt.groups, tt.now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !start.Equal(tt.wantStart) || mode != tt.wantMode {
+				t.Fatalf("Expected window %v (%q), got %v (%q)", tt.wantStart, tt.wantMode, start, mode)
+			}
+		})
+	}
+}
+
+func TestClusterMaintenanceAction(t *testing.T) {
+	window := time.Unix(1792375200, 0)
+	handled := map[string]string{"volatile.maintenance.window": "1792375200"}
+	previous := map[string]string{"volatile.maintenance.window": "1792368000"}
+
+	tests := []struct {
+		name        string
+		state       int
+		config      map[string]string
+		windowStart time.Time
+		want        string
+	}{
+		{name: "window starts", state: db.ClusterMemberStateCreated, windowStart: window, want: "evacuate"},
+		{name: "new window", state: db.ClusterMemberStateCreated, config: previous, windowStart: window, want: "evacuate"},
+		{name: "window already handled", state: db.ClusterMemberStateCreated, config: handled, windowStart: window},
+		{name: "window during evacuation", state: db.ClusterMemberStateEvacuating, config: handled, windowStart: window},
+		{name: "window while evacuated", state: db.ClusterMemberStateEvacuated, config: handled, windowStart: window},
+		{name: "window on manually evacuated member", state: db.ClusterMemberStateEvacuated, windowStart: window},
+		{name: "no window", state: db.ClusterMemberStateCreated},
+		{name: "no window on manually evacuated member", state: db.ClusterMemberStateEvacuated},
+		{name: "window ends", state: db.ClusterMemberStateEvacuated, config: handled, want: "restore"},
+		{name: "window ends after manual restore", state: db.ClusterMemberStateCreated, config: handled, want: "clear"},
+		{name: "window ends during evacuation", state: db.ClusterMemberStateEvacuating, config: handled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			member := db.NodeInfo{Name: "m1", State: tt.state, Config: tt.config}
+
+			got := clusterMaintenanceAction(member, tt.windowStart)
+			if got != tt.want {
+				t.Fatalf("Expected action %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClusterMaintenanceCheck(t *testing.T) {
+	online := time.Now()
+	offline := time.Now().Add(-time.Hour)
+
+	newMember := func(name string, state int, heartbeat time.Time) db.NodeInfo {
+		return db.NodeInfo{Name: name, Address: name + ":8443", State: state, Heartbeat: heartbeat}
+	}
+
+	voters := []string{"m1:8443", "m2:8443", "m3:8443"}
+
+	tests := []struct {
+		name    string
+		member  string
+		members []db.NodeInfo
+		wantErr bool
+	}{
+		{
+			name:   "healthy cluster",
+			member: "m1",
+			members: []db.NodeInfo{
+				newMember("m1", db.ClusterMemberStateCreated, online),
+				newMember("m2", db.ClusterMemberStateCreated, online),
+				newMember("m3", db.ClusterMemberStateCreated, online),
+			},
+		},
+		{
+			name:   "single member",
+			member: "m4",
+			members: []db.NodeInfo{
+				newMember("m4", db.ClusterMemberStateCreated, online),
+			},
+			wantErr: true,
+		},
+		{
+			name:   "other members unavailable",
+			member: "m4",
+			members: []db.NodeInfo{
+				newMember("m4", db.ClusterMemberStateCreated, online),
+				newMember("m5", db.ClusterMemberStateEvacuated, online),
+				newMember("m6", db.ClusterMemberStateCreated, offline),
+			},
+			wantErr: true,
+		},
+		{
+			name:   "database member with an offline database member",
+			member: "m1",
+			members: []db.NodeInfo{
+				newMember("m1", db.ClusterMemberStateCreated, online),
+				newMember("m2", db.ClusterMemberStateCreated, online),
+				newMember("m3", db.ClusterMemberStateCreated, offline),
+				newMember("m4", db.ClusterMemberStateCreated, online),
+			},
+			wantErr: true,
+		},
+		{
+			name:   "database member with an evacuated database member",
+			member: "m1",
+			members: []db.NodeInfo{
+				newMember("m1", db.ClusterMemberStateCreated, online),
+				newMember("m2", db.ClusterMemberStateEvacuated, online),
+				newMember("m3", db.ClusterMemberStateCreated, online),
+			},
+			wantErr: true,
+		},
+		{
+			name:   "other member with an offline database member",
+			member: "m4",
+			members: []db.NodeInfo{
+				newMember("m1", db.ClusterMemberStateCreated, online),
+				newMember("m2", db.ClusterMemberStateCreated, online),
+				newMember("m3", db.ClusterMemberStateCreated, offline),
+				newMember("m4", db.ClusterMemberStateCreated, online),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var member db.NodeInfo
+			for _, m := range tt.members {
+				if m.Name == tt.member {
+					member = m
+				}
+			}
+
+			err := clusterMaintenanceCheck(member, tt.members, voters, 20*time.Second)
+			if tt.wantErr && err == nil {
+				t.Fatal("Expected an error")
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"sort"
 	"strconv"
 	"time"
@@ -237,6 +238,22 @@ func clusterRebalanceServers(ctx context.Context, s *state.State, srcServer *Ser
 				instanceCandidates = append(instanceCandidates, c)
 			}
 
+			// Apply the placement groups of the instance, keeping it with the rest of its affinity groups.
+			members := make([]db.NodeInfo, 0, len(instanceCandidates))
+			for _, c := range instanceCandidates {
+				members = append(members, c.NodeInfo)
+			}
+
+			members, err = instance.PlacementFilterMembers(ctx, tx, inst.Project().Name, inst.Name(), inst.ExpandedConfig(), members, true)
+			if err != nil {
+				return err
+			}
+
+			instanceCandidates = instanceCandidates[:0]
+			for _, member := range members {
+				instanceCandidates = append(instanceCandidates, candidateByName[member.Name])
+			}
+
 			return nil
 		})
 		if err != nil {
@@ -313,8 +330,8 @@ func clusterRebalanceServers(ctx context.Context, s *state.State, srcServer *Ser
 
 		// Look up the score data for the chosen target.
 		chosenScore, ok := candidateByName[chosenTarget.Name]
-		if !ok {
-			// Chosen target isn't in our candidate list (shouldn't happen).
+		if !ok || !slices.Contains(instanceCandidates, chosenScore) {
+			// Chosen target isn't in the candidate list of the instance.
 			continue
 		}
 
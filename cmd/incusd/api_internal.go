@@ -882,8 +882,14 @@ func internalImportFromBackup(ctx context.Context, s *state.State, projectName s
 		}
 	}
 
-	// Override config.
+	// Override config, an empty value unsets the key.
 	for key, value := range configMap {
+		if value == "" {
+			delete(backupConf.Container.Config, key)
+			delete(backupConf.Container.ExpandedConfig, key)
+			continue
+		}
+
 		backupConf.Container.Config[key] = value
 		backupConf.Container.ExpandedConfig[key] = value
 	}
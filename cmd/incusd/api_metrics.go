@@ -25,6 +25,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/request"
 	"github.com/lxc/incus/v7/internal/server/response"
 	"github.com/lxc/incus/v7/internal/server/state"
+	storagePools "github.com/lxc/incus/v7/internal/server/storage"
 	"github.com/lxc/incus/v7/shared/api"
 	"github.com/lxc/incus/v7/shared/logger"
 )
@@ -132,6 +133,19 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Add the logging metrics.
+	if d.loggingController != nil {
+		intMetrics.Merge(d.loggingController.Metrics())
+	}
+
+	// Add the storage metrics.
+	intMetrics.Merge(storagePools.Metrics(s))
+
+	// Add the BGP metrics.
+	if d.bgp != nil {
+		intMetrics.Merge(d.bgp.Metrics())
+	}
+
 	// invalidProjectFilters returns project filters which are either not in cache or have expired.
 	invalidProjectFilters := func(projectNames []string) []dbCluster.InstanceFilter {
 		metricsCacheLock.Lock()
@@ -1797,6 +1797,16 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  shortdesc: Which image servers (HTTP host) are allowed for us in this project
 		"restricted.images.servers": validate.Optional(validate.IsListOf(validate.IsAny)),
 
+		// gendoc:generate(entity=project, group=restricted, key=restricted.instances.credentials)
+		// Possible values are `allow` or `block`.
+		// When set to `allow`, the password and SSH keys of the users of running instances can be set through the API.
+		// See {ref}`instances-access-credentials`.
+		// ---
+		//  type: string
+		//  defaultdesc: `block`
+		//  shortdesc: Whether to prevent setting credentials inside of instances
+		"restricted.instances.credentials": isEitherAllowOrBlock,
+
 		// gendoc:generate(entity=project, group=restricted, key=restricted.networks.access)
 		// Specify a comma-delimited list of network names that are allowed for use in this project.
 		// If this option is not set, all networks are accessible.
@@ -1855,6 +1865,14 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  type: string
 		//  shortdesc: Which storage pool names are allowed for use in this project
 		"restricted.storage-pools.access": validate.Optional(validate.IsListOf(validate.IsAny)),
+
+		// gendoc:generate(entity=project, group=restricted, key=restricted.storage.replication)
+		// Replicating a custom volume makes the server connect to the replication target set on the volume.
+		// ---
+		//  type: string
+		//  defaultdesc: `block`
+		//  shortdesc: Whether to prevent replicating custom storage volumes to remote servers
+		"restricted.storage.replication": isEitherAllowOrBlock,
 	}
 
 	// Add the storage pool keys.
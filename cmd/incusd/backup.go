@@ -7,15 +7,19 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/google/uuid"
 	"go.yaml.in/yaml/v4"
 
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
 	"github.com/lxc/incus/v7/internal/instancewriter"
 	"github.com/lxc/incus/v7/internal/server/backup"
 	"github.com/lxc/incus/v7/internal/server/db"
 	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
 	"github.com/lxc/incus/v7/internal/server/db/operationtype"
+	deviceConfig "github.com/lxc/incus/v7/internal/server/device/config"
 	"github.com/lxc/incus/v7/internal/server/instance"
 	"github.com/lxc/incus/v7/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v7/internal/server/lifecycle"
@@ -35,8 +39,14 @@ import (
 	"github.com/lxc/incus/v7/shared/util"
 )
 
+// backupFreezeDuration is the maximum time the guest file systems of a virtual machine are kept frozen while
+// starting the export of the changed blocks of an incremental backup.
+const backupFreezeDuration = time.Minute
+
 // Create a new backup.
-func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.Instance, op *operations.Operation, writer *io.PipeWriter) error {
+// For incremental backups written to a pipe, the optional stored function is called once the whole backup
+// was written and must only return once it has been safely stored.
+func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.Instance, op *operations.Operation, writer *io.PipeWriter, stored func() error) error {
 	l := logger.AddContext(logger.Ctx{"project": sourceInst.Project().Name, "instance": sourceInst.Name(), "name": args.Name})
 	l.Debug("Instance backup started")
 	defer l.Debug("Instance backup finished")
@@ -189,9 +199,40 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		resCh <- err
 	}(tarWriterRes)
 
+	// Export the changed blocks ahead of writing the index for incremental backups.
+	var incremental *backup.Incremental
+	var deltaPaths map[string]string
+
+	if args.Since != "" {
+		rootDiskName, _, err := internalInstance.GetRootDiskDevice(sourceInst.ExpandedDevices().CloneNative())
+		if err != nil {
+			return fmt.Errorf("Failed getting instance root disk: %w", err)
+		}
+
+		devNames := []string{rootDiskName}
+		if !b.RootOnly() {
+			err = sourceInst.ForEachDependentDiskType(func(dev deviceConfig.DeviceNamed) error {
+				devNames = append(devNames, dev.Name)
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		var cleanup func()
+		incremental, deltaPaths, cleanup, err = backupExportIncremental(sourceInst, args.Since, devNames, sourceInst.LocalConfig())
+		if err != nil {
+			return err
+		}
+
+		defer cleanup()
+	}
+
 	// Write index file.
 	l.Debug("Adding backup index file")
-	err = backupWriteIndex(sourceInst, pool, b.OptimizedStorage(), !b.InstanceOnly(), !b.RootOnly(), tarWriter)
+	err = backupWriteIndex(sourceInst, pool, b.OptimizedStorage(), !b.InstanceOnly() && incremental == nil, !b.RootOnly(), incremental, tarWriter)
 
 	// Check compression errors.
 	if compressErr != nil {
@@ -203,7 +244,12 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		return fmt.Errorf("Error writing backup index file: %w", err)
 	}
 
-	err = pool.BackupInstance(sourceInst, tarWriter, b.OptimizedStorage(), !b.InstanceOnly(), !b.RootOnly(), nil)
+	if incremental != nil {
+		err = backupWriteIncremental(incremental, deltaPaths, tarWriter)
+	} else {
+		err = pool.BackupInstance(sourceInst, tarWriter, b.OptimizedStorage(), !b.InstanceOnly(), !b.RootOnly(), nil)
+	}
+
 	if err != nil {
 		return fmt.Errorf("Backup create: %w", err)
 	}
@@ -230,6 +276,22 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		return fmt.Errorf("Error closing tar file: %w", err)
 	}
 
+	if incremental != nil {
+		if stored != nil {
+			err = stored()
+			if err != nil {
+				return err
+			}
+		}
+
+		err = backupCommitIncremental(sourceInst, incremental, func(checkpoint string) error {
+			return sourceInst.VolatileSet(map[string]string{backup.IncrementalCheckpointKey(incremental.Bitmap): checkpoint})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	reverter.Success()
 	s.Events.SendLifecycle(sourceInst.Project().Name, lifecycle.InstanceBackupCreated.Event(args.Name, b.Instance(), nil))
 
@@ -237,7 +299,7 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 }
 
 // backupWriteIndex generates an index.yaml file and then writes it to the root of the backup tarball.
-func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, dependentVolumes bool, tarWriter *instancewriter.InstanceTarWriter) error {
+func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, dependentVolumes bool, incremental *backup.Incremental, tarWriter *instancewriter.InstanceTarWriter) error {
 	// Indicate whether the driver will include a driver-specific optimized header.
 	poolDriverOptimizedHeader := false
 	if optimized {
@@ -272,6 +334,7 @@ func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, opti
 		OptimizedStorage: &optimized,
 		OptimizedHeader:  &poolDriverOptimizedHeader,
 		Config:           config,
+		Incremental:      incremental,
 	}
 
 	if snapshots {
@@ -305,6 +368,229 @@ func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, opti
 	return nil
 }
 
+// backupExportIncremental exports the blocks changed since the given bitmap on each of the instance devices
+// into temporary qcow2 images. The config is the one holding the checkpoint of the bitmap.
+// It returns the incremental backup information, the local path of each delta image keyed by its path in
+// the tarball and a cleanup function removing the temporary images and aborting any export which wasn't
+// committed with backupCommitIncremental.
+func backupExportIncremental(inst instance.Instance, bitmapName string, devNames []string, config map[string]string) (*backup.Incremental, map[string]string, func(), error) {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	parent := config[backup.IncrementalCheckpointKey(bitmapName)]
+	if parent == "" {
+		return nil, nil, nil, fmt.Errorf("Bitmap %q has no backup checkpoint, it must be recreated and followed by a full backup", bitmapName)
+	}
+
+	incremental := &backup.Incremental{Bitmap: bitmapName, ID: uuid.New().String(), Parent: parent}
+	deltaPaths := make(map[string]string, len(devNames))
+	waits := make([]func() error, 0, len(devNames))
+
+	// Freeze the guest file systems while starting the exports for an application-consistent backup.
+	// The guest is thawed as soon as all the exports are started as they copy the blocks as they were then.
+	thaw := func() error { return nil }
+	vm, ok := inst.(instance.VM)
+	if ok {
+		var err error
+
+		thaw, err = vm.FreezeFilesystems(backupFreezeDuration)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	err := func() error {
+		for _, devName := range devNames {
+			deltaFile, err := os.CreateTemp(internalUtil.VarPath("backups"), fmt.Sprintf("%s_incremental_", backup.WorkingDirPrefix))
+			if err != nil {
+				return err
+			}
+
+			_ = deltaFile.Close()
+			reverter.Add(func() { _ = os.Remove(deltaFile.Name()) })
+
+			wait, err := inst.ExportBitmap(devName, bitmapName, deltaFile.Name())
+			if err != nil {
+				return fmt.Errorf("Failed exporting changed blocks of device %q: %w", devName, err)
+			}
+
+			reverter.Add(func() { _ = inst.AbortBitmapExport(devName, bitmapName) })
+			reverter.Add(func() { _ = wait() })
+
+			waits = append(waits, wait)
+			deltaPaths[devName] = deltaFile.Name()
+		}
+
+		return nil
+	}()
+	thawErr := thaw()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if thawErr != nil {
+		return nil, nil, nil, thawErr
+	}
+
+	for i, devName := range devNames {
+		err := waits[i]()
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Failed exporting changed blocks of device %q: %w", devName, err)
+		}
+
+		deltaPath := deltaPaths[devName]
+		delete(deltaPaths, devName)
+
+		deltaInfo, err := drivers.Qcow2Info(deltaPath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		disk := backup.IncrementalDisk{
+			Device: devName,
+			Path:   filepath.Join(backup.IncrementalPrefix, fmt.Sprintf("%s.qcow2", devName)),
+			Size:   int64(deltaInfo.VirtualSize),
+		}
+
+		incremental.Disks = append(incremental.Disks, disk)
+		deltaPaths[disk.Path] = deltaPath
+	}
+
+	cleanup := reverter.Clone().Fail
+	reverter.Success()
+
+	return incremental, deltaPaths, cleanup, nil
+}
+
+// backupCommitIncremental records an incremental backup as the new checkpoint of its bitmap using the
+// setCheckpoint function, then moves the bitmap over to the changes made since the export.
+// It must only be called once the backup has been safely stored.
+func backupCommitIncremental(inst instance.Instance, incremental *backup.Incremental, setCheckpoint func(checkpoint string) error) error {
+	err := setCheckpoint(incremental.ID)
+	if err != nil {
+		return fmt.Errorf("Failed recording backup checkpoint of bitmap %q: %w", incremental.Bitmap, err)
+	}
+
+	// A bitmap which failed to be reset still covers all the changes since the export, so the next
+	// incremental backup just ends up being larger than needed.
+	for _, disk := range incremental.Disks {
+		err := inst.CommitBitmapExport(disk.Device, incremental.Bitmap)
+		if err != nil {
+			logger.Warn("Failed resetting bitmap after incremental backup", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "device": disk.Device, "bitmap": incremental.Bitmap, "err": err})
+		}
+	}
+
+	return nil
+}
+
+// backupWriteIncremental adds the delta images of an incremental backup to the backup tarball.
+func backupWriteIncremental(incremental *backup.Incremental, deltaPaths map[string]string, tarWriter *instancewriter.InstanceTarWriter) error {
+	for _, disk := range incremental.Disks {
+		err := func() error {
+			f, err := os.Open(deltaPaths[disk.Path])
+			if err != nil {
+				return err
+			}
+
+			defer func() { _ = f.Close() }()
+
+			fi, err := f.Stat()
+			if err != nil {
+				return err
+			}
+
+			deltaFileInfo := instancewriter.FileInfo{
+				FileName:    disk.Path,
+				FileSize:    fi.Size(),
+				FileMode:    0o600,
+				FileModTime: time.Now(),
+			}
+
+			return tarWriter.WriteFileFromReader(f, &deltaFileInfo)
+		}()
+		if err != nil {
+			return fmt.Errorf("Failed adding delta image %q to tarball: %w", disk.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// backupApplyIncremental applies the delta images of an incremental backup onto existing disks.
+// The getDiskPath function is called for each disk and must return the path of the disk to write to
+// along with a function releasing it.
+func backupApplyIncremental(s *state.State, bInfo *backup.Info, srcData *os.File, getDiskPath func(disk backup.IncrementalDisk) (string, func(), error)) error {
+	disks := make(map[string]backup.IncrementalDisk, len(bInfo.Incremental.Disks))
+	for _, disk := range bInfo.Incremental.Disks {
+		disks[disk.Path] = disk
+	}
+
+	tr, cancelFunc, err := backup.TarReader(srcData, s.OS, srcData.Name())
+	if err != nil {
+		return err
+	}
+
+	defer cancelFunc()
+
+	applied := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break // End of archive.
+		}
+
+		if err != nil {
+			return fmt.Errorf("Error reading backup file: %w", err)
+		}
+
+		disk, ok := disks[hdr.Name]
+		if !ok {
+			continue
+		}
+
+		err = func() error {
+			// Extract the delta image to a temporary file.
+			deltaFile, err := os.CreateTemp(internalUtil.VarPath("backups"), fmt.Sprintf("%s_incremental_", backup.WorkingDirPrefix))
+			if err != nil {
+				return err
+			}
+
+			defer func() { _ = os.Remove(deltaFile.Name()) }()
+
+			_, err = util.SafeCopy(deltaFile, tr)
+			if err != nil {
+				_ = deltaFile.Close()
+				return err
+			}
+
+			err = deltaFile.Close()
+			if err != nil {
+				return err
+			}
+
+			diskPath, release, err := getDiskPath(disk)
+			if err != nil {
+				return err
+			}
+
+			defer release()
+
+			return drivers.Qcow2ApplyDelta(deltaFile.Name(), diskPath)
+		}()
+		if err != nil {
+			return fmt.Errorf("Failed applying delta image %q: %w", disk.Path, err)
+		}
+
+		applied++
+	}
+
+	if applied != len(disks) {
+		return errors.New("Backup file is missing some of its delta images")
+	}
+
+	return nil
+}
+
 func pruneExpiredBackupsTask(d *Daemon) (task.Func, task.Schedule) {
 	f := func(ctx context.Context) {
 		s := d.State()
@@ -396,7 +682,7 @@ func pruneExpiredInstanceBackups(ctx context.Context, s *state.State) error {
 	return nil
 }
 
-func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, projectName string, poolName string, volumeName string, writer *io.PipeWriter) error {
+func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, projectName string, poolName string, volumeName string, writer *io.PipeWriter, stored func() error) error {
 	l := logger.AddContext(logger.Ctx{"project": projectName, "storage_volume": volumeName, "name": args.Name})
 	l.Debug("Volume backup started")
 	defer l.Debug("Volume backup finished")
@@ -504,6 +790,10 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 
 	defer func() { _ = fileWriter.Close() }()
 
+	// Incremental backups are only committed once the whole backup has been written.
+	var incremental *backup.Incremental
+	var sourceInst instance.Instance
+
 	// If dealing with an ISO volume, we want to return it unaltered.
 	if contentType == drivers.ContentTypeISO {
 		err = pool.BackupCustomVolume(projectName, volumeName, instancewriter.NewInstanceRawWriter(fileWriter), backup.DefaultBackupPrefix, backupRow.OptimizedStorage, !backupRow.VolumeOnly, nil)
@@ -538,9 +828,28 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 			resCh <- err
 		}(tarWriterRes)
 
+		// Export the changed blocks ahead of writing the index for incremental backups.
+		var deltaPaths map[string]string
+
+		if args.Since != "" {
+			inst, devName, err := storagePools.InstanceByVolumeName(s, pool.Name(), projectName, volumeName, db.StoragePoolVolumeTypeCustom)
+			if err != nil {
+				return fmt.Errorf("Failed finding the instance using the volume: %w", err)
+			}
+
+			var cleanup func()
+			incremental, deltaPaths, cleanup, err = backupExportIncremental(inst, args.Since, []string{devName}, volume.Config)
+			if err != nil {
+				return err
+			}
+
+			defer cleanup()
+			sourceInst = inst
+		}
+
 		// Write index file.
 		l.Debug("Adding backup index file")
-		err = volumeBackupWriteIndex(projectName, volumeName, pool, backupRow.OptimizedStorage, !backupRow.VolumeOnly, tarWriter)
+		err = volumeBackupWriteIndex(projectName, volumeName, pool, backupRow.OptimizedStorage, !backupRow.VolumeOnly && incremental == nil, incremental, tarWriter)
 
 		// Check compression errors.
 		if compressErr != nil {
@@ -552,7 +861,12 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 			return fmt.Errorf("Error writing backup index file: %w", err)
 		}
 
-		err = pool.BackupCustomVolume(projectName, volumeName, tarWriter, backup.DefaultBackupPrefix, backupRow.OptimizedStorage, !backupRow.VolumeOnly, nil)
+		if incremental != nil {
+			err = backupWriteIncremental(incremental, deltaPaths, tarWriter)
+		} else {
+			err = pool.BackupCustomVolume(projectName, volumeName, tarWriter, backup.DefaultBackupPrefix, backupRow.OptimizedStorage, !backupRow.VolumeOnly, nil)
+		}
+
 		if err != nil {
 			return fmt.Errorf("Backup create: %w", err)
 		}
@@ -580,12 +894,28 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 		return fmt.Errorf("Error closing backup file: %w", err)
 	}
 
+	if incremental != nil {
+		if stored != nil {
+			err = stored()
+			if err != nil {
+				return err
+			}
+		}
+
+		err = backupCommitIncremental(sourceInst, incremental, func(checkpoint string) error {
+			return customVolumeUpdateVolatile(context.TODO(), s, poolName, projectName, volumeName, map[string]string{backup.IncrementalCheckpointKey(incremental.Bitmap): checkpoint})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	reverter.Success()
 	return nil
 }
 
 // volumeBackupWriteIndex generates an index.yaml file and then writes it to the root of the backup tarball.
-func volumeBackupWriteIndex(projectName string, volumeName string, pool storagePools.Pool, optimized bool, snapshots bool, tarWriter *instancewriter.InstanceTarWriter) error {
+func volumeBackupWriteIndex(projectName string, volumeName string, pool storagePools.Pool, optimized bool, snapshots bool, incremental *backup.Incremental, tarWriter *instancewriter.InstanceTarWriter) error {
 	// Indicate whether the driver will include a driver-specific optimized header.
 	poolDriverOptimizedHeader := false
 	if optimized {
@@ -605,6 +935,7 @@ func volumeBackupWriteIndex(projectName string, volumeName string, pool storageP
 		OptimizedHeader:  &poolDriverOptimizedHeader,
 		Type:             backup.TypeCustom,
 		Config:           config,
+		Incremental:      incremental,
 	}
 
 	if snapshots {
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/x509"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -18,6 +19,7 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	dqliteClient "github.com/cowsql/go-cowsql/client"
@@ -143,6 +145,9 @@ type Daemon struct {
 	// Keep track of skews.
 	timeSkew bool
 
+	// Time of the last successful heartbeat (as Unix nanoseconds), used for self-fencing.
+	lastHeartbeat atomic.Int64
+
 	// Configuration.
 	globalConfig   *clusterConfig.Config
 	localConfig    *node.Config
@@ -1325,6 +1330,7 @@ func (d *Daemon) init() error {
 	syslogSocketEnabled := d.localConfig.SyslogSocket()
 	openfgaAPIURL, openfgaAPIToken, openfgaStoreID := d.globalConfig.OpenFGA()
 	instancePlacementScriptlet := d.globalConfig.InstancesPlacementScriptlet()
+	instanceValidationScriptlet := d.globalConfig.InstancesValidationScriptlet()
 	authorizationScriptlet := d.globalConfig.AuthorizationScriptlet()
 
 	d.endpoints.NetworkUpdateTrustedProxy(d.globalConfig.HTTPSTrustedProxy())
@@ -1415,6 +1421,27 @@ func (d *Daemon) init() error {
 
 		return resp, nil
 	})
+	// Refresh the DNS zones whenever their content may have changed.
+	d.internalListener.AddHandler("dns", func(event api.Event) {
+		if event.Type != api.EventTypeLifecycle {
+			return
+		}
+
+		lifecycleEvent := api.EventLifecycle{}
+		err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+		if err != nil {
+			return
+		}
+
+		switch lifecycleEvent.Action {
+		case api.EventLifecycleInstanceCreated, api.EventLifecycleInstanceDeleted, api.EventLifecycleInstanceRenamed,
+			api.EventLifecycleInstanceStarted, api.EventLifecycleInstanceStopped, api.EventLifecycleInstanceShutdown,
+			api.EventLifecycleNetworkZoneUpdated, api.EventLifecycleNetworkZoneRecordCreated,
+			api.EventLifecycleNetworkZoneRecordUpdated, api.EventLifecycleNetworkZoneRecordDeleted:
+			d.dns.RefreshZones()
+		}
+	})
+
 	if dnsAddress != "" {
 		err := d.dns.Start(dnsAddress)
 		if err != nil {
@@ -1459,6 +1486,14 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	// Load instance validation scriptlet.
+	if instanceValidationScriptlet != "" {
+		err = scriptletLoad.InstanceValidationSet(instanceValidationScriptlet)
+		if err != nil {
+			logger.Warn("Failed loading instance validation scriptlet", logger.Ctx{"err": err})
+		}
+	}
+
 	// Apply all patches that need to be run after networks are initialized.
 	err = patchesApply(d, patchPostNetworks)
 	if err != nil {
@@ -1572,6 +1607,9 @@ func (d *Daemon) init() error {
 		// Prune expired custom volume snapshots and take snapshots of custom volumes (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d))
 
+		// Replicate custom volumes to their replication target (minutely check of configurable cron expression)
+		d.tasks.Add(replicateCustomVolumesTask(d))
+
 		// Remove resolved warnings (daily)
 		d.tasks.Add(pruneResolvedWarningsTask(d))
 
@@ -1580,6 +1618,12 @@ func (d *Daemon) init() error {
 
 		// Remove expired tokens (hourly)
 		d.tasks.Add(autoRemoveExpiredTokensTask(d))
+
+		// Resize the memory balloon of virtual machines (every 30s)
+		d.tasks.Add(autoBalloonInstancesTask(d))
+
+		// Expire objects of local storage buckets (hourly)
+		d.tasks.Add(bucketLifecycleTask(d))
 	}
 
 	// Start all background tasks
@@ -1588,6 +1632,12 @@ func (d *Daemon) init() error {
 	// Restore instances
 	instancesStart(d.State(), instances)
 
+	// Establish the graceful restart BGP sessions now that all prefixes have been restored
+	err = d.bgp.RestartComplete()
+	if err != nil {
+		logger.Error("Failed establishing BGP sessions", logger.Ctx{"err": err})
+	}
+
 	// Re-balance in case things changed while the daemon was down
 	deviceTaskBalance(d.State())
 
@@ -1616,6 +1666,12 @@ func (d *Daemon) startClusterTasks() {
 	// Perform automatic evacuation for offline cluster members
 	d.clusterTasks.Add(autoHealClusterTask(d))
 
+	// Fence local instances if the member gets isolated from the cluster
+	d.clusterTasks.Add(autoSelfFenceClusterTask(d))
+
+	// Perform scheduled evacuation and restore of cluster members
+	d.clusterTasks.Add(autoMaintenanceClusterTask(d))
+
 	// Perform automatic live-migration to alance load on cluster
 	d.clusterTasks.Add(autoRebalanceClusterTask(d))
 
@@ -2307,6 +2363,11 @@ func (d *Daemon) heartbeatHandler(w http.ResponseWriter, _ *http.Request, isLead
 		return
 	}
 
+	// Record that we're still in contact with the leader.
+	if !isLeader {
+		d.lastHeartbeat.Store(time.Now().UnixNano())
+	}
+
 	if hbData.FullStateList {
 		// If there is an ongoing heartbeat round (and by implication this is the leader), then this could
 		// be a problem because it could be broadcasting the stale member state information which in turn
@@ -2346,6 +2407,11 @@ func (d *Daemon) nodeRefreshTask(heartbeatData *cluster.APIHeartbeat, isLeader b
 
 	localClusterAddress := s.LocalConfig.ClusterAddress()
 
+	// Record that the leader is still able to reach the rest of the cluster.
+	if isLeader {
+		d.lastHeartbeat.Store(time.Now().UnixNano())
+	}
+
 	if !heartbeatData.FullStateList || len(heartbeatData.Members) <= 0 {
 		logger.Error("Heartbeat member refresh task called with partial state list", logger.Ctx{"local": localClusterAddress})
 		return
@@ -1296,6 +1296,16 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 			} else if req.Source.Type == "url" {
 				/* Processing image copy from URL */
 				info, err = imgPostURLInfo(context.TODO(), s, r, req, op, projectName, budget)
+			} else if req.Format == "oci" {
+				/* Processing OCI image push from container */
+				imagePublishLock.Lock()
+				imageDigest, err := imgPostInstanceOCI(s, r, req, op, builddir)
+				imagePublishLock.Unlock()
+				if err != nil {
+					return err
+				}
+
+				return op.UpdateMetadata(map[string]any{"digest": imageDigest})
 			} else {
 				/* Processing image creation from container */
 				imagePublishLock.Lock()
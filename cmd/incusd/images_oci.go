@@ -0,0 +1,663 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/opencontainers/go-digest"
+	ociImageSpecs "github.com/opencontainers/image-spec/specs-go"
+	ociImage "github.com/opencontainers/image-spec/specs-go/v1"
+
+	incus "github.com/lxc/incus/v7/client"
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
+	"github.com/lxc/incus/v7/internal/server/instance"
+	"github.com/lxc/incus/v7/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v7/internal/server/operations"
+	"github.com/lxc/incus/v7/internal/server/request"
+	"github.com/lxc/incus/v7/internal/server/state"
+	internalUtil "github.com/lxc/incus/v7/internal/util"
+	"github.com/lxc/incus/v7/internal/version"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/ioprogress"
+	"github.com/lxc/incus/v7/shared/osarch"
+	"github.com/lxc/incus/v7/shared/util"
+)
+
+// ociLayerEntry holds the attributes of a layer entry used to detect the changes made by an instance.
+type ociLayerEntry struct {
+	typeflag byte
+	mode     int64
+	uid      int
+	gid      int
+	size     int64
+	modTime  int64
+	linkname string
+	devmajor int64
+	devminor int64
+	digest   digest.Digest
+}
+
+// newOCILayerEntry returns the layer entry of a tar header, without the content digest.
+func newOCILayerEntry(hdr *tar.Header) ociLayerEntry {
+	return ociLayerEntry{
+		typeflag: hdr.Typeflag,
+		mode:     hdr.Mode & 0o7777,
+		uid:      hdr.Uid,
+		gid:      hdr.Gid,
+		size:     hdr.Size,
+		modTime:  hdr.ModTime.Unix(),
+		linkname: hdr.Linkname,
+		devmajor: hdr.Devmajor,
+		devminor: hdr.Devminor,
+	}
+}
+
+// ociLayerPath returns the path used in image layers for a tarball entry name, relative to the root directory.
+func ociLayerPath(name string) string {
+	return strings.Trim(path.Clean("/"+name), "/")
+}
+
+// ociBlobPath returns the path of a blob in an OCI image layout.
+func ociBlobPath(layoutPath string, dgst digest.Digest) string {
+	return filepath.Join(layoutPath, "blobs", dgst.Algorithm().String(), dgst.Encoded())
+}
+
+// writeOCILayer adds a gzip compressed layer to the image layout at layoutPath, with the uncompressed content written
+// by fn. It returns the layer descriptor and the digest of the uncompressed content.
+func writeOCILayer(layoutPath string, fn func(w io.Writer) error) (*ociImage.Descriptor, digest.Digest, error) {
+	f, err := os.CreateTemp(layoutPath, "layer_")
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	blobHash := sha256.New()
+	diffHash := sha256.New()
+
+	gzipWriter := gzip.NewWriter(io.MultiWriter(f, blobHash))
+	err = fn(io.MultiWriter(gzipWriter, diffHash))
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, "", err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return nil, "", err
+	}
+
+	desc := &ociImage.Descriptor{
+		MediaType: ociImage.MediaTypeImageLayerGzip,
+		Digest:    digest.NewDigest(digest.SHA256, blobHash),
+		Size:      fi.Size(),
+	}
+
+	err = os.Rename(f.Name(), ociBlobPath(layoutPath, desc.Digest))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return desc, digest.NewDigest(digest.SHA256, diffHash), nil
+}
+
+// addOCIBaseLayer adds the root filesystem of the image an application container was created from as a layer of the
+// image layout at layoutPath. It returns the layer descriptor, the digest of the uncompressed content and the layer
+// entries, or a nil descriptor if the image isn't available locally as a compressed tarball.
+func addOCIBaseLayer(layoutPath string, fingerprint string) (*ociImage.Descriptor, digest.Digest, map[string]ociLayerEntry, error) {
+	rootfsPath := internalUtil.VarPath("images", fingerprint+".rootfs")
+
+	f, err := os.Open(rootfsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, "", nil, nil
+		}
+
+		return nil, "", nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	// The root filesystem of images retrieved from OCI registries is a gzip compressed tarball.
+	blobHash := sha256.New()
+	reader := bufio.NewReader(io.TeeReader(f, blobHash))
+
+	magic, err := reader.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return nil, "", nil, nil
+	}
+
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	diffHash := sha256.New()
+	diffReader := io.TeeReader(gzipReader, diffHash)
+
+	entries, err := ociLayerEntries(diffReader)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("Failed reading image %q: %w", fingerprint, err)
+	}
+
+	// Hash the remainder of the file.
+	_, err = io.Copy(io.Discard, diffReader)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	_, err = io.Copy(io.Discard, reader)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	desc := &ociImage.Descriptor{
+		MediaType: ociImage.MediaTypeImageLayerGzip,
+		Digest:    digest.NewDigest(digest.SHA256, blobHash),
+		Size:      fi.Size(),
+	}
+
+	err = os.Symlink(rootfsPath, ociBlobPath(layoutPath, desc.Digest))
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return desc, digest.NewDigest(digest.SHA256, diffHash), entries, nil
+}
+
+// ociLayerEntries returns the entries of the layer tarball read from r, indexed by path.
+func ociLayerEntries(r io.Reader) (map[string]ociLayerEntry, error) {
+	tarReader := tar.NewReader(r)
+
+	entries := map[string]ociLayerEntry{}
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		hdr.Name = ociLayerPath(hdr.Name)
+		if hdr.Typeflag == tar.TypeLink {
+			hdr.Linkname = ociLayerPath(hdr.Linkname)
+		}
+
+		entry := newOCILayerEntry(hdr)
+		if hdr.Typeflag == tar.TypeReg {
+			entry.digest, err = digest.FromReader(tarReader)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		entries[hdr.Name] = entry
+	}
+
+	return entries, nil
+}
+
+// writeOCIDiffLayer writes to w the layer holding the changes of the root filesystem in the instance export tarball
+// read from r compared to the base layer entries. Unchanged directories are only included as parents of changes and
+// removed files are recorded as whiteouts. Regular files whose attributes match the base layer are spooled to a
+// temporary file in tmpDir while comparing their content.
+func writeOCIDiffLayer(w io.Writer, r io.Reader, base map[string]ociLayerEntry, tmpDir string) error {
+	tarReader := tar.NewReader(r)
+	tarWriter := tar.NewWriter(w)
+
+	var spool *os.File
+	defer func() {
+		if spool != nil {
+			_ = spool.Close()
+			_ = os.Remove(spool.Name())
+		}
+	}()
+
+	types := map[string]byte{}
+	written := map[string]bool{}
+	unchangedDirs := map[string]*tar.Header{}
+
+	// writeParents writes the unchanged parent directories of a path which aren't in the layer yet.
+	writeParents := func(name string) error {
+		parents := []string{}
+		for dir := path.Dir(name); dir != "." && !written[dir]; dir = path.Dir(dir) {
+			parents = append(parents, dir)
+		}
+
+		for _, dir := range slices.Backward(parents) {
+			hdr, ok := unchangedDirs[dir]
+			if !ok {
+				continue
+			}
+
+			err := tarWriter.WriteHeader(hdr)
+			if err != nil {
+				return err
+			}
+
+			written[dir] = true
+		}
+
+		return nil
+	}
+
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// Only keep the root filesystem.
+		if hdr.Name != "rootfs" && !strings.HasPrefix(hdr.Name, "rootfs/") {
+			continue
+		}
+
+		hdr.Name = ociLayerPath(strings.TrimPrefix(hdr.Name, "rootfs"))
+		if hdr.Name == "" {
+			continue
+		}
+
+		if hdr.Typeflag == tar.TypeLink {
+			hdr.Linkname = ociLayerPath(strings.TrimPrefix(hdr.Linkname, "rootfs"))
+		}
+
+		hdr.Format = tar.FormatUnknown
+		types[hdr.Name] = hdr.Typeflag
+
+		// The content of regular files is compared separately as it can be modified without any change of size or
+		// modification time.
+		content := io.Reader(tarReader)
+		entry, ok := base[hdr.Name]
+		baseDigest := entry.digest
+		entry.digest = ""
+
+		if ok && entry == newOCILayerEntry(hdr) {
+			if hdr.Typeflag != tar.TypeReg {
+				if hdr.Typeflag == tar.TypeDir {
+					unchangedDirs[hdr.Name] = hdr
+				}
+
+				continue
+			}
+
+			if spool == nil {
+				spool, err = os.CreateTemp(tmpDir, "file_")
+				if err != nil {
+					return err
+				}
+			}
+
+			_, err = spool.Seek(0, io.SeekStart)
+			if err != nil {
+				return err
+			}
+
+			err = spool.Truncate(0)
+			if err != nil {
+				return err
+			}
+
+			digester := digest.Canonical.Digester()
+			_, err = io.Copy(io.MultiWriter(spool, digester.Hash()), tarReader)
+			if err != nil {
+				return err
+			}
+
+			if digester.Digest() == baseDigest {
+				continue
+			}
+
+			_, err = spool.Seek(0, io.SeekStart)
+			if err != nil {
+				return err
+			}
+
+			content = spool
+		}
+
+		err = writeParents(hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		err = tarWriter.WriteHeader(hdr)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(tarWriter, content)
+		if err != nil {
+			return err
+		}
+
+		written[hdr.Name] = true
+	}
+
+	// Record the removed files, skipping those whose parent directory was removed or replaced.
+	now := time.Now()
+	for _, name := range slices.Sorted(maps.Keys(base)) {
+		_, ok := types[name]
+		if ok || name == "" {
+			continue
+		}
+
+		dir := path.Dir(name)
+		if dir != "." && types[dir] != tar.TypeDir {
+			continue
+		}
+
+		err := writeParents(name)
+		if err != nil {
+			return err
+		}
+
+		err = tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     path.Join(dir, ".wh."+path.Base(name)),
+			Mode:     0o600,
+			ModTime:  now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tarWriter.Close()
+}
+
+// ociInstanceConfig returns the OCI image config of an application container, based on its configuration.
+func ociInstanceConfig(inst instance.Instance, diffIDs []digest.Digest) (*ociImage.Image, error) {
+	arch, variant, err := osarch.ArchitectureOCIPlatform(inst.Architecture())
+	if err != nil {
+		return nil, err
+	}
+
+	config := inst.ExpandedConfig()
+	created := time.Now().UTC()
+
+	image := &ociImage.Image{
+		Created: &created,
+		Platform: ociImage.Platform{
+			Architecture: arch,
+			OS:           "linux",
+			Variant:      variant,
+		},
+		Config: ociImage.ImageConfig{
+			WorkingDir: config["oci.cwd"],
+		},
+		RootFS: ociImage.RootFS{
+			Type:    "layers",
+			DiffIDs: diffIDs,
+		},
+	}
+
+	if config["oci.entrypoint"] != "" {
+		image.Config.Entrypoint, err = shellquote.Split(config["oci.entrypoint"])
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing the entrypoint: %w", err)
+		}
+	}
+
+	if config["oci.uid"] != "" || config["oci.gid"] != "" {
+		image.Config.User = config["oci.uid"]
+		if image.Config.User == "" {
+			image.Config.User = "0"
+		}
+
+		if config["oci.gid"] != "" {
+			image.Config.User += ":" + config["oci.gid"]
+		}
+	}
+
+	for key, value := range config {
+		name, ok := strings.CutPrefix(key, "environment.")
+		if ok {
+			image.Config.Env = append(image.Config.Env, name+"="+value)
+		}
+	}
+
+	slices.Sort(image.Config.Env)
+
+	return image, nil
+}
+
+// imgPostInstanceOCI publishes an application container or one of its snapshots as an OCI image and pushes it to the
+// target registry under the requested aliases. It returns the digest of the image manifest.
+//
+// The image is made of the root filesystem of the image the instance was created from, if still available, and of
+// a layer with the changes made by the instance.
+func imgPostInstanceOCI(s *state.State, r *http.Request, req api.ImagesPost, op *operations.Operation, builddir string) (string, error) {
+	projectName := request.ProjectParam(r)
+	name := req.Source.Name
+
+	if name == "" {
+		return "", errors.New("No source provided")
+	}
+
+	if req.Source.Type == "snapshot" && !internalInstance.IsSnapshot(name) {
+		return "", errors.New("Not a snapshot")
+	}
+
+	if req.Target == nil || req.Target.Server == "" {
+		return "", errors.New("No target registry provided")
+	}
+
+	registryURL, err := url.Parse(req.Target.Server)
+	if err != nil {
+		return "", errors.New("Invalid target registry URL")
+	}
+
+	if registryURL.User != nil {
+		return "", errors.New("The registry credentials must be set through the target username and password")
+	}
+
+	if len(req.Aliases) == 0 {
+		return "", errors.New("No image name provided")
+	}
+
+	c, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return "", err
+	}
+
+	if c.Type() != instancetype.Container || !util.IsTrue(c.LocalConfig()["volatile.container.oci"]) {
+		return "", errors.New("Only application containers can be published as OCI images")
+	}
+
+	// Prepare the image layout.
+	layoutPath := filepath.Join(builddir, "oci")
+	err = os.MkdirAll(filepath.Join(layoutPath, "blobs", digest.SHA256.String()), 0o700)
+	if err != nil {
+		return "", err
+	}
+
+	var layers []ociImage.Descriptor
+	var diffIDs []digest.Digest
+
+	baseLayer, baseDiffID, baseEntries, err := addOCIBaseLayer(layoutPath, c.LocalConfig()["volatile.base_image"])
+	if err != nil {
+		return "", err
+	}
+
+	if baseLayer != nil {
+		layers = append(layers, *baseLayer)
+		diffIDs = append(diffIDs, baseDiffID)
+	}
+
+	// Export the instance and extract its changes.
+	tracker := &ioprogress.ProgressTracker{
+		Handler: func(value, speed int64) {
+			metadata := make(map[string]any)
+			operations.SetProgressMetadata(metadata, "create_image_from_container_pack", "Exporting", value, 0, 0)
+			_ = op.UpdateMetadata(metadata)
+		},
+	}
+
+	exportReader, exportWriter := io.Pipe()
+	exportErr := make(chan error, 1)
+	go func() {
+		_, err := c.Export(exportWriter, nil, nil, time.Time{}, tracker)
+		_ = exportWriter.CloseWithError(err)
+		exportErr <- err
+	}()
+
+	layer, diffID, err := writeOCILayer(layoutPath, func(w io.Writer) error {
+		err := writeOCIDiffLayer(w, exportReader, baseEntries, builddir)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(io.Discard, exportReader)
+		return err
+	})
+
+	_ = exportReader.Close()
+	errExport := <-exportErr
+	if errExport != nil {
+		return "", errExport
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	layers = append(layers, *layer)
+	diffIDs = append(diffIDs, diffID)
+
+	// Write the config, manifest, index and layout marker.
+	config, err := ociInstanceConfig(c, diffIDs)
+	if err != nil {
+		return "", err
+	}
+
+	configData, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	configDigest := digest.FromBytes(configData)
+	err = os.WriteFile(ociBlobPath(layoutPath, configDigest), configData, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	manifest := ociImage.Manifest{
+		Versioned: ociImageSpecs.Versioned{SchemaVersion: 2},
+		MediaType: ociImage.MediaTypeImageManifest,
+		Config: ociImage.Descriptor{
+			MediaType: ociImage.MediaTypeImageConfig,
+			Digest:    configDigest,
+			Size:      int64(len(configData)),
+		},
+		Layers: layers,
+	}
+
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	manifestDigest := digest.FromBytes(manifestData)
+	err = os.WriteFile(ociBlobPath(layoutPath, manifestDigest), manifestData, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	index := ociImage.Index{
+		Versioned: ociImageSpecs.Versioned{SchemaVersion: 2},
+		MediaType: ociImage.MediaTypeImageIndex,
+		Manifests: []ociImage.Descriptor{{
+			MediaType: ociImage.MediaTypeImageManifest,
+			Digest:    manifestDigest,
+			Size:      int64(len(manifestData)),
+		}},
+	}
+
+	indexData, err := json.Marshal(index)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(filepath.Join(layoutPath, ociImage.ImageIndexFile), indexData, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	layoutData, err := json.Marshal(ociImage.ImageLayout{Version: ociImage.ImageLayoutVersion})
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(filepath.Join(layoutPath, ociImage.ImageLayoutFile), layoutData, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	// Push the image.
+	connectURL := *registryURL
+	if req.Target.Username != "" {
+		connectURL.User = url.UserPassword(req.Target.Username, req.Target.Password)
+	}
+
+	registry, err := incus.ConnectOCI(connectURL.String(), &incus.ConnectionArgs{
+		TLSServerCert: req.Target.Certificate,
+		UserAgent:     version.UserAgent,
+		Proxy:         s.Proxy,
+	})
+	if err != nil {
+		return "", fmt.Errorf("Failed to connect to oci server %q: %w", registryURL.Redacted(), err)
+	}
+
+	progress := func(progress ioprogress.ProgressData) {
+		_ = op.ExtendMetadata(map[string]any{"push_progress": progress.Text})
+	}
+
+	var imageDigest string
+	for _, alias := range req.Aliases {
+		imageDigest, err = registry.PushOCIImage(layoutPath, alias.Name, progress)
+		if err != nil {
+			return "", fmt.Errorf("Failed pushing image %q: %w", alias.Name, err)
+		}
+	}
+
+	return imageDigest, nil
+}
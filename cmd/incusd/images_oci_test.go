@@ -0,0 +1,185 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+	"time"
+)
+
+// ociTestEntry is an entry of a test tarball.
+type ociTestEntry struct {
+	name     string
+	typeflag byte
+	mode     int64
+	content  string
+	linkname string
+}
+
+// ociTestTarball returns a tarball holding the given entries, all with the same modification time.
+func ociTestTarball(t *testing.T, prefix string, entries []ociTestEntry) []byte {
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	buf := bytes.Buffer{}
+	tarWriter := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		mode := entry.mode
+		if mode == 0 {
+			mode = 0o644
+		}
+
+		err := tarWriter.WriteHeader(&tar.Header{
+			Typeflag: entry.typeflag,
+			Name:     prefix + entry.name,
+			Mode:     mode,
+			Size:     int64(len(entry.content)),
+			ModTime:  modTime,
+			Linkname: entry.linkname,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		_, err = tarWriter.Write([]byte(entry.content))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	err := tarWriter.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestWriteOCIDiffLayer(t *testing.T) {
+	base, err := ociLayerEntries(bytes.NewReader(ociTestTarball(t, "./", []ociTestEntry{
+		{name: "etc/", typeflag: tar.TypeDir, mode: 0o755},
+		{name: "etc/hostname", typeflag: tar.TypeReg, content: "base"},
+		{name: "etc/hosts", typeflag: tar.TypeReg, content: "127.0.0.1 localhost"},
+		{name: "etc/motd", typeflag: tar.TypeReg, content: "hello"},
+		{name: "etc/passwd", typeflag: tar.TypeReg, content: "root:x:0:0"},
+		{name: "etc/shadow", typeflag: tar.TypeReg, mode: 0o640, content: "root:*"},
+		{name: "etc/localtime", typeflag: tar.TypeSymlink, linkname: "/usr/share/zoneinfo/UTC"},
+		{name: "opt/", typeflag: tar.TypeDir, mode: 0o755},
+		{name: "opt/app/", typeflag: tar.TypeDir, mode: 0o755},
+		{name: "opt/app/bin", typeflag: tar.TypeReg, content: "binary"},
+		{name: "srv/", typeflag: tar.TypeDir, mode: 0o755},
+		{name: "srv/data", typeflag: tar.TypeReg, content: "data"},
+		{name: "usr/", typeflag: tar.TypeDir, mode: 0o755},
+		{name: "usr/bin/", typeflag: tar.TypeDir, mode: 0o755},
+		{name: "usr/bin/sh", typeflag: tar.TypeReg, mode: 0o755, content: "shell"},
+	})))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	export := ociTestTarball(t, "", []ociTestEntry{
+		{name: "backup/index.yaml", typeflag: tar.TypeReg, content: "name: c1"},
+		{name: "rootfs/", typeflag: tar.TypeDir, mode: 0o755},
+		{name: "rootfs/etc/", typeflag: tar.TypeDir, mode: 0o755},
+		// Unchanged.
+		{name: "rootfs/etc/hostname", typeflag: tar.TypeReg, content: "base"},
+		// Modified with the same size and modification time.
+		{name: "rootfs/etc/hosts", typeflag: tar.TypeReg, content: "127.0.0.1 otherhost"},
+		// Modified mode.
+		{name: "rootfs/etc/shadow", typeflag: tar.TypeReg, mode: 0o600, content: "root:*"},
+		// Modified symlink target.
+		{name: "rootfs/etc/localtime", typeflag: tar.TypeSymlink, linkname: "/usr/share/zoneinfo/Europe/Paris"},
+		// Removed directory replaced by a file.
+		{name: "rootfs/opt", typeflag: tar.TypeReg, content: "not a directory"},
+		// Unchanged parent directories of a new file.
+		{name: "rootfs/usr/", typeflag: tar.TypeDir, mode: 0o755},
+		{name: "rootfs/usr/bin/", typeflag: tar.TypeDir, mode: 0o755},
+		{name: "rootfs/usr/bin/sh", typeflag: tar.TypeReg, mode: 0o755, content: "shell"},
+		{name: "rootfs/usr/bin/app", typeflag: tar.TypeReg, mode: 0o755, content: "new"},
+		{name: "rootfs/usr/bin/app-link", typeflag: tar.TypeLink, linkname: "rootfs/usr/bin/app"},
+	})
+
+	buf := bytes.Buffer{}
+	err = writeOCIDiffLayer(&buf, bytes.NewReader(export), base, t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tarReader := tar.NewReader(&buf)
+	names := []string{}
+	contents := map[string]string{}
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		names = append(names, hdr.Name)
+		contents[hdr.Name] = string(content)
+
+		if hdr.Name == "usr/bin/app-link" && hdr.Linkname != "usr/bin/app" {
+			t.Fatalf("Expected hard link to %q, got %q", "usr/bin/app", hdr.Linkname)
+		}
+	}
+
+	// Removed files are whited out after the changes, only once for removed directories.
+	want := []string{
+		"etc",
+		"etc/hosts",
+		"etc/shadow",
+		"etc/localtime",
+		"opt",
+		"usr",
+		"usr/bin",
+		"usr/bin/app",
+		"usr/bin/app-link",
+		"etc/.wh.motd",
+		"etc/.wh.passwd",
+		".wh.srv",
+	}
+
+	if !slices.Equal(names, want) {
+		t.Fatalf("Expected layer entries %q, got %q", want, names)
+	}
+
+	if contents["etc/hosts"] != "127.0.0.1 otherhost" {
+		t.Fatalf("Unexpected content of modified file: %q", contents["etc/hosts"])
+	}
+
+	if contents["usr/bin/app"] != "new" {
+		t.Fatalf("Unexpected content of new file: %q", contents["usr/bin/app"])
+	}
+}
+
+func TestWriteOCIDiffLayerUnchanged(t *testing.T) {
+	entries := []ociTestEntry{
+		{name: "etc/", typeflag: tar.TypeDir, mode: 0o755},
+		{name: "etc/hostname", typeflag: tar.TypeReg, content: "base"},
+	}
+
+	base, err := ociLayerEntries(bytes.NewReader(ociTestTarball(t, "", entries)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	buf := bytes.Buffer{}
+	err = writeOCIDiffLayer(&buf, bytes.NewReader(ociTestTarball(t, "rootfs/", entries)), base, t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = tar.NewReader(&buf).Next()
+	if err != io.EOF {
+		t.Fatalf("Expected an empty layer, got %v", err)
+	}
+}
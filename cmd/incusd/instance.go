@@ -20,16 +20,19 @@ import (
 	"github.com/lxc/incus/v7/internal/server/db"
 	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
 	"github.com/lxc/incus/v7/internal/server/db/operationtype"
+	deviceConfig "github.com/lxc/incus/v7/internal/server/device/config"
 	"github.com/lxc/incus/v7/internal/server/instance"
 	"github.com/lxc/incus/v7/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v7/internal/server/instance/operationlock"
 	"github.com/lxc/incus/v7/internal/server/locking"
 	"github.com/lxc/incus/v7/internal/server/operations"
 	"github.com/lxc/incus/v7/internal/server/project"
+	"github.com/lxc/incus/v7/internal/server/scriptlet"
 	"github.com/lxc/incus/v7/internal/server/state"
 	storagePools "github.com/lxc/incus/v7/internal/server/storage"
 	"github.com/lxc/incus/v7/internal/server/task"
 	"github.com/lxc/incus/v7/shared/api"
+	apiScriptlet "github.com/lxc/incus/v7/shared/api/scriptlet"
 	"github.com/lxc/incus/v7/shared/logger"
 	"github.com/lxc/incus/v7/shared/revert"
 	"github.com/lxc/incus/v7/shared/util"
@@ -843,3 +846,47 @@ func instanceOperationLock(ctx context.Context, projectName string, instanceName
 
 	return locking.Lock(ctx, fmt.Sprintf("InstanceOperation_%s", project.Instance(projectName, instanceName)))
 }
+
+// instanceValidationRun runs the instance validation scriptlet (if configured) against the expanded config and
+// devices of an instance or profile. The config changes requested by the scriptlet are applied to the provided
+// local config, with an empty value unsetting the key.
+func instanceValidationRun(s *state.State, reason string, projectName string, name string, instanceType string, profiles []api.Profile, config map[string]string, devices map[string]map[string]string) error {
+	if s.GlobalConfig.InstancesValidationScriptlet() == "" {
+		return nil
+	}
+
+	profileNames := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		profileNames = append(profileNames, profile.Name)
+	}
+
+	req := apiScriptlet.InstanceValidation{
+		Reason:   reason,
+		Project:  projectName,
+		Name:     name,
+		Type:     instanceType,
+		Profiles: profileNames,
+		Config:   db.ExpandInstanceConfig(config, profiles),
+		Devices:  db.ExpandInstanceDevices(deviceConfig.NewDevices(devices), profiles).CloneNative(),
+	}
+
+	changes, err := scriptlet.InstanceValidationRun(logger.Log, &req)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusBadRequest) {
+			return err
+		}
+
+		return fmt.Errorf("Failed instance validation scriptlet: %w", err)
+	}
+
+	for key, value := range changes {
+		if value == "" {
+			delete(config, key)
+			continue
+		}
+
+		config[key] = value
+	}
+
+	return nil
+}
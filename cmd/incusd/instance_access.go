@@ -1,16 +1,37 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
 
 	internalInstance "github.com/lxc/incus/v7/internal/instance"
+	"github.com/lxc/incus/v7/internal/server/db"
+	"github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/internal/server/instance"
+	"github.com/lxc/incus/v7/internal/server/lifecycle"
+	"github.com/lxc/incus/v7/internal/server/project"
 	"github.com/lxc/incus/v7/internal/server/request"
 	"github.com/lxc/incus/v7/internal/server/response"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
 )
 
 // swagger:operation GET /1.0/instances/{name}/access instances instance_access
@@ -89,3 +110,422 @@ func instanceAccess(d *Daemon, r *http.Request) response.Response {
 
 	return response.SyncResponse(true, access)
 }
+
+// instanceAccessUserRegex matches the user names which can be passed to the guest tools as-is.
+var instanceAccessUserRegex = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,31}$`)
+
+// swagger:operation POST /1.0/instances/{name}/access/credentials instances instance_access_credentials_post
+//
+//	Set the credentials of a user of an instance
+//
+//	Sets the password and/or adds SSH authorized keys for a user inside of a running instance.
+//	This goes through the agent for virtual machines.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: name
+//	    description: Instance name
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: credentials
+//	    description: Credentials
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceAccessCredentialsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceAccessCredentialsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if internalInstance.IsSnapshot(name) {
+		return response.BadRequest(errors.New("Invalid instance name"))
+	}
+
+	// Check project restrictions.
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return err
+		}
+
+		p, err := dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		return project.AllowInstanceCredentials(p)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Handle requests targeted to an instance on a different node
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	// Parse the request.
+	req := api.InstanceAccessCredentialsPost{}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if !instanceAccessUserRegex.MatchString(req.User) {
+		return response.BadRequest(fmt.Errorf("Invalid user name %q", req.User))
+	}
+
+	if req.Password == "" && len(req.SSHKeys) == 0 {
+		return response.BadRequest(errors.New("A password or SSH keys must be provided"))
+	}
+
+	if strings.ContainsAny(req.Password, "\r\n") {
+		return response.BadRequest(errors.New("The password can't contain line breaks"))
+	}
+
+	for _, key := range req.SSHKeys {
+		_, _, _, rest, err := ssh.ParseAuthorizedKey([]byte(key))
+		if err != nil || len(rest) > 0 {
+			return response.BadRequest(fmt.Errorf("Invalid SSH key %q", key))
+		}
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !inst.IsRunning() {
+		return response.BadRequest(errors.New("Instance must be running to set credentials"))
+	}
+
+	if req.Password != "" {
+		err = instanceAccessSetPassword(inst, req.User, req.Password)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	if len(req.SSHKeys) > 0 {
+		err = instanceAccessAddSSHKeys(inst, req.User, req.SSHKeys)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceCredentialsUpdated.Event(inst, logger.Ctx{"user": req.User, "password": req.Password != "", "ssh_keys": len(req.SSHKeys)}))
+
+	return response.EmptySyncResponse
+}
+
+// instanceAccessSetPassword sets the password of a user using the guest tools.
+// The password is passed on stdin so that it doesn't show up in the command line or events.
+func instanceAccessSetPassword(inst instance.Instance, user string, password string) error {
+	var command []string
+	input := password + "\n"
+
+	switch inst.GuestOS() {
+	case "windows":
+		command = []string{"powershell.exe", "-NoProfile", "-NonInteractive", "-Command", fmt.Sprintf("Set-LocalUser -Name '%s' -Password (ConvertTo-SecureString ([Console]::In.ReadLine()) -AsPlainText -Force)", user)}
+	case "freebsd":
+		command = []string{"pw", "usermod", user, "-h", "0"}
+	case "macos":
+		return api.StatusErrorf(http.StatusNotImplemented, "Setting passwords isn't supported on macOS guests")
+	default:
+		command = []string{"chpasswd"}
+		input = user + ":" + input
+	}
+
+	return instanceAccessExec(inst, command, input)
+}
+
+// instanceAccessAddSSHKeys adds SSH keys to the authorized keys of a user, skipping those already present.
+func instanceAccessAddSSHKeys(inst instance.Instance, user string, keys []string) error {
+	if inst.GuestOS() == "windows" {
+		return api.StatusErrorf(http.StatusNotImplemented, "Adding SSH keys isn't supported on Windows guests")
+	}
+
+	client, err := inst.FileSFTP()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = client.Close() }()
+
+	// Look up the user.
+	passwd, err := client.Open("/etc/passwd")
+	if err != nil {
+		return fmt.Errorf("Failed to open /etc/passwd: %w", err)
+	}
+
+	defer func() { _ = passwd.Close() }()
+
+	var uid, gid int
+	var home string
+
+	scanner := bufio.NewScanner(passwd)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 6 || fields[0] != user {
+			continue
+		}
+
+		uid, err = strconv.Atoi(fields[2])
+		if err != nil {
+			return fmt.Errorf("Invalid uid for user %q: %w", user, err)
+		}
+
+		gid, err = strconv.Atoi(fields[3])
+		if err != nil {
+			return fmt.Errorf("Invalid gid for user %q: %w", user, err)
+		}
+
+		home = fields[5]
+		break
+	}
+
+	if home == "" {
+		return api.StatusErrorf(http.StatusNotFound, "User %q not found in the instance", user)
+	}
+
+	return instanceAccessWriteSSHKeys(client, home, uid, gid, keys)
+}
+
+// instanceAccessWriteSSHKeys adds SSH keys to the authorized keys in the given home directory.
+// As the files are written as root, symlinks are never followed and existing authorized keys are only
+// changed when owned by the user, so that users can't get other files of the instance changed.
+func instanceAccessWriteSSHKeys(client *sftp.Client, home string, uid int, gid int, keys []string) error {
+	info, err := instanceAccessLstat(client, home)
+	if err != nil {
+		return fmt.Errorf("Failed to check %q: %w", home, err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("Home directory %q isn't a directory", home)
+	}
+
+	// Create the .ssh directory.
+	sshPath := path.Join(home, ".ssh")
+	info, err = instanceAccessLstat(client, sshPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = client.Mkdir(sshPath)
+		if err != nil {
+			return fmt.Errorf("Failed to create %q: %w", sshPath, err)
+		}
+
+		// Check the new directory again right before changing it, in case it got replaced.
+		info, err = instanceAccessLstat(client, sshPath)
+		if err != nil {
+			return fmt.Errorf("Failed to check %q: %w", sshPath, err)
+		}
+
+		if !info.IsDir() {
+			return fmt.Errorf("%q isn't a directory", sshPath)
+		}
+
+		err = client.Chmod(sshPath, 0o700)
+		if err != nil {
+			return err
+		}
+
+		err = client.Chown(sshPath, uid, gid)
+		if err != nil {
+			return err
+		}
+	} else if err != nil {
+		return fmt.Errorf("Failed to check %q: %w", sshPath, err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("%q isn't a directory", sshPath)
+	}
+
+	// Open the authorized keys, creating them exclusively so that a symlink created in the meantime isn't followed.
+	keysPath := path.Join(sshPath, "authorized_keys")
+	created := false
+
+	info, err = instanceAccessLstat(client, keysPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		created = true
+	} else if err != nil {
+		return fmt.Errorf("Failed to check %q: %w", keysPath, err)
+	} else if !info.Mode().IsRegular() {
+		return fmt.Errorf("%q isn't a regular file", keysPath)
+	}
+
+	flags := os.O_RDWR | os.O_APPEND
+	if created {
+		flags |= os.O_CREATE | os.O_EXCL
+	}
+
+	f, err := client.OpenFile(keysPath, flags)
+	if err != nil {
+		return fmt.Errorf("Failed to open %q: %w", keysPath, err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	// Check what was actually opened, in case the file got replaced after being checked.
+	info, err = f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%q isn't a regular file", keysPath)
+	}
+
+	if !created {
+		stat, ok := info.Sys().(*sftp.FileStat)
+		if !ok || int(stat.UID) != uid {
+			return fmt.Errorf("%q isn't owned by the user", keysPath)
+		}
+	}
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	existing := strings.Split(string(content), "\n")
+
+	var added strings.Builder
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		added.WriteString("\n")
+	}
+
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if slices.Contains(existing, key) {
+			continue
+		}
+
+		added.WriteString(key + "\n")
+	}
+
+	_, err = f.Write([]byte(added.String()))
+	if err != nil {
+		return fmt.Errorf("Failed to write %q: %w", keysPath, err)
+	}
+
+	err = f.Chmod(0o600)
+	if err != nil {
+		return err
+	}
+
+	return f.Chown(uid, gid)
+}
+
+// instanceAccessLstat returns the file information of a path inside of the instance, failing if any of its
+// components is a symlink or if any but the last one isn't a directory.
+func instanceAccessLstat(client *sftp.Client, p string) (fs.FileInfo, error) {
+	p = path.Clean(p)
+	if !path.IsAbs(p) {
+		return nil, fmt.Errorf("Path %q isn't absolute", p)
+	}
+
+	current := "/"
+	info, err := client.Lstat(current)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, part := range strings.Split(strings.TrimPrefix(p, "/"), "/") {
+		if part == "" {
+			continue
+		}
+
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%q isn't a directory", current)
+		}
+
+		current = path.Join(current, part)
+
+		info, err = client.Lstat(current)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return nil, fmt.Errorf("Refusing to follow symlink %q", current)
+		}
+	}
+
+	return info, nil
+}
+
+// instanceAccessExec runs a command inside of the instance, passing it the given input on stdin.
+func instanceAccessExec(inst instance.Instance, command []string, input string) error {
+	stdinRead, stdinWrite, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stdinRead.Close() }()
+
+	output, err := os.CreateTemp("", "incus_credentials_")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = output.Close()
+		_ = os.Remove(output.Name())
+	}()
+
+	req := api.InstanceExecPost{
+		Command:     command,
+		Environment: map[string]string{"PATH": "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin", "LANG": "C.UTF-8"},
+	}
+
+	cmd, err := inst.Exec(req, stdinRead, output, output)
+	if err != nil {
+		_ = stdinWrite.Close()
+		return err
+	}
+
+	_, _ = stdinWrite.Write([]byte(input))
+	_ = stdinWrite.Close()
+
+	exitStatus, err := cmd.Wait()
+	if err != nil {
+		return err
+	}
+
+	if exitStatus != 0 {
+		msg, _ := os.ReadFile(output.Name())
+		return fmt.Errorf("Failed running %q (exit status %d): %s", command[0], exitStatus, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/sftp"
+)
+
+// instanceAccessTestClient returns an SFTP client for the local file system.
+func instanceAccessTestClient(t *testing.T) *sftp.Client {
+	clientConn, serverConn := net.Pipe()
+
+	server, err := sftp.NewServer(serverConn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	go func() { _ = server.Serve() }()
+
+	client, err := sftp.NewClientPipe(clientConn, clientConn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+	})
+
+	return client
+}
+
+func TestInstanceAccessWriteSSHKeys(t *testing.T) {
+	client := instanceAccessTestClient(t)
+
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	home := filepath.Join(tmpDir, "alice")
+	err = os.Mkdir(home, 0o755)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = instanceAccessWriteSSHKeys(client, home, os.Getuid(), os.Getgid(), []string{"ssh-ed25519 AAAA1 one", " ssh-ed25519 AAAA2 two "})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Keys already present are skipped.
+	err = instanceAccessWriteSSHKeys(client, home, os.Getuid(), os.Getgid(), []string{"ssh-ed25519 AAAA2 two", "ssh-ed25519 AAAA3 three"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(home, ".ssh", "authorized_keys"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "ssh-ed25519 AAAA1 one\nssh-ed25519 AAAA2 two\nssh-ed25519 AAAA3 three\n"
+	if string(content) != want {
+		t.Fatalf("Expected authorized keys %q, got %q", want, content)
+	}
+
+	for p, mode := range map[string]os.FileMode{".ssh": 0o700, ".ssh/authorized_keys": 0o600} {
+		info, err := os.Stat(filepath.Join(home, p))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if info.Mode().Perm() != mode {
+			t.Fatalf("Expected mode %o for %q, got %o", mode, p, info.Mode().Perm())
+		}
+	}
+
+	// A missing final newline is added before the new keys.
+	err = os.WriteFile(filepath.Join(home, ".ssh", "authorized_keys"), []byte("ssh-ed25519 AAAA1 one"), 0o600)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = instanceAccessWriteSSHKeys(client, home, os.Getuid(), os.Getgid(), []string{"ssh-ed25519 AAAA2 two"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err = os.ReadFile(filepath.Join(home, ".ssh", "authorized_keys"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want = "ssh-ed25519 AAAA1 one\nssh-ed25519 AAAA2 two\n"
+	if string(content) != want {
+		t.Fatalf("Expected authorized keys %q, got %q", want, content)
+	}
+}
+
+func TestInstanceAccessWriteSSHKeysSymlinks(t *testing.T) {
+	client := instanceAccessTestClient(t)
+
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A directory and a file which the user mustn't be able to get changed.
+	targetDir := filepath.Join(tmpDir, "etc")
+	targetFile := filepath.Join(targetDir, "shadow")
+
+	err = os.Mkdir(targetDir, 0o755)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = os.WriteFile(targetFile, []byte("root:secret\n"), 0o640)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		setup func(home string) error
+	}{
+		{
+			name: "symlinked .ssh",
+			setup: func(home string) error {
+				return os.Symlink(targetDir, filepath.Join(home, ".ssh"))
+			},
+		},
+		{
+			name: "symlinked authorized_keys",
+			setup: func(home string) error {
+				err := os.Mkdir(filepath.Join(home, ".ssh"), 0o700)
+				if err != nil {
+					return err
+				}
+
+				return os.Symlink(targetFile, filepath.Join(home, ".ssh", "authorized_keys"))
+			},
+		},
+		{
+			name: "authorized_keys not a regular file",
+			setup: func(home string) error {
+				return os.MkdirAll(filepath.Join(home, ".ssh", "authorized_keys"), 0o700)
+			},
+		},
+		{
+			name: ".ssh not a directory",
+			setup: func(home string) error {
+				return os.WriteFile(filepath.Join(home, ".ssh"), nil, 0o600)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := filepath.Join(tmpDir, "home-"+filepath.Base(t.Name()))
+			err := os.Mkdir(home, 0o755)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			err = tt.setup(home)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			err = instanceAccessWriteSSHKeys(client, home, os.Getuid(), os.Getgid(), []string{"ssh-ed25519 AAAA1 one"})
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+
+	// A symlinked home directory is refused too.
+	err = os.Symlink(targetDir, filepath.Join(tmpDir, "home-link"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = instanceAccessWriteSSHKeys(client, filepath.Join(tmpDir, "home-link"), os.Getuid(), os.Getgid(), []string{"ssh-ed25519 AAAA1 one"})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	// Nothing was written through the symlinks.
+	content, err := os.ReadFile(targetFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if string(content) != "root:secret\n" {
+		t.Fatalf("Unexpected change of %q: %q", targetFile, content)
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("Unexpected files created in %q: %v", targetDir, entries)
+	}
+
+	info, err := os.Stat(targetFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if info.Mode().Perm() != 0o640 {
+		t.Fatalf("Unexpected mode change of %q: %o", targetFile, info.Mode().Perm())
+	}
+}
+
+func TestInstanceAccessWriteSSHKeysOwnership(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("Requires root to create files owned by another user")
+	}
+
+	client := instanceAccessTestClient(t)
+
+	home := t.TempDir()
+	err := os.Mkdir(filepath.Join(home, ".ssh"), 0o700)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = os.WriteFile(filepath.Join(home, ".ssh", "authorized_keys"), nil, 0o600)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Existing authorized keys not owned by the user are left alone.
+	err = instanceAccessWriteSSHKeys(client, home, 1000, 1000, []string{"ssh-ed25519 AAAA1 one"})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+}
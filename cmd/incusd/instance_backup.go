@@ -19,6 +19,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/db"
 	"github.com/lxc/incus/v7/internal/server/db/operationtype"
 	"github.com/lxc/incus/v7/internal/server/instance"
+	"github.com/lxc/incus/v7/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v7/internal/server/lifecycle"
 	"github.com/lxc/incus/v7/internal/server/operations"
 	"github.com/lxc/incus/v7/internal/server/project"
@@ -304,6 +305,20 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	if req.Since != "" {
+		if inst.Type() != instancetype.VM {
+			return response.BadRequest(errors.New("Incremental backups are only supported for virtual machines"))
+		}
+
+		if !inst.IsRunning() {
+			return response.BadRequest(errors.New("Incremental backups require the instance to be running"))
+		}
+
+		if req.OptimizedStorage {
+			return response.BadRequest(errors.New("Incremental backups can't use the optimized storage format"))
+		}
+	}
+
 	var reader *io.PipeReader
 	var writer *io.PipeWriter
 	var fullName string
@@ -368,6 +383,7 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 			RootOnly:             req.RootOnly,
 			OptimizedStorage:     req.OptimizedStorage,
 			CompressionAlgorithm: req.CompressionAlgorithm,
+			Since:                req.Since,
 		}
 
 		if !direct && req.Target == nil {
@@ -377,14 +393,17 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 		uploadRes := make(chan error)
 
 		// Start the upload in the background if requested.
+		var stored func() error
 		if req.Target != nil {
 			go func(resCh chan<- error) {
 				resCh <- internalBackup.Upload(reader, req.Target)
 			}(uploadRes)
+
+			stored = func() error { return <-uploadRes }
 		}
 
 		// Create the backup.
-		err := backupCreate(s, args, inst, op, writer)
+		err := backupCreate(s, args, inst, op, writer, stored)
 		if err != nil {
 			// If we receive a pipe closed error, we first check for an explicit error returned by the
 			// reader.
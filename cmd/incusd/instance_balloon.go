@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/linux"
+	"github.com/lxc/incus/v7/internal/server/instance"
+	"github.com/lxc/incus/v7/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v7/internal/server/task"
+	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/util"
+)
+
+// The host is considered under memory pressure when less than autoBalloonPressureStart percent of its memory
+// is available, and until more than autoBalloonPressureEnd percent of it is available again.
+const (
+	autoBalloonPressureStart = 20
+	autoBalloonPressureEnd   = 30
+)
+
+func autoBalloonInstancesTask(d *Daemon) (task.Func, task.Schedule) {
+	hostPressure := false
+
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		memTotal, err := linux.DeviceTotalMemory()
+		if err != nil {
+			logger.Warn("Failed getting host total memory", logger.Ctx{"err": err})
+			return
+		}
+
+		memAvailable, err := linux.GetMeminfo("MemAvailable")
+		if err != nil {
+			logger.Warn("Failed getting host available memory", logger.Ctx{"err": err})
+			return
+		}
+
+		percentAvailable := memAvailable * 100 / memTotal
+		if !hostPressure && percentAvailable < autoBalloonPressureStart {
+			logger.Info("Host under memory pressure, shrinking virtual machines", logger.Ctx{"available": memAvailable, "total": memTotal})
+			hostPressure = true
+		} else if hostPressure && percentAvailable > autoBalloonPressureEnd {
+			logger.Info("Host memory pressure is over, growing virtual machines", logger.Ctx{"available": memAvailable, "total": memTotal})
+			hostPressure = false
+		}
+
+		instances, err := instance.LoadNodeAll(s, instancetype.VM)
+		if err != nil {
+			logger.Warn("Failed loading instances for memory ballooning", logger.Ctx{"err": err})
+			return
+		}
+
+		for _, inst := range instances {
+			if !util.IsTrue(inst.ExpandedConfig()["limits.memory.auto_balloon"]) || !inst.IsRunning() {
+				continue
+			}
+
+			vm, ok := inst.(instance.VM)
+			if !ok {
+				continue
+			}
+
+			err := vm.BalanceMemory(hostPressure)
+			if err != nil {
+				logger.Warn("Failed resizing memory balloon", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			}
+		}
+	}
+
+	return f, task.Every(30 * time.Second)
+}
@@ -7,12 +7,16 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 
 	internalInstance "github.com/lxc/incus/v7/internal/instance"
+	"github.com/lxc/incus/v7/internal/server/backup"
+	"github.com/lxc/incus/v7/internal/server/db"
 	deviceConfig "github.com/lxc/incus/v7/internal/server/device/config"
 	"github.com/lxc/incus/v7/internal/server/instance"
 	"github.com/lxc/incus/v7/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v7/internal/server/project"
 	"github.com/lxc/incus/v7/internal/server/request"
 	"github.com/lxc/incus/v7/internal/server/response"
 	"github.com/lxc/incus/v7/shared/api"
@@ -103,8 +107,10 @@ func instanceBitmapsPost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	devNames := []string{rootDiskName}
+	dependentDevs := []deviceConfig.DeviceNamed{}
 	err = inst.ForEachDependentDiskType(func(dev deviceConfig.DeviceNamed) error {
 		devNames = append(devNames, dev.Name)
+		dependentDevs = append(dependentDevs, dev)
 
 		return nil
 	})
@@ -117,5 +123,26 @@ func instanceBitmapsPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Record the initial backup checkpoint of the bitmap on the instance and on its dependent custom volumes.
+	checkpointKey := backup.IncrementalCheckpointKey(req.Name)
+	checkpoint := uuid.New().String()
+
+	err = inst.VolatileSet(map[string]string{checkpointKey: checkpoint})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	volProjectName, err := project.StorageVolumeProject(s.DB.Cluster, inst.Project().Name, db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	for _, dev := range dependentDevs {
+		err = customVolumeUpdateVolatile(r.Context(), s, dev.Config["pool"], volProjectName, dev.Config["source"], map[string]string{checkpointKey: checkpoint})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	return response.EmptySyncResponse
 }
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/incus/v7/internal/filter"
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
+	"github.com/lxc/incus/v7/internal/server/instance"
+	"github.com/lxc/incus/v7/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v7/internal/server/request"
+	"github.com/lxc/incus/v7/internal/server/response"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// swagger:operation GET /1.0/instances/{name}/inventory instances instance_inventory_get
+//
+//	Get the software inventory of an instance
+//
+//	Returns the OS release, installed packages, logged-in users and services of a running instance,
+//	as reported by its agent. Only supported for VMs.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: name
+//	    description: Instance name
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter applied to the packages, users and services
+//	    type: string
+//	    example: name eq openssl
+//	responses:
+//	  "200":
+//	    description: Inventory
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceInventory"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceInventoryGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if internalInstance.IsSnapshot(name) {
+		return response.BadRequest(errors.New("Invalid instance name"))
+	}
+
+	// Parse filter value.
+	clauses, err := filter.Parse(request.QueryParam(r, "filter"), filter.QueryOperatorSet())
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid filter: %w", err))
+	}
+
+	// Handle requests targeted to an instance on a different node
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if inst.Type() != instancetype.VM {
+		return response.BadRequest(errors.New("Inventory is only supported for virtual machines"))
+	}
+
+	if !inst.IsRunning() {
+		return response.BadRequest(errors.New("Instance must be running to get its inventory"))
+	}
+
+	v, ok := inst.(instance.VM)
+	if !ok {
+		return response.InternalError(errors.New("Failed to cast inst to VM"))
+	}
+
+	inventory, err := v.Inventory()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if clauses != nil && len(clauses.Clauses) > 0 {
+		inventory.Packages, err = instanceInventoryFilter(inventory.Packages, clauses)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		inventory.Users, err = instanceInventoryFilter(inventory.Users, clauses)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		inventory.Services, err = instanceInventoryFilter(inventory.Services, clauses)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	return response.SyncResponse(true, inventory)
+}
+
+// instanceInventoryLoad returns the inventory of a running virtual machine for instance listings.
+// Failing to get it, for example when the agent isn't running, isn't an error there and only results in no inventory.
+func instanceInventoryLoad(inst instance.Instance) *api.InstanceInventory {
+	if inst.Type() != instancetype.VM || !inst.IsRunning() {
+		return nil
+	}
+
+	v, ok := inst.(instance.VM)
+	if !ok {
+		return nil
+	}
+
+	inventory, err := v.Inventory()
+	if err != nil {
+		logger.Debug("Failed getting instance inventory", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+		return nil
+	}
+
+	return inventory
+}
+
+// instanceInventoryFilter returns the inventory entries matching the given filter.
+// No entry matches if the filter refers to fields which the entries don't have.
+func instanceInventoryFilter[T any](entries []T, clauses *filter.ClauseSet) ([]T, error) {
+	filtered := []T{}
+
+	var zero T
+	for _, clause := range clauses.Clauses {
+		if filter.ValueOf(zero, clause.Field) == nil {
+			return filtered, nil
+		}
+	}
+
+	for _, entry := range entries {
+		match, err := filter.Match(entry, *clauses)
+		if err != nil {
+			return nil, err
+		}
+
+		if match {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return filtered, nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/response"
 	localUtil "github.com/lxc/incus/v7/internal/server/util"
 	"github.com/lxc/incus/v7/shared/api"
+	apiScriptlet "github.com/lxc/incus/v7/shared/api/scriptlet"
 	"github.com/lxc/incus/v7/shared/osarch"
 )
 
@@ -189,7 +190,7 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
-	// Check project limits.
+	// Load the profiles.
 	apiProfiles := make([]api.Profile, 0, len(req.Profiles))
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		profiles, err := cluster.GetProfilesIfEnabled(ctx, tx.Tx(), projectName, req.Profiles)
@@ -216,6 +217,20 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 			apiProfiles = append(apiProfiles, *apiProfile)
 		}
 
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Run the instance validation scriptlet, outside of any transaction as it may take a while.
+	err = instanceValidationRun(s, apiScriptlet.InstanceValidationReasonUpdate, projectName, name, c.Type().String(), apiProfiles, req.Config, req.Devices)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Check project limits.
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		return projecthelpers.AllowInstanceUpdate(tx, projectName, name, req, c.LocalConfig())
 	})
 	if err != nil {
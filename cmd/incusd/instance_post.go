@@ -307,6 +307,11 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			// Check if the current location is fine.
 			targetMemberInfo, _, err = project.CheckTarget(ctx, s.Authorizer, r, tx, targetProject, instLocation, allMembers)
 			if err == nil && targetMemberInfo != nil {
+				// An explicitly targeted member must satisfy the anti-affinity rules of the instance.
+				if target != "" && targetMemberInfo.Name != inst.Location() {
+					return instance.PlacementCheckMember(ctx, tx, instProject, name, inst.ExpandedConfig(), *targetMemberInfo)
+				}
+
 				return nil
 			}
 
@@ -316,6 +321,14 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				return err
 			}
 
+			// An explicitly targeted member must satisfy the anti-affinity rules of the instance.
+			if targetMemberInfo != nil {
+				err = instance.PlacementCheckMember(ctx, tx, instProject, name, inst.ExpandedConfig(), *targetMemberInfo)
+				if err != nil {
+					return err
+				}
+			}
+
 			// If no specific server, get a list of allowed candidates.
 			if targetMemberInfo == nil {
 				clusterGroupsAllowed := project.GetRestrictedClusterGroups(targetProject)
@@ -324,6 +337,12 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				if err != nil {
 					return err
 				}
+
+				// Apply the placement groups of the instance.
+				targetCandidates, err = instance.PlacementFilterMembers(ctx, tx, instProject, name, inst.ExpandedConfig(), targetCandidates, false)
+				if err != nil {
+					return err
+				}
 			}
 
 			return nil
@@ -25,6 +25,7 @@ import (
 	localUtil "github.com/lxc/incus/v7/internal/server/util"
 	"github.com/lxc/incus/v7/internal/version"
 	"github.com/lxc/incus/v7/shared/api"
+	apiScriptlet "github.com/lxc/incus/v7/shared/api/scriptlet"
 	"github.com/lxc/incus/v7/shared/osarch"
 	"github.com/lxc/incus/v7/shared/revert"
 )
@@ -130,7 +131,7 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 	var do func(*operations.Operation) error
 	var opType operationtype.Type
 	if configRaw.Restore == "" {
-		// Check project limits.
+		// Load the profiles.
 		apiProfiles := make([]api.Profile, 0, len(configRaw.Profiles))
 		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 			profiles, err := cluster.GetProfilesIfEnabled(ctx, tx.Tx(), projectName, configRaw.Profiles)
@@ -157,6 +158,24 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 				apiProfiles = append(apiProfiles, *apiProfile)
 			}
 
+			return nil
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Run the instance validation scriptlet, outside of any transaction as it may take a while.
+		if configRaw.Config == nil {
+			configRaw.Config = map[string]string{}
+		}
+
+		err = instanceValidationRun(s, apiScriptlet.InstanceValidationReasonUpdate, projectName, name, inst.Type().String(), apiProfiles, configRaw.Config, configRaw.Devices)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Check project limits.
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 			return projecthelpers.AllowInstanceUpdate(tx, projectName, name, configRaw, inst.LocalConfig())
 		})
 		if err != nil {
@@ -174,6 +174,20 @@ var instanceAccessCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: instanceAccess, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "name")},
 }
 
+var instanceAccessCredentialsCmd = APIEndpoint{
+	Name: "instanceAccessCredentials",
+	Path: "instances/{name}/access/credentials",
+
+	Post: APIEndpointAction{Handler: instanceAccessCredentialsPost, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanExec, "name")},
+}
+
+var instanceInventoryCmd = APIEndpoint{
+	Name: "instanceInventory",
+	Path: "instances/{name}/inventory",
+
+	Get: APIEndpointAction{Handler: instanceInventoryGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "name")},
+}
+
 var instanceDebugMemoryCmd = APIEndpoint{
 	Name: "instanceDebugMemory",
 	Path: "instances/{name}/debug/memory",
@@ -165,6 +165,10 @@ import (
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
+//    - in: query
+//      name: inventory
+//      description: Include the software inventory of running virtual machines
+//      type: boolean
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -215,6 +219,12 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 
 	mustLoadObjects := recursion > 0 || (recursion == 0 && clauses != nil && len(clauses.Clauses) > 0)
 
+	// The inventory is part of the full instance structs.
+	withInventory := util.IsTrue(r.FormValue("inventory"))
+	if withInventory && recursion < 2 {
+		return response.BadRequest(errors.New("The inventory requires recursion=2"))
+	}
+
 	// Detect project mode.
 	projectName := request.QueryParam(r, "project")
 	allProjects := util.IsTrue(r.FormValue("all-projects"))
@@ -345,7 +355,7 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 					return
 				}
 
-				cs, err := doInstancesFullGetFromNode(filteredProjects, memberAddress, allProjects, networkCert, s.ServerCert(), r)
+				cs, err := doInstancesFullGetFromNode(filteredProjects, memberAddress, allProjects, withInventory, networkCert, s.ServerCert(), r)
 				if err != nil {
 					for _, inst := range instances {
 						resultErrListAppend(inst, err)
@@ -419,9 +429,14 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 						c, _, err := inst.RenderFull(hostInterfaces)
 						if err != nil {
 							resultErrListAppend(dbInst, err)
-						} else {
-							resultFullListAppend(c)
+							continue
+						}
+
+						if withInventory {
+							c.Inventory = instanceInventoryLoad(inst)
 						}
+
+						resultFullListAppend(c)
 					}
 
 					wg.Done()
@@ -527,7 +542,7 @@ func doInstancesGetFromNode(projects []string, node string, allProjects bool, ne
 	return containers, err
 }
 
-func doInstancesFullGetFromNode(projects []string, node string, allProjects bool, networkCert *localtls.CertInfo, serverCert *localtls.CertInfo, r *http.Request) ([]api.InstanceFull, error) {
+func doInstancesFullGetFromNode(projects []string, node string, allProjects bool, withInventory bool, networkCert *localtls.CertInfo, serverCert *localtls.CertInfo, r *http.Request) ([]api.InstanceFull, error) {
 	f := func() ([]api.InstanceFull, error) {
 		client, err := cluster.Connect(node, networkCert, serverCert, r, true)
 		if err != nil {
@@ -536,7 +551,12 @@ func doInstancesFullGetFromNode(projects []string, node string, allProjects bool
 
 		var instances []api.InstanceFull
 		if allProjects {
-			instances, err = client.GetInstancesFullAllProjects(api.InstanceTypeAny)
+			if withInventory {
+				instances, err = client.GetInstancesFullAllProjectsWithInventory(api.InstanceTypeAny, nil)
+			} else {
+				instances, err = client.GetInstancesFullAllProjects(api.InstanceTypeAny)
+			}
+
 			if err != nil {
 				return nil, fmt.Errorf("Failed to get instances from member %s: %w", node, err)
 			}
@@ -544,7 +564,13 @@ func doInstancesFullGetFromNode(projects []string, node string, allProjects bool
 			for _, project := range projects {
 				client = client.UseProject(project)
 
-				tmpInstances, err := client.GetInstancesFull(api.InstanceTypeAny)
+				var tmpInstances []api.InstanceFull
+				if withInventory {
+					tmpInstances, err = client.GetInstancesFullWithInventory(api.InstanceTypeAny, nil)
+				} else {
+					tmpInstances, err = client.GetInstancesFull(api.InstanceTypeAny)
+				}
+
 				if err != nil {
 					return nil, fmt.Errorf("Failed to get instances from member %s: %w", node, err)
 				}
@@ -556,7 +582,12 @@ func doInstancesFullGetFromNode(projects []string, node string, allProjects bool
 		return instances, nil
 	}
 
+	// Getting the inventory of the instances involves their agents.
 	timeout := time.After(30 * time.Second)
+	if withInventory {
+		timeout = time.After(2 * time.Minute)
+	}
+
 	done := make(chan struct{})
 
 	var instances []api.InstanceFull
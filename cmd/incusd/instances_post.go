@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"os"
 	"slices"
@@ -16,6 +17,7 @@ import (
 
 	internalInstance "github.com/lxc/incus/v7/internal/instance"
 	internalIO "github.com/lxc/incus/v7/internal/io"
+	"github.com/lxc/incus/v7/internal/server/auth"
 	"github.com/lxc/incus/v7/internal/server/backup"
 	"github.com/lxc/incus/v7/internal/server/cluster"
 	"github.com/lxc/incus/v7/internal/server/db"
@@ -883,6 +885,11 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 		bInfo.Name = instanceName
 	}
 
+	// Incremental backups are applied on top of an existing instance.
+	if bInfo.Incremental != nil {
+		return instanceApplyIncrementalBackup(s, r, bInfo, backupFile, reverter)
+	}
+
 	// Override config.
 	configMap := map[string]string{}
 	if config != "" {
@@ -917,6 +924,61 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 		}
 	}
 
+	// Run the instance validation scriptlet on the backup configuration with the overrides applied.
+	if s.GlobalConfig.InstancesValidationScriptlet() != "" {
+		var profiles []api.Profile
+
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			profiles, err = tx.GetProfiles(ctx, bInfo.Project, bInfo.Config.Container.Profiles)
+
+			return err
+		})
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed loading profiles (%v) for instance: %w", strings.Join(bInfo.Config.Container.Profiles, ", "), err))
+		}
+
+		instConfig := maps.Clone(bInfo.Config.Container.Config)
+		if instConfig == nil {
+			instConfig = map[string]string{}
+		}
+
+		maps.Copy(instConfig, configMap)
+
+		instDevices := map[string]map[string]string{}
+		for devName, dev := range bInfo.Config.Container.Devices {
+			instDevices[devName] = maps.Clone(dev)
+		}
+
+		for devName, dev := range deviceMap {
+			if instDevices[devName] == nil {
+				instDevices[devName] = map[string]string{}
+			}
+
+			maps.Copy(instDevices[devName], dev)
+		}
+
+		err = instanceValidationRun(s, apiScriptlet.InstanceValidationReasonCreate, bInfo.Project, bInfo.Name, bInfo.Config.Container.Type, profiles, instConfig, instDevices)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Turn the resulting configuration into overrides, an empty value unsets the key.
+		for key, value := range instConfig {
+			if bInfo.Config.Container.Config[key] != value {
+				configMap[key] = value
+			}
+		}
+
+		for _, keys := range []map[string]string{bInfo.Config.Container.Config, configMap} {
+			for key := range keys {
+				_, found := instConfig[key]
+				if !found {
+					configMap[key] = ""
+				}
+			}
+		}
+	}
+
 	logger.Debug("Backup file info loaded", logger.Ctx{
 		"type":      bInfo.Type,
 		"name":      bInfo.Name,
@@ -1096,6 +1158,131 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 	return operations.OperationResponse(op)
 }
 
+// instanceApplyIncrementalBackup applies the changed blocks from an incremental backup onto the disks of an
+// existing stopped virtual machine.
+func instanceApplyIncrementalBackup(s *state.State, r *http.Request, bInfo *backup.Info, backupFile *os.File, reverter *revert.Reverter) response.Response {
+	if bInfo.Type != backup.TypeVM {
+		return response.BadRequest(errors.New("Incremental backups can only be applied to virtual machines"))
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, bInfo.Project, bInfo.Name)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading instance %q to apply the incremental backup to: %w", bInfo.Name, err))
+	}
+
+	err = s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectInstance(inst.Project().Name, inst.Name()), auth.EntitlementCanEdit)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if inst.Type() != instancetype.VM {
+		return response.BadRequest(fmt.Errorf("Instance %q isn't a virtual machine", inst.Name()))
+	}
+
+	if s.ServerClustered && inst.Location() != s.ServerName {
+		return response.BadRequest(fmt.Errorf("Incremental backups must be applied on the cluster member hosting the instance (%q)", inst.Location()))
+	}
+
+	if inst.IsRunning() {
+		return response.BadRequest(errors.New("Incremental backups can only be applied to stopped instances"))
+	}
+
+	err = bInfo.Incremental.ValidateParent(inst.LocalConfig())
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	rootDiskName, _, err := internalInstance.GetRootDiskDevice(inst.ExpandedDevices().CloneNative())
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed getting instance root disk: %w", err))
+	}
+
+	volProjectName, err := project.StorageVolumeProject(s.DB.Cluster, inst.Project().Name, db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Copy reverter so far so we can use it inside run after this function has finished.
+	runReverter := reverter.Clone()
+
+	run := func(op *operations.Operation) error {
+		defer func() { _ = backupFile.Close() }()
+		defer runReverter.Fail()
+
+		if inst.IsRunning() {
+			return errors.New("Incremental backups can only be applied to stopped instances")
+		}
+
+		err := backupApplyIncremental(s, bInfo, backupFile, func(disk backup.IncrementalDisk) (string, func(), error) {
+			if disk.Device == rootDiskName {
+				pool, err := storagePools.LoadByInstance(s, inst)
+				if err != nil {
+					return "", nil, err
+				}
+
+				mountInfo, err := pool.MountInstance(inst, op)
+				if err != nil {
+					return "", nil, err
+				}
+
+				release := func() { _ = pool.UnmountInstance(inst, op) }
+				if mountInfo.DiskPath == "" {
+					release()
+					return "", nil, errors.New("Instance root disk has no block device path")
+				}
+
+				return mountInfo.DiskPath, release, nil
+			}
+
+			dev, ok := inst.ExpandedDevices()[disk.Device]
+			if !ok || dev["type"] != "disk" || dev["pool"] == "" {
+				return "", nil, fmt.Errorf("Instance has no volume attached as %q", disk.Device)
+			}
+
+			pool, err := storagePools.LoadByName(s, dev["pool"])
+			if err != nil {
+				return "", nil, err
+			}
+
+			mountInfo, err := pool.MountCustomVolume(volProjectName, dev["source"], op)
+			if err != nil {
+				return "", nil, err
+			}
+
+			release := func() { _, _ = pool.UnmountCustomVolume(volProjectName, dev["source"], op) }
+			if mountInfo.DiskPath == "" {
+				release()
+				return "", nil, fmt.Errorf("Volume %q has no block device path", dev["source"])
+			}
+
+			return mountInfo.DiskPath, release, nil
+		})
+		if err != nil {
+			return fmt.Errorf("Failed applying incremental backup: %w", err)
+		}
+
+		err = inst.VolatileSet(map[string]string{backup.IncrementalCheckpointKey(bInfo.Incremental.Bitmap): bInfo.Incremental.ID})
+		if err != nil {
+			return fmt.Errorf("Failed recording backup checkpoint: %w", err)
+		}
+
+		runReverter.Success()
+
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", bInfo.Name)}
+
+	op, err := operations.OperationCreate(s, bInfo.Project, operations.OperationClassTask, operationtype.BackupRestore, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	reverter.Success()
+	return operations.OperationResponse(op)
+}
+
 // swagger:operation POST /1.0/instances instances instances_post
 //
 //	Create a new instance
@@ -1220,6 +1407,7 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 	var candidateMembers []db.NodeInfo
 	var targetMemberInfo *db.NodeInfo
 	var targetGroupName string
+	var allMembers []db.NodeInfo
 
 	target := request.QueryParam(r, "target")
 	if !s.ServerClustered && target != "" {
@@ -1237,8 +1425,6 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
-		var allMembers []db.NodeInfo
-
 		if s.ServerClustered && !clusterNotification {
 			allMembers, err = tx.GetNodes(ctx)
 			if err != nil {
@@ -1383,6 +1569,23 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			}
 		}
 
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Run the instance validation scriptlet, unless it already ran on the member forwarding the request.
+	// This uses the profiles loaded above and happens outside of any transaction as it may take a while.
+	// It runs before placement so that the scheduler and the placement scriptlet see the final configuration.
+	if !clusterNotification && !clusterInternal {
+		err = instanceValidationRun(s, apiScriptlet.InstanceValidationReasonCreate, targetProjectName, req.Name, string(req.Type), profiles, req.Config, req.Devices)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		if s.ServerClustered && !clusterNotification && targetMemberInfo == nil {
 			architectures, err := instance.SuitableArchitectures(ctx, s, tx, targetProjectName, sourceInst, sourceImageRef, req)
 			if err != nil {
@@ -1415,12 +1618,21 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			if err != nil {
 				return err
 			}
-		}
 
-		if !clusterNotification {
-			// Check that the project's limits are not violated. Note this check is performed after
-			// automatically generated config values (such as ones from an InstanceType) have been set.
-			err = project.AllowInstanceCreation(tx, targetProjectName, req)
+			// Apply the placement groups of the instance.
+			if len(candidateMembers) > 0 {
+				candidateMembers, err = instance.PlacementFilterMembers(ctx, tx, targetProjectName, req.Name, db.ExpandInstanceConfig(req.Config, profiles), candidateMembers, false)
+				if err != nil {
+					return err
+				}
+
+				if len(candidateMembers) == 0 {
+					return api.StatusErrorf(http.StatusConflict, "No cluster member satisfies the placement anti-affinity rules of the instance")
+				}
+			}
+		} else if s.ServerClustered && !clusterNotification {
+			// An explicitly targeted member must satisfy the anti-affinity rules of the instance.
+			err = instance.PlacementCheckMember(ctx, tx, targetProjectName, req.Name, db.ExpandInstanceConfig(req.Config, profiles), *targetMemberInfo)
 			if err != nil {
 				return err
 			}
@@ -1432,6 +1644,17 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	if !clusterNotification {
+		// Check that the project's limits are not violated. Note this check is performed after
+		// automatically generated config values (such as ones from an InstanceType) have been set.
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return project.AllowInstanceCreation(tx, targetProjectName, req)
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	err = instance.ValidName(req.Name, false)
 	if err != nil {
 		return response.BadRequest(err)
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/url"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -50,6 +51,7 @@ func newMigrationSource(inst instance.Instance, stateful bool, instanceOnly bool
 
 		ret.live = true
 		secretNames = append(secretNames, api.SecretNameState)
+		secretNames = append(secretNames, migrationMultifdSecretNames(inst)...)
 	}
 
 	ret.conns = make(map[string]*migrationConn, len(secretNames))
@@ -115,6 +117,10 @@ func (s *migrationSourceWs) do(migrateOp *operations.Operation) error {
 		return wsConn, nil
 	}
 
+	multifdConnsFunc := func(ctx context.Context) ([]io.ReadWriteCloser, error) {
+		return migrationMultifdConns(ctx, s.conns, migrationMultifdSecretNames(s.instance))
+	}
+
 	filesystemConnFunc := func(ctx context.Context) (io.ReadWriteCloser, error) {
 		conn := s.conns[api.SecretNameFilesystem]
 		if conn == nil {
@@ -135,6 +141,7 @@ func (s *migrationSourceWs) do(migrateOp *operations.Operation) error {
 			ControlSend:    s.send,
 			ControlReceive: s.recv,
 			StateConn:      stateConnFunc,
+			MultifdConns:   multifdConnsFunc,
 			FilesystemConn: filesystemConnFunc,
 			Snapshots:      !s.instanceOnly,
 			Live:           s.live,
@@ -187,6 +194,7 @@ func newMigrationSink(args *migrationSinkArgs) (*migrationSink, error) {
 		}
 
 		secretNames = append(secretNames, api.SecretNameState)
+		secretNames = append(secretNames, migrationMultifdSecretNames(sink.instance)...)
 	}
 
 	sink.conns = make(map[string]*migrationConn, len(secretNames))
@@ -250,6 +258,10 @@ func (c *migrationSink) do(instOp *operationlock.InstanceOperation) error {
 		return wsConn, nil
 	}
 
+	multifdConnsFunc := func(ctx context.Context) ([]io.ReadWriteCloser, error) {
+		return migrationMultifdConns(ctx, c.conns, migrationMultifdSecretNames(c.instance))
+	}
+
 	filesystemConnFunc := func(ctx context.Context) (io.ReadWriteCloser, error) {
 		conn := c.conns[api.SecretNameFilesystem]
 		if conn == nil {
@@ -269,6 +281,7 @@ func (c *migrationSink) do(instOp *operationlock.InstanceOperation) error {
 			ControlSend:    c.send,
 			ControlReceive: c.recv,
 			StateConn:      stateConnFunc,
+			MultifdConns:   multifdConnsFunc,
 			FilesystemConn: filesystemConnFunc,
 			Snapshots:      !c.instanceOnly,
 			Live:           c.live,
@@ -296,3 +309,43 @@ func (c *migrationSink) do(instOp *operationlock.InstanceOperation) error {
 
 	return nil
 }
+
+// migrationMultifdSecretNames returns the names of the connections used for the multifd channels when live
+// migrating a virtual machine.
+func migrationMultifdSecretNames(inst instance.Instance) []string {
+	if inst.Type() != instancetype.VM {
+		return nil
+	}
+
+	channels, err := strconv.Atoi(inst.ExpandedConfig()["migration.stateful.multifd"])
+	if err != nil || channels <= 0 {
+		return nil
+	}
+
+	secretNames := make([]string, 0, channels)
+	for i := range channels {
+		secretNames = append(secretNames, fmt.Sprintf("%s-%d", api.SecretNameMultifd, i))
+	}
+
+	return secretNames
+}
+
+// migrationMultifdConns waits for the multifd channel connections to be established.
+func migrationMultifdConns(ctx context.Context, conns map[string]*migrationConn, secretNames []string) ([]io.ReadWriteCloser, error) {
+	multifdConns := make([]io.ReadWriteCloser, 0, len(secretNames))
+	for _, secretName := range secretNames {
+		conn := conns[secretName]
+		if conn == nil {
+			return nil, fmt.Errorf("Migration %q connection not initialized", secretName)
+		}
+
+		wsConn, err := conn.WebsocketIO(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting migration %q connection: %w", secretName, err)
+		}
+
+		multifdConns = append(multifdConns, wsConn)
+	}
+
+	return multifdConns, nil
+}
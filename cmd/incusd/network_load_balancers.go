@@ -19,6 +19,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/project"
 	"github.com/lxc/incus/v7/internal/server/request"
 	"github.com/lxc/incus/v7/internal/server/response"
+	"github.com/lxc/incus/v7/internal/server/state"
 	localUtil "github.com/lxc/incus/v7/internal/server/util"
 	"github.com/lxc/incus/v7/internal/version"
 	"github.com/lxc/incus/v7/shared/api"
@@ -447,6 +448,12 @@ func networkLoadBalancerDelete(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Member specific load balancers are handled by the member they're on.
+	resp = forwardedResponseIfLoadBalancerIsRemote(s, r, n, listenAddress)
+	if resp != nil {
+		return resp
+	}
+
 	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
 
 	err = n.LoadBalancerDelete(listenAddress, clientType)
@@ -704,6 +711,12 @@ func networkLoadBalancerPut(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Member specific load balancers are handled by the member they're on.
+	resp = forwardedResponseIfLoadBalancerIsRemote(s, r, n, listenAddress)
+	if resp != nil {
+		return resp
+	}
+
 	// Decode the request.
 	req := api.NetworkLoadBalancerPut{}
 	err = json.NewDecoder(r.Body).Decode(&req)
@@ -865,6 +878,12 @@ func networkLoadBalancerStateGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Member specific load balancers are handled by the member they're on.
+	resp = forwardedResponseIfLoadBalancerIsRemote(s, r, n, listenAddress)
+	if resp != nil {
+		return resp
+	}
+
 	var loadBalancer *api.NetworkLoadBalancer
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		networkID := n.ID()
@@ -901,3 +920,40 @@ func networkLoadBalancerStateGet(d *Daemon, r *http.Request) response.Response {
 
 	return response.SyncResponse(true, lbState)
 }
+
+// forwardedResponseIfLoadBalancerIsRemote forwards a request to the cluster member a member specific load balancer
+// is located on, unless a target member was requested. If the load balancer is local or isn't specific to a member,
+// nil is returned.
+func forwardedResponseIfLoadBalancerIsRemote(s *state.State, r *http.Request, n network.Network, listenAddress string) response.Response {
+	if request.QueryParam(r, "target") != "" {
+		return nil
+	}
+
+	var location string
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networkID := n.ID()
+
+		dbLoadBalancers, err := dbCluster.GetNetworkLoadBalancers(ctx, tx.Tx(), dbCluster.NetworkLoadBalancerFilter{
+			NetworkID:     &networkID,
+			ListenAddress: &listenAddress,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(dbLoadBalancers) == 1 && dbLoadBalancers[0].Location != nil {
+			location = *dbLoadBalancers[0].Location
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if location == "" {
+		return nil
+	}
+
+	return forwardedResponseToNode(s, r, location)
+}
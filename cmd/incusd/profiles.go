@@ -29,6 +29,7 @@ import (
 	localUtil "github.com/lxc/incus/v7/internal/server/util"
 	"github.com/lxc/incus/v7/internal/version"
 	"github.com/lxc/incus/v7/shared/api"
+	apiScriptlet "github.com/lxc/incus/v7/shared/api/scriptlet"
 	"github.com/lxc/incus/v7/shared/logger"
 	"github.com/lxc/incus/v7/shared/util"
 	"github.com/lxc/incus/v7/shared/validate"
@@ -349,6 +350,16 @@ func profilesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Invalid profile name: %w", err))
 	}
 
+	// Run the instance validation scriptlet.
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	err = instanceValidationRun(s, apiScriptlet.InstanceValidationReasonProfile, p.Name, req.Name, "", nil, req.Config, req.Devices)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	err = instance.ValidConfig(d.os, req.Config, false, instancetype.Any)
 	if err != nil {
 		return response.BadRequest(err)
@@ -14,11 +14,22 @@ import (
 	"github.com/lxc/incus/v7/internal/server/project"
 	"github.com/lxc/incus/v7/internal/server/state"
 	"github.com/lxc/incus/v7/shared/api"
+	apiScriptlet "github.com/lxc/incus/v7/shared/api/scriptlet"
 )
 
 func doProfileUpdate(ctx context.Context, s *state.State, p api.Project, profileName string, profile *api.Profile, req api.ProfilePut) error {
+	// Run the instance validation scriptlet.
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	err := instanceValidationRun(s, apiScriptlet.InstanceValidationReasonProfile, p.Name, profileName, "", nil, req.Config, req.Devices)
+	if err != nil {
+		return err
+	}
+
 	// Check project limits.
-	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 		return project.AllowProfileUpdate(tx, p.Name, profileName, req)
 	})
 	if err != nil {
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/server/db"
+	"github.com/lxc/incus/v7/internal/server/state"
+	storagePools "github.com/lxc/incus/v7/internal/server/storage"
+	storageDrivers "github.com/lxc/incus/v7/internal/server/storage/drivers"
+	"github.com/lxc/incus/v7/internal/server/storage/s3/local"
+	"github.com/lxc/incus/v7/internal/server/task"
+	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/util"
+)
+
+// newLocalBucketServer returns the S3 server of a bucket backed by a local storage driver, set up according to
+// the bucket config.
+func newLocalBucketServer(bucketDir string, config map[string]string, creds []local.Credential) *local.Server {
+	srv := local.NewServer(bucketDir, creds)
+	srv.PublicPrefixes = storageDrivers.BucketPublicPrefixes(config)
+
+	versions, days := storageDrivers.BucketVersionRetention(config)
+	srv.Versioning = local.Versioning{
+		Enabled:      util.IsTrue(config["versioning"]),
+		KeepVersions: versions,
+		KeepFor:      time.Duration(days) * 24 * time.Hour,
+	}
+
+	// The config is validated when set.
+	rules, _ := storageDrivers.BucketLifecycleRules(config["lifecycle.expiry"])
+	for _, rule := range rules {
+		srv.Lifecycle = append(srv.Lifecycle, local.LifecycleRule{
+			Prefix: rule.Prefix,
+			Age:    time.Duration(rule.Days) * 24 * time.Hour,
+		})
+	}
+
+	return srv
+}
+
+// bucketLifecycleTask expires the objects and previous object versions of the local buckets according to their
+// lifecycle and versioning settings. Buckets on remote storage pools are handled by the storage itself.
+func bucketLifecycleTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		var buckets []*db.StorageBucket
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			allBuckets, err := tx.GetStoragePoolBuckets(ctx, true)
+			if err != nil {
+				return err
+			}
+
+			for _, bucket := range allBuckets {
+				if bucket.Location == "" {
+					continue // Ignore buckets on remote storage pools.
+				}
+
+				if bucket.Config["lifecycle.expiry"] == "" && bucket.Config["versioning.retention.versions"] == "" && bucket.Config["versioning.retention.days"] == "" {
+					continue
+				}
+
+				buckets = append(buckets, bucket)
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed getting storage buckets for lifecycle task", logger.Ctx{"err": err})
+			return
+		}
+
+		for _, bucket := range buckets {
+			err := applyBucketLifecycle(s, bucket)
+			if err != nil {
+				logger.Warn("Failed applying storage bucket lifecycle", logger.Ctx{"project": bucket.Project, "pool": bucket.PoolName, "bucket": bucket.Name, "err": err})
+			}
+		}
+	}
+
+	return f, task.Hourly()
+}
+
+// applyBucketLifecycle mounts a local bucket and applies its lifecycle settings.
+func applyBucketLifecycle(s *state.State, bucket *db.StorageBucket) error {
+	pool, err := storagePools.LoadByName(s, bucket.PoolName)
+	if err != nil {
+		return err
+	}
+
+	bucketDir, unmount, err := pool.MountLocalBucket(bucket.Project, bucket.Name, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = unmount() }()
+
+	// Data left over from the legacy minio layout must be migrated first.
+	err = local.MigrateMinioBucket(bucketDir, bucket.Name)
+	if err != nil {
+		return err
+	}
+
+	return newLocalBucketServer(bucketDir, bucket.Config, nil).ApplyLifecycle(time.Now())
+}
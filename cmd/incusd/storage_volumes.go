@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
@@ -2503,6 +2504,14 @@ func storagePoolVolumePatch(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// Possibly check if project limits are honored.
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return project.AllowVolumeUpdate(tx, projectName, volumeName, req, dbVolume.Config)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Use an empty operation for this sync response to pass the requestor
 	op := &operations.Operation{}
 	op.SetRequestor(r)
@@ -2752,6 +2761,93 @@ func createStoragePoolVolumeFromISO(s *state.State, r *http.Request, requestProj
 	return operations.OperationResponse(op)
 }
 
+// storagePoolVolumeApplyIncrementalBackup applies the changed blocks from an incremental backup onto an
+// existing custom block volume which isn't in use by a running instance.
+func storagePoolVolumeApplyIncrementalBackup(s *state.State, r *http.Request, requestProjectName string, bInfo *backup.Info, backupFile *os.File, reverter *revert.Reverter) response.Response {
+	if bInfo.Type != backup.TypeCustom || len(bInfo.Incremental.Disks) != 1 {
+		return response.BadRequest(errors.New("Invalid incremental storage volume backup"))
+	}
+
+	pool, err := storagePools.LoadByName(s, bInfo.Pool)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	dbVol, err := storagePools.VolumeDBGet(pool, bInfo.Project, bInfo.Name, storageDrivers.VolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading volume %q to apply the incremental backup to: %w", bInfo.Name, err))
+	}
+
+	err = s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectStorageVolume(bInfo.Project, pool.Name(), dbVol.Type, dbVol.Name, dbVol.Location), auth.EntitlementCanEdit)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if dbVol.ContentType != db.StoragePoolVolumeContentTypeNameBlock {
+		return response.BadRequest(fmt.Errorf("Volume %q isn't a block volume", bInfo.Name))
+	}
+
+	inst, _, err := storagePools.InstanceByVolumeName(s, pool.Name(), bInfo.Project, bInfo.Name, db.StoragePoolVolumeTypeCustom)
+	if err != nil && !errors.Is(err, storagePools.ErrVolumeNotAttachedToRunningInstance) {
+		return response.SmartError(err)
+	}
+
+	if inst != nil && inst.IsRunning() {
+		return response.BadRequest(fmt.Errorf("Volume %q is in use by running instance %q", bInfo.Name, inst.Name()))
+	}
+
+	err = bInfo.Incremental.ValidateParent(dbVol.Config)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Copy reverter so far so we can use it inside run after this function has finished.
+	runReverter := reverter.Clone()
+
+	run := func(op *operations.Operation) error {
+		defer func() { _ = backupFile.Close() }()
+		defer runReverter.Fail()
+
+		err := backupApplyIncremental(s, bInfo, backupFile, func(disk backup.IncrementalDisk) (string, func(), error) {
+			mountInfo, err := pool.MountCustomVolume(bInfo.Project, bInfo.Name, op)
+			if err != nil {
+				return "", nil, err
+			}
+
+			release := func() { _, _ = pool.UnmountCustomVolume(bInfo.Project, bInfo.Name, op) }
+			if mountInfo.DiskPath == "" {
+				release()
+				return "", nil, fmt.Errorf("Volume %q has no block device path", bInfo.Name)
+			}
+
+			return mountInfo.DiskPath, release, nil
+		})
+		if err != nil {
+			return fmt.Errorf("Failed applying incremental backup: %w", err)
+		}
+
+		err = customVolumeUpdateVolatile(context.TODO(), s, pool.Name(), bInfo.Project, bInfo.Name, map[string]string{backup.IncrementalCheckpointKey(bInfo.Incremental.Bitmap): bInfo.Incremental.ID})
+		if err != nil {
+			return fmt.Errorf("Failed recording backup checkpoint: %w", err)
+		}
+
+		runReverter.Success()
+
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_volumes"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", bInfo.Pool, "volumes", string(bInfo.Type), bInfo.Name)}
+
+	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.CustomVolumeBackupRestore, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	reverter.Success()
+	return operations.OperationResponse(op)
+}
+
 func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestProjectName string, projectName string, data io.Reader, pool string, volName string) response.Response {
 	reverter := revert.New()
 	defer reverter.Fail()
@@ -2847,6 +2943,11 @@ func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestP
 		bInfo.Name = volName
 	}
 
+	// Incremental backups are applied on top of an existing volume.
+	if bInfo.Incremental != nil {
+		return storagePoolVolumeApplyIncrementalBackup(s, r, requestProjectName, bInfo, backupFile, reverter)
+	}
+
 	logger.Debug("Backup file info loaded", logger.Ctx{
 		"type":      bInfo.Type,
 		"name":      bInfo.Name,
@@ -2932,3 +3033,34 @@ func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestP
 	reverter.Success()
 	return operations.OperationResponse(op)
 }
+
+// customVolumeUpdateVolatile applies the given changes to the volatile configuration of a custom volume.
+// An empty value unsets the key.
+func customVolumeUpdateVolatile(ctx context.Context, s *state.State, poolName string, projectName string, volumeName string, changes map[string]string) error {
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		poolID, err := tx.GetStoragePoolID(ctx, poolName)
+		if err != nil {
+			return err
+		}
+
+		dbVol, err := tx.GetStoragePoolVolume(ctx, poolID, projectName, db.StoragePoolVolumeTypeCustom, volumeName, true)
+		if err != nil {
+			return err
+		}
+
+		config := maps.Clone(dbVol.Config)
+		if config == nil {
+			config = map[string]string{}
+		}
+
+		for key, value := range changes {
+			if value == "" {
+				delete(config, key)
+			} else {
+				config[key] = value
+			}
+		}
+
+		return tx.UpdateStoragePoolVolume(ctx, projectName, volumeName, db.StoragePoolVolumeTypeCustom, poolID, dbVol.Description, config)
+	})
+}
@@ -451,6 +451,16 @@ func storagePoolVolumeTypeCustomBackupsPost(d *Daemon, r *http.Request) response
 		}
 	}
 
+	if req.Since != "" {
+		if dbVolume.ContentType != db.StoragePoolVolumeContentTypeNameBlock {
+			return response.BadRequest(errors.New("Incremental backups are only supported for block volumes"))
+		}
+
+		if req.OptimizedStorage {
+			return response.BadRequest(errors.New("Incremental backups can't use the optimized storage format"))
+		}
+	}
+
 	var reader *io.PipeReader
 	var writer *io.PipeWriter
 	var fullName string
@@ -520,6 +530,7 @@ func storagePoolVolumeTypeCustomBackupsPost(d *Daemon, r *http.Request) response
 			VolumeOnly:           req.VolumeOnly,
 			OptimizedStorage:     req.OptimizedStorage,
 			CompressionAlgorithm: req.CompressionAlgorithm,
+			Since:                req.Since,
 		}
 
 		if !direct && req.Target == nil {
@@ -529,14 +540,17 @@ func storagePoolVolumeTypeCustomBackupsPost(d *Daemon, r *http.Request) response
 		uploadRes := make(chan error)
 
 		// Start the upload in the background if requested.
+		var stored func() error
 		if req.Target != nil {
 			go func(resCh chan<- error) {
 				resCh <- internalBackup.Upload(reader, req.Target)
 			}(uploadRes)
+
+			stored = func() error { return <-uploadRes }
 		}
 
 		// Create the backup.
-		err := volumeBackupCreate(s, args, projectName, poolName, volumeName, writer)
+		err := volumeBackupCreate(s, args, projectName, poolName, volumeName, writer, stored)
 		if err != nil {
 			// If we receive a pipe closed error, we first check for an explicit error returned by the
 			// reader.
@@ -1,18 +1,23 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"slices"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 
 	"github.com/lxc/incus/v7/internal/server/auth"
+	"github.com/lxc/incus/v7/internal/server/backup"
 	"github.com/lxc/incus/v7/internal/server/db"
+	"github.com/lxc/incus/v7/internal/server/instance"
 	"github.com/lxc/incus/v7/internal/server/request"
 	"github.com/lxc/incus/v7/internal/server/response"
+	"github.com/lxc/incus/v7/internal/server/state"
 	storagePools "github.com/lxc/incus/v7/internal/server/storage"
 	localUtil "github.com/lxc/incus/v7/internal/server/util"
 	"github.com/lxc/incus/v7/internal/version"
@@ -391,6 +396,11 @@ func storagePoolVolumeTypeBitmapsPost(d *Daemon, r *http.Request) response.Respo
 		return response.SmartError(err)
 	}
 
+	err = storagePoolVolumeBitmapSetCheckpoint(s, inst, poolName, projectName, volumeName, volumeDBType, req.Name, uuid.New().String())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	return response.EmptySyncResponse
 }
 
@@ -681,5 +691,22 @@ func storagePoolVolumeTypeBitmapDelete(d *Daemon, r *http.Request) response.Resp
 		return response.SmartError(err)
 	}
 
+	err = storagePoolVolumeBitmapSetCheckpoint(s, inst, poolName, projectName, volumeName, volumeDBType, bitmapName, "")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	return response.EmptySyncResponse
 }
+
+// storagePoolVolumeBitmapSetCheckpoint sets the backup checkpoint of a bitmap, an empty value unsets it.
+// Custom volumes keep it in their own configuration while virtual-machine volumes use the instance one.
+func storagePoolVolumeBitmapSetCheckpoint(s *state.State, inst instance.Instance, poolName string, projectName string, volumeName string, volumeDBType int, bitmapName string, checkpoint string) error {
+	changes := map[string]string{backup.IncrementalCheckpointKey(bitmapName): checkpoint}
+
+	if volumeDBType == db.StoragePoolVolumeTypeVM {
+		return inst.VolatileSet(changes)
+	}
+
+	return customVolumeUpdateVolatile(context.TODO(), s, poolName, projectName, volumeName, changes)
+}
@@ -0,0 +1,510 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	incus "github.com/lxc/incus/v7/client"
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/internal/server/db/operationtype"
+	"github.com/lxc/incus/v7/internal/server/operations"
+	"github.com/lxc/incus/v7/internal/server/project"
+	"github.com/lxc/incus/v7/internal/server/state"
+	storagePools "github.com/lxc/incus/v7/internal/server/storage"
+	storageDrivers "github.com/lxc/incus/v7/internal/server/storage/drivers"
+	"github.com/lxc/incus/v7/internal/server/task"
+	localUtil "github.com/lxc/incus/v7/internal/server/util"
+	"github.com/lxc/incus/v7/internal/version"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/revert"
+	localtls "github.com/lxc/incus/v7/shared/tls"
+)
+
+// customVolumeReplicationSnapshotPattern is the name pattern of the snapshots taken for custom volume replication.
+const customVolumeReplicationSnapshotPattern = "replication-%d"
+
+func replicateCustomVolumesTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+		var volumes, remoteVolumes []db.StorageVolumeArgs
+		var memberCount int
+		var onlineMemberIDs []int64
+
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			projs, err := dbCluster.GetProjects(ctx, tx.Tx())
+			if err != nil {
+				return fmt.Errorf("Failed loading projects: %w", err)
+			}
+
+			// Key by project name for lookup later.
+			projects := make(map[string]*api.Project, len(projs))
+			for _, p := range projs {
+				projects[p.Name], err = p.ToAPI(ctx, tx.Tx())
+				if err != nil {
+					return fmt.Errorf("Failed loading project %q: %w", p.Name, err)
+				}
+			}
+
+			allVolumes, err := tx.GetStoragePoolVolumesWithType(ctx, db.StoragePoolVolumeTypeCustom, true)
+			if err != nil {
+				return fmt.Errorf("Failed getting volumes for custom volume replication task: %w", err)
+			}
+
+			for _, v := range allVolumes {
+				if v.Config["replication.target"] == "" {
+					continue
+				}
+
+				err = project.AllowVolumeReplication(projects[v.ProjectName])
+				if err != nil {
+					continue
+				}
+
+				schedule := v.Config["replication.schedule"]
+				if schedule == "" {
+					continue
+				}
+
+				// Check if replication is scheduled.
+				if !snapshotIsScheduledNow(schedule, v.ID) {
+					continue
+				}
+
+				if v.NodeID < 0 {
+					// Keep a separate list of remote volumes in order to select a member to
+					// perform the replication later.
+					remoteVolumes = append(remoteVolumes, v)
+				} else {
+					logger.Debug("Scheduling local custom volume replication", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName})
+					volumes = append(volumes, v) // Always include local volumes.
+				}
+			}
+
+			if len(remoteVolumes) > 0 {
+				// Get list of cluster members.
+				members, err := tx.GetNodes(ctx)
+				if err != nil {
+					return fmt.Errorf("Failed getting cluster members: %w", err)
+				}
+
+				memberCount = len(members)
+
+				// Filter to online members.
+				for _, member := range members {
+					if member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+						continue
+					}
+
+					onlineMemberIDs = append(onlineMemberIDs, member.ID)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed getting custom volume info", logger.Ctx{"err": err})
+			return
+		}
+
+		if len(remoteVolumes) > 0 {
+			// Skip replicating remote custom volumes if there are no online members, as we can't be
+			// sure that the cluster isn't partitioned and we may end up attempting the replication on
+			// multiple members.
+			if memberCount > 1 && len(onlineMemberIDs) <= 0 {
+				logger.Error("Skipping remote volumes for custom volume replication task due to no online members")
+			} else {
+				localMemberID := s.DB.Cluster.GetNodeID()
+
+				for _, v := range remoteVolumes {
+					// If there are multiple cluster members, a stable random member is chosen
+					// to perform the replication from.
+					if memberCount > 1 {
+						selectedNodeID, err := localUtil.GetStableRandomInt64FromList(int64(v.ID), onlineMemberIDs)
+						if err != nil {
+							logger.Error("Failed scheduling remote custom volume replication task", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName, "err": err})
+							continue
+						}
+
+						// Don't replicate, if we're not the chosen one.
+						if localMemberID != selectedNodeID {
+							continue
+						}
+					}
+
+					logger.Debug("Scheduling remote custom volume replication", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName})
+					volumes = append(volumes, v)
+				}
+			}
+		}
+
+		if len(volumes) == 0 {
+			return
+		}
+
+		opRun := func(op *operations.Operation) error {
+			return replicateCustomVolumes(ctx, s, volumes, op)
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.CustomVolumeReplicate, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating custom volume replication operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Replicating custom volumes")
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting custom volume replication operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed replicating custom volumes", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Done replicating custom volumes")
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Minute
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
+
+// replicateCustomVolumes replicates the given custom volumes sequentially and records the outcome in their
+// volatile configuration. A failure to replicate one volume doesn't prevent the others from being replicated.
+func replicateCustomVolumes(ctx context.Context, s *state.State, volumes []db.StorageVolumeArgs, op *operations.Operation) error {
+	var errs []error
+
+	for _, v := range volumes {
+		err := ctx.Err()
+		if err != nil {
+			return err // Stop if context is cancelled.
+		}
+
+		l := logger.AddContext(logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName, "target": v.Config["replication.target"]})
+
+		changes := map[string]string{}
+
+		snapshotName, err := replicateCustomVolume(ctx, s, v, op)
+		if err != nil {
+			l.Warn("Failed replicating custom volume", logger.Ctx{"err": err})
+			errs = append(errs, fmt.Errorf("Failed replicating volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err))
+			changes["volatile.replication.last_error"] = err.Error()
+		} else {
+			l.Debug("Replicated custom volume", logger.Ctx{"snapshot": snapshotName})
+			changes["volatile.replication.last_error"] = ""
+			changes["volatile.replication.last_snapshot"] = snapshotName
+			changes["volatile.replication.last_success"] = time.Now().UTC().Format(time.RFC3339)
+		}
+
+		err = customVolumeUpdateVolatile(ctx, s, v.PoolName, v.ProjectName, v.Name, changes)
+		if err != nil {
+			l.Warn("Failed recording custom volume replication state", logger.Ctx{"err": err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// replicateCustomVolume takes a new replication snapshot of the custom volume, refreshes the copy of the volume
+// on the replication target with it and then prunes the replication snapshots exceeding the retention.
+// It returns the name of the replicated snapshot.
+func replicateCustomVolume(ctx context.Context, s *state.State, v db.StorageVolumeArgs, op *operations.Operation) (string, error) {
+	targetURL, targetPoolName, err := storagePools.ParseReplicationTarget(v.Config["replication.target"])
+	if err != nil {
+		return "", err
+	}
+
+	pool, err := storagePools.LoadByName(s, v.PoolName)
+	if err != nil {
+		return "", fmt.Errorf("Failed loading storage pool: %w", err)
+	}
+
+	dbVol, err := storagePools.VolumeDBGet(pool, v.ProjectName, v.Name, storageDrivers.VolumeTypeCustom)
+	if err != nil {
+		return "", err
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	// Take the snapshot to replicate.
+	var snapshotIndex int
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		snapshotIndex = tx.GetNextStorageVolumeSnapshotIndex(ctx, v.PoolName, v.Name, db.StoragePoolVolumeTypeCustom, customVolumeReplicationSnapshotPattern)
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	snapshotName := fmt.Sprintf(customVolumeReplicationSnapshotPattern, snapshotIndex)
+
+	err = pool.CreateCustomVolumeSnapshot(v.ProjectName, v.Name, snapshotName, time.Time{}, false, op)
+	if err != nil {
+		return "", fmt.Errorf("Failed creating replication snapshot: %w", err)
+	}
+
+	reverter.Add(func() {
+		_ = pool.DeleteCustomVolumeSnapshot(v.ProjectName, v.Name+internalInstance.SnapshotDelimiter+snapshotName, op)
+	})
+
+	target, err := customVolumeReplicationConnect(ctx, s, v, targetURL)
+	if err != nil {
+		return "", err
+	}
+
+	target = target.UseProject(v.ProjectName)
+
+	// Connect to the local server to act as the migration source.
+	source, err := incus.ConnectIncusUnix(s.OS.GetUnixSocket(), nil)
+	if err != nil {
+		return "", fmt.Errorf("Failed connecting to local server: %w", err)
+	}
+
+	source = source.UseProject(v.ProjectName)
+	if s.ServerClustered && v.NodeID >= 0 {
+		source = source.UseTarget(s.ServerName)
+	}
+
+	// Only refresh the volume if it was already replicated, otherwise do a full copy.
+	_, _, err = target.GetStoragePoolVolume(targetPoolName, "custom", v.Name)
+	if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return "", fmt.Errorf("Failed checking for volume on replication target: %w", err)
+	}
+
+	refresh := err == nil
+
+	vol := api.StorageVolume{
+		Name:        v.Name,
+		Type:        db.StoragePoolVolumeTypeNameCustom,
+		ContentType: dbVol.ContentType,
+		StorageVolumePut: api.StorageVolumePut{
+			Config:      customVolumeReplicationConfig(dbVol.Config),
+			Description: dbVol.Description,
+		},
+	}
+
+	remoteOp, err := target.CopyStoragePoolVolume(targetPoolName, source, v.PoolName, vol, &incus.StoragePoolVolumeCopyArgs{
+		Name:    v.Name,
+		Mode:    "push",
+		Refresh: refresh,
+	})
+	if err != nil {
+		return "", fmt.Errorf("Failed starting transfer to replication target: %w", err)
+	}
+
+	err = remoteOp.Wait()
+	if err != nil {
+		return "", fmt.Errorf("Failed transferring volume to replication target: %w", err)
+	}
+
+	reverter.Success()
+
+	// Prune the replication snapshots exceeding the retention. The snapshots removed here get removed
+	// from the target on the next refresh.
+	err = pruneCustomVolumeReplicationSnapshots(pool, v, op)
+	if err != nil {
+		logger.Warn("Failed pruning custom volume replication snapshots", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName, "err": err})
+	}
+
+	return snapshotName, nil
+}
+
+// customVolumeReplicationConnect connects to the replication target of a custom volume using the client
+// certificate of the volume, generating it on first use. If the target doesn't trust that certificate yet,
+// it gets added to the target using the trust token of the volume.
+func customVolumeReplicationConnect(ctx context.Context, s *state.State, v db.StorageVolumeArgs, targetURL string) (incus.InstanceServer, error) {
+	clientCert := v.Config["volatile.replication.certificate"]
+	clientKey := v.Config["volatile.replication.key"]
+	if clientCert == "" || clientKey == "" {
+		cert, key, err := localtls.GenerateMemCert(true, false)
+		if err != nil {
+			return nil, fmt.Errorf("Failed generating replication client certificate: %w", err)
+		}
+
+		clientCert = string(cert)
+		clientKey = string(key)
+
+		err = customVolumeUpdateVolatile(ctx, s, v.PoolName, v.ProjectName, v.Name, map[string]string{
+			"volatile.replication.certificate": clientCert,
+			"volatile.replication.key":         clientKey,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Failed storing replication client certificate: %w", err)
+		}
+	}
+
+	args := &incus.ConnectionArgs{
+		TLSServerCert: v.Config["replication.target.certificate"],
+		TLSClientCert: clientCert,
+		TLSClientKey:  clientKey,
+		UserAgent:     version.UserAgent,
+		Proxy:         s.Proxy,
+		SkipGetEvents: true,
+	}
+
+	target, err := incus.ConnectIncusWithContext(ctx, targetURL, args)
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to replication target: %w", err)
+	}
+
+	server, _, err := target.GetServer()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting replication target information: %w", err)
+	}
+
+	if server.Auth == "trusted" {
+		return target, nil
+	}
+
+	token := v.Config["replication.target.token"]
+	if token == "" {
+		return nil, errors.New("Replication target doesn't trust the volume client certificate and no trust token is set")
+	}
+
+	err = target.CreateCertificate(api.CertificatesPost{TrustToken: token})
+	if err != nil {
+		return nil, fmt.Errorf("Failed adding the volume client certificate to the replication target: %w", err)
+	}
+
+	// Reconnect now that the certificate is trusted.
+	target, err = incus.ConnectIncusWithContext(ctx, targetURL, args)
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to replication target: %w", err)
+	}
+
+	return target, nil
+}
+
+// customVolumeReplicationConfig returns the config to apply to the copy of the volume on the replication target.
+// The replication settings and the volatile state aren't replicated.
+func customVolumeReplicationConfig(config map[string]string) map[string]string {
+	out := make(map[string]string, len(config))
+	for key, value := range config {
+		if strings.HasPrefix(key, "replication.") || strings.HasPrefix(key, "volatile.") {
+			continue
+		}
+
+		out[key] = value
+	}
+
+	return out
+}
+
+// isCustomVolumeReplicationSnapshot returns whether the snapshot was taken for custom volume replication.
+func isCustomVolumeReplicationSnapshot(snapName string) bool {
+	var index int
+	n, err := fmt.Sscanf(snapName, customVolumeReplicationSnapshotPattern, &index)
+
+	return err == nil && n == 1 && fmt.Sprintf(customVolumeReplicationSnapshotPattern, index) == snapName
+}
+
+// customVolumeReplicationSnapshotsToPrune returns the oldest replication snapshots exceeding the retention
+// set in replication.retention (1 by default).
+func customVolumeReplicationSnapshotsToPrune(config map[string]string, snapshots []db.StorageVolumeArgs) ([]db.StorageVolumeArgs, error) {
+	retention := 1
+	if config["replication.retention"] != "" {
+		var err error
+
+		retention, err = strconv.Atoi(config["replication.retention"])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid replication.retention: %w", err)
+		}
+	}
+
+	// Only consider the snapshots taken for replication.
+	snapshots = slices.DeleteFunc(slices.Clone(snapshots), func(snap db.StorageVolumeArgs) bool {
+		_, snapName, _ := api.GetParentAndSnapshotName(snap.Name)
+
+		return !isCustomVolumeReplicationSnapshot(snapName)
+	})
+
+	if len(snapshots) <= retention {
+		return nil, nil
+	}
+
+	slices.SortFunc(snapshots, func(a db.StorageVolumeArgs, b db.StorageVolumeArgs) int {
+		return a.CreationDate.Compare(b.CreationDate)
+	})
+
+	return snapshots[:len(snapshots)-retention], nil
+}
+
+// pruneCustomVolumeReplicationSnapshots deletes the oldest replication snapshots of the volume
+// so that only the number of snapshots set in replication.retention are kept.
+func pruneCustomVolumeReplicationSnapshots(pool storagePools.Pool, v db.StorageVolumeArgs, op *operations.Operation) error {
+	snapshots, err := storagePools.VolumeDBSnapshotsGet(pool, v.ProjectName, v.Name, storageDrivers.VolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	prune, err := customVolumeReplicationSnapshotsToPrune(v.Config, snapshots)
+	if err != nil {
+		return err
+	}
+
+	for _, snap := range prune {
+		err := pool.DeleteCustomVolumeSnapshot(v.ProjectName, snap.Name, op)
+		if err != nil {
+			return fmt.Errorf("Failed deleting replication snapshot %q: %w", snap.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// customVolumeReplicationState returns the replication state of a custom volume or nil if replication isn't configured.
+func customVolumeReplicationState(pool storagePools.Pool, projectName string, dbVol *db.StorageVolume) *api.StorageVolumeStateReplication {
+	if dbVol.Config["replication.target"] == "" {
+		return nil
+	}
+
+	replication := &api.StorageVolumeStateReplication{
+		Target:       dbVol.Config["replication.target"],
+		LastSnapshot: dbVol.Config["volatile.replication.last_snapshot"],
+		LastError:    dbVol.Config["volatile.replication.last_error"],
+	}
+
+	lastSuccess, err := time.Parse(time.RFC3339, dbVol.Config["volatile.replication.last_success"])
+	if err != nil {
+		return replication
+	}
+
+	replication.LastSuccess = &lastSuccess
+
+	// The lag is the age of the data on the target, so measure it from when the replicated snapshot was taken.
+	since := lastSuccess
+	if replication.LastSnapshot != "" {
+		snap, err := storagePools.VolumeDBGet(pool, projectName, dbVol.Name+internalInstance.SnapshotDelimiter+replication.LastSnapshot, storageDrivers.VolumeTypeCustom)
+		if err == nil && !snap.CreatedAt.IsZero() {
+			since = snap.CreatedAt
+		}
+	}
+
+	replication.Lag = int64(time.Since(since).Seconds())
+
+	return replication
+}
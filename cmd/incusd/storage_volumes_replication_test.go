@@ -0,0 +1,137 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/server/db"
+)
+
+func TestCustomVolumeReplicationConfig(t *testing.T) {
+	config := customVolumeReplicationConfig(map[string]string{
+		"size":                               "10GiB",
+		"snapshots.expiry":                   "1d",
+		"replication.target":                 "https://backup.example.net:8443/default",
+		"replication.schedule":               "@hourly",
+		"volatile.replication.last_snapshot": "replication-3",
+		"volatile.uuid":                      "a5c8c2d8-2d6a-4d2c-8dc8-2b7c1c2d4b7e",
+	})
+
+	if len(config) != 2 || config["size"] != "10GiB" || config["snapshots.expiry"] != "1d" {
+		t.Fatalf("Unexpected replicated config: %v", config)
+	}
+}
+
+func TestIsCustomVolumeReplicationSnapshot(t *testing.T) {
+	tests := map[string]bool{
+		"replication-0":    true,
+		"replication-12":   true,
+		"replication-":     false,
+		"replication-1a":   false,
+		"replication-01":   false,
+		"snap0":            false,
+		"my-replication-1": false,
+	}
+
+	for name, want := range tests {
+		if isCustomVolumeReplicationSnapshot(name) != want {
+			t.Errorf("Unexpected result for %q, expected %v", name, want)
+		}
+	}
+}
+
+func TestCustomVolumeReplicationSnapshotsToPrune(t *testing.T) {
+	now := time.Now()
+
+	snapshots := []db.StorageVolumeArgs{
+		{Name: "vol/replication-2", CreationDate: now.Add(-2 * time.Hour)},
+		{Name: "vol/snap0", CreationDate: now.Add(-10 * time.Hour)},
+		{Name: "vol/replication-0", CreationDate: now.Add(-4 * time.Hour)},
+		{Name: "vol/replication-3", CreationDate: now.Add(-1 * time.Hour)},
+		{Name: "vol/replication-1", CreationDate: now.Add(-3 * time.Hour)},
+	}
+
+	names := func(snapshots []db.StorageVolumeArgs) []string {
+		out := []string{}
+		for _, snap := range snapshots {
+			out = append(out, snap.Name)
+		}
+
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		retention string
+		want      []string
+		wantErr   bool
+	}{
+		{name: "default retention", want: []string{"vol/replication-0", "vol/replication-1", "vol/replication-2"}},
+		{name: "keep two", retention: "2", want: []string{"vol/replication-0", "vol/replication-1"}},
+		{name: "keep all", retention: "4", want: []string{}},
+		{name: "keep more", retention: "10", want: []string{}},
+		{name: "invalid retention", retention: "many", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]string{"replication.retention": tt.retention}
+
+			prune, err := customVolumeReplicationSnapshotsToPrune(config, snapshots)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !slices.Equal(names(prune), tt.want) {
+				t.Fatalf("Expected %v to be pruned, got %v", tt.want, names(prune))
+			}
+		})
+	}
+
+	// The provided list is left untouched.
+	if snapshots[0].Name != "vol/replication-2" || len(snapshots) != 5 {
+		t.Fatal("Snapshot list was modified")
+	}
+}
+
+func TestCustomVolumeReplicationState(t *testing.T) {
+	// Volumes without replication have no state.
+	state := customVolumeReplicationState(nil, "default", &db.StorageVolume{})
+	if state != nil {
+		t.Fatalf("Unexpected replication state: %+v", state)
+	}
+
+	// Volumes which were never replicated have no lag.
+	vol := &db.StorageVolume{}
+	vol.Config = map[string]string{
+		"replication.target":              "https://backup.example.net:8443/default",
+		"volatile.replication.last_error": "Connection refused",
+	}
+
+	state = customVolumeReplicationState(nil, "default", vol)
+	if state == nil || state.LastError != "Connection refused" || state.LastSuccess != nil || state.Lag != 0 {
+		t.Fatalf("Unexpected replication state: %+v", state)
+	}
+
+	// Without a snapshot, the lag is measured from the last success.
+	vol.Config["volatile.replication.last_error"] = ""
+	vol.Config["volatile.replication.last_success"] = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	state = customVolumeReplicationState(nil, "default", vol)
+	if state == nil || state.LastSuccess == nil {
+		t.Fatalf("Unexpected replication state: %+v", state)
+	}
+
+	if state.Lag < 3590 || state.Lag > 3610 {
+		t.Fatalf("Expected a lag of about an hour, got %ds", state.Lag)
+	}
+}
@@ -163,6 +163,16 @@ func storagePoolVolumeTypeStateGet(d *Daemon, r *http.Request) response.Response
 	// Prepare the state struct.
 	state := api.StorageVolumeState{}
 
+	// Add the replication state of custom volumes.
+	if volumeType == db.StoragePoolVolumeTypeCustom {
+		dbVol, err := storagePools.VolumeDBGet(pool, projectName, volumeName, storageDrivers.VolumeTypeCustom)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		state.Replication = customVolumeReplicationState(pool, projectName, dbVol)
+	}
+
 	if usage != nil {
 		state.Usage = &api.StorageVolumeStateUsage{}
 
@@ -29,6 +29,16 @@ func DotPrefixMatch(short string, full string) bool {
 func ValueOf(obj any, field string) any {
 	value := reflect.ValueOf(obj)
 	typ := value.Type()
+
+	if value.Kind() == reflect.Pointer {
+		// Fields of unset structs have their zero value.
+		if value.IsNil() {
+			return ValueOf(reflect.Zero(typ.Elem()).Interface(), field)
+		}
+
+		return ValueOf(value.Elem().Interface(), field)
+	}
+
 	parts := strings.Split(field, ".")
 
 	key := parts[0]
@@ -45,6 +55,11 @@ func ValueOf(obj any, field string) any {
 				m = mm
 			}
 
+			v, ok := m[field]
+			if ok {
+				return v
+			}
+
 			for k, v := range m {
 				if DotPrefixMatch(field, k) {
 					return v
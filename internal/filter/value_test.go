@@ -63,3 +63,35 @@ func TestValueOf_Instance(t *testing.T) {
 		})
 	}
 }
+
+func TestValueOf_InstanceFull(t *testing.T) {
+	instance := api.InstanceFull{
+		Instance: api.Instance{Name: "v1"},
+		State:    &api.InstanceState{Status: "Running"},
+		Inventory: &api.InstanceInventory{
+			OSRelease: map[string]string{
+				"ID":         "debian",
+				"VERSION":    "12 (bookworm)",
+				"VERSION_ID": "12",
+			},
+		},
+	}
+
+	cases := map[string]any{}
+	cases["name"] = "v1"
+	cases["state.status"] = "Running"
+	cases["inventory.os_release.ID"] = "debian"
+	cases["inventory.os_release.VERSION"] = "12 (bookworm)"
+	cases["inventory.os_release.VERSION_ID"] = "12"
+
+	for field := range cases {
+		t.Run(field, func(t *testing.T) {
+			value := filter.ValueOf(instance, field)
+			assert.Equal(t, cases[field], value)
+		})
+	}
+
+	// Instances without an inventory have empty inventory values.
+	instance.Inventory = nil
+	assert.Equal(t, "", filter.ValueOf(instance, "inventory.os_release.ID"))
+}
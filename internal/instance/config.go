@@ -178,6 +178,49 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What to do when evacuating the instance
 	"cluster.evacuate": validate.Optional(validate.IsOneOf("auto", "migrate", "live-migrate", "stop", "stateful-stop", "force-stop")),
 
+	// gendoc:generate(entity=instance, group=miscellaneous, key=cluster.healing.priority)
+	// When healing an offline cluster member, instances with the highest value are restarted first.
+	// Instances with the same priority are restarted in parallel.
+	//
+	// See {ref}`cluster-automatic-evacuation` for more information.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0`
+	//  liveupdate: yes
+	//  shortdesc: What order to restart the instances in when healing
+	"cluster.healing.priority": validate.Optional(validate.IsInt64),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=cluster.healing.delay)
+	// The number of seconds to wait after the instance was restarted before restarting instances of a lower priority.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0`
+	//  liveupdate: yes
+	//  shortdesc: Delay after restarting the instance when healing
+	"cluster.healing.delay": validate.Optional(validate.IsUint32),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=placement.affinity)
+	// Comma-separated list of placement groups. Instances of the same project sharing an affinity group
+	// are placed on the same cluster member whenever possible.
+	//
+	// See {ref}`cluster-placement-groups` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Groups of instances to keep on the same cluster member
+	"placement.affinity": validate.Optional(validate.IsListOf(isPlacementGroup)),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=placement.anti_affinity)
+	// Comma-separated list of placement groups. Instances of the same project sharing an anti-affinity group
+	// are never placed on the same cluster member.
+	//
+	// See {ref}`cluster-placement-groups` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Groups of instances to spread over different cluster members
+	"placement.anti_affinity": validate.Optional(validate.IsListOf(isPlacementGroup)),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu)
 	// A number or a specific range of CPUs to expose to the instance.
 	//
@@ -1061,6 +1104,100 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether to back the instance using huge pages
 	"limits.memory.hugepages": validate.Optional(validate.IsBool),
 
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.auto_balloon)
+	// If this option is set to `true`, Incus shrinks the memory of the instance through its balloon device when the host is under memory pressure, based on the memory usage reported by the `incus-agent`, and grows it back once the pressure is gone.
+	// This also enables free page reporting, which lets the host reclaim memory freed by the guest.
+	//
+	// See {ref}`instances-limit-memory-auto-balloon` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether to automatically resize the memory balloon
+	"limits.memory.auto_balloon": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.auto_balloon.min)
+	// Percentage of {config:option}`instance-resource-limits:limits.memory` or a fixed value in bytes.
+	// The memory of the instance is never shrunk below this value.
+	// ---
+	//  type: string
+	//  defaultdesc: `25%`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Minimum memory of the instance when automatically resizing the memory balloon
+	"limits.memory.auto_balloon.min": func(value string) error {
+		_, err := AutoBalloonMin(value, 0)
+		return err
+	},
+
+	// gendoc:generate(entity=instance, group=migration, key=migration.stateful.auto_converge)
+	// When enabled, the instance is progressively slowed down during live migration if its memory is written to faster than it can be transferred.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Whether to throttle the instance to help live migration converge
+	"migration.stateful.auto_converge": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=migration, key=migration.stateful.auto_converge.initial)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: `50`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Initial percentage of CPU throttling when live migration isn't converging
+	"migration.stateful.auto_converge.initial": validate.Optional(validate.IsInRange(1, 99)),
+
+	// gendoc:generate(entity=instance, group=migration, key=migration.stateful.auto_converge.increment)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: `10`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Percentage by which CPU throttling is increased while live migration isn't converging
+	"migration.stateful.auto_converge.increment": validate.Optional(validate.IsInRange(1, 99)),
+
+	// gendoc:generate(entity=instance, group=migration, key=migration.stateful.max_downtime)
+	// Time (in milliseconds) for which the instance may be paused at the end of a live migration, while the last of its memory and its device state are transferred.
+	// The migration carries on until the remaining memory can be transferred within that time.
+	// ---
+	//  type: integer
+	//  defaultdesc: `300`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Maximum downtime target for live migration
+	"migration.stateful.max_downtime": validate.Optional(validate.IsInRange(1, 2000000)),
+
+	// gendoc:generate(entity=instance, group=migration, key=migration.stateful.postcopy)
+	// Number of passes over the instance memory after which a live migration switches to post-copy.
+	// The instance then runs on the target while its remaining memory is transferred on demand.
+	//
+	// See {ref}`live-migration-vms-postcopy` for more information.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0` (disabled)
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Number of pre-copy passes before switching to post-copy
+	"migration.stateful.postcopy": validate.Optional(validate.IsUint32),
+
+	// gendoc:generate(entity=instance, group=migration, key=migration.stateful.multifd)
+	// Number of additional connections over which the memory of the instance is transferred during a live migration.
+	// Both the source and the target server must support it, and it can't be combined with {config:option}`instance-migration:migration.stateful.postcopy`.
+	//
+	// See {ref}`live-migration-vms-multifd` for more information.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0` (disabled)
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Number of multifd channels used for live migration
+	"migration.stateful.multifd": validate.Optional(validate.IsInRange(0, 32)),
+
 	// Caller is responsible for full validation of any raw.* value.
 
 	// gendoc:generate(entity=instance, group=raw, key=raw.qemu)
@@ -1197,6 +1334,29 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: The guest owner's `base64`-encoded session blob
 	"security.sev.session.data": validate.Optional(validate.IsAny),
 
+	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.freeze)
+	// Controls whether the guest file systems are frozen through the `incus-agent` while taking snapshots and backups of a running instance.
+	// Possible values are `auto` (freeze if the agent is available, otherwise fall back to a crash-consistent snapshot), `required` (fail if the file systems can't be frozen) and `disabled`.
+	//
+	// See {ref}`instances-snapshots-freeze` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `disabled`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Whether to freeze the guest file systems for snapshots
+	"snapshots.freeze": validate.Optional(validate.IsOneOf("auto", "required", "disabled")),
+
+	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.freeze.timeout)
+	// Time (in seconds) to wait for the guest, including its freeze hooks, to freeze its file systems.
+	// ---
+	//  type: integer
+	//  defaultdesc: `30`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: How long to wait for the guest file systems to be frozen
+	"snapshots.freeze.timeout": validate.Optional(validate.IsUint32),
+
 	// gendoc:generate(entity=instance, group=miscellaneous, key=agent.nic_config)
 	// For containers, the name and MTU of the default network interfaces is used for the instance devices.
 	// For virtual machines, set this option to `true` to set the name and MTU of the default network interfaces to be the same as the instance devices.
@@ -1282,6 +1442,15 @@ func ConfigKeyChecker(key string, instanceType api.InstanceType) (func(value str
 	}
 
 	if strings.HasPrefix(key, ConfigVolatilePrefix) {
+		// gendoc:generate(entity=instance, group=volatile, key=volatile.bitmap.<name>.checkpoint)
+		// Identifies the last incremental backup exported from or applied with the dirty bitmap.
+		// ---
+		//  type: string
+		//  shortdesc: Incremental backup checkpoint
+		if strings.HasPrefix(key, "volatile.bitmap.") && strings.HasSuffix(key, ".checkpoint") {
+			return validate.IsUUID, nil
+		}
+
 		// gendoc:generate(entity=instance, group=volatile, key=volatile.<name>.apply_quota)
 		// The disk quota is applied the next time the instance starts.
 		// ---
@@ -1714,3 +1883,40 @@ func InstanceIncludeWhenCopying(configKey string, remoteCopy bool) bool {
 
 	return true // Keep all other keys.
 }
+
+// isPlacementGroup validates the name of a placement group.
+func isPlacementGroup(value string) error {
+	if value == "" {
+		return errors.New("Placement group name cannot be empty")
+	}
+
+	return validate.IsAPIName(value, false)
+}
+
+// AutoBalloonMin returns the minimum memory (in bytes) of an instance with limits.memory.auto_balloon enabled,
+// from the value of limits.memory.auto_balloon.min and the memory limit of the instance.
+func AutoBalloonMin(value string, memoryLimit int64) (int64, error) {
+	if value == "" {
+		return memoryLimit / 4, nil
+	}
+
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.ParseInt(strings.TrimSuffix(value, "%"), 10, 64)
+		if err != nil {
+			return -1, err
+		}
+
+		if percent <= 0 || percent > 100 {
+			return -1, errors.New("Percentage must be between 1 and 100")
+		}
+
+		return memoryLimit * percent / 100, nil
+	}
+
+	memoryMin, err := units.ParseByteSizeString(value)
+	if err != nil {
+		return -1, err
+	}
+
+	return min(memoryMin, memoryLimit), nil
+}
@@ -1,6 +1,7 @@
 package backup
 
 import (
+	"errors"
 	"fmt"
 	"io"
 
@@ -35,6 +36,9 @@ const DefaultBackupPrefix = "backup"
 
 const backupIndexPath = "backup/index.yaml"
 
+// IncrementalPrefix is the path prefix used for the disk deltas of incremental backups.
+const IncrementalPrefix = "backup/incremental"
+
 // InstanceTypeToBackupType converts instance type to backup type.
 func InstanceTypeToBackupType(instanceType api.InstanceType) Type {
 	switch instanceType {
@@ -58,6 +62,51 @@ type Info struct {
 	OptimizedHeader  *bool          `json:"optimized_header,omitempty" yaml:"optimized_header,omitempty"` // Optional field to handle older optimized backups that don't have this field.
 	Type             Type           `json:"type,omitempty" yaml:"type,omitempty"`                         // Type of backup.
 	Config           *config.Config `json:"config,omitempty" yaml:"config,omitempty"`                     // Equivalent of backup.yaml but embedded in index for quick retrieval.
+	Incremental      *Incremental   `json:"incremental,omitempty" yaml:"incremental,omitempty"`           // Only set for incremental backups.
+}
+
+// Incremental represents the content of an incremental backup.
+type Incremental struct {
+	Bitmap string            `json:"bitmap" yaml:"bitmap"` // Name of the dirty bitmap the backup was generated from.
+	ID     string            `json:"id" yaml:"id"`         // Checkpoint reached once the backup is applied.
+	Parent string            `json:"parent" yaml:"parent"` // Checkpoint the backup must be applied on top of.
+	Disks  []IncrementalDisk `json:"disks" yaml:"disks"`
+}
+
+// IncrementalCheckpointKey returns the configuration key recording the last checkpoint of a dirty bitmap.
+// On the source, it identifies the last backup exported from the bitmap. On restored instances and volumes,
+// it identifies the last backup applied to them.
+func IncrementalCheckpointKey(bitmapName string) string {
+	return fmt.Sprintf("volatile.bitmap.%s.checkpoint", bitmapName)
+}
+
+// ValidateParent checks that the incremental backup can be applied on top of the given configuration.
+func (i *Incremental) ValidateParent(config map[string]string) error {
+	if i.Parent == "" || i.ID == "" {
+		return errors.New("Incremental backup is missing its checkpoint information")
+	}
+
+	current := config[IncrementalCheckpointKey(i.Bitmap)]
+	if current == "" {
+		return fmt.Errorf("Target has no checkpoint for bitmap %q, a full backup must be restored first", i.Bitmap)
+	}
+
+	if current == i.ID {
+		return fmt.Errorf("Incremental backup %q was already applied", i.ID)
+	}
+
+	if current != i.Parent {
+		return fmt.Errorf("Incremental backup applies on top of checkpoint %q but the target is at checkpoint %q", i.Parent, current)
+	}
+
+	return nil
+}
+
+// IncrementalDisk represents the changed blocks of a single disk within an incremental backup.
+type IncrementalDisk struct {
+	Device string `json:"device,omitempty" yaml:"device,omitempty"` // Instance device name (empty for custom volume backups).
+	Path   string `json:"path" yaml:"path"`                         // Path of the qcow2 delta image in the tarball.
+	Size   int64  `json:"size" yaml:"size"`                         // Virtual size of the disk.
 }
 
 // GetInfo extracts backup information from a given ReadSeeker.
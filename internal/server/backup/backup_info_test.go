@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"testing"
+)
+
+func TestIncrementalValidateParent(t *testing.T) {
+	key := IncrementalCheckpointKey("daily")
+
+	tests := []struct {
+		name        string
+		incremental Incremental
+		config      map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "Applies on top of the parent checkpoint",
+			incremental: Incremental{Bitmap: "daily", ID: "b", Parent: "a"},
+			config:      map[string]string{key: "a"},
+		},
+		{
+			name:        "Target at another checkpoint",
+			incremental: Incremental{Bitmap: "daily", ID: "c", Parent: "b"},
+			config:      map[string]string{key: "a"},
+			wantErr:     true,
+		},
+		{
+			name:        "Already applied",
+			incremental: Incremental{Bitmap: "daily", ID: "b", Parent: "a"},
+			config:      map[string]string{key: "b"},
+			wantErr:     true,
+		},
+		{
+			name:        "Target without checkpoint",
+			incremental: Incremental{Bitmap: "daily", ID: "b", Parent: "a"},
+			config:      map[string]string{},
+			wantErr:     true,
+		},
+		{
+			name:        "Checkpoint of another bitmap",
+			incremental: Incremental{Bitmap: "daily", ID: "b", Parent: "a"},
+			config:      map[string]string{IncrementalCheckpointKey("weekly"): "a"},
+			wantErr:     true,
+		},
+		{
+			name:        "Backup without parent",
+			incremental: Incremental{Bitmap: "daily", ID: "b"},
+			config:      map[string]string{key: ""},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.incremental.ValidateParent(tt.config)
+			if tt.wantErr && err == nil {
+				t.Fatal("Expected an error, got none")
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
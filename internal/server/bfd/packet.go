@@ -0,0 +1,157 @@
+package bfd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// controlPacketLength is the length of a BFD control packet without authentication section.
+const controlPacketLength = 24
+
+// State represents the state of a BFD session.
+type State uint8
+
+// BFD session states (RFC 5880 section 4.1).
+const (
+	StateAdminDown State = 0
+	StateDown      State = 1
+	StateInit      State = 2
+	StateUp        State = 3
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateAdminDown:
+		return "admin-down"
+	case StateDown:
+		return "down"
+	case StateInit:
+		return "init"
+	case StateUp:
+		return "up"
+	}
+
+	return fmt.Sprintf("unknown(%d)", uint8(s))
+}
+
+// Diagnostic represents the reason of the last state change of a BFD session.
+type Diagnostic uint8
+
+// BFD diagnostic codes (RFC 5880 section 4.1).
+const (
+	DiagnosticNone                 Diagnostic = 0
+	DiagnosticControlDetectionTime Diagnostic = 1
+	DiagnosticNeighborSignaledDown Diagnostic = 3
+	DiagnosticAdministrativelyDown Diagnostic = 7
+)
+
+// controlPacket represents a BFD control packet.
+type controlPacket struct {
+	diagnostic Diagnostic
+	state      State
+	poll       bool
+	final      bool
+	demand     bool
+	detectMult uint8
+
+	myDiscriminator   uint32
+	yourDiscriminator uint32
+
+	// Intervals in microseconds.
+	desiredMinTxInterval    uint32
+	requiredMinRxInterval   uint32
+	requiredMinEchoInterval uint32
+}
+
+// marshal returns the wire representation of the packet.
+func (p *controlPacket) marshal() []byte {
+	b := make([]byte, controlPacketLength)
+
+	// Version 1.
+	b[0] = 1<<5 | byte(p.diagnostic)&0x1f
+	b[1] = byte(p.state) << 6
+
+	if p.poll {
+		b[1] |= 1 << 5
+	}
+
+	if p.final {
+		b[1] |= 1 << 4
+	}
+
+	if p.demand {
+		b[1] |= 1 << 1
+	}
+
+	b[2] = p.detectMult
+	b[3] = controlPacketLength
+
+	binary.BigEndian.PutUint32(b[4:], p.myDiscriminator)
+	binary.BigEndian.PutUint32(b[8:], p.yourDiscriminator)
+	binary.BigEndian.PutUint32(b[12:], p.desiredMinTxInterval)
+	binary.BigEndian.PutUint32(b[16:], p.requiredMinRxInterval)
+	binary.BigEndian.PutUint32(b[20:], p.requiredMinEchoInterval)
+
+	return b
+}
+
+// parseControlPacket parses and validates a received BFD control packet (RFC 5880 section 6.8.6).
+func parseControlPacket(b []byte) (*controlPacket, error) {
+	if len(b) < controlPacketLength {
+		return nil, errors.New("Packet too short")
+	}
+
+	version := b[0] >> 5
+	if version != 1 {
+		return nil, fmt.Errorf("Unsupported version %d", version)
+	}
+
+	length := int(b[3])
+	if length < controlPacketLength || length > len(b) {
+		return nil, fmt.Errorf("Invalid length %d", length)
+	}
+
+	// Authentication isn't supported.
+	if b[1]&(1<<2) != 0 {
+		return nil, errors.New("Authentication isn't supported")
+	}
+
+	// Multipoint must be zero.
+	if b[1]&1 != 0 {
+		return nil, errors.New("Multipoint bit set")
+	}
+
+	p := &controlPacket{
+		diagnostic:              Diagnostic(b[0] & 0x1f),
+		state:                   State(b[1] >> 6),
+		poll:                    b[1]&(1<<5) != 0,
+		final:                   b[1]&(1<<4) != 0,
+		demand:                  b[1]&(1<<1) != 0,
+		detectMult:              b[2],
+		myDiscriminator:         binary.BigEndian.Uint32(b[4:]),
+		yourDiscriminator:       binary.BigEndian.Uint32(b[8:]),
+		desiredMinTxInterval:    binary.BigEndian.Uint32(b[12:]),
+		requiredMinRxInterval:   binary.BigEndian.Uint32(b[16:]),
+		requiredMinEchoInterval: binary.BigEndian.Uint32(b[20:]),
+	}
+
+	if p.detectMult == 0 {
+		return nil, errors.New("Detection multiplier is zero")
+	}
+
+	if p.myDiscriminator == 0 {
+		return nil, errors.New("Discriminator is zero")
+	}
+
+	if p.poll && p.final {
+		return nil, errors.New("Both poll and final bits set")
+	}
+
+	if p.yourDiscriminator == 0 && p.state != StateDown && p.state != StateAdminDown {
+		return nil, errors.New("Missing discriminator of the receiver")
+	}
+
+	return p, nil
+}
@@ -0,0 +1,59 @@
+package bfd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlPacketRoundTrip(t *testing.T) {
+	p := &controlPacket{
+		diagnostic:            DiagnosticNeighborSignaledDown,
+		state:                 StateInit,
+		poll:                  true,
+		detectMult:            3,
+		myDiscriminator:       0x11223344,
+		yourDiscriminator:     0x55667788,
+		desiredMinTxInterval:  300000,
+		requiredMinRxInterval: 300000,
+	}
+
+	b := p.marshal()
+	assert.Len(t, b, controlPacketLength)
+	assert.Equal(t, byte(0x23), b[0])
+	assert.Equal(t, byte(0xa0), b[1])
+
+	parsed, err := parseControlPacket(b)
+	require.NoError(t, err)
+	assert.Equal(t, p, parsed)
+}
+
+func TestControlPacketInvalid(t *testing.T) {
+	valid := func() []byte {
+		p := &controlPacket{state: StateUp, detectMult: 3, myDiscriminator: 1, yourDiscriminator: 2}
+		return p.marshal()
+	}
+
+	tests := []struct {
+		name   string
+		mangle func(b []byte) []byte
+	}{
+		{"short", func(b []byte) []byte { return b[:10] }},
+		{"version", func(b []byte) []byte { b[0] = 2 << 5; return b }},
+		{"length", func(b []byte) []byte { b[3] = 48; return b }},
+		{"authentication", func(b []byte) []byte { b[1] |= 1 << 2; return b }},
+		{"multipoint", func(b []byte) []byte { b[1] |= 1; return b }},
+		{"multiplier", func(b []byte) []byte { b[2] = 0; return b }},
+		{"discriminator", func(b []byte) []byte { b[7] = 0; return b }},
+		{"poll and final", func(b []byte) []byte { b[1] |= 3 << 4; return b }},
+		{"your discriminator", func(b []byte) []byte { b[11] = 0; return b }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseControlPacket(tt.mangle(valid()))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Package bfd implements single-hop Bidirectional Forwarding Detection (RFC 5880 and RFC 5881).
+package bfd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// Server represents a BFD server, handling sessions with multiple peers over a shared listener.
+type Server struct {
+	conn     *net.UDPConn
+	sessions map[uint32]*Session
+
+	mu sync.Mutex
+}
+
+// NewServer returns a new BFD server.
+func NewServer() *Server {
+	return &Server{
+		sessions: map[uint32]*Session{},
+	}
+}
+
+// AddSession starts a new BFD session with the peer.
+// The onChange function is called from a separate go routine whenever the session changes state.
+func (s *Server) AddSession(peer net.IP, interval time.Duration, multiplier uint8, onChange func(State)) (*Session, error) {
+	if interval <= 0 {
+		return nil, errors.New("Invalid BFD interval")
+	}
+
+	if multiplier == 0 {
+		return nil, errors.New("Invalid BFD multiplier")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Start the listener with the first session.
+	if s.conn == nil {
+		conn, err := listenControl()
+		if err != nil {
+			return nil, err
+		}
+
+		s.conn = conn
+		go s.serve(conn)
+	}
+
+	// Pick a unique non-zero discriminator.
+	var discriminator uint32
+	for discriminator == 0 || s.sessions[discriminator] != nil {
+		discriminator = rand.Uint32()
+	}
+
+	session := newSession(peer, discriminator, interval, multiplier, onChange)
+	err := session.start()
+	if err != nil {
+		s.stopListener()
+		return nil, err
+	}
+
+	s.sessions[discriminator] = session
+
+	return session, nil
+}
+
+// RemoveSession stops a BFD session.
+func (s *Server) RemoveSession(session *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sessions[session.localDiscriminator] != session {
+		return
+	}
+
+	delete(s.sessions, session.localDiscriminator)
+	session.stop()
+	s.stopListener()
+}
+
+// stopListener closes the listener once no session remains.
+func (s *Server) stopListener() {
+	if s.conn == nil || len(s.sessions) > 0 {
+		return
+	}
+
+	_ = s.conn.Close()
+	s.conn = nil
+}
+
+// findSession returns the session a received packet belongs to (RFC 5880 section 6.3).
+func (s *Server) findSession(p *controlPacket, source net.IP) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.yourDiscriminator != 0 {
+		session := s.sessions[p.yourDiscriminator]
+		if session == nil || !session.peer.Equal(source) {
+			return nil
+		}
+
+		return session
+	}
+
+	for _, session := range s.sessions {
+		if session.peer.Equal(source) {
+			return session
+		}
+	}
+
+	return nil
+}
+
+// serve receives control packets and hands them to the matching session.
+func (s *Server) serve(conn *net.UDPConn) {
+	buf := make([]byte, 1500)
+	oob := make([]byte, 128)
+
+	for {
+		n, oobn, _, addr, err := conn.ReadMsgUDP(buf, oob)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+
+			logger.Debug("Failed receiving BFD packet", logger.Ctx{"err": err})
+			continue
+		}
+
+		// Drop anything which may have been forwarded (RFC 5881 section 5).
+		ttl, ok := receivedTTL(oob[:oobn])
+		if ok && ttl != 255 {
+			continue
+		}
+
+		p, err := parseControlPacket(buf[:n])
+		if err != nil {
+			logger.Debug("Dropping invalid BFD packet", logger.Ctx{"source": addr.IP.String(), "err": err})
+			continue
+		}
+
+		session := s.findSession(p, addr.IP)
+		if session == nil {
+			continue
+		}
+
+		session.deliver(p)
+	}
+}
+
+// listenControl opens the listener for control packets.
+func listenControl() (*net.UDPConn, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: controlPort})
+	if err != nil {
+		return nil, fmt.Errorf("Failed starting BFD listener: %w", err)
+	}
+
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	// Request the TTL of received packets. This is best effort as either option
+	// fails when the matching address family is disabled on the system.
+	err = rawConn.Control(func(fd uintptr) {
+		_ = unix.SetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_RECVTTL, 1)
+		_ = unix.SetsockoptInt(int(fd), unix.IPPROTO_IPV6, unix.IPV6_RECVHOPLIMIT, 1)
+	})
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// receivedTTL extracts the TTL or hop limit from the control messages of a received packet.
+func receivedTTL(oob []byte) (int, bool) {
+	msgs, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return 0, false
+	}
+
+	for _, msg := range msgs {
+		isTTL := msg.Header.Level == unix.IPPROTO_IP && msg.Header.Type == unix.IP_TTL
+		isHopLimit := msg.Header.Level == unix.IPPROTO_IPV6 && msg.Header.Type == unix.IPV6_HOPLIMIT
+
+		if (isTTL || isHopLimit) && len(msg.Data) >= 4 {
+			return int(binary.NativeEndian.Uint32(msg.Data)), true
+		}
+	}
+
+	return 0, false
+}
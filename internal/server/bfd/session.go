@@ -0,0 +1,338 @@
+package bfd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// controlPort is the destination port for single-hop BFD control packets (RFC 5881 section 4).
+const controlPort = 3784
+
+// slowInterval is the minimum transmit interval used while the session isn't up (RFC 5880 section 6.8.3).
+const slowInterval = time.Second
+
+// Session represents a single-hop asynchronous BFD session with a peer.
+type Session struct {
+	peer               net.IP
+	localDiscriminator uint32
+	interval           time.Duration
+	multiplier         uint8
+	onChange           func(State)
+
+	conn   *net.UDPConn
+	rx     chan *controlPacket
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu                  sync.Mutex
+	state               State
+	diagnostic          Diagnostic
+	remoteState         State
+	remoteDiscriminator uint32
+	remoteMinRx         time.Duration
+	remoteDesiredMinTx  time.Duration
+	remoteMultiplier    uint8
+	pollActive          bool
+}
+
+// newSession prepares a new session, start must be called to bring it up.
+func newSession(peer net.IP, discriminator uint32, interval time.Duration, multiplier uint8, onChange func(State)) *Session {
+	return &Session{
+		peer:               peer,
+		localDiscriminator: discriminator,
+		interval:           interval,
+		multiplier:         multiplier,
+		onChange:           onChange,
+		rx:                 make(chan *controlPacket, 16),
+		done:               make(chan struct{}),
+		state:              StateDown,
+		remoteState:        StateDown,
+		remoteMinRx:        time.Microsecond,
+	}
+}
+
+// Peer returns the address of the remote system.
+func (s *Session) Peer() net.IP {
+	return s.peer
+}
+
+// State returns the current state of the session.
+func (s *Session) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state
+}
+
+// start opens the transmit socket and starts the session.
+func (s *Session) start() error {
+	conn, err := dialControl(s.peer)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.conn = conn
+	s.cancel = cancel
+
+	go s.run(ctx)
+
+	return nil
+}
+
+// stop signals the peer that the session is administratively down and stops it.
+func (s *Session) stop() {
+	s.cancel()
+	<-s.done
+	_ = s.conn.Close()
+}
+
+// deliver queues a received packet for processing by the session.
+func (s *Session) deliver(p *controlPacket) {
+	select {
+	case s.rx <- p:
+	default:
+		// Drop the packet if the session is lagging behind, the next one will make up for it.
+	}
+}
+
+// run is the main loop of the session.
+func (s *Session) run(ctx context.Context) {
+	defer close(s.done)
+
+	txTimer := time.NewTimer(s.txInterval())
+	defer txTimer.Stop()
+
+	detectTimer := time.NewTimer(time.Hour)
+	detectTimer.Stop()
+	defer detectTimer.Stop()
+
+	// Send the first packet right away.
+	s.send(false)
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Let the peer know the session is going away rather than having it time out.
+			s.setState(StateAdminDown, DiagnosticAdministrativelyDown)
+			s.send(false)
+
+			return
+
+		case p := <-s.rx:
+			s.receive(p)
+			detectTimer.Reset(s.detectionTime())
+
+			// Answer a poll sequence right away.
+			if p.poll {
+				s.send(true)
+			}
+
+		case <-detectTimer.C:
+			s.mu.Lock()
+			s.remoteDiscriminator = 0
+			s.mu.Unlock()
+
+			if s.State() == StateInit || s.State() == StateUp {
+				s.setState(StateDown, DiagnosticControlDetectionTime)
+			}
+
+		case <-txTimer.C:
+			s.send(false)
+			txTimer.Reset(s.txInterval())
+		}
+	}
+}
+
+// receive runs a received packet through the state machine (RFC 5880 section 6.8.6).
+func (s *Session) receive(p *controlPacket) {
+	s.mu.Lock()
+	s.remoteState = p.state
+	s.remoteDiscriminator = p.myDiscriminator
+	s.remoteDesiredMinTx = time.Duration(p.desiredMinTxInterval) * time.Microsecond
+	s.remoteMinRx = time.Duration(p.requiredMinRxInterval) * time.Microsecond
+	s.remoteMultiplier = p.detectMult
+
+	// A final bit terminates our poll sequence.
+	if p.final {
+		s.pollActive = false
+	}
+
+	state := s.state
+	s.mu.Unlock()
+
+	if state == StateAdminDown {
+		return
+	}
+
+	if p.state == StateAdminDown {
+		if state != StateDown {
+			s.setState(StateDown, DiagnosticNeighborSignaledDown)
+		}
+
+		return
+	}
+
+	switch state {
+	case StateDown:
+		switch p.state {
+		case StateDown:
+			s.setState(StateInit, DiagnosticNone)
+		case StateInit:
+			s.setState(StateUp, DiagnosticNone)
+		}
+
+	case StateInit:
+		if p.state == StateInit || p.state == StateUp {
+			s.setState(StateUp, DiagnosticNone)
+		}
+
+	case StateUp:
+		if p.state == StateDown {
+			s.setState(StateDown, DiagnosticNeighborSignaledDown)
+		}
+	}
+}
+
+// setState updates the session state and notifies the caller of the change.
+func (s *Session) setState(state State, diagnostic Diagnostic) {
+	s.mu.Lock()
+	if s.state == state {
+		s.mu.Unlock()
+		return
+	}
+
+	oldState := s.state
+	s.state = state
+	s.diagnostic = diagnostic
+
+	// Switching from the slow to the configured rate requires a poll sequence (RFC 5880 section 6.8.3).
+	if state == StateUp && s.interval < slowInterval {
+		s.pollActive = true
+	}
+
+	s.mu.Unlock()
+
+	logger.Debug("BFD session state changed", logger.Ctx{"peer": s.peer.String(), "from": oldState.String(), "to": state.String()})
+
+	if s.onChange != nil {
+		go s.onChange(state)
+	}
+}
+
+// localDesiredMinTx returns the currently advertised transmit interval.
+func (s *Session) localDesiredMinTx() time.Duration {
+	if s.state != StateUp && s.interval < slowInterval {
+		return slowInterval
+	}
+
+	return s.interval
+}
+
+// txInterval returns the jittered interval until the next periodic packet (RFC 5880 section 6.8.7).
+func (s *Session) txInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interval := max(s.localDesiredMinTx(), s.remoteMinRx)
+
+	// Reduce the interval by a random 0-25% (10-25% with a multiplier of 1).
+	jitter := 75 + rand.IntN(26)
+	if s.multiplier == 1 {
+		jitter = 75 + rand.IntN(16)
+	}
+
+	return interval * time.Duration(jitter) / 100
+}
+
+// detectionTime returns the time after which the session is declared down without received packets.
+func (s *Session) detectionTime() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return time.Duration(s.remoteMultiplier) * max(s.interval, s.remoteDesiredMinTx)
+}
+
+// send transmits a control packet to the peer.
+func (s *Session) send(final bool) {
+	s.mu.Lock()
+
+	// The remote system doesn't want any periodic packets.
+	if !final && s.remoteDiscriminator != 0 && s.remoteMinRx == 0 {
+		s.mu.Unlock()
+		return
+	}
+
+	p := &controlPacket{
+		diagnostic:            s.diagnostic,
+		state:                 s.state,
+		poll:                  s.pollActive && !final,
+		final:                 final,
+		detectMult:            s.multiplier,
+		myDiscriminator:       s.localDiscriminator,
+		yourDiscriminator:     s.remoteDiscriminator,
+		desiredMinTxInterval:  uint32(s.localDesiredMinTx() / time.Microsecond),
+		requiredMinRxInterval: uint32(s.interval / time.Microsecond),
+	}
+
+	s.mu.Unlock()
+
+	_, err := s.conn.Write(p.marshal())
+	if err != nil {
+		logger.Debug("Failed sending BFD packet", logger.Ctx{"peer": s.peer.String(), "err": err})
+	}
+}
+
+// dialControl opens the socket used to send control packets to a peer (RFC 5881 section 4).
+func dialControl(peer net.IP) (*net.UDPConn, error) {
+	remote := &net.UDPAddr{IP: peer, Port: controlPort}
+
+	// The source port must be in the range 49152 through 65535.
+	var conn *net.UDPConn
+	var err error
+	for range 100 {
+		conn, err = net.DialUDP("udp", &net.UDPAddr{Port: 49152 + rand.IntN(16384)}, remote)
+		if err == nil || !errors.Is(err, unix.EADDRINUSE) {
+			break
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed opening BFD socket to %q: %w", peer.String(), err)
+	}
+
+	// Send with a TTL of 255 so the peer can ensure the packet didn't get forwarded (RFC 5881 section 5).
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	var sockErr error
+	err = rawConn.Control(func(fd uintptr) {
+		if peer.To4() != nil {
+			sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_TTL, 255)
+		} else {
+			sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IPV6, unix.IPV6_UNICAST_HOPS, 255)
+		}
+	})
+	if err == nil {
+		err = sockErr
+	}
+
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("Failed setting TTL on BFD socket: %w", err)
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,45 @@
+package bfd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionStateMachine(t *testing.T) {
+	s := newSession(net.ParseIP("192.0.2.1"), 1, 300*time.Millisecond, 3, nil)
+
+	remote := func(state State) *controlPacket {
+		return &controlPacket{state: state, detectMult: 3, myDiscriminator: 2, desiredMinTxInterval: 300000, requiredMinRxInterval: 300000}
+	}
+
+	// Three-way handshake.
+	s.receive(remote(StateDown))
+	assert.Equal(t, StateInit, s.State())
+
+	s.receive(remote(StateUp))
+	assert.Equal(t, StateUp, s.State())
+	assert.True(t, s.pollActive)
+	assert.Equal(t, 900*time.Millisecond, s.detectionTime())
+
+	// Final bit ends the poll sequence.
+	final := remote(StateUp)
+	final.final = true
+	s.receive(final)
+	assert.False(t, s.pollActive)
+
+	// Peer going down.
+	s.receive(remote(StateDown))
+	assert.Equal(t, StateDown, s.State())
+	assert.Equal(t, DiagnosticNeighborSignaledDown, s.diagnostic)
+
+	// Handshake from the other direction.
+	s.receive(remote(StateInit))
+	assert.Equal(t, StateUp, s.State())
+
+	// Peer administratively down.
+	s.receive(remote(StateAdminDown))
+	assert.Equal(t, StateDown, s.State())
+}
@@ -9,10 +9,11 @@ type DebugInfo struct {
 
 // DebugInfoServer exposes the shared listener configuration.
 type DebugInfoServer struct {
-	Address  string `json:"address" yaml:"address"`
-	ASN      uint32 `json:"asn" yaml:"asn"`
-	RouterID string `json:"router_id" yaml:"router_id"`
-	Running  bool   `json:"running" yaml:"running"`
+	Address    string `json:"address" yaml:"address"`
+	ASN        uint32 `json:"asn" yaml:"asn"`
+	RouterID   string `json:"router_id" yaml:"router_id"`
+	Running    bool   `json:"running" yaml:"running"`
+	Restarting bool   `json:"restarting" yaml:"restarting"`
 }
 
 // DebugInfoPrefix exposes details on a single BGP prefix.
@@ -29,6 +30,14 @@ type DebugInfoPeer struct {
 	Password string `json:"password" yaml:"password"`
 	Count    int    `json:"count" yaml:"count"`
 	HoldTime uint64 `json:"holdtime" yaml:"holdtime"`
+
+	BFDInterval         string   `json:"bfd_interval" yaml:"bfd_interval"`
+	BFDMultiplier       uint8    `json:"bfd_multiplier" yaml:"bfd_multiplier"`
+	BFDState            string   `json:"bfd_state" yaml:"bfd_state"`
+	GracefulRestartTime uint32   `json:"graceful_restart_time" yaml:"graceful_restart_time"`
+	Communities         []string `json:"communities" yaml:"communities"`
+	MED                 *uint32  `json:"med" yaml:"med"`
+	RejectImport        bool     `json:"reject_import" yaml:"reject_import"`
 }
 
 // Debug returns a dump of the current configuration.
@@ -48,6 +57,7 @@ func (s *Server) debug() DebugInfo {
 	debug.Server.ASN = s.asn
 	debug.Server.Address = s.address
 	debug.Server.RouterID = s.routerID.String()
+	debug.Server.Restarting = s.restarting
 
 	// Fill in the peers.
 	debug.Peers = []DebugInfoPeer{}
@@ -55,9 +65,22 @@ func (s *Server) debug() DebugInfo {
 		entry := DebugInfoPeer{}
 		entry.Address = peer.address.String()
 		entry.ASN = peer.asn
-		entry.Password = peer.password
+		entry.Password = peer.options.Password
 		entry.Count = peer.count
-		entry.HoldTime = peer.holdtime
+		entry.HoldTime = peer.options.HoldTime
+		entry.GracefulRestartTime = peer.options.GracefulRestartTime
+		entry.Communities = peer.options.Communities
+		entry.MED = peer.options.MED
+		entry.RejectImport = peer.options.RejectImport
+
+		if peer.options.BFDInterval > 0 {
+			entry.BFDInterval = peer.options.BFDInterval.String()
+			entry.BFDMultiplier = peer.options.BFDMultiplier
+		}
+
+		if peer.bfd != nil {
+			entry.BFDState = peer.bfd.State().String()
+		}
 
 		debug.Peers = append(debug.Peers, entry)
 	}
@@ -7,8 +7,11 @@ import (
 	"log/slog"
 	"maps"
 	"net"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	bgpAPI "github.com/osrg/gobgp/v4/api"
@@ -17,6 +20,7 @@ import (
 	bgpServer "github.com/osrg/gobgp/v4/pkg/server"
 
 	"github.com/lxc/incus/v7/internal/ports"
+	"github.com/lxc/incus/v7/internal/server/bfd"
 	"github.com/lxc/incus/v7/shared/logger"
 	"github.com/lxc/incus/v7/shared/revert"
 )
@@ -24,6 +28,10 @@ import (
 // Server represents a BGP server instance.
 type Server struct {
 	bgp *bgpServer.BgpServer
+	bfd *bfd.Server
+
+	// Set until RestartComplete is called, graceful restart peers are only established once all prefixes are restored.
+	restarting bool
 
 	// Internal state (to handle reconfiguration)
 	address  string
@@ -42,19 +50,64 @@ type path struct {
 }
 
 type peer struct {
-	address  net.IP
-	asn      uint32
-	password string
-	holdtime uint64
-	count    int
+	address net.IP
+	asn     uint32
+	options PeerOptions
+	count   int
+	bfd     *bfd.Session
+
+	lastError     string
+	lastErrorTime time.Time
+}
+
+// PeerOptions represents the optional configuration of a BGP peer.
+type PeerOptions struct {
+	Password string
+	HoldTime uint64
+
+	// BFD failure detection (disabled when BFDInterval is zero).
+	BFDInterval   time.Duration
+	BFDMultiplier uint8
+
+	// Graceful restart time in seconds (disabled when zero).
+	GracefulRestartTime uint32
+
+	// Export policy.
+	Communities []string
+	MED         *uint32
+
+	// Import policy.
+	RejectImport bool
+}
+
+// equal returns whether both sets of options are identical.
+func (o PeerOptions) equal(other PeerOptions) bool {
+	if o.MED == nil || other.MED == nil {
+		if o.MED != other.MED {
+			return false
+		}
+	} else if *o.MED != *other.MED {
+		return false
+	}
+
+	return o.Password == other.Password &&
+		o.HoldTime == other.HoldTime &&
+		o.BFDInterval == other.BFDInterval &&
+		o.BFDMultiplier == other.BFDMultiplier &&
+		o.GracefulRestartTime == other.GracefulRestartTime &&
+		slices.Equal(o.Communities, other.Communities) &&
+		o.RejectImport == other.RejectImport
 }
 
 // NewServer returns a new server instance.
+// The server starts in restarting mode, see RestartComplete.
 func NewServer() *Server {
 	// Setup new struct.
 	s := &Server{
-		paths: map[string]path{},
-		peers: map[string]peer{},
+		bfd:        bfd.NewServer(),
+		restarting: true,
+		paths:      map[string]path{},
+		peers:      map[string]peer{},
 	}
 
 	return s
@@ -139,12 +192,18 @@ func (s *Server) start(address string, asn uint32, routerID net.IP) error {
 	// Add existing peers.
 	s.peers = map[string]peer{}
 	for _, peer := range oldPeers {
-		err := s.addPeer(peer.address, peer.asn, peer.password, peer.holdtime)
+		err := s.addPeer(peer.address, peer.asn, peer.options)
 		if err != nil {
 			return err
 		}
 	}
 
+	// Apply the per-peer policies.
+	err = s.updatePolicies()
+	if err != nil {
+		return err
+	}
+
 	// Record the address.
 	s.address = address
 	s.asn = asn
@@ -160,6 +219,15 @@ func (s *Server) stop() error {
 		return nil
 	}
 
+	// Stop all BFD sessions.
+	for key, bgpPeer := range s.peers {
+		if bgpPeer.bfd != nil {
+			s.bfd.RemoveSession(bgpPeer.bfd)
+			bgpPeer.bfd = nil
+			s.peers[key] = bgpPeer
+		}
+	}
+
 	// Save the peer list.
 	oldPeers := map[string]peer{}
 	maps.Copy(oldPeers, s.peers)
@@ -465,15 +533,20 @@ func (s *Server) removePrefixByUUID(pathUUID string) error {
 }
 
 // AddPeer adds a new BGP peer.
-func (s *Server) AddPeer(address net.IP, asn uint32, password string, holdTime uint64) error {
+func (s *Server) AddPeer(address net.IP, asn uint32, options PeerOptions) error {
 	// Locking.
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.addPeer(address, asn, password, holdTime)
+	err := s.addPeer(address, asn, options)
+	if err != nil {
+		return err
+	}
+
+	return s.updatePolicies()
 }
 
-func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime uint64) error {
+func (s *Server) addPeer(address net.IP, asn uint32, options PeerOptions) error {
 	// Look for an existing peer.
 	bgpPeer, bgpPeerExists := s.peers[address.String()]
 	if bgpPeerExists {
@@ -481,29 +554,56 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime u
 			return fmt.Errorf("Peer %q already used but with differing ASN (%d vs %d)", address, asn, bgpPeer.asn)
 		}
 
-		if bgpPeer.password != password {
+		if bgpPeer.options.Password != options.Password {
 			return fmt.Errorf("Peer %q already used but with a different password", address)
 		}
 
+		if !bgpPeer.options.equal(options) {
+			return fmt.Errorf("Peer %q already used but with different options", address)
+		}
+
 		// Reuse the existing entry.
 		bgpPeer.count++
 		s.peers[address.String()] = bgpPeer
 		return nil
 	}
 
+	bgpPeer = peer{
+		address: address,
+		asn:     asn,
+		options: options,
+		count:   1,
+	}
+
+	// Add the peer, unless held back until RestartComplete.
+	if s.bgp != nil && !s.held(bgpPeer) {
+		err := s.establishPeer(&bgpPeer, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Add the peer to the list.
+	s.peers[address.String()] = bgpPeer
+
+	return nil
+}
+
+// held returns whether the peer is held back until RestartComplete.
+// Only peers configured for graceful restart retain our routes in the meantime, others are established right away.
+func (s *Server) held(bgpPeer peer) bool {
+	return s.restarting && bgpPeer.options.GracefulRestartTime > 0
+}
+
+// establishPeer adds the peer to the BGP server and starts its BFD session.
+func (s *Server) establishPeer(bgpPeer *peer, restarting bool) error {
 	// Setup the configuration.
 	n := &bgpAPI.Peer{
 		// Peer information.
 		Conf: &bgpAPI.PeerConf{
-			NeighborAddress: address.String(),
-			PeerAsn:         uint32(asn),
-			AuthPassword:    password,
-		},
-
-		// Allow for 120s offline before route removal.
-		GracefulRestart: &bgpAPI.GracefulRestart{
-			Enabled:     true,
-			RestartTime: 3600,
+			NeighborAddress: bgpPeer.address.String(),
+			PeerAsn:         bgpPeer.asn,
+			AuthPassword:    bgpPeer.options.Password,
 		},
 
 		// Always allow for the maximum multihop.
@@ -513,11 +613,21 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime u
 		},
 	}
 
+	// Have the peer retain our routes while we're restarting.
+	gracefulRestart := bgpPeer.options.GracefulRestartTime > 0
+	if gracefulRestart {
+		n.GracefulRestart = &bgpAPI.GracefulRestart{
+			Enabled:         true,
+			RestartTime:     bgpPeer.options.GracefulRestartTime,
+			LocalRestarting: restarting,
+		}
+	}
+
 	// Add hold time if configured.
-	if holdTime > 0 {
+	if bgpPeer.options.HoldTime > 0 {
 		n.Timers = &bgpAPI.Timers{
 			Config: &bgpAPI.TimersConfig{
-				HoldTime: holdTime,
+				HoldTime: bgpPeer.options.HoldTime,
 			},
 		}
 	}
@@ -540,7 +650,7 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime u
 		n.AfiSafis = append(n.AfiSafis, &bgpAPI.AfiSafi{
 			MpGracefulRestart: &bgpAPI.MpGracefulRestart{
 				Config: &bgpAPI.MpGracefulRestartConfig{
-					Enabled: true,
+					Enabled: gracefulRestart,
 				},
 			},
 			Config: &bgpAPI.AfiSafiConfig{Family: family},
@@ -548,28 +658,205 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime u
 	}
 
 	// Add the peer.
-	if s.bgp != nil {
-		err := s.bgp.AddPeer(context.Background(), &bgpAPI.AddPeerRequest{Peer: n})
+	err := s.bgp.AddPeer(context.Background(), &bgpAPI.AddPeerRequest{Peer: n})
+	if err != nil {
+		return err
+	}
+
+	// Start BFD.
+	if bgpPeer.options.BFDInterval > 0 {
+		address := bgpPeer.address
+
+		session, err := s.bfd.AddSession(address, bgpPeer.options.BFDInterval, bgpPeer.options.BFDMultiplier, func(state bfd.State) {
+			if state == bfd.StateDown {
+				s.bfdDown(address)
+			}
+		})
 		if err != nil {
+			_ = s.bgp.DeletePeer(context.Background(), &bgpAPI.DeletePeerRequest{Address: address.String()})
 			return err
 		}
+
+		bgpPeer.bfd = session
 	}
 
-	// Add the peer to the list.
-	if bgpPeerExists {
-		bgpPeer.count++
-		s.peers[address.String()] = bgpPeer
-	} else {
-		s.peers[address.String()] = peer{
-			address:  address,
-			asn:      asn,
-			password: password,
-			holdtime: holdTime,
-			count:    1,
+	return nil
+}
+
+// bfdDown resets the BGP session with a peer whose BFD session went down.
+func (s *Server) bfdDown(address net.IP) {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Ignore stale notifications from sessions which have been replaced or removed in the meantime.
+	bgpPeer, bgpPeerExists := s.peers[address.String()]
+	if !bgpPeerExists || bgpPeer.bfd == nil || bgpPeer.bfd.State() != bfd.StateDown || s.bgp == nil {
+		return
+	}
+
+	logger.Warn("BFD session down, resetting BGP peer", logger.Ctx{"peer": address.String()})
+
+	bgpPeer.lastError = "BFD session down"
+	bgpPeer.lastErrorTime = time.Now()
+	s.peers[address.String()] = bgpPeer
+
+	err := s.bgp.ResetPeer(context.Background(), &bgpAPI.ResetPeerRequest{Address: address.String(), Communication: "BFD session down"})
+	if err != nil {
+		logger.Warn("Failed resetting BGP peer", logger.Ctx{"peer": address.String(), "err": err})
+	}
+}
+
+// RestartComplete establishes the graceful restart BGP sessions once all prefixes have been restored after a daemon restart.
+// Those peers retain the previously advertised routes until then.
+// Failing peers don't prevent the others from being established, all errors are returned once done.
+func (s *Server) RestartComplete() error {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.restarting {
+		return nil
+	}
+
+	if s.bgp == nil {
+		s.restarting = false
+		return nil
+	}
+
+	errs := []error{}
+	for key, bgpPeer := range s.peers {
+		if !s.held(bgpPeer) {
+			continue
+		}
+
+		err := s.establishPeer(&bgpPeer, true)
+		if err != nil {
+			bgpPeer.lastError = err.Error()
+			bgpPeer.lastErrorTime = time.Now()
+			errs = append(errs, fmt.Errorf("Failed adding BGP peer %q: %w", bgpPeer.address, err))
 		}
+
+		s.peers[key] = bgpPeer
 	}
 
-	return nil
+	s.restarting = false
+
+	err := s.updatePolicies()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("Failed applying BGP policies: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// updatePolicies applies the export and import policies of all peers.
+func (s *Server) updatePolicies() error {
+	if s.bgp == nil {
+		return nil
+	}
+
+	return s.bgp.SetPolicies(context.Background(), s.policies())
+}
+
+// policies returns the export and import policies of all peers.
+func (s *Server) policies() *bgpAPI.SetPoliciesRequest {
+	definedSets := []*bgpAPI.DefinedSet{}
+	exportStatements := []*bgpAPI.Statement{}
+	importStatements := []*bgpAPI.Statement{}
+
+	// Go through the peers in a stable order.
+	for _, key := range slices.Sorted(maps.Keys(s.peers)) {
+		bgpPeer := s.peers[key]
+
+		prefixLen := 128
+		if bgpPeer.address.To4() != nil {
+			prefixLen = 32
+		}
+
+		setName := "peer-" + bgpPeer.address.String()
+		neighborSet := &bgpAPI.MatchSet{Type: bgpAPI.MatchSet_TYPE_ANY, Name: setName}
+
+		// Export policy.
+		actions := &bgpAPI.Actions{}
+		communities := []string{}
+		largeCommunities := []string{}
+		for _, community := range bgpPeer.options.Communities {
+			if strings.Count(community, ":") == 2 {
+				largeCommunities = append(largeCommunities, community)
+			} else {
+				communities = append(communities, community)
+			}
+		}
+
+		if len(communities) > 0 {
+			actions.Community = &bgpAPI.CommunityAction{Type: bgpAPI.CommunityAction_TYPE_ADD, Communities: communities}
+		}
+
+		if len(largeCommunities) > 0 {
+			actions.LargeCommunity = &bgpAPI.CommunityAction{Type: bgpAPI.CommunityAction_TYPE_ADD, Communities: largeCommunities}
+		}
+
+		if bgpPeer.options.MED != nil {
+			actions.Med = &bgpAPI.MedAction{Type: bgpAPI.MedAction_TYPE_REPLACE, Value: int64(*bgpPeer.options.MED)}
+		}
+
+		hasExport := actions.Community != nil || actions.LargeCommunity != nil || actions.Med != nil
+		if hasExport {
+			exportStatements = append(exportStatements, &bgpAPI.Statement{
+				Name:       "export-" + bgpPeer.address.String(),
+				Conditions: &bgpAPI.Conditions{NeighborSet: neighborSet},
+				Actions:    actions,
+			})
+		}
+
+		// Import policy.
+		if bgpPeer.options.RejectImport {
+			importStatements = append(importStatements, &bgpAPI.Statement{
+				Name:       "import-" + bgpPeer.address.String(),
+				Conditions: &bgpAPI.Conditions{NeighborSet: neighborSet},
+				Actions:    &bgpAPI.Actions{RouteAction: bgpAPI.RouteAction_ROUTE_ACTION_REJECT},
+			})
+		}
+
+		if hasExport || bgpPeer.options.RejectImport {
+			definedSets = append(definedSets, &bgpAPI.DefinedSet{
+				DefinedType: bgpAPI.DefinedType_DEFINED_TYPE_NEIGHBOR,
+				Name:        setName,
+				List:        []string{fmt.Sprintf("%s/%d", bgpPeer.address.String(), prefixLen)},
+			})
+		}
+	}
+
+	req := &bgpAPI.SetPoliciesRequest{
+		DefinedSets: definedSets,
+		Policies:    []*bgpAPI.Policy{},
+		Assignments: []*bgpAPI.PolicyAssignment{},
+	}
+
+	for _, policy := range []struct {
+		name       string
+		direction  bgpAPI.PolicyDirection
+		statements []*bgpAPI.Statement
+	}{
+		{"incus-export", bgpAPI.PolicyDirection_POLICY_DIRECTION_EXPORT, exportStatements},
+		{"incus-import", bgpAPI.PolicyDirection_POLICY_DIRECTION_IMPORT, importStatements},
+	} {
+		if len(policy.statements) == 0 {
+			continue
+		}
+
+		p := &bgpAPI.Policy{Name: policy.name, Statements: policy.statements}
+		req.Policies = append(req.Policies, p)
+		req.Assignments = append(req.Assignments, &bgpAPI.PolicyAssignment{
+			Name:          "global",
+			Direction:     policy.direction,
+			Policies:      []*bgpAPI.Policy{p},
+			DefaultAction: bgpAPI.RouteAction_ROUTE_ACTION_ACCEPT,
+		})
+	}
+
+	return req
 }
 
 // RemovePeer removes a prefix from the BGP server.
@@ -578,7 +865,12 @@ func (s *Server) RemovePeer(address net.IP) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.removePeer(address)
+	err := s.removePeer(address)
+	if err != nil {
+		return err
+	}
+
+	return s.updatePolicies()
 }
 
 func (s *Server) removePeer(address net.IP) error {
@@ -588,8 +880,14 @@ func (s *Server) removePeer(address net.IP) error {
 		return ErrPeerNotFound
 	}
 
+	// Stop BFD.
+	if bgpPeer.bfd != nil && bgpPeer.count == 1 {
+		s.bfd.RemoveSession(bgpPeer.bfd)
+		bgpPeer.bfd = nil
+	}
+
 	// Remove the peer from the BGP server.
-	if s.bgp != nil && bgpPeer.count == 1 {
+	if s.bgp != nil && !s.held(bgpPeer) && bgpPeer.count == 1 {
 		err := s.bgp.DeletePeer(context.Background(), &bgpAPI.DeletePeerRequest{Address: address.String()})
 		if err != nil {
 			return err
@@ -0,0 +1,240 @@
+package bgp
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+
+	bgpAPI "github.com/osrg/gobgp/v4/api"
+)
+
+// testServer returns a running server which doesn't listen for incoming connections.
+func testServer(t *testing.T) *Server {
+	t.Helper()
+
+	s := NewServer()
+
+	err := s.Configure("127.0.0.1:-1", 65000, net.ParseIP("192.0.2.254"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = s.Configure("", 0, nil) })
+
+	return s
+}
+
+// testPeers returns the addresses of the peers configured on the GoBGP server.
+func testPeers(t *testing.T, s *Server) []string {
+	t.Helper()
+
+	addresses := []string{}
+	err := s.bgp.ListPeer(context.Background(), &bgpAPI.ListPeerRequest{}, func(p *bgpAPI.Peer) {
+		addresses = append(addresses, p.GetConf().GetNeighborAddress())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slices.Sort(addresses)
+
+	return addresses
+}
+
+func TestServerRestartComplete(t *testing.T) {
+	s := testServer(t)
+
+	graceful := net.ParseIP("192.0.2.1")
+	plain := net.ParseIP("192.0.2.2")
+
+	err := s.AddPeer(graceful, 65001, PeerOptions{GracefulRestartTime: 120})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.AddPeer(plain, 65002, PeerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the graceful restart peer is held back.
+	peers := testPeers(t, s)
+	if !slices.Equal(peers, []string{"192.0.2.2"}) {
+		t.Fatalf("Unexpected peers while restarting: %v", peers)
+	}
+
+	state, err := s.PeerState(graceful)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.State != "restarting" {
+		t.Fatalf("Expected the held back peer to be restarting, got %q", state.State)
+	}
+
+	// Held back peers can be removed before being established.
+	err = s.AddPeer(net.ParseIP("192.0.2.3"), 65003, PeerOptions{GracefulRestartTime: 120})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.RemovePeer(net.ParseIP("192.0.2.3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.RestartComplete()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peers = testPeers(t, s)
+	if !slices.Equal(peers, []string{"192.0.2.1", "192.0.2.2"}) {
+		t.Fatalf("Unexpected peers after restarting: %v", peers)
+	}
+
+	// Later calls are no-ops.
+	err = s.RestartComplete()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Peers are now established right away.
+	err = s.AddPeer(net.ParseIP("192.0.2.4"), 65004, PeerOptions{GracefulRestartTime: 120})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peers = testPeers(t, s)
+	if !slices.Equal(peers, []string{"192.0.2.1", "192.0.2.2", "192.0.2.4"}) {
+		t.Fatalf("Unexpected peers after adding a peer: %v", peers)
+	}
+}
+
+func TestServerRestartCompleteStopped(t *testing.T) {
+	s := NewServer()
+
+	err := s.AddPeer(net.ParseIP("192.0.2.1"), 65001, PeerOptions{GracefulRestartTime: 120})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.RestartComplete()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.restarting {
+		t.Fatal("Server still restarting")
+	}
+}
+
+func TestServerPolicies(t *testing.T) {
+	med := uint32(50)
+
+	s := NewServer()
+	s.peers = map[string]peer{
+		"192.0.2.1":   {address: net.ParseIP("192.0.2.1"), options: PeerOptions{Communities: []string{"65000:100", "65000:1:2"}, MED: &med}},
+		"2001:db8::1": {address: net.ParseIP("2001:db8::1"), options: PeerOptions{RejectImport: true}},
+		"192.0.2.2":   {address: net.ParseIP("192.0.2.2")},
+	}
+
+	req := s.policies()
+
+	// Peers without any policy don't get a neighbor set.
+	sets := map[string][]string{}
+	for _, set := range req.DefinedSets {
+		sets[set.Name] = set.List
+	}
+
+	if len(sets) != 2 || !slices.Equal(sets["peer-192.0.2.1"], []string{"192.0.2.1/32"}) || !slices.Equal(sets["peer-2001:db8::1"], []string{"2001:db8::1/128"}) {
+		t.Fatalf("Unexpected neighbor sets: %v", sets)
+	}
+
+	if len(req.Policies) != 2 || len(req.Assignments) != 2 {
+		t.Fatalf("Expected an export and an import policy, got %d policies and %d assignments", len(req.Policies), len(req.Assignments))
+	}
+
+	// Export policy.
+	export := req.Policies[0]
+	if export.Name != "incus-export" || len(export.Statements) != 1 || req.Assignments[0].Direction != bgpAPI.PolicyDirection_POLICY_DIRECTION_EXPORT {
+		t.Fatalf("Unexpected export policy: %v", export)
+	}
+
+	actions := export.Statements[0].Actions
+	if export.Statements[0].Conditions.NeighborSet.Name != "peer-192.0.2.1" {
+		t.Fatalf("Unexpected export neighbor set: %v", export.Statements[0].Conditions.NeighborSet)
+	}
+
+	if !slices.Equal(actions.Community.Communities, []string{"65000:100"}) || !slices.Equal(actions.LargeCommunity.Communities, []string{"65000:1:2"}) {
+		t.Fatalf("Unexpected communities: %v, %v", actions.Community, actions.LargeCommunity)
+	}
+
+	if actions.Med == nil || actions.Med.Value != 50 || actions.Med.Type != bgpAPI.MedAction_TYPE_REPLACE {
+		t.Fatalf("Unexpected MED action: %v", actions.Med)
+	}
+
+	// Import policy.
+	imp := req.Policies[1]
+	if imp.Name != "incus-import" || len(imp.Statements) != 1 || req.Assignments[1].Direction != bgpAPI.PolicyDirection_POLICY_DIRECTION_IMPORT {
+		t.Fatalf("Unexpected import policy: %v", imp)
+	}
+
+	if imp.Statements[0].Conditions.NeighborSet.Name != "peer-2001:db8::1" || imp.Statements[0].Actions.RouteAction != bgpAPI.RouteAction_ROUTE_ACTION_REJECT {
+		t.Fatalf("Unexpected import statement: %v", imp.Statements[0])
+	}
+
+	// Without any policy, nothing gets assigned.
+	s.peers = map[string]peer{"192.0.2.2": {address: net.ParseIP("192.0.2.2")}}
+	req = s.policies()
+	if len(req.DefinedSets) != 0 || len(req.Policies) != 0 || len(req.Assignments) != 0 {
+		t.Fatalf("Unexpected policies without any peer policy: %v", req)
+	}
+}
+
+func TestServerUpdatePolicies(t *testing.T) {
+	s := testServer(t)
+
+	err := s.RestartComplete()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.AddPeer(net.ParseIP("192.0.2.1"), 65001, PeerOptions{Communities: []string{"65000:100"}, RejectImport: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The policies are applied to the GoBGP server.
+	names := []string{}
+	err = s.bgp.ListPolicy(context.Background(), &bgpAPI.ListPolicyRequest{}, func(p *bgpAPI.Policy) {
+		names = append(names, p.Name)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"incus-export", "incus-import"}) {
+		t.Fatalf("Unexpected policies: %v", names)
+	}
+
+	// And removed along with the peer.
+	err = s.RemovePeer(net.ParseIP("192.0.2.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assignments := 0
+	err = s.bgp.ListPolicyAssignment(context.Background(), &bgpAPI.ListPolicyAssignmentRequest{Name: "global", Direction: bgpAPI.PolicyDirection_POLICY_DIRECTION_EXPORT}, func(a *bgpAPI.PolicyAssignment) {
+		assignments += len(a.Policies)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assignments != 0 {
+		t.Fatalf("Expected no export policy assignment, got %d", assignments)
+	}
+}
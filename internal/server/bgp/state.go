@@ -0,0 +1,175 @@
+package bgp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	bgpAPI "github.com/osrg/gobgp/v4/api"
+
+	"github.com/lxc/incus/v7/internal/server/metrics"
+)
+
+// PeerState represents the live state of a BGP peer.
+type PeerState struct {
+	Address net.IP
+	ASN     uint32
+
+	// Session state (as reported by GoBGP, "stopped" when the server isn't running, "restarting" while held back until RestartComplete).
+	State  string
+	Uptime time.Duration
+
+	// Prefix counters (all address families combined).
+	ReceivedPrefixes   uint64
+	AcceptedPrefixes   uint64
+	AdvertisedPrefixes uint64
+
+	// BFD session state (empty when BFD isn't in use).
+	BFDState string
+
+	LastError     string
+	LastErrorTime time.Time
+}
+
+// Prefix represents a prefix advertised by the BGP server.
+type Prefix struct {
+	Owner   string
+	Prefix  net.IPNet
+	Nexthop net.IP
+}
+
+// PeerState returns the live state of a peer.
+func (s *Server) PeerState(address net.IP) (*PeerState, error) {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bgpPeer, bgpPeerExists := s.peers[address.String()]
+	if !bgpPeerExists {
+		return nil, ErrPeerNotFound
+	}
+
+	return s.peerState(bgpPeer)
+}
+
+// PeersState returns the live state of all peers.
+func (s *Server) PeersState() ([]PeerState, error) {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make([]PeerState, 0, len(s.peers))
+	for _, bgpPeer := range s.peers {
+		state, err := s.peerState(bgpPeer)
+		if err != nil {
+			return nil, err
+		}
+
+		states = append(states, *state)
+	}
+
+	return states, nil
+}
+
+func (s *Server) peerState(bgpPeer peer) (*PeerState, error) {
+	state := &PeerState{
+		Address:       bgpPeer.address,
+		ASN:           bgpPeer.asn,
+		LastError:     bgpPeer.lastError,
+		LastErrorTime: bgpPeer.lastErrorTime,
+	}
+
+	if bgpPeer.bfd != nil {
+		state.BFDState = bgpPeer.bfd.State().String()
+	}
+
+	if s.bgp == nil {
+		state.State = "stopped"
+		return state, nil
+	}
+
+	if s.held(bgpPeer) {
+		state.State = "restarting"
+		return state, nil
+	}
+
+	err := s.bgp.ListPeer(context.Background(), &bgpAPI.ListPeerRequest{Address: bgpPeer.address.String(), EnableAdvertised: true}, func(p *bgpAPI.Peer) {
+		// Turn the protobuf enum name (e.g. SESSION_STATE_ESTABLISHED) into a plain state name.
+		sessionState := p.GetState().GetSessionState().String()
+		state.State = strings.ToLower(strings.TrimPrefix(sessionState, "SESSION_STATE_"))
+
+		uptime := p.GetTimers().GetState().GetUptime()
+		if state.State == "established" && uptime != nil {
+			state.Uptime = time.Since(uptime.AsTime()).Truncate(time.Second)
+		}
+
+		for _, afiSafi := range p.GetAfiSafis() {
+			state.ReceivedPrefixes += afiSafi.GetState().GetReceived()
+			state.AcceptedPrefixes += afiSafi.GetState().GetAccepted()
+			state.AdvertisedPrefixes += afiSafi.GetState().GetAdvertised()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Prefixes returns the prefixes advertised on behalf of the given owners.
+func (s *Server) Prefixes(owners ...string) []Prefix {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefixes := []Prefix{}
+	for _, path := range s.paths {
+		for _, owner := range owners {
+			if path.owner != owner {
+				continue
+			}
+
+			prefixes = append(prefixes, Prefix{Owner: path.owner, Prefix: path.prefix, Nexthop: path.nexthop})
+			break
+		}
+	}
+
+	return prefixes
+}
+
+// Metrics returns the metrics of the BGP peers.
+func (s *Server) Metrics() *metrics.MetricSet {
+	out := metrics.NewMetricSet(nil)
+
+	states, err := s.PeersState()
+	if err != nil {
+		return out
+	}
+
+	for _, state := range states {
+		labels := map[string]string{"address": state.Address.String(), "asn": fmt.Sprintf("%d", state.ASN)}
+
+		up := 0.0
+		if state.State == "established" {
+			up = 1.0
+		}
+
+		out.AddSamples(metrics.BGPPeerUp, metrics.Sample{Labels: labels, Value: up})
+		out.AddSamples(metrics.BGPPeerUptimeSeconds, metrics.Sample{Labels: labels, Value: state.Uptime.Seconds()})
+		out.AddSamples(metrics.BGPPeerReceivedPrefixes, metrics.Sample{Labels: labels, Value: float64(state.ReceivedPrefixes)})
+		out.AddSamples(metrics.BGPPeerAdvertisedPrefixes, metrics.Sample{Labels: labels, Value: float64(state.AdvertisedPrefixes)})
+
+		if state.BFDState != "" {
+			bfdUp := 0.0
+			if state.BFDState == "up" {
+				bfdUp = 1.0
+			}
+
+			out.AddSamples(metrics.BGPPeerBFDUp, metrics.Sample{Labels: labels, Value: bfdUp})
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,172 @@
+package bgp
+
+import (
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/server/metrics"
+)
+
+func TestServerPeerState(t *testing.T) {
+	s := NewServer()
+	address := net.ParseIP("192.0.2.1")
+
+	_, err := s.PeerState(address)
+	if !errors.Is(err, ErrPeerNotFound) {
+		t.Fatalf("Expected ErrPeerNotFound, got: %v", err)
+	}
+
+	err = s.AddPeer(address, 65001, PeerOptions{GracefulRestartTime: 120})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Peers of a server which isn't running are stopped.
+	state, err := s.PeerState(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.State != "stopped" || state.ASN != 65001 || !state.Address.Equal(address) {
+		t.Fatalf("Unexpected state: %+v", state)
+	}
+
+	// Errors are reported along with the state.
+	errorTime := time.Now()
+	bgpPeer := s.peers[address.String()]
+	bgpPeer.lastError = "BFD session down"
+	bgpPeer.lastErrorTime = errorTime
+	s.peers[address.String()] = bgpPeer
+
+	state, err = s.PeerState(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.LastError != "BFD session down" || !state.LastErrorTime.Equal(errorTime) {
+		t.Fatalf("Unexpected error state: %+v", state)
+	}
+}
+
+func TestServerPeersState(t *testing.T) {
+	s := testServer(t)
+
+	err := s.AddPeer(net.ParseIP("192.0.2.1"), 65001, PeerOptions{GracefulRestartTime: 120})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.AddPeer(net.ParseIP("192.0.2.2"), 65002, PeerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	states, err := s.PeersState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(states) != 2 {
+		t.Fatalf("Expected 2 peer states, got %d", len(states))
+	}
+
+	for _, state := range states {
+		switch state.Address.String() {
+		case "192.0.2.1":
+			if state.State != "restarting" {
+				t.Fatalf("Expected the graceful restart peer to be restarting, got %q", state.State)
+			}
+
+		case "192.0.2.2":
+			// The peer can't be reached so the session is never established.
+			if state.State == "" || state.State == "restarting" || state.State == "established" || state.Uptime != 0 {
+				t.Fatalf("Unexpected state for the unreachable peer: %+v", state)
+			}
+
+		default:
+			t.Fatalf("Unexpected peer %q", state.Address)
+		}
+	}
+}
+
+func TestServerPrefixes(t *testing.T) {
+	s := NewServer()
+
+	_, subnetA, _ := net.ParseCIDR("198.51.100.0/24")
+	_, subnetB, _ := net.ParseCIDR("2001:db8:1::/64")
+	_, subnetC, _ := net.ParseCIDR("203.0.113.0/24")
+
+	for _, prefix := range []Prefix{
+		{Owner: "network_1", Prefix: *subnetA, Nexthop: net.ParseIP("192.0.2.10")},
+		{Owner: "network_1", Prefix: *subnetB, Nexthop: net.ParseIP("2001:db8::10")},
+		{Owner: "network_2", Prefix: *subnetC, Nexthop: net.ParseIP("192.0.2.20")},
+	} {
+		err := s.AddPrefix(prefix.Prefix, prefix.Nexthop, prefix.Owner)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prefixList := func(prefixes []Prefix) []string {
+		out := []string{}
+		for _, prefix := range prefixes {
+			out = append(out, prefix.Owner+" "+prefix.Prefix.String()+" via "+prefix.Nexthop.String())
+		}
+
+		slices.Sort(out)
+
+		return out
+	}
+
+	got := prefixList(s.Prefixes("network_1"))
+	want := []string{"network_1 198.51.100.0/24 via 192.0.2.10", "network_1 2001:db8:1::/64 via 2001:db8::10"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Unexpected prefixes: %v", got)
+	}
+
+	if len(s.Prefixes("network_1", "network_2")) != 3 {
+		t.Fatal("Expected the prefixes of both owners")
+	}
+
+	if len(s.Prefixes("network_3")) != 0 || len(s.Prefixes()) != 0 {
+		t.Fatal("Unexpected prefixes for unknown owners")
+	}
+}
+
+func TestServerMetrics(t *testing.T) {
+	s := NewServer()
+
+	err := s.AddPeer(net.ParseIP("192.0.2.1"), 65001, PeerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := s.Metrics().String()
+
+	for _, line := range []string{
+		`incus_bgp_peer_up{address="192.0.2.1",asn="65001"} 0`,
+		`incus_bgp_peer_uptime_seconds{address="192.0.2.1",asn="65001"} 0`,
+		`incus_bgp_peer_received_prefixes{address="192.0.2.1",asn="65001"} 0`,
+		`incus_bgp_peer_advertised_prefixes{address="192.0.2.1",asn="65001"} 0`,
+		"# TYPE incus_bgp_peer_up gauge",
+		"# TYPE incus_bgp_peer_uptime_seconds gauge",
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Fatalf("Missing %q in metrics:\n%s", line, out)
+		}
+	}
+
+	// BFD metrics are only reported for peers using BFD.
+	if strings.Contains(out, metrics.MetricNames[metrics.BGPPeerBFDUp]) {
+		t.Fatalf("Unexpected BFD metrics:\n%s", out)
+	}
+
+	// Without peers, no metrics are reported.
+	if strings.Contains(NewServer().Metrics().String(), "incus_bgp_peer") {
+		t.Fatal("Unexpected metrics without peers")
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/config"
 	"github.com/lxc/incus/v7/internal/server/db"
 	scriptletLoad "github.com/lxc/incus/v7/internal/server/scriptlet/load"
+	"github.com/lxc/incus/v7/shared/util"
 	"github.com/lxc/incus/v7/shared/validate"
 )
 
@@ -220,6 +221,11 @@ func (c *Config) InstancesPlacementScriptlet() string {
 	return c.m.GetString("instances.placement.scriptlet")
 }
 
+// InstancesValidationScriptlet returns the instances validation scriptlet source code.
+func (c *Config) InstancesValidationScriptlet() string {
+	return c.m.GetString("instances.validation.scriptlet")
+}
+
 // AuthorizationScriptlet returns the authorization scriptlet source code.
 func (c *Config) AuthorizationScriptlet() string {
 	return c.m.GetString("authorization.scriptlet")
@@ -304,6 +310,16 @@ func (c *Config) OIDCServer() (string, string, string, string, string) {
 	return c.m.GetString("oidc.issuer"), c.m.GetString("oidc.client.id"), c.m.GetString("oidc.scopes"), c.m.GetString("oidc.audience"), c.m.GetString("oidc.claim")
 }
 
+// ClusterHealingFencing returns the fencing methods to use before healing an offline cluster member.
+func (c *Config) ClusterHealingFencing() []string {
+	return util.SplitNTrimSpace(c.m.GetString("cluster.healing.fencing"), ",", -1, true)
+}
+
+// ClusterHealingFencingCommand returns the command used to fence an offline cluster member.
+func (c *Config) ClusterHealingFencingCommand() string {
+	return c.m.GetString("cluster.healing.fencing.command")
+}
+
 // ClusterHealingThreshold returns the configured healing threshold, i.e. the
 // number of seconds after which an offline node will be evacuated automatically. If the config key
 // is set but its value is lower than cluster.offline_threshold it returns
@@ -424,6 +440,29 @@ func (c *Config) LoggingConfigForWebhook(loggerName string) (string, string, str
 	return c.m.GetString(addressKey), c.m.GetString(usernameKey), c.m.GetString(passwordKey), c.m.GetString(caCertKey), int(c.m.GetInt64(retryKey))
 }
 
+// LoggingConfigForOTLP returns all the OTLP settings needed to connect to a collector.
+func (c *Config) LoggingConfigForOTLP(loggerName string) (string, string, string, string, string, string, int) {
+	prefix := fmt.Sprintf("logging.%s", loggerName)
+	addressKey := fmt.Sprintf("%s.%s", prefix, "target.address")
+	usernameKey := fmt.Sprintf("%s.%s", prefix, "target.username")
+	passwordKey := fmt.Sprintf("%s.%s", prefix, "target.password")
+	caCertKey := fmt.Sprintf("%s.%s", prefix, "target.ca_cert")
+	instanceKey := fmt.Sprintf("%s.%s", prefix, "target.instance")
+	protocolKey := fmt.Sprintf("%s.%s", prefix, "target.protocol")
+	retryKey := fmt.Sprintf("%s.%s", prefix, "target.retry")
+
+	return c.m.GetString(addressKey), c.m.GetString(usernameKey), c.m.GetString(passwordKey), c.m.GetString(caCertKey), c.m.GetString(instanceKey), c.m.GetString(protocolKey), int(c.m.GetInt64(retryKey))
+}
+
+// LoggingConfigForSpool returns the on-disk spool settings of a logger.
+func (c *Config) LoggingConfigForSpool(loggerName string) (string, string) {
+	prefix := fmt.Sprintf("logging.%s", loggerName)
+	sizeKey := fmt.Sprintf("%s.%s", prefix, "spool.size")
+	maxAgeKey := fmt.Sprintf("%s.%s", prefix, "spool.max_age")
+
+	return c.m.GetString(sizeKey), c.m.GetString(maxAgeKey)
+}
+
 // Dump current configuration keys and their values. Keys with values matching
 // their defaults are omitted.
 func (c *Config) Dump() map[string]string {
@@ -588,6 +627,25 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Threshold when to evacuate an offline cluster member
 	"cluster.healing_threshold": {Type: config.Int64, Default: "0"},
 
+	// gendoc:generate(entity=server, group=cluster, key=cluster.healing.fencing)
+	// Comma-separated list of fencing methods that must all succeed before an offline cluster member is evacuated.
+	// Possible values are `command`, `storage` and `self`.
+	// See {ref}`cluster-healing-fencing` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Fencing methods to use before healing
+	"cluster.healing.fencing": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("command", "storage", "self")))},
+
+	// gendoc:generate(entity=server, group=cluster, key=cluster.healing.fencing.command)
+	// Path to the command run by the cluster leader to fence an offline cluster member when using the `command` fencing method.
+	// The command is passed the name and address of the cluster member and must exit successfully once the member is fenced.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Command used to fence an offline cluster member
+	"cluster.healing.fencing.command": {},
+
 	// gendoc:generate(entity=server, group=cluster, key=cluster.join_token_expiry)
 	//
 	// ---
@@ -856,6 +914,15 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Instance placement scriptlet for automatic instance placement
 	"instances.placement.scriptlet": {Validator: validate.Optional(scriptletLoad.InstancePlacementValidate)},
 
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.validation.scriptlet)
+	// When using custom instance validation logic, this option stores the scriptlet.
+	// See {ref}`instance-validation-scriptlet` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Instance validation scriptlet for instance and profile changes
+	"instances.validation.scriptlet": {Validator: validate.Optional(scriptletLoad.InstanceValidationValidate)},
+
 	// gendoc:generate(entity=server, group=loki, key=loki.auth.username)
 	//
 	// ---
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -63,7 +64,7 @@ func GetLoggingRuleForKey(key string) (Key, error) {
 		//  type: string
 		//  scope: global
 		//  defaultdesc: Local server host name or cluster member name
-		//  shortdesc: Name to use as the instance field in Loki events.
+		//  shortdesc: Name to use as the instance field in Loki events or as `service.instance.id` in OTLP records.
 		return Key{}, nil
 	case "target.labels":
 		// gendoc:generate(entity=server, group=logging, key=logging.NAME.target.labels)
@@ -81,14 +82,23 @@ func GetLoggingRuleForKey(key string) (Key, error) {
 		//  scope: global
 		//  shortdesc: The syslog facility defines the category of the log message
 		return Key{Default: "daemon"}, nil
+	case "target.protocol":
+		// gendoc:generate(entity=server, group=logging, key=logging.NAME.target.protocol)
+		// Specify whether to use OTLP over HTTP (`http`, protobuf encoded) or over gRPC (`grpc`).
+		// ---
+		//  type: string
+		//  scope: global
+		//  defaultdesc: `http`
+		//  shortdesc: Protocol used to send OTLP records
+		return Key{Validator: validate.Optional(validate.IsOneOf("http", "grpc")), Default: "http"}, nil
 	case "target.type":
 		// gendoc:generate(entity=server, group=logging, key=logging.NAME.target.type)
 		//
 		// ---
 		//  type: string
 		//  scope: global
-		//  shortdesc: The type of the logger. One of `loki`, `syslog` or `webhook`.
-		return Key{Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("syslog", "loki", "webhook")))}, nil
+		//  shortdesc: The type of the logger. One of `loki`, `otlp`, `syslog` or `webhook`.
+		return Key{Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("syslog", "loki", "otlp", "webhook")))}, nil
 	case "target.retry":
 		// gendoc:generate(entity=server, group=logging, key=logging.NAME.target.retry)
 		//
@@ -97,6 +107,25 @@ func GetLoggingRuleForKey(key string) (Key, error) {
 		//  scope: global
 		//  shortdesc: number of delivery retries, default 3
 		return Key{Validator: validate.Optional(), Default: "3"}, nil
+	case "spool.size":
+		// gendoc:generate(entity=server, group=logging, key=logging.NAME.spool.size)
+		// When set, events are queued on disk and replayed in order once the target is reachable again.
+		// The oldest events are dropped when the spool is full.
+		// Only supported by the `loki` and `webhook` targets.
+		// ---
+		//  type: string
+		//  scope: global
+		//  shortdesc: Maximum size of the on-disk event spool
+		return Key{Validator: validate.Optional(validate.IsSize)}, nil
+	case "spool.max_age":
+		// gendoc:generate(entity=server, group=logging, key=logging.NAME.spool.max_age)
+		// Events which have been in the spool for longer than this are dropped instead of being delivered.
+		// ---
+		//  type: string
+		//  scope: global
+		//  defaultdesc: `24h`
+		//  shortdesc: Maximum age of the events in the on-disk spool
+		return Key{Validator: validate.Optional(validate.IsMinimumDuration(time.Minute)), Default: "24h"}, nil
 	case "types":
 		// gendoc:generate(entity=server, group=logging, key=logging.NAME.types)
 		// Specify a comma-separated list of events to send to the logger.
@@ -26,6 +26,7 @@ type InstanceBackup struct {
 	RootOnly             bool
 	OptimizedStorage     bool
 	CompressionAlgorithm string
+	Since                string
 }
 
 // StoragePoolVolumeBackup is a value object holding all db-related details about a storage volume backup.
@@ -38,6 +39,7 @@ type StoragePoolVolumeBackup struct {
 	VolumeOnly           bool
 	OptimizedStorage     bool
 	CompressionAlgorithm string
+	Since                string
 }
 
 // StoragePoolBucketBackup is a value object holding all db-related details about a storage bucket backup.
@@ -32,8 +32,10 @@ import (
 // NetworkLoadBalancer is the generated entity backing the networks_load_balancers table.
 type NetworkLoadBalancer struct {
 	ID            int64
-	NetworkID     int64  `db:"primary=yes&column=network_id"`
-	ListenAddress string `db:"primary=yes"`
+	NetworkID     int64         `db:"primary=yes&column=network_id"`
+	NodeID        sql.NullInt64 `db:"column=node_id&nullable=true"`
+	Location      *string       `db:"leftjoin=nodes.name&omit=create,update"`
+	ListenAddress string        `db:"primary=yes"`
 	Description   string
 	Backends      []api.NetworkLoadBalancerBackend `db:"marshal=json"`
 	Ports         []api.NetworkLoadBalancerPort    `db:"marshal=json"`
@@ -43,6 +45,7 @@ type NetworkLoadBalancer struct {
 type NetworkLoadBalancerFilter struct {
 	ID            *int64
 	NetworkID     *int64
+	NodeID        *int64
 	ListenAddress *string
 }
 
@@ -65,5 +68,9 @@ func (n *NetworkLoadBalancer) ToAPI(ctx context.Context, tx *sql.Tx) (*api.Netwo
 		ListenAddress: n.ListenAddress,
 	}
 
+	if n.Location != nil {
+		out.Location = *n.Location
+	}
+
 	return &out, nil
 }
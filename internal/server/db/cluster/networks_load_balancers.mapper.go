@@ -13,21 +13,24 @@ import (
 )
 
 var networkLoadBalancerObjects = RegisterStmt(`
-SELECT networks_load_balancers.id, networks_load_balancers.network_id, networks_load_balancers.listen_address, networks_load_balancers.description, networks_load_balancers.backends, networks_load_balancers.ports
+SELECT networks_load_balancers.id, networks_load_balancers.network_id, networks_load_balancers.node_id, nodes.name AS location, networks_load_balancers.listen_address, networks_load_balancers.description, networks_load_balancers.backends, networks_load_balancers.ports
   FROM networks_load_balancers
+  LEFT JOIN nodes ON networks_load_balancers.node_id = nodes.id
   ORDER BY networks_load_balancers.network_id, networks_load_balancers.listen_address
 `)
 
 var networkLoadBalancerObjectsByNetworkID = RegisterStmt(`
-SELECT networks_load_balancers.id, networks_load_balancers.network_id, networks_load_balancers.listen_address, networks_load_balancers.description, networks_load_balancers.backends, networks_load_balancers.ports
+SELECT networks_load_balancers.id, networks_load_balancers.network_id, networks_load_balancers.node_id, nodes.name AS location, networks_load_balancers.listen_address, networks_load_balancers.description, networks_load_balancers.backends, networks_load_balancers.ports
   FROM networks_load_balancers
+  LEFT JOIN nodes ON networks_load_balancers.node_id = nodes.id
   WHERE ( networks_load_balancers.network_id = ? )
   ORDER BY networks_load_balancers.network_id, networks_load_balancers.listen_address
 `)
 
 var networkLoadBalancerObjectsByNetworkIDAndListenAddress = RegisterStmt(`
-SELECT networks_load_balancers.id, networks_load_balancers.network_id, networks_load_balancers.listen_address, networks_load_balancers.description, networks_load_balancers.backends, networks_load_balancers.ports
+SELECT networks_load_balancers.id, networks_load_balancers.network_id, networks_load_balancers.node_id, nodes.name AS location, networks_load_balancers.listen_address, networks_load_balancers.description, networks_load_balancers.backends, networks_load_balancers.ports
   FROM networks_load_balancers
+  LEFT JOIN nodes ON networks_load_balancers.node_id = nodes.id
   WHERE ( networks_load_balancers.network_id = ? AND networks_load_balancers.listen_address = ? )
   ORDER BY networks_load_balancers.network_id, networks_load_balancers.listen_address
 `)
@@ -38,13 +41,13 @@ SELECT networks_load_balancers.id FROM networks_load_balancers
 `)
 
 var networkLoadBalancerCreate = RegisterStmt(`
-INSERT INTO networks_load_balancers (network_id, listen_address, description, backends, ports)
-  VALUES (?, ?, ?, ?, ?)
+INSERT INTO networks_load_balancers (network_id, node_id, listen_address, description, backends, ports)
+  VALUES (?, ?, ?, ?, ?, ?)
 `)
 
 var networkLoadBalancerUpdate = RegisterStmt(`
 UPDATE networks_load_balancers
-  SET network_id = ?, listen_address = ?, description = ?, backends = ?, ports = ?
+  SET network_id = ?, node_id = ?, listen_address = ?, description = ?, backends = ?, ports = ?
  WHERE id = ?
 `)
 
@@ -55,7 +58,7 @@ DELETE FROM networks_load_balancers WHERE network_id = ? AND id = ?
 // networkLoadBalancerColumns returns a string of column names to be used with a SELECT statement for the entity.
 // Use this function when building statements to retrieve database entries matching the NetworkLoadBalancer entity.
 func networkLoadBalancerColumns() string {
-	return "networks_load_balancers.id, networks_load_balancers.network_id, networks_load_balancers.listen_address, networks_load_balancers.description, networks_load_balancers.backends, networks_load_balancers.ports"
+	return "networks_load_balancers.id, networks_load_balancers.network_id, networks_load_balancers.node_id, nodes.name AS location, networks_load_balancers.listen_address, networks_load_balancers.description, networks_load_balancers.backends, networks_load_balancers.ports"
 }
 
 // getNetworkLoadBalancers can be used to run handwritten sql.Stmts to return a slice of objects.
@@ -66,7 +69,7 @@ func getNetworkLoadBalancers(ctx context.Context, stmt *sql.Stmt, args ...any) (
 		n := NetworkLoadBalancer{}
 		var backendsStr string
 		var portsStr string
-		err := scan(&n.ID, &n.NetworkID, &n.ListenAddress, &n.Description, &backendsStr, &portsStr)
+		err := scan(&n.ID, &n.NetworkID, &n.NodeID, &n.Location, &n.ListenAddress, &n.Description, &backendsStr, &portsStr)
 		if err != nil {
 			return err
 		}
@@ -102,7 +105,7 @@ func getNetworkLoadBalancersRaw(ctx context.Context, db dbtx, sql string, args .
 		n := NetworkLoadBalancer{}
 		var backendsStr string
 		var portsStr string
-		err := scan(&n.ID, &n.NetworkID, &n.ListenAddress, &n.Description, &backendsStr, &portsStr)
+		err := scan(&n.ID, &n.NetworkID, &n.NodeID, &n.Location, &n.ListenAddress, &n.Description, &backendsStr, &portsStr)
 		if err != nil {
 			return err
 		}
@@ -155,7 +158,7 @@ func GetNetworkLoadBalancers(ctx context.Context, db dbtx, filters ...NetworkLoa
 	}
 
 	for i, filter := range filters {
-		if filter.NetworkID != nil && filter.ListenAddress != nil && filter.ID == nil {
+		if filter.NetworkID != nil && filter.ListenAddress != nil && filter.ID == nil && filter.NodeID == nil {
 			args = append(args, []any{filter.NetworkID, filter.ListenAddress}...)
 			if len(filters) == 1 {
 				sqlStmt, err = Stmt(db, networkLoadBalancerObjectsByNetworkIDAndListenAddress)
@@ -179,7 +182,7 @@ func GetNetworkLoadBalancers(ctx context.Context, db dbtx, filters ...NetworkLoa
 
 			_, where, _ := strings.Cut(parts[0], "WHERE")
 			queryParts[0] += "OR" + where
-		} else if filter.NetworkID != nil && filter.ID == nil && filter.ListenAddress == nil {
+		} else if filter.NetworkID != nil && filter.ID == nil && filter.NodeID == nil && filter.ListenAddress == nil {
 			args = append(args, []any{filter.NetworkID}...)
 			if len(filters) == 1 {
 				sqlStmt, err = Stmt(db, networkLoadBalancerObjectsByNetworkID)
@@ -203,7 +206,7 @@ func GetNetworkLoadBalancers(ctx context.Context, db dbtx, filters ...NetworkLoa
 
 			_, where, _ := strings.Cut(parts[0], "WHERE")
 			queryParts[0] += "OR" + where
-		} else if filter.ID == nil && filter.NetworkID == nil && filter.ListenAddress == nil {
+		} else if filter.ID == nil && filter.NetworkID == nil && filter.NodeID == nil && filter.ListenAddress == nil {
 			return nil, fmt.Errorf("Cannot filter on empty NetworkLoadBalancerFilter")
 		} else {
 			return nil, errors.New("No statement exists for the given Filter")
@@ -304,24 +307,25 @@ func CreateNetworkLoadBalancer(ctx context.Context, db dbtx, object NetworkLoadB
 		_err = mapErr(_err, "Network_load_balancer")
 	}()
 
-	args := make([]any, 5)
+	args := make([]any, 6)
 
 	// Populate the statement arguments.
 	args[0] = object.NetworkID
-	args[1] = object.ListenAddress
-	args[2] = object.Description
+	args[1] = object.NodeID
+	args[2] = object.ListenAddress
+	args[3] = object.Description
 	marshaledBackends, err := marshalJSON(object.Backends)
 	if err != nil {
 		return -1, err
 	}
 
-	args[3] = marshaledBackends
+	args[4] = marshaledBackends
 	marshaledPorts, err := marshalJSON(object.Ports)
 	if err != nil {
 		return -1, err
 	}
 
-	args[4] = marshaledPorts
+	args[5] = marshaledPorts
 
 	// Prepared statement to use.
 	stmt, err := Stmt(db, networkLoadBalancerCreate)
@@ -399,7 +403,7 @@ func UpdateNetworkLoadBalancer(ctx context.Context, db tx, networkID int64, list
 		return err
 	}
 
-	result, err := stmt.Exec(object.NetworkID, object.ListenAddress, object.Description, marshaledBackends, marshaledPorts, id)
+	result, err := stmt.Exec(object.NetworkID, object.NodeID, object.ListenAddress, object.Description, marshaledBackends, marshaledPorts, id)
 	if err != nil {
 		return fmt.Errorf("Update \"networks_load_balancers\" entry failed: %w", err)
 	}
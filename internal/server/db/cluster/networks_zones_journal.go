@@ -0,0 +1,81 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lxc/incus/v7/internal/server/db/query"
+)
+
+// NetworkZoneJournalEntry represents a version of the content of a network zone.
+type NetworkZoneJournalEntry struct {
+	Serial uint32
+
+	// Hash of the zone content, excluding the serial.
+	Hash string
+
+	// Records removed and added compared to the previous version, in zone file format.
+	// Those are nil when the difference isn't known.
+	Removed *string
+	Added   *string
+}
+
+// GetNetworkZoneJournal returns the journal of the network zone, ordered by serial.
+func GetNetworkZoneJournal(ctx context.Context, tx *sql.Tx, networkZoneID int64) ([]NetworkZoneJournalEntry, error) {
+	stmt := `
+SELECT serial, hash, removed, added
+  FROM networks_zones_journal
+ WHERE network_zone_id=?
+ ORDER BY serial
+`
+
+	entries := []NetworkZoneJournalEntry{}
+	err := query.Scan(ctx, tx, stmt, func(scan func(dest ...any) error) error {
+		entry := NetworkZoneJournalEntry{}
+
+		err := scan(&entry.Serial, &entry.Hash, &entry.Removed, &entry.Added)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, entry)
+
+		return nil
+	}, networkZoneID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching network zone journal: %w", err)
+	}
+
+	return entries, nil
+}
+
+// CreateNetworkZoneJournalEntry adds a new version to the journal of the network zone.
+// Only the most recent keep entries are kept.
+func CreateNetworkZoneJournalEntry(ctx context.Context, tx *sql.Tx, networkZoneID int64, entry NetworkZoneJournalEntry, keep int) error {
+	stmt := `
+INSERT INTO networks_zones_journal (network_zone_id, serial, hash, removed, added)
+VALUES (?, ?, ?, ?, ?)
+`
+
+	_, err := tx.ExecContext(ctx, stmt, networkZoneID, entry.Serial, entry.Hash, entry.Removed, entry.Added)
+	if err != nil {
+		return fmt.Errorf("Failed adding network zone journal entry: %w", err)
+	}
+
+	stmt = `
+DELETE FROM networks_zones_journal
+ WHERE network_zone_id=? AND serial NOT IN (
+   SELECT serial FROM networks_zones_journal WHERE network_zone_id=? ORDER BY serial DESC LIMIT ?
+ )
+`
+
+	_, err = tx.ExecContext(ctx, stmt, networkZoneID, networkZoneID, keep)
+	if err != nil {
+		return fmt.Errorf("Failed expiring network zone journal entries: %w", err)
+	}
+
+	return nil
+}
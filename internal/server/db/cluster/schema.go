@@ -370,6 +370,16 @@ CREATE TABLE "networks_zones_config" (
     UNIQUE (network_zone_id, key),
     FOREIGN KEY (network_zone_id) REFERENCES "networks_zones" (id) ON DELETE CASCADE
 );
+CREATE TABLE "networks_zones_journal" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_zone_id INTEGER NOT NULL,
+    serial INTEGER NOT NULL,
+    hash TEXT NOT NULL,
+    removed TEXT,
+    added TEXT,
+    UNIQUE (network_zone_id, serial),
+    FOREIGN KEY (network_zone_id) REFERENCES "networks_zones" (id) ON DELETE CASCADE
+);
 CREATE TABLE "networks_zones_records" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     network_zone_id INTEGER NOT NULL,
@@ -675,5 +685,5 @@ CREATE TABLE "warnings" (
 );
 CREATE UNIQUE INDEX warnings_unique_node_id_project_id_entity_type_code_entity_id_type_code ON warnings(IFNULL(node_id, -1), IFNULL(project_id, -1), entity_type_code, entity_id, type_code);
 
-INSERT INTO schema (version, updated_at) VALUES (77, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (78, strftime("%s"))
 `
@@ -114,6 +114,29 @@ var updates = map[int]schema.Update{
 	75: updateFromV74,
 	76: updateFromV75,
 	77: updateFromV76,
+	78: updateFromV77,
+}
+
+// updateFromV77 adds a journal of the changes made to network zones.
+func updateFromV77(ctx context.Context, tx *sql.Tx) error {
+	q := `
+CREATE TABLE "networks_zones_journal" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_zone_id INTEGER NOT NULL,
+    serial INTEGER NOT NULL,
+    hash TEXT NOT NULL,
+    removed TEXT,
+    added TEXT,
+    UNIQUE (network_zone_id, serial),
+    FOREIGN KEY (network_zone_id) REFERENCES "networks_zones" (id) ON DELETE CASCADE
+);
+`
+	_, err := tx.Exec(q)
+	if err != nil {
+		return fmt.Errorf("Failed adding network zone journal table: %w", err)
+	}
+
+	return nil
 }
 
 func updateFromV76(ctx context.Context, tx *sql.Tx) error {
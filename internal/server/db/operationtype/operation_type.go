@@ -79,6 +79,8 @@ const (
 	BucketBackupRemove
 	BucketBackupRename
 	BucketBackupRestore
+	CustomVolumeReplicate
+	ClusterMemberMaintenance
 )
 
 // Description return a human-readable description of the operation type.
@@ -188,6 +190,8 @@ func (t Type) Description() string {
 		return "Renaming custom volume backup"
 	case CustomVolumeBackupRestore:
 		return "Restoring custom volume backup"
+	case CustomVolumeReplicate:
+		return "Replicating custom volumes"
 	case WarningsPruneResolved:
 		return "Pruning resolved warnings"
 	case ClusterMemberEvacuate:
@@ -202,6 +206,8 @@ func (t Type) Description() string {
 		return "Remove expired tokens"
 	case ClusterHeal:
 		return "Healing cluster"
+	case ClusterMemberMaintenance:
+		return "Running cluster member maintenance"
 	case BucketBackupCreate:
 		return "Creating bucket backup"
 	case BucketBackupRemove:
@@ -291,6 +297,8 @@ func (t Type) Permission() (auth.ObjectType, auth.Entitlement) {
 		return auth.ObjectTypeStorageVolume, auth.EntitlementCanManageBackups
 	case CustomVolumeBackupRestore:
 		return auth.ObjectTypeStorageVolume, auth.EntitlementCanEdit
+	case CustomVolumeReplicate:
+		return auth.ObjectTypeStorageVolume, auth.EntitlementCanEdit
 
 	case BucketBackupCreate:
 		return auth.ObjectTypeStorageVolume, auth.EntitlementCanManageBackups
@@ -886,6 +886,7 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 
 		if brNetfilterEnabled {
 			var listenAddresses map[int64]string
+			var hasLoadBalancers bool
 
 			err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 				networkID := d.network.ID()
@@ -903,17 +904,30 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 					}
 				}
 
+				loadBalancers, err := cluster.GetNetworkLoadBalancers(ctx, tx.Tx(), cluster.NetworkLoadBalancerFilter{
+					NetworkID: &networkID,
+				})
+				if err != nil {
+					return err
+				}
+
+				for _, loadBalancer := range loadBalancers {
+					if !loadBalancer.NodeID.Valid || (loadBalancer.NodeID.Int64 == tx.GetNodeID()) {
+						hasLoadBalancers = true
+					}
+				}
+
 				return nil
 			})
 			if err != nil {
 				return nil, fmt.Errorf("Failed loading network forwards: %w", err)
 			}
 
-			// If br_netfilter is enabled and bridge has forwards, we enable hairpin mode on NIC's
-			// bridge port in case any of the forwards target this NIC and the instance attempts to
-			// connect to the forward's listener. Without hairpin mode on the target of the forward
-			// will not be able to connect to the listener.
-			if len(listenAddresses) > 0 {
+			// If br_netfilter is enabled and bridge has forwards or load balancers, we enable hairpin
+			// mode on NIC's bridge port in case any of them target this NIC and the instance attempts
+			// to connect to the listener. Without hairpin mode on the target of the forward will not
+			// be able to connect to the listener.
+			if len(listenAddresses) > 0 || hasLoadBalancers {
 				link := &ip.Link{Name: saveData["host_name"]}
 				err = link.BridgeLinkSetHairpin(true)
 				if err != nil {
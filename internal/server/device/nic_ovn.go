@@ -548,6 +548,11 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader, partialValidatio
 		if err != nil {
 			return err
 		}
+
+		err = acl.ValidateOVN(d.state, networkProjectName, util.SplitNTrimSpace(d.config["security.acls"], ",", -1, true)...)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Avoid setting both ingress/egress and max to avoid confusion or implicit behavior.
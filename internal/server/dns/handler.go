@@ -12,6 +12,9 @@ import (
 	"github.com/lxc/incus/v7/shared/logger"
 )
 
+// maxTransferMsgSize is the size at which zone transfers are split across multiple messages.
+const maxTransferMsgSize = 32 * 1024
+
 type dnsHandler struct {
 	server *Server
 }
@@ -45,15 +48,9 @@ func (d dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 
-	// Check that it's a supported request type.
-	if r.Question[0].Qtype != dns.TypeAXFR && r.Question[0].Qtype != dns.TypeIXFR && r.Question[0].Qtype != dns.TypeSOA {
-		m := &dns.Msg{}
-		m.SetRcode(r, dns.RcodeNotImplemented)
-		err := w.WriteMsg(m)
-		if err != nil {
-			logger.Error("Unable to write message", logger.Ctx{"err": err})
-		}
-
+	// Only regular queries are supported.
+	if r.Opcode != dns.OpcodeQuery {
+		writeRcode(w, r, dns.RcodeNotImplemented)
 		return
 	}
 
@@ -71,30 +68,85 @@ func (d dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 
-	// Prepare the response.
-	m := &dns.Msg{}
-	m.SetReply(r)
-	m.Authoritative = true
+	// Answer regular queries straight from the zone data.
+	if r.Question[0].Qtype != dns.TypeAXFR && r.Question[0].Qtype != dns.TypeIXFR {
+		d.serveQuery(w, r, ip)
+		return
+	}
 
 	// Load the zone.
-	zone, err := d.server.zoneRetriever(name, r.Question[0].Qtype != dns.TypeSOA)
+	zone, err := d.server.zoneRetriever(name, false)
 	if err != nil {
 		// On failure, return NXDOMAIN.
-		m := &dns.Msg{}
-		m.SetRcode(r, dns.RcodeNameError)
-		err := w.WriteMsg(m)
-		if err != nil {
-			logger.Error("Unable to write message", logger.Ctx{"err": err})
-		}
-
+		writeRcode(w, r, dns.RcodeNameError)
 		return
 	}
 
 	// Check access.
 	if !isAllowed(zone.Info, ip, r.IsTsig(), w.TsigStatus() == nil) {
 		// On auth failure, return NXDOMAIN to avoid information leaks.
+		writeRcode(w, r, dns.RcodeNameError)
+		return
+	}
+
+	// Get the latest zone content.
+	journal, err := d.server.refreshZone(name)
+	if err != nil {
+		logger.Errorf("Failed to load DNS zone %q: %v", name, err)
+		writeRcode(w, r, dns.RcodeServerFailure)
+		return
+	}
+
+	var records []dns.RR
+	if r.Question[0].Qtype == dns.TypeIXFR {
+		// Use the serial provided by the client in the authority section.
+		for _, rr := range r.Ns {
+			soa, ok := rr.(*dns.SOA)
+			if ok {
+				records, _ = journal.ixfr(soa.Serial)
+				break
+			}
+		}
+	}
+
+	// Fall back to a full transfer.
+	if records == nil {
+		records = journal.axfr()
+		if records == nil {
+			writeRcode(w, r, dns.RcodeServerFailure)
+			return
+		}
+	}
+
+	d.writeTransfer(w, r, records)
+}
+
+// writeTransfer sends the records making up a zone transfer, splitting them over multiple messages as needed.
+func (d dnsHandler) writeTransfer(w dns.ResponseWriter, r *dns.Msg, records []dns.RR) {
+	// Over UDP, a transfer which doesn't fit in a single message is replaced by the current SOA.
+	// The client then retries over TCP.
+	_, isUDP := w.RemoteAddr().(*net.UDPAddr)
+	if isUDP {
+		size := dns.MinMsgSize
+		opt := r.IsEdns0()
+		if opt != nil {
+			size = max(int(opt.UDPSize()), dns.MinMsgSize)
+		}
+
 		m := &dns.Msg{}
-		m.SetRcode(r, dns.RcodeNameError)
+		m.SetReply(r)
+		m.Authoritative = true
+		m.Answer = records
+
+		if len(records) > 0 && m.Len() > size {
+			m.Answer = records[:1]
+		}
+
+		tsig := r.IsTsig()
+		if tsig != nil && w.TsigStatus() == nil {
+			m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+		}
+
 		err := w.WriteMsg(m)
 		if err != nil {
 			logger.Error("Unable to write message", logger.Ctx{"err": err})
@@ -103,49 +155,50 @@ func (d dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 
-	zoneRR := dns.NewZoneParser(strings.NewReader(zone.Content), "", "")
-	for {
-		rr, ok := zoneRR.Next()
-		if !ok {
-			err := zoneRR.Err()
-			if err != nil {
-				logger.Errorf("Bad DNS record in zone %q: %v", name, err)
-
-				m := &dns.Msg{}
-				m.SetRcode(r, dns.RcodeFormatError)
-				err := w.WriteMsg(m)
-				if err != nil {
-					logger.Error("Unable to write message", logger.Ctx{"err": err})
-				}
-
-				return
+	tsig := r.IsTsig()
+	for len(records) > 0 {
+		// Fill the message up to the maximum transfer message size.
+		size := 0
+		count := 0
+		for _, rr := range records {
+			size += dns.Len(rr)
+			if count > 0 && size > maxTransferMsgSize {
+				break
 			}
 
-			break
+			count++
 		}
 
-		m.Answer = append(m.Answer, rr)
-	}
+		m := &dns.Msg{}
+		m.SetReply(r)
+		m.Authoritative = true
+		m.Answer = records[:count]
+		records = records[count:]
 
-	tsig := r.IsTsig()
-	if tsig != nil && w.TsigStatus() == nil {
-		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
-	}
+		if tsig != nil && w.TsigStatus() == nil {
+			m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+		}
 
-	err = w.WriteMsg(m)
-	if err != nil {
-		logger.Error("Unable to write message", logger.Ctx{"err": err})
+		err := w.WriteMsg(m)
+		if err != nil {
+			logger.Error("Unable to write message", logger.Ctx{"err": err})
+			return
+		}
+
+		// Subsequent messages only carry the TSIG timers.
+		w.TsigTimersOnly(true)
 	}
 }
 
-func isAllowed(zone api.NetworkZone, ip string, tsig *dns.TSIG, tsigStatus bool) bool {
-	type peer struct {
-		address string
-		key     string
-	}
+// zonePeer represents a DNS server allowed to access a zone.
+type zonePeer struct {
+	address string
+	key     string
+}
 
-	// Build a list of peers.
-	peers := map[string]*peer{}
+// zonePeers returns the peers configured on the zone.
+func zonePeers(zone api.NetworkZone) map[string]*zonePeer {
+	peers := map[string]*zonePeer{}
 	for k, v := range zone.Config {
 		if !strings.HasPrefix(k, "peers.") {
 			continue
@@ -160,7 +213,7 @@ func isAllowed(zone api.NetworkZone, ip string, tsig *dns.TSIG, tsigStatus bool)
 		peerName := fields[1]
 
 		if peers[peerName] == nil {
-			peers[peerName] = &peer{}
+			peers[peerName] = &zonePeer{}
 		}
 
 		// Add the correct validation rule for the dynamic field based on last part of key.
@@ -172,8 +225,12 @@ func isAllowed(zone api.NetworkZone, ip string, tsig *dns.TSIG, tsigStatus bool)
 		}
 	}
 
+	return peers
+}
+
+func isAllowed(zone api.NetworkZone, ip string, tsig *dns.TSIG, tsigStatus bool) bool {
 	// Validate access.
-	for peerName, peer := range peers {
+	for peerName, peer := range zonePeers(zone) {
 		peerKeyName := fmt.Sprintf("%s_%s.", zone.Name, peerName)
 
 		if peer.address != "" && ip != peer.address {
@@ -0,0 +1,317 @@
+package dns
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+)
+
+// maxJournalEntries is the maximum number of changes kept for each zone.
+const maxJournalEntries = 100
+
+// journalEntry represents a single change to a zone.
+type journalEntry struct {
+	fromSerial uint32
+	toSerial   uint32
+	removed    []dns.RR
+	added      []dns.RR
+
+	// complete is false when the change itself isn't known, only the resulting serial.
+	complete bool
+}
+
+// zoneJournal keeps the current content of a zone along with the recent changes applied to it.
+// The serials and changes come from the cluster database so all members serve the same ones.
+type zoneJournal struct {
+	soa     *dns.SOA
+	records []dns.RR
+	hash    string
+	index   *zoneIndex
+	entries []journalEntry
+	loaded  time.Time
+
+	mu sync.Mutex
+
+	// refreshMu serializes the refreshes of the zone.
+	refreshMu sync.Mutex
+}
+
+// zoneContent splits the zone content into its SOA record and the other records, dropping duplicates.
+func zoneContent(content []dns.RR) (*dns.SOA, []dns.RR) {
+	var soa *dns.SOA
+
+	records := make([]dns.RR, 0, len(content))
+	keys := make(map[string]struct{}, len(content))
+	for _, rr := range content {
+		if rr.Header().Rrtype == dns.TypeSOA {
+			soa, _ = rr.(*dns.SOA)
+			continue
+		}
+
+		key := rr.String()
+		_, found := keys[key]
+		if found {
+			continue
+		}
+
+		keys[key] = struct{}{}
+		records = append(records, rr)
+	}
+
+	return soa, records
+}
+
+// zoneHash returns a hash of the zone content which doesn't depend on the SOA serial or the record order.
+func zoneHash(soa *dns.SOA, records []dns.RR) string {
+	lines := make([]string, 0, len(records))
+	for _, rr := range records {
+		lines = append(lines, rr.String())
+	}
+
+	slices.Sort(lines)
+
+	soa = dns.Copy(soa).(*dns.SOA)
+	soa.Serial = 0
+
+	hash := sha256.New()
+	hash.Write([]byte(soa.String() + "\n"))
+	hash.Write([]byte(strings.Join(lines, "\n")))
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// zoneDiff returns the records removed and added between two versions of a zone.
+func zoneDiff(oldRecords []dns.RR, newRecords []dns.RR) ([]dns.RR, []dns.RR) {
+	oldKeys := make(map[string]struct{}, len(oldRecords))
+	for _, rr := range oldRecords {
+		oldKeys[rr.String()] = struct{}{}
+	}
+
+	newKeys := make(map[string]struct{}, len(newRecords))
+	for _, rr := range newRecords {
+		newKeys[rr.String()] = struct{}{}
+	}
+
+	removed := []dns.RR{}
+	for _, rr := range oldRecords {
+		_, found := newKeys[rr.String()]
+		if !found {
+			removed = append(removed, rr)
+		}
+	}
+
+	added := []dns.RR{}
+	for _, rr := range newRecords {
+		_, found := oldKeys[rr.String()]
+		if !found {
+			added = append(added, rr)
+		}
+	}
+
+	return removed, added
+}
+
+// nextSerial returns the serial following the latest one.
+// Serials are based on the current time so they keep increasing should the journal be lost.
+func nextSerial(latest uint32, now time.Time) uint32 {
+	return max(latest+1, uint32(now.Unix()))
+}
+
+// formatRecords returns the records in zone file format.
+func formatRecords(records []dns.RR) string {
+	lines := make([]string, 0, len(records))
+	for _, rr := range records {
+		lines = append(lines, rr.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// newJournalEntry returns the database journal entry for a new version of the zone.
+// The change is only recorded if the previous content is known.
+func newJournalEntry(serial uint32, hash string, previous []dns.RR, records []dns.RR) dbCluster.NetworkZoneJournalEntry {
+	entry := dbCluster.NetworkZoneJournalEntry{
+		Serial: serial,
+		Hash:   hash,
+	}
+
+	if previous != nil {
+		removed, added := zoneDiff(previous, records)
+
+		removedText := formatRecords(removed)
+		addedText := formatRecords(added)
+
+		entry.Removed = &removedText
+		entry.Added = &addedText
+	}
+
+	return entry
+}
+
+// journalEntries converts the database journal into the changes between consecutive serials.
+func journalEntries(dbEntries []dbCluster.NetworkZoneJournalEntry) []journalEntry {
+	entries := []journalEntry{}
+	for i := 1; i < len(dbEntries); i++ {
+		entry := journalEntry{
+			fromSerial: dbEntries[i-1].Serial,
+			toSerial:   dbEntries[i].Serial,
+		}
+
+		if dbEntries[i].Removed != nil && dbEntries[i].Added != nil {
+			removed, errRemoved := parseZone(*dbEntries[i].Removed)
+			added, errAdded := parseZone(*dbEntries[i].Added)
+			if errRemoved == nil && errAdded == nil {
+				entry.removed = removed
+				entry.added = added
+				entry.complete = true
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// previous returns the zone records if the journal currently holds the content matching the serial and hash.
+func (j *zoneJournal) previous(serial uint32, hash string) []dns.RR {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.soa == nil || j.soa.Serial != serial || j.hash != hash {
+		return nil
+	}
+
+	return j.records
+}
+
+// set replaces the zone content and the changes leading to it.
+func (j *zoneJournal) set(soa *dns.SOA, records []dns.RR, hash string, entries []journalEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.soa = soa
+	j.records = records
+	j.hash = hash
+	j.index = newZoneIndex(soa, records)
+	j.entries = entries
+	j.loaded = time.Now()
+}
+
+// serial returns the current serial of the zone.
+func (j *zoneJournal) serial() uint32 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.soa == nil {
+		return 0
+	}
+
+	return j.soa.Serial
+}
+
+// fresh returns whether the zone content was loaded less than maxAge ago.
+func (j *zoneJournal) fresh(maxAge time.Duration) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.soa != nil && time.Since(j.loaded) < maxAge
+}
+
+// lookupIndex returns the zone records indexed by owner name.
+func (j *zoneJournal) lookupIndex() *zoneIndex {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.index
+}
+
+// full returns the full zone content, starting with the SOA record.
+func (j *zoneJournal) full() []dns.RR {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.soa == nil {
+		return nil
+	}
+
+	return append([]dns.RR{j.soa}, j.records...)
+}
+
+// axfr returns the records making up a full zone transfer.
+func (j *zoneJournal) axfr() []dns.RR {
+	records := j.full()
+	if records == nil {
+		return nil
+	}
+
+	return append(records, records[0])
+}
+
+// ixfr returns the records making up an incremental zone transfer from the provided serial.
+// The second return value is false if the journal doesn't go back far enough, is missing some of the
+// changes or if a full transfer would be smaller.
+func (j *zoneJournal) ixfr(serial uint32) ([]dns.RR, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.soa == nil {
+		return nil, false
+	}
+
+	// The client is already up to date.
+	if serial == j.soa.Serial {
+		return []dns.RR{j.soa}, true
+	}
+
+	start := -1
+	for i, entry := range j.entries {
+		if entry.fromSerial == serial {
+			start = i
+			break
+		}
+	}
+
+	if start < 0 || j.entries[len(j.entries)-1].toSerial != j.soa.Serial {
+		return nil, false
+	}
+
+	size := 0
+	for _, entry := range j.entries[start:] {
+		if !entry.complete {
+			return nil, false
+		}
+
+		size += len(entry.removed) + len(entry.added)
+	}
+
+	if size > len(j.records) {
+		return nil, false
+	}
+
+	soaWithSerial := func(serial uint32) *dns.SOA {
+		soa := dns.Copy(j.soa).(*dns.SOA)
+		soa.Serial = serial
+		return soa
+	}
+
+	// Each change is made of the old SOA, the removed records, the new SOA and the added records.
+	records := []dns.RR{j.soa}
+	for _, entry := range j.entries[start:] {
+		records = append(records, soaWithSerial(entry.fromSerial))
+		records = append(records, entry.removed...)
+		records = append(records, soaWithSerial(entry.toSerial))
+		records = append(records, entry.added...)
+	}
+
+	records = append(records, j.soa)
+
+	return records, true
+}
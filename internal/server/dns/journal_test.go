@@ -0,0 +1,266 @@
+package dns
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+)
+
+// testZoneContent returns the SOA and other records of a zone made of the provided records.
+func testZoneContent(t *testing.T, records ...string) (*dns.SOA, []dns.RR) {
+	t.Helper()
+
+	content, err := parseZone("example.com. 3600 IN SOA ns1.example.com. hostmaster.example.com. 1 120 60 86400 30\n" + strings.Join(records, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return zoneContent(content)
+}
+
+func recordStrings(records []dns.RR) []string {
+	lines := []string{}
+	for _, rr := range records {
+		lines = append(lines, rr.String())
+	}
+
+	slices.Sort(lines)
+
+	return lines
+}
+
+func TestZoneContent(t *testing.T) {
+	soa, records := testZoneContent(t,
+		"a.example.com. 300 IN A 192.0.2.1",
+		"a.example.com. 300 IN A 192.0.2.1",
+		"b.example.com. 300 IN A 192.0.2.2",
+	)
+
+	if soa == nil || soa.Serial != 1 {
+		t.Fatalf("Unexpected SOA record: %v", soa)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected duplicates to be dropped, got %d records", len(records))
+	}
+}
+
+func TestZoneHash(t *testing.T) {
+	soa, records := testZoneContent(t, "a.example.com. 300 IN A 192.0.2.1", "b.example.com. 300 IN A 192.0.2.2")
+	hash := zoneHash(soa, records)
+
+	// The order of the records and the serial don't matter.
+	otherSOA, otherRecords := testZoneContent(t, "b.example.com. 300 IN A 192.0.2.2", "a.example.com. 300 IN A 192.0.2.1")
+	otherSOA.Serial = 12345
+	if zoneHash(otherSOA, otherRecords) != hash {
+		t.Fatal("Hash depends on the record order or the serial")
+	}
+
+	if soa.Serial != 1 {
+		t.Fatal("Hashing modified the SOA record")
+	}
+
+	// Changes to the records or the rest of the SOA do.
+	_, changedRecords := testZoneContent(t, "a.example.com. 300 IN A 192.0.2.1", "b.example.com. 300 IN A 192.0.2.3")
+	if zoneHash(soa, changedRecords) == hash {
+		t.Fatal("Hash didn't change with the records")
+	}
+
+	changedSOA := dns.Copy(soa).(*dns.SOA)
+	changedSOA.Refresh = 600
+	if zoneHash(changedSOA, records) == hash {
+		t.Fatal("Hash didn't change with the SOA record")
+	}
+}
+
+func TestZoneDiff(t *testing.T) {
+	_, oldRecords := testZoneContent(t,
+		"a.example.com. 300 IN A 192.0.2.1",
+		"b.example.com. 300 IN A 192.0.2.2",
+		"c.example.com. 300 IN TXT \"old\"",
+	)
+
+	_, newRecords := testZoneContent(t,
+		"a.example.com. 300 IN A 192.0.2.1",
+		"b.example.com. 300 IN A 192.0.2.20",
+		"d.example.com. 300 IN AAAA 2001:db8::1",
+	)
+
+	removed, added := zoneDiff(oldRecords, newRecords)
+
+	wantRemoved := []string{"b.example.com.\t300\tIN\tA\t192.0.2.2", "c.example.com.\t300\tIN\tTXT\t\"old\""}
+	if !slices.Equal(recordStrings(removed), wantRemoved) {
+		t.Fatalf("Unexpected removed records: %v", recordStrings(removed))
+	}
+
+	wantAdded := []string{"b.example.com.\t300\tIN\tA\t192.0.2.20", "d.example.com.\t300\tIN\tAAAA\t2001:db8::1"}
+	if !slices.Equal(recordStrings(added), wantAdded) {
+		t.Fatalf("Unexpected added records: %v", recordStrings(added))
+	}
+
+	removed, added = zoneDiff(newRecords, newRecords)
+	if len(removed) != 0 || len(added) != 0 {
+		t.Fatal("Identical content reported as changed")
+	}
+}
+
+func TestNextSerial(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	if nextSerial(0, now) != 1700000000 {
+		t.Fatal("First serial isn't based on the current time")
+	}
+
+	if nextSerial(1700000000, now) != 1700000001 {
+		t.Fatal("Serial didn't increase within the same second")
+	}
+
+	if nextSerial(1800000000, now) != 1800000001 {
+		t.Fatal("Serial went backward")
+	}
+}
+
+// testJournal returns a journal going through the provided versions of a zone, as recorded in the database.
+// Versions without a known previous content are recorded without their change.
+func testJournal(t *testing.T, known []bool, versions ...[]string) *zoneJournal {
+	t.Helper()
+
+	dbEntries := []dbCluster.NetworkZoneJournalEntry{}
+
+	var soa *dns.SOA
+	var records []dns.RR
+	for i, version := range versions {
+		var previous []dns.RR
+		if i > 0 && known[i] {
+			previous = records
+		}
+
+		soa, records = testZoneContent(t, version...)
+		dbEntries = append(dbEntries, newJournalEntry(uint32(100+i), zoneHash(soa, records), previous, records))
+	}
+
+	soa.Serial = dbEntries[len(dbEntries)-1].Serial
+
+	j := &zoneJournal{}
+	j.set(soa, records, zoneHash(soa, records), journalEntries(dbEntries))
+
+	return j
+}
+
+func TestJournalEntries(t *testing.T) {
+	dbEntries := []dbCluster.NetworkZoneJournalEntry{
+		newJournalEntry(100, "a", nil, nil),
+		newJournalEntry(101, "b", []dns.RR{}, []dns.RR{}),
+		newJournalEntry(102, "c", nil, nil),
+	}
+
+	entries := journalEntries(dbEntries)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 changes, got %d", len(entries))
+	}
+
+	if entries[0].fromSerial != 100 || entries[0].toSerial != 101 || !entries[0].complete {
+		t.Fatalf("Unexpected first change: %+v", entries[0])
+	}
+
+	if entries[1].fromSerial != 101 || entries[1].toSerial != 102 || entries[1].complete {
+		t.Fatalf("Unexpected second change: %+v", entries[1])
+	}
+}
+
+func TestJournalIXFR(t *testing.T) {
+	base := []string{
+		"a.example.com. 300 IN A 192.0.2.1",
+		"b.example.com. 300 IN A 192.0.2.2",
+		"c.example.com. 300 IN A 192.0.2.3",
+		"d.example.com. 300 IN A 192.0.2.4",
+	}
+
+	v1 := append(slices.Clone(base), "e.example.com. 300 IN A 192.0.2.5")
+	v2 := append(slices.Clone(base[1:]), "e.example.com. 300 IN A 192.0.2.5")
+
+	j := testJournal(t, []bool{true, true, true}, base, v1, v2)
+
+	// Up to date client.
+	records, ok := j.ixfr(102)
+	if !ok || len(records) != 1 || records[0].(*dns.SOA).Serial != 102 {
+		t.Fatalf("Unexpected transfer for an up to date client: %v", records)
+	}
+
+	// Two changes, each wrapped in the SOA records of both serials.
+	records, ok = j.ixfr(100)
+	if !ok {
+		t.Fatal("Expected an incremental transfer")
+	}
+
+	serials := []uint32{}
+	others := []string{}
+	for _, rr := range records {
+		soa, isSOA := rr.(*dns.SOA)
+		if isSOA {
+			serials = append(serials, soa.Serial)
+			continue
+		}
+
+		others = append(others, rr.Header().Name)
+	}
+
+	if !slices.Equal(serials, []uint32{102, 100, 101, 101, 102, 102}) {
+		t.Fatalf("Unexpected SOA serials: %v", serials)
+	}
+
+	if !slices.Equal(others, []string{"e.example.com.", "a.example.com."}) {
+		t.Fatalf("Unexpected changed records: %v", others)
+	}
+
+	// Unknown serials need a full transfer.
+	_, ok = j.ixfr(99)
+	if ok {
+		t.Fatal("Expected no incremental transfer from an unknown serial")
+	}
+
+	// As do changes recorded without their content.
+	j = testJournal(t, []bool{true, false, true}, base, v1, v2)
+	_, ok = j.ixfr(100)
+	if ok {
+		t.Fatal("Expected no incremental transfer over an unknown change")
+	}
+
+	_, ok = j.ixfr(101)
+	if !ok {
+		t.Fatal("Expected an incremental transfer after the unknown change")
+	}
+
+	// And changes larger than the zone itself.
+	j = testJournal(t, []bool{true, true}, base, []string{"z.example.com. 300 IN A 192.0.2.26"})
+	_, ok = j.ixfr(100)
+	if ok {
+		t.Fatal("Expected no incremental transfer larger than a full one")
+	}
+}
+
+func TestJournalPrevious(t *testing.T) {
+	soa, records := testZoneContent(t, "a.example.com. 300 IN A 192.0.2.1")
+	soa.Serial = 100
+	hash := zoneHash(soa, records)
+
+	j := &zoneJournal{}
+	if j.previous(100, hash) != nil {
+		t.Fatal("Empty journal returned previous content")
+	}
+
+	j.set(soa, records, hash, nil)
+	if len(j.previous(100, hash)) != 1 {
+		t.Fatal("Expected the current content")
+	}
+
+	// Content loaded by another member for another version isn't usable.
+	if j.previous(101, hash) != nil || j.previous(100, "other") != nil {
+		t.Fatal("Content returned for a different version")
+	}
+}
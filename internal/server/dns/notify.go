@@ -0,0 +1,71 @@
+package dns
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// notifyAttempts is the number of times a NOTIFY is sent to a peer before giving up.
+const notifyAttempts = 3
+
+// notifyPeers sends a DNS NOTIFY for the zone to all peers with a configured address.
+func notifyPeers(zone api.NetworkZone, soa *dns.SOA) {
+	for peerName, peer := range zonePeers(zone) {
+		if peer.address == "" {
+			continue
+		}
+
+		go func(peerName string, peer *zonePeer) {
+			err := notifyPeer(zone.Name, peerName, peer, soa)
+			if err != nil {
+				logger.Warn("Failed to notify DNS peer", logger.Ctx{"zone": zone.Name, "peer": peerName, "err": err})
+				return
+			}
+
+			logger.Debug("Notified DNS peer", logger.Ctx{"zone": zone.Name, "peer": peerName, "serial": soa.Serial})
+		}(peerName, peer)
+	}
+}
+
+// notifyPeer sends a DNS NOTIFY to a single peer, signing it if the peer has a TSIG key.
+func notifyPeer(zoneName string, peerName string, peer *zonePeer, soa *dns.SOA) error {
+	client := &dns.Client{Net: "udp", Timeout: 5 * time.Second}
+	address := net.JoinHostPort(peer.address, "53")
+
+	var err error
+	for attempt := range notifyAttempts {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 5 * time.Second)
+		}
+
+		m := &dns.Msg{}
+		m.SetNotify(dns.Fqdn(zoneName))
+		m.Answer = []dns.RR{soa}
+
+		if peer.key != "" {
+			keyName := fmt.Sprintf("%s_%s.", zoneName, peerName)
+			client.TsigSecret = map[string]string{keyName: peer.key}
+			m.SetTsig(keyName, dns.HmacSHA256, 300, time.Now().Unix())
+		}
+
+		var resp *dns.Msg
+		resp, _, err = client.Exchange(m, address)
+		if err != nil {
+			continue
+		}
+
+		if resp.Rcode != dns.RcodeSuccess {
+			return fmt.Errorf("Peer returned %s", dns.RcodeToString[resp.Rcode])
+		}
+
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,273 @@
+package dns
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// maxCNAMEChain is the maximum number of CNAME records followed within a zone.
+const maxCNAMEChain = 8
+
+// queryCacheTime is how long the zone content is used to answer queries before being reloaded.
+const queryCacheTime = 10 * time.Second
+
+// serveQuery answers a regular (non-transfer) query from the zone data.
+func (d dnsHandler) serveQuery(w dns.ResponseWriter, r *dns.Msg, ip string) {
+	question := r.Question[0]
+
+	// Only the internet class is supported.
+	if question.Qclass != dns.ClassINET && question.Qclass != dns.ClassANY {
+		writeRcode(w, r, dns.RcodeNotImplemented)
+		return
+	}
+
+	// Find the closest enclosing zone.
+	qname := dns.CanonicalName(question.Name)
+	zone := d.findZone(strings.TrimSuffix(qname, "."))
+	if zone == nil {
+		// Not authoritative for this name.
+		writeRcode(w, r, dns.RcodeRefused)
+		return
+	}
+
+	// Check access.
+	if !isAllowed(zone.Info, ip, r.IsTsig(), w.TsigStatus() == nil) {
+		// On auth failure, behave as if the zone didn't exist to avoid information leaks.
+		writeRcode(w, r, dns.RcodeRefused)
+		return
+	}
+
+	// Answer from the cached zone content, reloading it once it gets too old.
+	journal := d.server.cachedZone(zone.Info.Name)
+	if journal == nil || !journal.fresh(queryCacheTime) {
+		var err error
+
+		journal, err = d.server.refreshZone(zone.Info.Name)
+		if err != nil {
+			logger.Errorf("Failed to load DNS zone %q: %v", zone.Info.Name, err)
+			writeRcode(w, r, dns.RcodeServerFailure)
+			return
+		}
+	}
+
+	index := journal.lookupIndex()
+	if index == nil {
+		writeRcode(w, r, dns.RcodeServerFailure)
+		return
+	}
+
+	// Prepare the response.
+	m := &dns.Msg{}
+	m.SetReply(r)
+	m.Authoritative = true
+
+	answer, rcode := index.lookup(question.Name, question.Qtype)
+	m.Answer = answer
+	m.Rcode = rcode
+
+	// Negative answers carry the zone SOA for caching.
+	if len(answer) == 0 {
+		m.Ns = []dns.RR{index.soa}
+	}
+
+	// Handle EDNS and truncation of UDP responses.
+	size := dns.MinMsgSize
+
+	opt := r.IsEdns0()
+	if opt != nil {
+		size = max(int(opt.UDPSize()), dns.MinMsgSize)
+		m.SetEdns0(uint16(size), false)
+	}
+
+	_, isUDP := w.RemoteAddr().(*net.UDPAddr)
+	if isUDP {
+		m.Truncate(size)
+	}
+
+	tsig := r.IsTsig()
+	if tsig != nil && w.TsigStatus() == nil {
+		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+	}
+
+	err := w.WriteMsg(m)
+	if err != nil {
+		logger.Error("Unable to write message", logger.Ctx{"err": err})
+	}
+}
+
+// findZone returns the zone closest to the given name (SOA only) or nil if none exists.
+func (d dnsHandler) findZone(name string) *Zone {
+	for name != "" {
+		zone, err := d.server.zoneRetriever(name, false)
+		if err == nil {
+			return zone
+		}
+
+		// Move on to the parent domain.
+		_, parent, found := strings.Cut(name, ".")
+		if !found {
+			break
+		}
+
+		name = parent
+	}
+
+	return nil
+}
+
+// parseZone parses the zone content into a list of records.
+// The SOA record repeated at the end of zone transfers is skipped.
+func parseZone(content string) ([]dns.RR, error) {
+	records := []dns.RR{}
+	hasSOA := false
+
+	zoneRR := dns.NewZoneParser(strings.NewReader(content), "", "")
+	for {
+		rr, ok := zoneRR.Next()
+		if !ok {
+			break
+		}
+
+		if rr.Header().Rrtype == dns.TypeSOA {
+			if hasSOA {
+				continue
+			}
+
+			hasSOA = true
+		}
+
+		records = append(records, rr)
+	}
+
+	err := zoneRR.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// zoneIndex holds the records of a zone indexed by owner name.
+type zoneIndex struct {
+	soa    *dns.SOA
+	owners map[string][]dns.RR
+
+	// names holds all the owner names along with the names above them, which exist even without records
+	// of their own.
+	names map[string]struct{}
+}
+
+// newZoneIndex indexes the zone records.
+func newZoneIndex(soa *dns.SOA, records []dns.RR) *zoneIndex {
+	idx := &zoneIndex{
+		soa:    soa,
+		owners: make(map[string][]dns.RR, len(records)+1),
+		names:  make(map[string]struct{}, len(records)+1),
+	}
+
+	for _, rr := range append([]dns.RR{soa}, records...) {
+		name := dns.CanonicalName(rr.Header().Name)
+		idx.owners[name] = append(idx.owners[name], rr)
+
+		for name != "" {
+			idx.names[name] = struct{}{}
+			_, name, _ = strings.Cut(name, ".")
+		}
+	}
+
+	return idx
+}
+
+// lookup returns the records answering the query along with the response code.
+// An empty answer with a success response code means that the name exists but not with the requested type.
+func (idx *zoneIndex) lookup(qname string, qtype uint16) ([]dns.RR, int) {
+	answer := []dns.RR{}
+
+	for range maxCNAMEChain {
+		owners := idx.recordsForName(qname)
+		if len(owners) == 0 {
+			// Names with records below them exist even without records of their own.
+			_, found := idx.names[dns.CanonicalName(qname)]
+			if found {
+				return answer, dns.RcodeSuccess
+			}
+
+			// Once a CNAME was followed, the name is outside of the records we know about.
+			if len(answer) > 0 {
+				return answer, dns.RcodeSuccess
+			}
+
+			return answer, dns.RcodeNameError
+		}
+
+		var cname *dns.CNAME
+		matched := false
+		for _, rr := range owners {
+			if qtype == dns.TypeANY || rr.Header().Rrtype == qtype {
+				answer = append(answer, rr)
+				matched = true
+				continue
+			}
+
+			if rr.Header().Rrtype == dns.TypeCNAME {
+				cname, _ = rr.(*dns.CNAME)
+			}
+		}
+
+		// Follow CNAME records pointing within the zone.
+		if matched || cname == nil {
+			return answer, dns.RcodeSuccess
+		}
+
+		answer = append(answer, cname)
+		qname = cname.Target
+	}
+
+	return answer, dns.RcodeSuccess
+}
+
+// recordsForName returns the records owned by the name, falling back to a matching wildcard.
+func (idx *zoneIndex) recordsForName(qname string) []dns.RR {
+	name := dns.CanonicalName(qname)
+
+	owners, found := idx.owners[name]
+	if found {
+		return owners
+	}
+
+	// Look for a wildcard record directly above the name.
+	_, parent, found := strings.Cut(name, ".")
+	if !found || parent == "" {
+		return nil
+	}
+
+	wildcards := idx.owners["*."+parent]
+	if len(wildcards) == 0 {
+		return nil
+	}
+
+	// Synthesize the records for the requested name.
+	owners = make([]dns.RR, 0, len(wildcards))
+	for _, rr := range wildcards {
+		rr = dns.Copy(rr)
+		rr.Header().Name = qname
+		owners = append(owners, rr)
+	}
+
+	return owners
+}
+
+// writeRcode sends an empty response with the given response code.
+func writeRcode(w dns.ResponseWriter, r *dns.Msg, rcode int) {
+	m := &dns.Msg{}
+	m.SetRcode(r, rcode)
+	err := w.WriteMsg(m)
+	if err != nil {
+		logger.Error("Unable to write message", logger.Ctx{"err": err})
+	}
+}
@@ -0,0 +1,216 @@
+package dns
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func testZoneIndex(t *testing.T) *zoneIndex {
+	t.Helper()
+
+	records, err := parseZone(`
+example.com. 300 IN SOA ns1.example.com. hostmaster.example.com. 1 120 60 86400 30
+example.com. 300 IN NS ns1.example.com.
+ns1.example.com. 300 IN A 192.0.2.1
+c1.example.com. 300 IN A 192.0.2.10
+c1.example.com. 300 IN AAAA 2001:db8::10
+c1.example.com. 300 IN TXT "hello"
+www.example.com. 300 IN CNAME c1.example.com.
+alias.example.com. 300 IN CNAME www.example.com.
+external.example.com. 300 IN CNAME host.example.net.
+loop1.example.com. 300 IN CNAME loop2.example.com.
+loop2.example.com. 300 IN CNAME loop1.example.com.
+host.deep.sub.example.com. 300 IN A 192.0.2.20
+*.apps.example.com. 300 IN A 192.0.2.30
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var soa *dns.SOA
+	other := []dns.RR{}
+	for _, rr := range records {
+		if rr.Header().Rrtype == dns.TypeSOA {
+			soa = rr.(*dns.SOA)
+			continue
+		}
+
+		other = append(other, rr)
+	}
+
+	return newZoneIndex(soa, other)
+}
+
+func TestZoneIndexLookup(t *testing.T) {
+	idx := testZoneIndex(t)
+
+	tests := []struct {
+		name      string
+		qname     string
+		qtype     uint16
+		wantRcode int
+		wantTypes []uint16
+		wantNames []string
+	}{
+		{
+			name:      "exact match",
+			qname:     "c1.example.com.",
+			qtype:     dns.TypeA,
+			wantRcode: dns.RcodeSuccess,
+			wantTypes: []uint16{dns.TypeA},
+		},
+		{
+			name:      "case insensitive",
+			qname:     "C1.Example.COM.",
+			qtype:     dns.TypeAAAA,
+			wantRcode: dns.RcodeSuccess,
+			wantTypes: []uint16{dns.TypeAAAA},
+		},
+		{
+			name:      "any",
+			qname:     "c1.example.com.",
+			qtype:     dns.TypeANY,
+			wantRcode: dns.RcodeSuccess,
+			wantTypes: []uint16{dns.TypeA, dns.TypeAAAA, dns.TypeTXT},
+		},
+		{
+			name:      "zone apex SOA",
+			qname:     "example.com.",
+			qtype:     dns.TypeSOA,
+			wantRcode: dns.RcodeSuccess,
+			wantTypes: []uint16{dns.TypeSOA},
+		},
+		{
+			name:      "missing name",
+			qname:     "missing.example.com.",
+			qtype:     dns.TypeA,
+			wantRcode: dns.RcodeNameError,
+		},
+		{
+			name:      "missing type",
+			qname:     "ns1.example.com.",
+			qtype:     dns.TypeAAAA,
+			wantRcode: dns.RcodeSuccess,
+		},
+		{
+			name:      "empty non-terminal",
+			qname:     "sub.example.com.",
+			qtype:     dns.TypeA,
+			wantRcode: dns.RcodeSuccess,
+		},
+		{
+			name:      "wildcard",
+			qname:     "foo.apps.example.com.",
+			qtype:     dns.TypeA,
+			wantRcode: dns.RcodeSuccess,
+			wantTypes: []uint16{dns.TypeA},
+			wantNames: []string{"foo.apps.example.com."},
+		},
+		{
+			name:      "wildcard only covers one label",
+			qname:     "foo.bar.apps.example.com.",
+			qtype:     dns.TypeA,
+			wantRcode: dns.RcodeNameError,
+		},
+		{
+			name:      "cname chain",
+			qname:     "alias.example.com.",
+			qtype:     dns.TypeA,
+			wantRcode: dns.RcodeSuccess,
+			wantTypes: []uint16{dns.TypeCNAME, dns.TypeCNAME, dns.TypeA},
+			wantNames: []string{"alias.example.com.", "www.example.com.", "c1.example.com."},
+		},
+		{
+			name:      "cname query",
+			qname:     "www.example.com.",
+			qtype:     dns.TypeCNAME,
+			wantRcode: dns.RcodeSuccess,
+			wantTypes: []uint16{dns.TypeCNAME},
+		},
+		{
+			name:      "cname outside of the zone",
+			qname:     "external.example.com.",
+			qtype:     dns.TypeA,
+			wantRcode: dns.RcodeSuccess,
+			wantTypes: []uint16{dns.TypeCNAME},
+		},
+		{
+			name:      "cname loop",
+			qname:     "loop1.example.com.",
+			qtype:     dns.TypeA,
+			wantRcode: dns.RcodeSuccess,
+			wantTypes: slices.Repeat([]uint16{dns.TypeCNAME}, maxCNAMEChain),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer, rcode := idx.lookup(tt.qname, tt.qtype)
+			if rcode != tt.wantRcode {
+				t.Fatalf("Expected rcode %s, got %s", dns.RcodeToString[tt.wantRcode], dns.RcodeToString[rcode])
+			}
+
+			gotTypes := []uint16{}
+			gotNames := []string{}
+			for _, rr := range answer {
+				gotTypes = append(gotTypes, rr.Header().Rrtype)
+				gotNames = append(gotNames, rr.Header().Name)
+			}
+
+			if !slices.Equal(gotTypes, tt.wantTypes) {
+				t.Fatalf("Expected record types %v, got %v", tt.wantTypes, gotTypes)
+			}
+
+			if tt.wantNames != nil && !slices.Equal(gotNames, tt.wantNames) {
+				t.Fatalf("Expected owner names %v, got %v", tt.wantNames, gotNames)
+			}
+		})
+	}
+}
+
+func TestZoneIndexWildcardCopy(t *testing.T) {
+	idx := testZoneIndex(t)
+
+	_, _ = idx.lookup("foo.apps.example.com.", dns.TypeA)
+
+	// Synthesizing a record must leave the wildcard itself untouched.
+	answer, _ := idx.lookup("*.apps.example.com.", dns.TypeA)
+	if len(answer) != 1 || answer[0].Header().Name != "*.apps.example.com." {
+		t.Fatalf("Wildcard record was modified: %v", answer)
+	}
+}
+
+func TestZoneJournalIndex(t *testing.T) {
+	j := &zoneJournal{}
+	if j.fresh(queryCacheTime) || j.lookupIndex() != nil {
+		t.Fatal("Empty journal reported as loaded")
+	}
+
+	soa, records := testZoneContent(t, "c1.example.com. 300 IN A 192.0.2.10")
+	j.set(soa, records, zoneHash(soa, records), nil)
+	if !j.fresh(queryCacheTime) {
+		t.Fatal("Journal not fresh after being loaded")
+	}
+
+	// The index follows content changes.
+	soa, records = testZoneContent(t, "c2.example.com. 300 IN A 192.0.2.11")
+	soa.Serial = 2
+	j.set(soa, records, zoneHash(soa, records), nil)
+
+	idx := j.lookupIndex()
+	_, rcode := idx.lookup("c1.example.com.", dns.TypeA)
+	if rcode != dns.RcodeNameError {
+		t.Fatalf("Removed record still found")
+	}
+
+	answer, _ := idx.lookup("c2.example.com.", dns.TypeA)
+	if len(answer) != 1 {
+		t.Fatalf("Added record not found")
+	}
+
+	if idx.soa.Serial != j.serial() {
+		t.Fatalf("Index SOA serial %d doesn't match the journal serial %d", idx.soa.Serial, j.serial())
+	}
+}
@@ -33,9 +33,21 @@ type Server struct {
 
 	cmd chan serverCmdInfo
 
+	// Zone journals (to handle IXFR and NOTIFY).
+	journals    map[string]*zoneJournal
+	journalMu   sync.Mutex
+	refresh     chan struct{}
+	refreshStop chan struct{}
+
 	mu sync.Mutex
 }
 
+// refreshInterval is how often the zones are checked for changes.
+const refreshInterval = time.Minute
+
+// refreshDelay is how long to wait after a change was reported before checking the zones.
+const refreshDelay = 2 * time.Second
+
 type serverCmd int
 
 const (
@@ -55,7 +67,13 @@ type serverCmdInfo struct {
 // NewServer returns a new server instance.
 func NewServer(db *db.Cluster, retriever ZoneRetriever) *Server {
 	// Setup new struct.
-	s := &Server{db: db, zoneRetriever: retriever}
+	s := &Server{
+		db:            db,
+		zoneRetriever: retriever,
+		journals:      map[string]*zoneJournal{},
+		refresh:       make(chan struct{}, 1),
+	}
+
 	return s
 }
 
@@ -200,6 +218,10 @@ func (s *Server) start(address string) error {
 		return err
 	}
 
+	// Start watching the zones for changes.
+	s.refreshStop = make(chan struct{})
+	go s.runRefresh(s.refreshStop)
+
 	// Record the address.
 	s.address = address
 
@@ -225,6 +247,12 @@ func (s *Server) stop() {
 	_ = s.tcpDNS.Shutdown()
 	_ = s.udpDNS.Shutdown()
 
+	// Stop watching the zones.
+	if s.refreshStop != nil {
+		close(s.refreshStop)
+		s.refreshStop = nil
+	}
+
 	// Unset the address.
 	s.address = ""
 }
@@ -298,3 +326,199 @@ func (s *Server) updateTSIG() error {
 
 	return nil
 }
+
+// RefreshZones schedules a check of all zones served to peers.
+// Any zone whose content changed gets a new serial and its peers are notified.
+func (s *Server) RefreshZones() {
+	select {
+	case s.refresh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Server) runRefresh(stop chan struct{}) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-s.refresh:
+			// Let related changes settle before looking at the zones.
+			select {
+			case <-stop:
+				return
+			case <-time.After(refreshDelay):
+			}
+		}
+
+		s.refreshZones()
+	}
+}
+
+// refreshZones updates the journal of all zones which have peers configured.
+func (s *Server) refreshZones() {
+	if s.db == nil {
+		return
+	}
+
+	zoneNames := map[string]struct{}{}
+
+	err := s.db.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Get all the network zones.
+		zones, err := dbCluster.GetNetworkZones(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		for _, zone := range zones {
+			config, err := dbCluster.GetNetworkZoneConfig(ctx, tx.Tx(), zone.ID)
+			if err != nil {
+				return err
+			}
+
+			// Zones without peers can't be accessed.
+			for key := range config {
+				if strings.HasPrefix(key, "peers.") {
+					zoneNames[zone.Name] = struct{}{}
+					break
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.Warn("Failed to load DNS zones", logger.Ctx{"err": err})
+		return
+	}
+
+	// Forget about zones which are gone.
+	s.journalMu.Lock()
+	for name := range s.journals {
+		_, found := zoneNames[name]
+		if !found {
+			delete(s.journals, name)
+		}
+	}
+
+	s.journalMu.Unlock()
+
+	for name := range zoneNames {
+		_, err := s.refreshZone(name)
+		if err != nil {
+			logger.Warn("Failed to refresh DNS zone", logger.Ctx{"zone": name, "err": err})
+		}
+	}
+}
+
+// refreshZone loads the current zone content into its journal, notifying peers if the serial changed.
+// Serials are shared through the cluster database, the member noticing a change being the one recording it.
+func (s *Server) refreshZone(name string) (*zoneJournal, error) {
+	zone, err := s.zoneRetriever(name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := parseZone(zone.Content)
+	if err != nil {
+		return nil, fmt.Errorf("Bad DNS record in zone %q: %w", name, err)
+	}
+
+	soa, records := zoneContent(content)
+	if soa == nil {
+		return nil, fmt.Errorf("DNS zone %q has no SOA record", name)
+	}
+
+	hash := zoneHash(soa, records)
+
+	s.journalMu.Lock()
+	journal, ok := s.journals[name]
+	if !ok {
+		journal = &zoneJournal{}
+		s.journals[name] = journal
+	}
+
+	s.journalMu.Unlock()
+
+	journal.refreshMu.Lock()
+	defer journal.refreshMu.Unlock()
+
+	var entries []journalEntry
+	changed := false
+
+	// Another member may record the same change concurrently, in which case its serial gets used.
+	for attempt := range 2 {
+		changed = false
+
+		err = s.db.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			zoneID, err := dbCluster.GetNetworkZoneID(ctx, tx.Tx(), zone.Info.Project, zone.Info.Name)
+			if err != nil {
+				return err
+			}
+
+			dbEntries, err := dbCluster.GetNetworkZoneJournal(ctx, tx.Tx(), zoneID)
+			if err != nil {
+				return err
+			}
+
+			// Record a new version if the content changed.
+			var previous []dns.RR
+			var latest uint32
+			if len(dbEntries) > 0 {
+				last := dbEntries[len(dbEntries)-1]
+				if last.Hash == hash {
+					soa.Serial = last.Serial
+					entries = journalEntries(dbEntries)
+					return nil
+				}
+
+				latest = last.Serial
+				previous = journal.previous(last.Serial, last.Hash)
+			}
+
+			entry := newJournalEntry(nextSerial(latest, time.Now()), hash, previous, records)
+
+			err = dbCluster.CreateNetworkZoneJournalEntry(ctx, tx.Tx(), zoneID, entry, maxJournalEntries)
+			if err != nil {
+				return err
+			}
+
+			dbEntries, err = dbCluster.GetNetworkZoneJournal(ctx, tx.Tx(), zoneID)
+			if err != nil {
+				return err
+			}
+
+			soa.Serial = entry.Serial
+			entries = journalEntries(dbEntries)
+			changed = true
+
+			return nil
+		})
+		if err == nil {
+			break
+		}
+
+		if attempt > 0 {
+			return nil, fmt.Errorf("Failed updating the journal of DNS zone %q: %w", name, err)
+		}
+	}
+
+	journal.set(soa, records, hash, entries)
+
+	if changed {
+		notifyPeers(zone.Info, soa)
+	}
+
+	return journal, nil
+}
+
+// cachedZone returns the journal of the zone if it was already loaded.
+func (s *Server) cachedZone(name string) *zoneJournal {
+	s.journalMu.Lock()
+	defer s.journalMu.Unlock()
+
+	return s.journals[name]
+}
@@ -40,6 +40,9 @@ type ACLRule struct {
 	DestinationPort string
 	ICMPType        string
 	ICMPCode        string
+	ConnectionState string // Comma separated list of conntrack states to match.
+	RateLimit       string // Per source rate limit (<packets>/<unit>), only matches traffic exceeding it.
+	ConnectionLimit string // Per source connection limit, only matches connections exceeding it.
 }
 
 // AddressForward represents a NAT address forward.
@@ -52,6 +55,21 @@ type AddressForward struct {
 	SNAT          bool
 }
 
+// LoadBalancer represents a NAT load balancer spreading connections across multiple backends.
+type LoadBalancer struct {
+	ListenAddress net.IP
+	Protocol      string
+	ListenPorts   []uint64
+	Backends      []LoadBalancerBackend
+}
+
+// LoadBalancerBackend represents a load balancer backend.
+// If TargetPorts is empty, the listen ports are used.
+type LoadBalancerBackend struct {
+	TargetAddress net.IP
+	TargetPorts   []uint64
+}
+
 // AddressSet represent an address set.
 type AddressSet struct {
 	Name      string
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"net"
 	"os/exec"
 	"slices"
@@ -23,6 +24,7 @@ import (
 const (
 	nftablesNamespace       = "incus"
 	nftablesContentTemplate = "nftablesContent"
+	nftablesACLMeterPrefix  = "aclmeter_"
 )
 
 // nftablesChainSeparator The "." character is specifically chosen here so as to prevent the ability for collisions
@@ -76,11 +78,11 @@ func (d Nftables) Compat() (bool, error) {
 
 // nftGenericItem represents some common fields amongst the different nftables types.
 type nftGenericItem struct {
-	ItemType string `json:"-"`      // Type of item (table, chain or rule). Populated by Incus.
+	ItemType string `json:"-"`      // Type of item (table, chain, set or rule). Populated by Incus.
 	Family   string `json:"family"` // Family of item (ip, ip6, bridge etc).
 	Table    string `json:"table"`  // Table the item belongs to (for chains and rules).
 	Chain    string `json:"chain"`  // Chain the item belongs to (for rules).
-	Name     string `json:"name"`   // Name of item (for tables and chains).
+	Name     string `json:"name"`   // Name of item (for tables, chains and sets).
 }
 
 // nftParseRuleset parses the ruleset and returns the generic parts as a slice of items.
@@ -114,6 +116,7 @@ func (d Nftables) nftParseRuleset() ([]nftGenericItem, error) {
 		rule, foundRule := item["rule"]
 		chain, foundChain := item["chain"]
 		table, foundTable := item["table"]
+		set, foundSet := item["set"]
 		if foundRule {
 			rule.ItemType = "rule"
 			items = append(items, rule)
@@ -123,6 +126,9 @@ func (d Nftables) nftParseRuleset() ([]nftGenericItem, error) {
 		} else if foundTable {
 			table.ItemType = "table"
 			items = append(items, table)
+		} else if foundSet {
+			set.ItemType = "set"
+			items = append(items, set)
 		}
 	}
 
@@ -311,6 +317,7 @@ func (d Nftables) NetworkClear(networkName string, _ bool, _ []uint) error {
 		"fwd", "pstrt", "in", "out", // Chains used for network operation rules.
 		"aclin", "aclout", "aclfwd", "acl", // Chains used by ACL rules.
 		"fwdprert", "fwdout", "fwdpstrt", // Chains used by Address Forward rules.
+		"lbprert", "lbout", "lbpstrt", // Chains used by Load Balancer rules.
 		"egress", // Chains added for limits.priority option
 	}
 
@@ -321,6 +328,12 @@ func (d Nftables) NetworkClear(networkName string, _ bool, _ []uint) error {
 		return fmt.Errorf("Failed clearing nftables rules for network %q: %w", networkName, err)
 	}
 
+	// Remove the meters used by ACL rules.
+	err = d.removeUnusedACLMeters("inet", networkName, nil)
+	if err != nil {
+		return fmt.Errorf("Failed clearing nftables ACL meters for network %q: %w", networkName, err)
+	}
+
 	// Attempt to delete our address sets.
 	// This will fail so long as there are still rules referencing them (other networks).
 	_ = d.RemoveIncusAddressSets("bridge")
@@ -422,6 +435,13 @@ func (d Nftables) InstanceSetupBridgeFilter(projectName string, instanceName str
 		return fmt.Errorf("Failed adding bridge filter rules for instance device %q (%s): %w", deviceLabel, tplFields["family"], err)
 	}
 
+	// Remove the meters of ACL rules which no longer exist.
+	usedRules := slices.Concat(nftRules.inDropRules, nftRules.inRejectRules, nftRules.inAcceptRules4, nftRules.inAcceptRules6, nftRules.outDropRules, nftRules.outAcceptRules)
+	err = d.removeUnusedACLMeters("bridge", hostName, usedRules)
+	if err != nil {
+		return fmt.Errorf("Failed clearing unused ACL meters for instance device %q: %w", deviceLabel, err)
+	}
+
 	return nil
 }
 
@@ -435,6 +455,12 @@ func (d Nftables) InstanceClearBridgeFilter(projectName string, instanceName str
 		return fmt.Errorf("Failed clearing bridge filter rules for instance device %q: %w", deviceLabel, err)
 	}
 
+	// Remove the meters used by ACL rules.
+	err = d.removeUnusedACLMeters("bridge", hostName, nil)
+	if err != nil {
+		return fmt.Errorf("Failed clearing ACL meters for instance device %q: %w", deviceLabel, err)
+	}
+
 	return nil
 }
 
@@ -916,11 +942,97 @@ func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) erro
 		return err
 	}
 
+	// Remove the meters of ACL rules which no longer exist.
+	err = d.removeUnusedACLMeters("inet", networkName, completeNftRules)
+	if err != nil {
+		return fmt.Errorf("Failed clearing unused ACL meters for network %q: %w", networkName, err)
+	}
+
 	return nil
 }
 
-// buildRemainingRuleParts is a helper that returns the protocol, port, logging, and action parts of a rule.
-func (d Nftables) buildRemainingRuleParts(rule *ACLRule, ipVersion uint) (string, error) {
+// aclMeterLabelPrefix returns the prefix of the meters used by the ACL rules applied to the given network or
+// instance device (the label may be quoted).
+func (d Nftables) aclMeterLabelPrefix(label string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.Trim(label, `"`)))
+
+	return fmt.Sprintf("%s%08x_", nftablesACLMeterPrefix, h.Sum32())
+}
+
+// aclRuleMeterName returns the name of the meter used to track the per-source limits of an ACL rule.
+// The action isn't part of the name so that converted reject rules share the meter of the original rule.
+func (d Nftables) aclRuleMeterName(label string, ipVersion uint, rule *ACLRule) string {
+	h := fnv.New32a()
+	for _, field := range []string{rule.Direction, rule.Source, rule.Destination, rule.Protocol, rule.SourcePort, rule.DestinationPort, rule.ICMPType, rule.ICMPCode, rule.ConnectionState, rule.RateLimit, rule.ConnectionLimit} {
+		_, _ = h.Write([]byte(field))
+		_, _ = h.Write([]byte{0})
+	}
+
+	return fmt.Sprintf("%s%08x_ipv%d", d.aclMeterLabelPrefix(label), h.Sum32(), ipVersion)
+}
+
+// aclRuleLimitToMeter converts the per-source limits of an ACL rule into a meter statement.
+// The meter only matches once the source exceeds the limit.
+func (d Nftables) aclRuleLimitToMeter(label string, ipVersion uint, rule *ACLRule) []string {
+	ipFamily := "ip"
+	if ipVersion == 6 {
+		ipFamily = "ip6"
+	}
+
+	var statement string
+	if rule.RateLimit != "" {
+		// Forget about sources once their bucket would have been refilled.
+		_, unit, _ := strings.Cut(rule.RateLimit, "/")
+		timeout := map[string]string{"second": "1m", "minute": "1m", "hour": "1h", "day": "1d"}[unit]
+
+		statement = fmt.Sprintf("%s saddr timeout %s limit rate over %s", ipFamily, timeout, rule.RateLimit)
+	} else {
+		statement = fmt.Sprintf("%s saddr ct count over %s", ipFamily, rule.ConnectionLimit)
+	}
+
+	return []string{"meter", d.aclRuleMeterName(label, ipVersion, rule), "size", "65535", fmt.Sprintf("{ %s }", statement)}
+}
+
+// removeUnusedACLMeters deletes the ACL meters of a network or instance device which aren't used by the given rules.
+func (d Nftables) removeUnusedACLMeters(family string, label string, rules []string) error {
+	usedMeters := map[string]struct{}{}
+	for _, rule := range rules {
+		fields := strings.Fields(rule)
+		for i, field := range fields {
+			if field == "meter" && i+1 < len(fields) {
+				usedMeters[fields[i+1]] = struct{}{}
+			}
+		}
+	}
+
+	ruleset, err := d.nftParseRuleset()
+	if err != nil {
+		return err
+	}
+
+	prefix := d.aclMeterLabelPrefix(label)
+	for _, item := range ruleset {
+		if item.ItemType != "set" || item.Family != family || item.Table != nftablesNamespace || !strings.HasPrefix(item.Name, prefix) {
+			continue
+		}
+
+		_, found := usedMeters[item.Name]
+		if found {
+			continue
+		}
+
+		_, err = subprocess.RunCommand("nft", "delete", "set", item.Family, nftablesNamespace, item.Name)
+		if err != nil {
+			return fmt.Errorf("Failed deleting nftables set %q (%s): %w", item.Name, item.Family, err)
+		}
+	}
+
+	return nil
+}
+
+// buildRemainingRuleParts is a helper that returns the protocol, port, state, limit, logging, and action parts of a rule.
+func (d Nftables) buildRemainingRuleParts(networkName string, rule *ACLRule, ipVersion uint) (string, error) {
 	args := []string{}
 
 	// Add protocol filters.
@@ -955,6 +1067,16 @@ func (d Nftables) buildRemainingRuleParts(rule *ACLRule, ipVersion uint) (string
 		}
 	}
 
+	// Add connection tracking state filters.
+	if rule.ConnectionState != "" {
+		args = append(args, "ct", "state", fmt.Sprintf("{%s}", rule.ConnectionState))
+	}
+
+	// Add per-source limits.
+	if rule.RateLimit != "" || rule.ConnectionLimit != "" {
+		args = append(args, d.aclRuleLimitToMeter(networkName, ipVersion, rule)...)
+	}
+
 	// Handle logging.
 	if rule.Log {
 		args = append(args, "log")
@@ -1067,8 +1189,13 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 		ruleFragments = append(ruleFragments, slices.Clone(baseArgs))
 	}
 
-	// Build the remaining parts (protocol, ports, logging, action).
-	suffixParts, err := d.buildRemainingRuleParts(rule, ipVersion)
+	// Per-source limits are keyed on the source address, so IP agnostic rules need a rule for each IP version.
+	if ipVersion == 4 && rule.Source == "" && rule.Destination == "" && (rule.RateLimit != "" || rule.ConnectionLimit != "") && !slices.Contains([]string{"icmp4", "icmp6"}, rule.Protocol) {
+		overallPartial = true
+	}
+
+	// Build the remaining parts (protocol, ports, state, limits, logging, action).
+	suffixParts, err := d.buildRemainingRuleParts(networkName, rule, ipVersion)
 	if err != nil {
 		return nil, overallPartial, err
 	}
@@ -1391,6 +1518,107 @@ func (d Nftables) NetworkApplyForwards(networkName string, rules []AddressForwar
 	return nil
 }
 
+// NetworkApplyLoadBalancers apply network load balancer rules to firewall.
+func (d Nftables) NetworkApplyLoadBalancers(networkName string, rules []LoadBalancer) error {
+	var dnatRules []map[string]any
+	var snatRules []map[string]any
+
+	for ruleIndex, rule := range rules {
+		// Validate the rule.
+		if rule.ListenAddress == nil {
+			return fmt.Errorf("Invalid load balancer %d, listen address is required", ruleIndex)
+		}
+
+		if rule.Protocol != "tcp" && rule.Protocol != "udp" {
+			return fmt.Errorf("Invalid load balancer %d, protocol must be tcp or udp", ruleIndex)
+		}
+
+		if len(rule.ListenPorts) == 0 {
+			return fmt.Errorf("Invalid load balancer %d, listen ports are required", ruleIndex)
+		}
+
+		if len(rule.Backends) == 0 {
+			return fmt.Errorf("Invalid load balancer %d, at least one backend is required", ruleIndex)
+		}
+
+		ipFamily := "ip"
+		if rule.ListenAddress.To4() == nil {
+			ipFamily = "ip6"
+		}
+
+		// New TCP connections are spread randomly across the backends while UDP flows are hashed on
+		// their source so that a client keeps talking to the same backend once its conntrack entry expires.
+		selector := fmt.Sprintf("numgen random mod %d", len(rule.Backends))
+		if rule.Protocol == "udp" {
+			selector = fmt.Sprintf("jhash %s saddr . udp sport mod %d", ipFamily, len(rule.Backends))
+		}
+
+		dnatMaps, withPorts, err := getLoadBalancerDNATMaps(&rule)
+		if err != nil {
+			return fmt.Errorf("Invalid load balancer %d: %w", ruleIndex, err)
+		}
+
+		for listenPortRange, targets := range dnatMaps {
+			dnatRules = append(dnatRules, map[string]any{
+				"ipFamily":      ipFamily,
+				"protocol":      rule.Protocol,
+				"listenAddress": rule.ListenAddress.String(),
+				"listenPorts":   portRangeStr(listenPortRange, "-"),
+				"selector":      selector,
+				"withPorts":     withPorts,
+				"targets":       strings.Join(targets, ", "),
+			})
+		}
+
+		// Allow backends to reach themselves through the load balancer.
+		for _, backend := range rule.Backends {
+			targetPorts := backend.TargetPorts
+			if len(targetPorts) == 0 {
+				targetPorts = rule.ListenPorts
+			}
+
+			for _, targetPortRange := range portRangesFromSlice(targetPorts) {
+				snatRules = append(snatRules, map[string]any{
+					"ipFamily":    ipFamily,
+					"protocol":    rule.Protocol,
+					"targetHost":  backend.TargetAddress.String(),
+					"targetPorts": portRangeStr(targetPortRange, "-"),
+				})
+			}
+		}
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"family":         "inet",
+		"label":          networkName,
+		"dnatRules":      dnatRules,
+		"snatRules":      snatRules,
+	}
+
+	// Apply rules or remove chains if no rules generated.
+	if len(dnatRules) > 0 {
+		config := &strings.Builder{}
+		err := nftablesNetLoadBalancer.Execute(config, tplFields)
+		if err != nil {
+			return fmt.Errorf("Failed running %q template: %w", nftablesNetLoadBalancer.Name(), err)
+		}
+
+		err = subprocess.RunCommandWithFds(context.TODO(), strings.NewReader(config.String()), nil, "nft", "-f", "-")
+		if err != nil {
+			return err
+		}
+	} else {
+		err := d.removeChains([]string{"inet"}, networkName, "lbprert", "lbout", "lbpstrt")
+		if err != nil {
+			return fmt.Errorf("Failed clearing nftables load balancer rules for network %q: %w", networkName, err)
+		}
+	}
+
+	return nil
+}
+
 // NetworkApplyAddressSets creates or updates named nft sets for all address sets.
 func (d Nftables) NetworkApplyAddressSets(sets []AddressSet, nftTable string) error {
 	_, err := subprocess.RunCommand("nft", "create", "table", nftTable, nftablesNamespace)
@@ -115,6 +115,39 @@ table {{.family}} {{.namespace}} {
 }
 `))
 
+var nftablesNetLoadBalancer = template.Must(template.New("nftablesNetLoadBalancer").Parse(`
+add table {{.family}} {{.namespace}}
+add chain {{.family}} {{.namespace}} lbprert{{.chainSeparator}}{{.label}} {type nat hook prerouting priority -100; policy accept;}
+add chain {{.family}} {{.namespace}} lbout{{.chainSeparator}}{{.label}} {type nat hook output priority -100; policy accept;}
+add chain {{.family}} {{.namespace}} lbpstrt{{.chainSeparator}}{{.label}} {type nat hook postrouting priority 100; policy accept;}
+flush chain {{.family}} {{.namespace}} lbprert{{.chainSeparator}}{{.label}}
+flush chain {{.family}} {{.namespace}} lbout{{.chainSeparator}}{{.label}}
+flush chain {{.family}} {{.namespace}} lbpstrt{{.chainSeparator}}{{.label}}
+
+table {{.family}} {{.namespace}} {
+	chain lbprert{{.chainSeparator}}{{.label}} {
+		type nat hook prerouting priority -100; policy accept;
+		{{ range .dnatRules }}
+		{{.ipFamily}} daddr {{.listenAddress}} {{.protocol}} dport {{.listenPorts}} dnat {{.ipFamily}} {{ if .withPorts }}addr . port {{ end }}to {{.selector}} map { {{.targets}} }
+		{{ end }}
+	}
+
+	chain lbout{{.chainSeparator}}{{.label}} {
+		type nat hook output priority -100; policy accept;
+		{{ range .dnatRules }}
+		{{.ipFamily}} daddr {{.listenAddress}} {{.protocol}} dport {{.listenPorts}} dnat {{.ipFamily}} {{ if .withPorts }}addr . port {{ end }}to {{.selector}} map { {{.targets}} }
+		{{ end }}
+	}
+
+	chain lbpstrt{{.chainSeparator}}{{.label}} {
+		type nat hook postrouting priority 100; policy accept;
+		{{ range .snatRules }}
+		{{.ipFamily}} saddr {{.targetHost}} {{.ipFamily}} daddr {{.targetHost}} {{.protocol}} dport {{.targetPorts}} masquerade
+		{{ end }}
+	}
+}
+`))
+
 var nftablesNetACLSetup = template.Must(template.New("nftablesNetACLSetup").Parse(`
 add table {{.family}} {{.namespace}}
 add chain {{.family}} {{.namespace}} acl{{.chainSeparator}}{{.networkName}}
@@ -112,6 +112,60 @@ func getOptimisedDNATRanges(forward *AddressForward) map[[2]uint64][2]uint64 {
 	return snatRules
 }
 
+// getLoadBalancerDNATMaps returns a map of listen port ranges to the elements of the map used to pick a backend.
+//
+// When none of the backends change the destination port, the listen port ranges are kept and only the backend
+// addresses are mapped. Otherwise each listen port gets its own map of backend addresses and ports, which is
+// indicated by the second return value.
+func getLoadBalancerDNATMaps(lb *LoadBalancer) (map[[2]uint64][]string, bool, error) {
+	dnatMaps := map[[2]uint64][]string{}
+
+	keepPorts := true
+	for _, backend := range lb.Backends {
+		if len(backend.TargetPorts) > 0 {
+			keepPorts = false
+			break
+		}
+	}
+
+	if keepPorts {
+		elements := make([]string, 0, len(lb.Backends))
+		for i, backend := range lb.Backends {
+			elements = append(elements, fmt.Sprintf("%d : %s", i, backend.TargetAddress.String()))
+		}
+
+		for _, listenPortRange := range portRangesFromSlice(lb.ListenPorts) {
+			dnatMaps[listenPortRange] = elements
+		}
+
+		return dnatMaps, false, nil
+	}
+
+	for portIndex, listenPort := range lb.ListenPorts {
+		elements := make([]string, 0, len(lb.Backends))
+		for i, backend := range lb.Backends {
+			targetPort := listenPort
+
+			switch len(backend.TargetPorts) {
+			case 0:
+				// No target ports specified, use listen port.
+			case 1:
+				targetPort = backend.TargetPorts[0]
+			case len(lb.ListenPorts):
+				targetPort = backend.TargetPorts[portIndex]
+			default:
+				return nil, false, fmt.Errorf("Mismatch between listen port(s) and target port(s) count for backend %q", backend.TargetAddress.String())
+			}
+
+			elements = append(elements, fmt.Sprintf("%d : %s . %d", i, backend.TargetAddress.String(), targetPort))
+		}
+
+		dnatMaps[[2]uint64{listenPort, 1}] = elements
+	}
+
+	return dnatMaps, true, nil
+}
+
 // subnetMask returns the subnet mask of the given network as a string. Both IPv4 and IPv6 are handled.
 func subnetMask(ipNet *net.IPNet) string {
 	if ipNet.IP.To4() != nil {
@@ -2,6 +2,7 @@ package drivers
 
 import (
 	"log"
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -145,3 +146,84 @@ func Test_getOptimisedSNATRanges(t *testing.T) {
 		assert.Equal(t, tt.expected, actual)
 	}
 }
+
+func Test_getLoadBalancerDNATMaps(t *testing.T) {
+	backend1 := net.ParseIP("10.0.0.1")
+	backend2 := net.ParseIP("10.0.0.2")
+
+	tests := []struct {
+		name      string
+		lb        *LoadBalancer
+		expected  map[[2]uint64][]string
+		withPorts bool
+		err       bool
+	}{
+		{
+			name: "Same ports",
+			lb: &LoadBalancer{
+				ListenPorts: []uint64{80, 81, 82, 443},
+				Backends: []LoadBalancerBackend{
+					{TargetAddress: backend1},
+					{TargetAddress: backend2},
+				},
+			},
+			expected: map[[2]uint64][]string{
+				{80, 3}:  {"0 : 10.0.0.1", "1 : 10.0.0.2"},
+				{443, 1}: {"0 : 10.0.0.1", "1 : 10.0.0.2"},
+			},
+		},
+		{
+			name: "Single target port",
+			lb: &LoadBalancer{
+				ListenPorts: []uint64{80, 81},
+				Backends: []LoadBalancerBackend{
+					{TargetAddress: backend1, TargetPorts: []uint64{8080}},
+					{TargetAddress: backend2},
+				},
+			},
+			expected: map[[2]uint64][]string{
+				{80, 1}: {"0 : 10.0.0.1 . 8080", "1 : 10.0.0.2 . 80"},
+				{81, 1}: {"0 : 10.0.0.1 . 8080", "1 : 10.0.0.2 . 81"},
+			},
+			withPorts: true,
+		},
+		{
+			name: "One to one target ports",
+			lb: &LoadBalancer{
+				ListenPorts: []uint64{80, 81},
+				Backends: []LoadBalancerBackend{
+					{TargetAddress: backend1, TargetPorts: []uint64{8080, 8081}},
+					{TargetAddress: backend2, TargetPorts: []uint64{9080, 9081}},
+				},
+			},
+			expected: map[[2]uint64][]string{
+				{80, 1}: {"0 : 10.0.0.1 . 8080", "1 : 10.0.0.2 . 9080"},
+				{81, 1}: {"0 : 10.0.0.1 . 8081", "1 : 10.0.0.2 . 9081"},
+			},
+			withPorts: true,
+		},
+		{
+			name: "Mismatched target ports",
+			lb: &LoadBalancer{
+				ListenPorts: []uint64{80, 81, 82},
+				Backends: []LoadBalancerBackend{
+					{TargetAddress: backend1, TargetPorts: []uint64{8080, 8081}},
+				},
+			},
+			err: true,
+		},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		dnatMaps, withPorts, err := getLoadBalancerDNATMaps(tt.lb)
+		if tt.err {
+			assert.Error(t, err)
+			continue
+		}
+
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, dnatMaps)
+		assert.Equal(t, tt.withPorts, withPorts)
+	}
+}
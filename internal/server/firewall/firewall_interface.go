@@ -24,6 +24,7 @@ type Firewall interface {
 	NetworkClear(networkName string, removeChains bool, ipVersions []uint) error
 	NetworkApplyACLRules(networkName string, rules []drivers.ACLRule) error
 	NetworkApplyForwards(networkName string, rules []drivers.AddressForward) error
+	NetworkApplyLoadBalancers(networkName string, rules []drivers.LoadBalancer) error
 	NetworkApplyAddressSets(sets []drivers.AddressSet, nftTable string) error
 	NetworkDeleteAddressSetsIfUnused(nftTable string) error
 
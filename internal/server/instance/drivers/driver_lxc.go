@@ -9493,3 +9493,18 @@ func (d *lxc) DeleteBitmap(deviceName string, bitmapName string) error {
 func (d *lxc) GetBitmaps(deviceName string) ([]api.StorageVolumeBitmap, error) {
 	return nil, instance.ErrNotImplemented
 }
+
+// ExportBitmap exports the blocks tracked by a dirty bitmap. Not supported by containers.
+func (d *lxc) ExportBitmap(deviceName string, bitmapName string, targetPath string) (func() error, error) {
+	return nil, instance.ErrNotImplemented
+}
+
+// CommitBitmapExport commits the export of a dirty bitmap. Not supported by containers.
+func (d *lxc) CommitBitmapExport(deviceName string, bitmapName string) error {
+	return instance.ErrNotImplemented
+}
+
+// AbortBitmapExport aborts the export of a dirty bitmap. Not supported by containers.
+func (d *lxc) AbortBitmapExport(deviceName string, bitmapName string) error {
+	return instance.ErrNotImplemented
+}
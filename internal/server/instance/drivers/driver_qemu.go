@@ -27,6 +27,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unsafe"
 
@@ -124,6 +125,9 @@ const qemuMountTagPrefix = "incus_"
 // 4 are reserved, and the other 4 can be used for any USB device.
 const qemuSparseUSBPorts = 8
 
+// qemuSnapshotFreezeDuration is the maximum time the guest file systems are kept frozen while taking a snapshot.
+const qemuSnapshotFreezeDuration = 10 * time.Minute
+
 var errQemuAgentOffline = errors.New("VM agent isn't currently running")
 
 type monitorHook func(m *qmp.Monitor) error
@@ -351,6 +355,7 @@ type qemu struct {
 
 	// Stateful migration streams.
 	migrationReceiveStateful map[string]io.ReadWriteCloser
+	migrationReceiveMultifd  []io.ReadWriteCloser
 
 	// Indicate whether the root disk will be live-migrated.
 	migrationRootDisk bool
@@ -994,7 +999,7 @@ func (d *qemu) restoreStateHandle(ctx context.Context, monitor *qmp.Monitor, f *
 		return err
 	}
 
-	err = monitor.MigrateIncoming(ctx, "migration")
+	err = monitor.MigrateIncoming(ctx, qmp.MigrationAddressFD("migration"))
 	if err != nil {
 		if errors.Is(err, qmp.ErrMonitorDisconnect) && util.PathExists(d.LogFilePath()) {
 			qemuError, err := os.ReadFile(d.LogFilePath())
@@ -1103,25 +1108,42 @@ func (d *qemu) restoreState(monitor *qmp.Monitor) error {
 			}()
 		}
 
+		// Allow the source to switch to post-copy.
+		if d.migrationPostcopyPasses() > 0 {
+			err := monitor.MigrateSetCapabilities(map[string]bool{"postcopy-ram": true})
+			if err != nil {
+				return fmt.Errorf("Failed enabling post-copy migration: %w", err)
+			}
+		}
+
 		// Receive checkpoint from QEMU process on source.
 		d.logger.Debug("Stateful migration checkpoint receive starting")
-		pipeRead, pipeWrite, err := os.Pipe()
-		if err != nil {
-			return err
-		}
 
-		go func() {
-			_, _ = util.SafeCopy(pipeWrite, stateConn)
+		var stateCleanup func()
+		var err error
+		if len(d.migrationReceiveMultifd) > 0 {
+			stateCleanup, err = d.restoreStateMultifd(context.Background(), monitor, append([]io.ReadWriteCloser{stateConn}, d.migrationReceiveMultifd...))
+		} else {
+			var stateFile *os.File
+			stateFile, stateCleanup, err = qemuMigrationStateSocket(stateConn)
+			if err != nil {
+				return err
+			}
 
-			_ = pipeRead.Close()
-			_ = pipeWrite.Close()
-		}()
+			err = d.restoreStateHandle(context.Background(), monitor, stateFile)
+		}
 
-		err = d.restoreStateHandle(context.Background(), monitor, pipeRead)
 		if err != nil {
+			stateCleanup()
 			return fmt.Errorf("Failed restoring checkpoint from source: %w", err)
 		}
 
+		// The stream is relayed until the migration completes, which may be after the guest was resumed when using post-copy.
+		go func() {
+			_ = monitor.MigrateWait(context.Background(), "completed")
+			stateCleanup()
+		}()
+
 		d.logger.Debug("Stateful migration checkpoint receive finished")
 	} else {
 		statePath := d.StatePath()
@@ -1177,7 +1199,7 @@ func (d *qemu) saveStateHandle(monitor *qmp.Monitor, f *os.File) error {
 	}
 
 	// Issue the migration command.
-	err = monitor.Migrate("migration")
+	err = monitor.Migrate(qmp.MigrationAddressFD("migration"))
 	if err != nil {
 		return err
 	}
@@ -3168,6 +3190,12 @@ func (d *qemu) migrateSockPath() string {
 	return filepath.Join(d.RunPath(), "migrate.sock")
 }
 
+// migrateMultifdSockPath returns the path of the socket used for the live migration state with multifd.
+// It lives in the devices directory as it must be reachable by the unprivileged QEMU process.
+func (d *qemu) migrateMultifdSockPath() string {
+	return filepath.Join(d.DevicesPath(), "migrate-multifd.sock")
+}
+
 func (d *qemu) spiceCmdlineConfig() string {
 	return fmt.Sprintf("unix=on,disable-ticketing=on,addr=%s", d.spicePath())
 }
@@ -3875,7 +3903,7 @@ func (d *qemu) generateQemuConfig(bs *qemuBootState, mountInfo *storagePools.Mou
 		multifunction: multi,
 	}
 
-	conf = append(conf, qemuBalloon(&balloonOpts)...)
+	conf = append(conf, qemuBalloon(&balloonOpts, util.IsTrue(d.expandedConfig["limits.memory.auto_balloon"]))...)
 
 	devBus, devAddr, multi = bus.allocate(busFunctionGroupGeneric)
 	rngOpts := qemuDevOpts{
@@ -5804,12 +5832,32 @@ func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
 		}
 	}
 
+	// Freeze the guest file systems for an application-consistent snapshot.
+	thaw := func() error { return nil }
+	if !stateful && d.IsRunning() {
+		thaw, err = d.FreezeFilesystems(qemuSnapshotFreezeDuration)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create the snapshot.
 	err = d.snapshotCommon(d, name, expiry, stateful)
+	thawErr := thaw()
 	if err != nil {
 		return err
 	}
 
+	if thawErr != nil {
+		// Don't keep a snapshot which isn't consistent when asked not to.
+		snap, err := instance.LoadByProjectAndName(d.state, d.project.Name, d.name+internalInstance.SnapshotDelimiter+name)
+		if err == nil {
+			_ = snap.Delete(true, true)
+		}
+
+		return thawErr
+	}
+
 	// Resume the VM once the disk state has been saved.
 	if stateful {
 		// Remove the state from the main volume.
@@ -6926,6 +6974,96 @@ func (d *qemu) updateMemoryLimit(newLimit string) error {
 	return fmt.Errorf("Failed setting memory to %dMiB (currently %dMiB) as it was taking too long", newSizeMB, curSizeMB)
 }
 
+// BalanceMemory resizes the memory balloon of the VM when limits.memory.auto_balloon is enabled.
+// When the host is under memory pressure, the VM is shrunk down to its memory usage (as reported by the agent)
+// plus some headroom, but never below limits.memory.auto_balloon.min. Otherwise it is grown back to limits.memory.
+func (d *qemu) BalanceMemory(hostPressure bool) error {
+	if !util.IsTrue(d.expandedConfig["limits.memory.auto_balloon"]) || util.IsTrue(d.expandedConfig["limits.memory.hugepages"]) || !d.IsRunning() {
+		return nil
+	}
+
+	memoryLimitStr := qemudefault.MemSize
+	if d.expandedConfig["limits.memory"] != "" {
+		memoryLimitStr = d.expandedConfig["limits.memory"]
+	}
+
+	memoryLimit, err := ParseMemoryStr(memoryLimitStr)
+	if err != nil {
+		return err
+	}
+
+	memoryMin, err := internalInstance.AutoBalloonMin(d.expandedConfig["limits.memory.auto_balloon.min"], memoryLimit)
+	if err != nil {
+		return err
+	}
+
+	// Connect to the monitor.
+	monitor, err := d.qmpConnect()
+	if err != nil {
+		return err
+	}
+
+	curSizeBytes, err := monitor.GetMemoryBalloonSizeBytes()
+	if err != nil {
+		return err
+	}
+
+	var usedBytes int64
+	if hostPressure {
+		// Get the guest memory usage, leaving the balloon alone if the agent isn't available.
+		if !d.agentMetricsEnabled() {
+			return nil
+		}
+
+		guestMetrics, err := d.agentGetMetrics()
+		if err != nil {
+			if errors.Is(err, errQemuAgentOffline) {
+				return nil
+			}
+
+			return fmt.Errorf("Failed getting guest memory usage: %w", err)
+		}
+
+		if guestMetrics.Memory.MemTotalBytes == 0 || guestMetrics.Memory.MemAvailableBytes > guestMetrics.Memory.MemTotalBytes {
+			return nil
+		}
+
+		usedBytes = int64(guestMetrics.Memory.MemTotalBytes - guestMetrics.Memory.MemAvailableBytes)
+	}
+
+	newSizeBytes, ok := balloonTargetSize(memoryLimit, memoryMin, curSizeBytes, usedBytes, hostPressure)
+	if !ok {
+		return nil
+	}
+
+	d.logger.Debug("Resizing memory balloon", logger.Ctx{"current": curSizeBytes, "target": newSizeBytes, "hostPressure": hostPressure})
+
+	return monitor.SetMemoryBalloonSizeBytes(newSizeBytes)
+}
+
+// balloonTargetSize returns the size to resize the memory balloon of a VM to, and whether it should be resized.
+// Under host memory pressure, the VM is shrunk to its used memory plus some headroom, within memoryMin and
+// memoryLimit. Otherwise it is grown back to memoryLimit.
+func balloonTargetSize(memoryLimit int64, memoryMin int64, curSizeBytes int64, usedBytes int64, hostPressure bool) (int64, bool) {
+	newSizeBytes := memoryLimit
+	if hostPressure {
+		// Keep a quarter of the memory available to the guest.
+		newSizeBytes = min(max(usedBytes/3*4, memoryMin), memoryLimit)
+	}
+
+	// Skip small changes to avoid constantly resizing the balloon.
+	diff := newSizeBytes - curSizeBytes
+	if diff < 0 {
+		diff = -diff
+	}
+
+	if diff < memoryLimit/20 {
+		return -1, false
+	}
+
+	return newSizeBytes, true
+}
+
 // hotplugMemory attaches a memory device to a running VM,
 // respecting NUMA node placement and hugepages.
 func (d *qemu) hotplugMemory(monitor *qmp.Monitor, sizeBytes int64) error {
@@ -7720,12 +7858,19 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 	// Detect whether the far side has chosen to use QEMU to QEMU live state transfer mode, and if so then
 	// wait for the connection to be established.
 	var stateConn io.ReadWriteCloser
+	var multifdConns []io.ReadWriteCloser
 	if args.Live && respHeader.Criu != nil && *respHeader.Criu == migration.CRIUType_VM_QEMU {
 		stateConn, err = args.StateConn(connectionsCtx)
 		if err != nil {
 			op.Done(err)
 			return err
 		}
+
+		multifdConns, err = args.MultifdConns(connectionsCtx)
+		if err != nil {
+			op.Done(err)
+			return err
+		}
 	}
 
 	g, ctx := errgroup.WithContext(context.Background())
@@ -7792,7 +7937,7 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 				defer instanceRefClear(d)
 			}
 
-			err = d.migrateSendLive(ctx, pool, args.ClusterMoveSourceName, args.StoragePool, blockSize, filesystemConn, stateConn, volSourceArgs)
+			err = d.migrateSendLive(ctx, pool, args.ClusterMoveSourceName, args.StoragePool, blockSize, filesystemConn, stateConn, multifdConns, volSourceArgs)
 			if err != nil {
 				return err
 			}
@@ -8070,8 +8215,277 @@ func (d *qemu) sendMigrationSnapshot(diskName string, filesystemConn io.ReadWrit
 	return finalizeFunc, nil
 }
 
+// qemuMigrationStateSocket returns one end of a socket pair to be passed to QEMU for the live migration state
+// stream, relaying it in both directions over the given connection. A bidirectional stream is required for
+// post-copy migration, where the target requests the missing memory pages from the source. The returned
+// function closes both ends of the socket pair.
+func qemuMigrationStateSocket(conn io.ReadWriteCloser) (*os.File, func(), error) {
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed creating migration socket pair: %w", err)
+	}
+
+	qemuFile := os.NewFile(uintptr(fds[0]), "migration")
+	relayFile := os.NewFile(uintptr(fds[1]), "migration-relay")
+
+	relayConn, err := net.FileConn(relayFile)
+	_ = relayFile.Close()
+	if err != nil {
+		_ = qemuFile.Close()
+		return nil, nil, fmt.Errorf("Failed setting up migration socket: %w", err)
+	}
+
+	qemuMigrationRelay(relayConn, conn)
+
+	cleanup := func() {
+		_ = qemuFile.Close()
+		_ = relayConn.Close()
+	}
+
+	return qemuFile, cleanup, nil
+}
+
+// qemuMigrationRelay relays a live migration stream in both directions between a connection to QEMU and a
+// connection to the other server.
+func qemuMigrationRelay(qemuConn net.Conn, conn io.ReadWriteCloser) {
+	go func() {
+		_, _ = util.SafeCopy(qemuConn, conn)
+
+		unixConn, ok := qemuConn.(*net.UnixConn)
+		if ok {
+			_ = unixConn.CloseWrite()
+		}
+	}()
+
+	go func() {
+		_, _ = util.SafeCopy(conn, qemuConn)
+		_ = qemuConn.Close()
+	}()
+}
+
+// migrateSendMultifd starts sending the live migration state over the given connections, the first one being
+// used for the main channel and the others for the multifd channels. QEMU connects to a unix socket once per
+// channel, starting with the main channel, and each of those connections is relayed over the next connection.
+// The returned function stops relaying.
+func (d *qemu) migrateSendMultifd(monitor *qmp.Monitor, conns []io.ReadWriteCloser) (func(), error) {
+	socketPath := d.migrateMultifdSockPath()
+	_ = os.Remove(socketPath)
+
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating migration socket %q: %w", socketPath, err)
+	}
+
+	// Allow the unprivileged QEMU process to connect.
+	if d.state.OS.UnprivUser != "" {
+		err = os.Chown(socketPath, int(d.state.OS.UnprivUID), -1)
+		if err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("Failed setting ownership of migration socket %q: %w", socketPath, err)
+		}
+	}
+
+	var qemuConnsMu sync.Mutex
+	qemuConns := []net.Conn{}
+
+	go func() {
+		for i := 0; i < len(conns); {
+			qemuConn, err := listener.AcceptUnix()
+			if err != nil {
+				return
+			}
+
+			// Only relay the migration stream to the QEMU process of this instance.
+			ucred, err := linux.GetUcred(qemuConn)
+			if err != nil || int(ucred.Pid) != d.InitPID() {
+				d.logger.Warn("Rejecting unexpected connection to migration socket", logger.Ctx{"err": err})
+				_ = qemuConn.Close()
+				continue
+			}
+
+			qemuConnsMu.Lock()
+			qemuConns = append(qemuConns, qemuConn)
+			qemuConnsMu.Unlock()
+
+			qemuMigrationRelay(qemuConn, conns[i])
+			i++
+		}
+	}()
+
+	cleanup := func() {
+		_ = listener.Close()
+		_ = os.Remove(socketPath)
+
+		qemuConnsMu.Lock()
+		for _, qemuConn := range qemuConns {
+			_ = qemuConn.Close()
+		}
+
+		qemuConnsMu.Unlock()
+	}
+
+	err = monitor.Migrate(qmp.MigrationAddressUnix(socketPath))
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	return cleanup, nil
+}
+
+// restoreStateMultifd receives the live migration state over the given connections, the first one being used
+// for the main channel and the others for the multifd channels. QEMU listens on a unix socket to which a
+// connection is made for each channel, starting with the main channel, and relayed over the matching connection.
+// It returns once the migration completed or switched to post-copy, along with a function to stop relaying.
+func (d *qemu) restoreStateMultifd(ctx context.Context, monitor *qmp.Monitor, conns []io.ReadWriteCloser) (func(), error) {
+	err := monitor.MigrateSetCapabilities(map[string]bool{"multifd": true})
+	if err != nil {
+		return func() {}, fmt.Errorf("Failed enabling multifd migration: %w", err)
+	}
+
+	err = monitor.MigrateSetParameters(map[string]any{"multifd-channels": len(conns) - 1})
+	if err != nil {
+		return func() {}, fmt.Errorf("Failed setting multifd migration channels: %w", err)
+	}
+
+	socketPath := d.migrateMultifdSockPath()
+	_ = os.Remove(socketPath)
+
+	var qemuConnsMu sync.Mutex
+	qemuConns := []net.Conn{}
+
+	relayCtx, relayCancel := context.WithCancel(ctx)
+	cleanup := func() {
+		relayCancel()
+		_ = os.Remove(socketPath)
+
+		qemuConnsMu.Lock()
+		for _, qemuConn := range qemuConns {
+			_ = qemuConn.Close()
+		}
+
+		qemuConnsMu.Unlock()
+	}
+
+	// Connect each channel once QEMU listens on the socket.
+	go func() {
+		for _, conn := range conns {
+			var qemuConn net.Conn
+			var err error
+
+			for range 300 {
+				qemuConn, err = (&net.Dialer{}).DialContext(relayCtx, "unix", socketPath)
+				if err == nil || relayCtx.Err() != nil {
+					break
+				}
+
+				time.Sleep(100 * time.Millisecond)
+			}
+
+			if err != nil {
+				d.logger.Warn("Failed connecting to migration socket", logger.Ctx{"err": err})
+				return
+			}
+
+			qemuConnsMu.Lock()
+			qemuConns = append(qemuConns, qemuConn)
+			qemuConnsMu.Unlock()
+
+			qemuMigrationRelay(qemuConn, conn)
+		}
+	}()
+
+	err = monitor.MigrateIncoming(ctx, qmp.MigrationAddressUnix(socketPath))
+	if err != nil {
+		return cleanup, err
+	}
+
+	return cleanup, nil
+}
+
+// migrateSetParameters sets the capabilities and parameters used when live migrating the VM.
+func (d *qemu) migrateSetParameters(monitor *qmp.Monitor, pauseBeforeSwitchover bool) error {
+	capabilities := map[string]bool{
+		// Automatically throttle down the guest to speed up convergence of RAM migration.
+		"auto-converge": util.IsTrueOrEmpty(d.expandedConfig["migration.stateful.auto_converge"]),
+
+		// Allow switching to post-copy after a number of pre-copy passes.
+		"postcopy-ram": d.migrationPostcopyPasses() > 0,
+
+		// Transfer memory over multiple connections.
+		"multifd": d.migrationMultifdChannels() > 0,
+	}
+
+	if capabilities["postcopy-ram"] && capabilities["multifd"] {
+		return errors.New("Post-copy and multifd live migration can't be used together")
+	}
+
+	if pauseBeforeSwitchover {
+		capabilities["pause-before-switchover"] = true
+	}
+
+	err := monitor.MigrateSetCapabilities(capabilities)
+	if err != nil {
+		return fmt.Errorf("Failed setting migration capabilities: %w", err)
+	}
+
+	parameters := map[string]any{
+		"cpu-throttle-initial":       50,
+		"cpu-throttle-increment":     10,
+		"throttle-trigger-threshold": 20,
+	}
+
+	for key, parameter := range map[string]string{
+		"migration.stateful.auto_converge.initial":   "cpu-throttle-initial",
+		"migration.stateful.auto_converge.increment": "cpu-throttle-increment",
+		"migration.stateful.max_downtime":            "downtime-limit",
+	} {
+		if d.expandedConfig[key] == "" {
+			continue
+		}
+
+		value, err := strconv.ParseInt(d.expandedConfig[key], 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid value for %q: %w", key, err)
+		}
+
+		parameters[parameter] = value
+	}
+
+	if capabilities["multifd"] {
+		parameters["multifd-channels"] = d.migrationMultifdChannels()
+	}
+
+	err = monitor.MigrateSetParameters(parameters)
+	if err != nil {
+		return fmt.Errorf("Failed setting migration parameters: %w", err)
+	}
+
+	return nil
+}
+
+// migrationPostcopyPasses returns the number of pre-copy passes after which to switch to post-copy (0 if disabled).
+func (d *qemu) migrationPostcopyPasses() int64 {
+	passes, err := strconv.ParseInt(d.expandedConfig["migration.stateful.postcopy"], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return passes
+}
+
+// migrationMultifdChannels returns the number of multifd channels to use for live migration (0 if disabled).
+func (d *qemu) migrationMultifdChannels() int64 {
+	channels, err := strconv.ParseInt(d.expandedConfig["migration.stateful.multifd"], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return channels
+}
+
 // migrateSendLive performs live migration send process.
-func (d *qemu) migrateSendLive(ctx context.Context, pool storagePools.Pool, clusterMoveSourceName string, storagePool string, rootDiskSize int64, filesystemConn io.ReadWriteCloser, stateConn io.ReadWriteCloser, volSourceArgs *localMigration.VolumeSourceArgs) error {
+func (d *qemu) migrateSendLive(ctx context.Context, pool storagePools.Pool, clusterMoveSourceName string, storagePool string, rootDiskSize int64, filesystemConn io.ReadWriteCloser, stateConn io.ReadWriteCloser, multifdConns []io.ReadWriteCloser, volSourceArgs *localMigration.VolumeSourceArgs) error {
 	monitor, err := d.qmpConnect()
 	if err != nil {
 		return err
@@ -8094,35 +8508,18 @@ func (d *qemu) migrateSendLive(ctx context.Context, pool storagePools.Pool, clus
 
 	reverter := revert.New()
 
+	// Setup migration capabilities and parameters.
+	// For non-shared storage, allow the migration to be paused after the source qemu releases the block devices
+	// but before the serialisation of the device state, to avoid a race condition between migration and
+	// blockdev-mirror. This requires that the migration be continued after it has reached the "pre-switchover"
+	// status.
+	err = d.migrateSetParameters(monitor, !sameSharedStorage || dependentVolumeMove)
+	if err != nil {
+		return err
+	}
+
 	// Non-shared storage snapshot setup.
 	if !sameSharedStorage || dependentVolumeMove {
-		// Setup migration capabilities.
-		capabilities := map[string]bool{
-			// Automatically throttle down the guest to speed up convergence of RAM migration.
-			"auto-converge": true,
-
-			// Allow the migration to be paused after the source qemu releases the block devices but
-			// before the serialisation of the device state, to avoid a race condition between
-			// migration and blockdev-mirror. This requires that the migration be continued after it
-			// has reached the "pre-switchover" status.
-			"pause-before-switchover": true,
-		}
-
-		err = monitor.MigrateSetCapabilities(capabilities)
-		if err != nil {
-			return fmt.Errorf("Failed setting migration capabilities: %w", err)
-		}
-
-		parameters := map[string]any{
-			"cpu-throttle-initial":       50,
-			"throttle-trigger-threshold": 20,
-		}
-
-		err = monitor.MigrateSetParameters(parameters)
-		if err != nil {
-			return fmt.Errorf("Failed setting migration parameters: %w", err)
-		}
-
 		if !sameSharedStorage {
 			cleanup, err := d.createEphemeralSnapshot(rootDiskName, rootDiskSize)
 			if err != nil {
@@ -8146,27 +8543,6 @@ func (d *qemu) migrateSendLive(ctx context.Context, pool storagePools.Pool, clus
 		}
 
 		d.logger.Debug("Setup temporary migration storage snapshot")
-	} else {
-		// Still set some options for shared storage.
-		capabilities := map[string]bool{
-			// Automatically throttle down the guest to speed up convergence of RAM migration.
-			"auto-converge": true,
-		}
-
-		err = monitor.MigrateSetCapabilities(capabilities)
-		if err != nil {
-			return fmt.Errorf("Failed setting migration capabilities: %w", err)
-		}
-
-		parameters := map[string]any{
-			"cpu-throttle-initial":       50,
-			"throttle-trigger-threshold": 20,
-		}
-
-		err = monitor.MigrateSetParameters(parameters)
-		if err != nil {
-			return fmt.Errorf("Failed setting migration parameters: %w", err)
-		}
 	}
 
 	// Perform storage transfer while instance is still running.
@@ -8231,61 +8607,90 @@ func (d *qemu) migrateSendLive(ctx context.Context, pool storagePools.Pool, clus
 	d.logger.Debug("Stateful migration checkpoint send starting")
 
 	// Send checkpoint to QEMU process on target. This will pause the guest OS (if not already paused).
-	pipeRead, pipeWrite, err := os.Pipe()
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		_ = pipeRead.Close()
-		_ = pipeWrite.Close()
-	}()
-
-	go func() { _, _ = util.SafeCopy(stateConn, pipeRead) }()
+	var stateCleanup func()
+	if len(multifdConns) > 0 {
+		stateCleanup, err = d.migrateSendMultifd(monitor, append([]io.ReadWriteCloser{stateConn}, multifdConns...))
+		if err != nil {
+			return fmt.Errorf("Failed starting state transfer to target: %w", err)
+		}
+	} else {
+		var stateFile *os.File
+		stateFile, stateCleanup, err = qemuMigrationStateSocket(stateConn)
+		if err != nil {
+			return err
+		}
 
-	err = d.saveStateHandle(monitor, pipeWrite)
-	if err != nil {
-		return fmt.Errorf("Failed starting state transfer to target: %w", err)
+		err = d.saveStateHandle(monitor, stateFile)
+		if err != nil {
+			stateCleanup()
+			return fmt.Errorf("Failed starting state transfer to target: %w", err)
+		}
 	}
 
+	defer stateCleanup()
+
 	// Start monitoring the migration progress.
 	chMonitor := make(chan bool, 1)
+	postcopyPasses := d.migrationPostcopyPasses()
 
-	if d.op != nil {
-		go func() {
-			for {
-				// Wait for next update.
-				select {
-				case <-chMonitor:
-					return
+	go func() {
+		postcopy := false
 
-				case <-time.After(time.Second):
-				}
+		for {
+			// Wait for next update.
+			select {
+			case <-chMonitor:
+				return
+
+			case <-time.After(time.Second):
+			}
 
-				// Get current migration progress.
-				progress, err := monitor.QueryMigrate()
+			// Get current migration progress.
+			progress, err := monitor.QueryMigrate()
+			if err != nil {
+				// Stop monitoring on error.
+				return
+			}
+
+			// Switch to post-copy once enough pre-copy passes were done.
+			if postcopyPasses > 0 && !postcopy && progress.Status == "active" && progress.RAM.DirtySyncCount >= postcopyPasses {
+				d.logger.Debug("Switching live migration to post-copy", logger.Ctx{"passes": progress.RAM.DirtySyncCount})
+
+				err = monitor.MigrateStartPostcopy()
 				if err != nil {
-					// Stop monitoring on error.
-					return
+					d.logger.Warn("Failed switching live migration to post-copy", logger.Ctx{"err": err})
 				}
 
-				// Post update.
-				percent := int64(float64(progress.RAM.Transferred) / float64(progress.RAM.Total) * float64(100))
-				speed := int64(progress.RAM.MBps * 1024 * 1024 / 8)
+				postcopy = true
+			}
 
-				metadata := map[string]any{}
-				metadata["progress"] = map[string]string{
-					"stage":     "live_migrate_instance",
-					"processed": strconv.FormatInt(progress.RAM.Transferred, 10),
-					"percent":   strconv.FormatInt(percent, 10),
-					"speed":     strconv.FormatInt(speed, 10),
-				}
+			if d.op == nil {
+				continue
+			}
+
+			// Post update.
+			percent := int64(float64(progress.RAM.Transferred) / float64(progress.RAM.Total) * float64(100))
+			speed := int64(progress.RAM.MBps * 1024 * 1024 / 8)
+			dirtyRate := progress.RAM.DirtyPagesRate * progress.RAM.PageSize
 
-				metadata["live_migrate_instance_progress"] = fmt.Sprintf("Live migration: %s remaining (%s/s) (%d%% CPU throttle)", units.GetByteSizeString(progress.RAM.Remaining, 2), units.GetByteSizeString(speed, 2), progress.CPUThrottlePercentage)
-				_ = d.op.UpdateMetadata(metadata)
+			metadata := map[string]any{}
+			metadata["progress"] = map[string]string{
+				"stage":      "live_migrate_instance",
+				"processed":  strconv.FormatInt(progress.RAM.Transferred, 10),
+				"percent":    strconv.FormatInt(percent, 10),
+				"speed":      strconv.FormatInt(speed, 10),
+				"remaining":  strconv.FormatInt(progress.RAM.Remaining, 10),
+				"dirty_rate": strconv.FormatInt(dirtyRate, 10),
+			}
+
+			metadata["live_migrate_instance_progress"] = fmt.Sprintf("Live migration: %s remaining (%s/s) (%s/s dirty rate) (%d%% CPU throttle)", units.GetByteSizeString(progress.RAM.Remaining, 2), units.GetByteSizeString(speed, 2), units.GetByteSizeString(dirtyRate, 2), progress.CPUThrottlePercentage)
+			if progress.Status == "postcopy-active" {
+				metadata["live_migrate_instance_progress"] = fmt.Sprintf("Live migration (post-copy): %s remaining (%s/s)", units.GetByteSizeString(progress.RAM.Remaining, 2), units.GetByteSizeString(speed, 2))
 			}
-		}()
-	}
+
+			_ = d.op.UpdateMetadata(metadata)
+		}
+	}()
 
 	// Non-shared storage snapshot transfer finalization.
 	if !sameSharedStorage || dependentVolumeMove {
@@ -8522,11 +8927,17 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 
 	// Establish state transfer connection if needed.
 	var stateConn io.ReadWriteCloser
+	var multifdConns []io.ReadWriteCloser
 	if args.Live && useStateConn {
 		stateConn, err = args.StateConn(connectionsCtx)
 		if err != nil {
 			return err
 		}
+
+		multifdConns, err = args.MultifdConns(connectionsCtx)
+		if err != nil {
+			return err
+		}
 	}
 
 	reverter := revert.New()
@@ -8777,6 +9188,8 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 					api.SecretNameState: stateConn,
 				}
 
+				d.migrationReceiveMultifd = multifdConns
+
 				for _, vol := range dependentVolumes {
 					d.disksToMigrate = append(d.disksToMigrate, vol)
 				}
@@ -9415,6 +9828,140 @@ func (d *qemu) agentGetState() (*api.InstanceState, error) {
 	return status, nil
 }
 
+// Inventory connects to the agent inside of the VM to get the guest software inventory.
+func (d *qemu) Inventory() (*api.InstanceInventory, error) {
+	client, err := d.getAgentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	agent, err := incus.ConnectIncusHTTPWithContext(ctx, nil, client)
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to agent: %w", err)
+	}
+
+	defer agent.Disconnect()
+
+	inventory, err := agent.GetInstanceInventory("")
+	if err != nil {
+		return nil, err
+	}
+
+	return inventory, nil
+}
+
+// FreezeFilesystems asks the agent to freeze the guest file systems (running the guest's freeze hooks) and
+// returns a function to thaw them. The guest automatically thaws its file systems after maxDuration.
+// Depending on snapshots.freeze, failing to freeze the file systems, or finding out when thawing them that the
+// guest already did so on its own, is either an error or only logged.
+func (d *qemu) FreezeFilesystems(maxDuration time.Duration) (func() error, error) {
+	mode := d.expandedConfig["snapshots.freeze"]
+	if (mode != "auto" && mode != "required") || !d.IsRunning() {
+		return func() error { return nil }, nil
+	}
+
+	err := d.agentFreeze(maxDuration)
+	if err != nil {
+		// Without an answer from the agent, the guest may have frozen after we gave up on it.
+		if !errors.Is(err, errQemuAgentOffline) && !api.StatusErrorCheck(err) {
+			_ = d.agentThaw()
+		}
+
+		if mode == "required" {
+			return nil, fmt.Errorf("Failed freezing guest file systems: %w", err)
+		}
+
+		d.logger.Warn("Failed freezing guest file systems, falling back to crash-consistent copy", logger.Ctx{"err": err})
+
+		return func() error { return nil }, nil
+	}
+
+	return func() error {
+		err := d.agentThaw()
+		if err == nil {
+			return nil
+		}
+
+		// The guest gave up waiting and thawed its file systems while they were being copied.
+		if api.StatusErrorCheck(err, http.StatusConflict) {
+			if mode == "required" {
+				return fmt.Errorf("Guest file systems were thawed after %s, before the copy completed", maxDuration)
+			}
+
+			d.logger.Warn("Guest file systems were thawed before the copy completed, falling back to crash-consistent copy", logger.Ctx{"maxDuration": maxDuration})
+
+			return nil
+		}
+
+		d.logger.Warn("Failed thawing guest file systems", logger.Ctx{"err": err})
+
+		return nil
+	}, nil
+}
+
+// agentFreeze asks the agent to freeze the guest file systems, waiting up to snapshots.freeze.timeout.
+func (d *qemu) agentFreeze(maxDuration time.Duration) error {
+	timeout := 30 * time.Second
+	if d.expandedConfig["snapshots.freeze.timeout"] != "" {
+		value, err := strconv.ParseUint(d.expandedConfig["snapshots.freeze.timeout"], 10, 32)
+		if err != nil {
+			return err
+		}
+
+		timeout = time.Duration(value) * time.Second
+	}
+
+	client, err := d.getAgentClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	agent, err := incus.ConnectIncusHTTPWithContext(ctx, nil, client)
+	if err != nil {
+		return fmt.Errorf("Failed connecting to agent: %w", err)
+	}
+
+	defer agent.Disconnect()
+
+	_, _, err = agent.RawQuery("POST", "/1.0/freeze", agentAPI.FreezePost{Timeout: int64(maxDuration.Seconds())}, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// agentThaw asks the agent to thaw the guest file systems.
+func (d *qemu) agentThaw() error {
+	client, err := d.getAgentClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	agent, err := incus.ConnectIncusHTTPWithContext(ctx, nil, client)
+	if err != nil {
+		return fmt.Errorf("Failed connecting to agent: %w", err)
+	}
+
+	defer agent.Disconnect()
+
+	_, _, err = agent.RawQuery("POST", "/1.0/thaw", nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // IsRunning returns whether or not the instance is running.
 func (d *qemu) IsRunning() bool {
 	return d.isRunningStatusCode(d.statusCode())
@@ -10219,6 +10766,21 @@ func (d *qemu) Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, erro
 }
 
 func (d *qemu) getAgentMetrics() (*metrics.MetricSet, error) {
+	m, err := d.agentGetMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	metricSet, err := metrics.MetricSetFromAPI(m, map[string]string{"project": d.project.Name, "name": d.name, "type": instancetype.VM.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	return metricSet, nil
+}
+
+// agentGetMetrics connects to the agent inside of the VM and retrieves its raw metrics.
+func (d *qemu) agentGetMetrics() (*metrics.Metrics, error) {
 	client, err := d.getAgentClient()
 	if err != nil {
 		return nil, err
@@ -10252,12 +10814,7 @@ func (d *qemu) getAgentMetrics() (*metrics.MetricSet, error) {
 		return nil, err
 	}
 
-	metricSet, err := metrics.MetricSetFromAPI(&m, map[string]string{"project": d.project.Name, "name": d.name, "type": instancetype.VM.String()})
-	if err != nil {
-		return nil, err
-	}
-
-	return metricSet, nil
+	return &m, nil
 }
 
 func (d *qemu) getNetworkState() (map[string]api.InstanceStateNetwork, error) {
@@ -11217,8 +11774,16 @@ func (d *qemu) ConnectNBD(diskName string, volSize int64, writable bool) (net.Co
 	return nbdConn, cleanup, nil
 }
 
+// qemuPendingBitmapSuffix is appended to a bitmap name to get the name of the bitmap recording the writes
+// made while an export of that bitmap is in progress.
+const qemuPendingBitmapSuffix = ".pending"
+
 // CreateBitmap creates a dirty bitmap.
 func (d *qemu) CreateBitmap(deviceNames []string, data api.StorageVolumeBitmapsPost) error {
+	if strings.HasSuffix(data.Name, qemuPendingBitmapSuffix) {
+		return api.StatusErrorf(http.StatusBadRequest, "Bitmap names ending with %q are reserved", qemuPendingBitmapSuffix)
+	}
+
 	monitor, err := d.qmpConnect()
 	if err != nil {
 		return err
@@ -11296,6 +11861,10 @@ func (d *qemu) GetBitmaps(deviceName string) ([]api.StorageVolumeBitmap, error)
 	for _, block := range blocks {
 		if block.Inserted.NodeName == blockName {
 			for _, bitmap := range block.Inserted.DirtyBitmaps {
+				if strings.HasSuffix(bitmap.Name, qemuPendingBitmapSuffix) {
+					continue
+				}
+
 				result = append(result, api.StorageVolumeBitmap{
 					Name:         bitmap.Name,
 					Count:        bitmap.Count,
@@ -11313,3 +11882,187 @@ func (d *qemu) GetBitmaps(deviceName string) ([]api.StorageVolumeBitmap, error)
 
 	return nil, fmt.Errorf("Requested device not found")
 }
+
+// ExportBitmap starts writing the blocks marked as dirty in a bitmap to a new qcow2 image at targetPath and
+// returns a function waiting for the export to complete. The blocks are exported as they were when the
+// function returns, so the guest only needs to be frozen until then for a consistent export.
+// Only the dirty clusters are allocated in the resulting image. The bitmap itself is left untouched and
+// a pending bitmap records the writes made from the start of the export. Once the exported data is safely
+// stored, CommitBitmapExport must be called to move over to the pending bitmap, otherwise
+// AbortBitmapExport discards it and the next export still covers all the changes.
+func (d *qemu) ExportBitmap(deviceName string, bitmapName string, targetPath string) (func() error, error) {
+	monitor, err := d.qmpConnect()
+	if err != nil {
+		return nil, err
+	}
+
+	escapedDeviceName := linux.PathNameEncode(deviceName)
+	nodeName := d.blockNodeName(escapedDeviceName)
+
+	blockDevs, err := d.fetchBlockDeviceChain(monitor, nodeName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching disk chain: %w", err)
+	}
+
+	blockName := blockDevs[len(blockDevs)-1]
+
+	blocks, err := monitor.QueryBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var diskSize int64
+	var bitmapFound bool
+	for _, block := range blocks {
+		if block.Inserted.NodeName != blockName {
+			continue
+		}
+
+		diskSize = block.Inserted.Image.VirtualSize
+		for _, bitmap := range block.Inserted.DirtyBitmaps {
+			if bitmap.Name == bitmapName {
+				bitmapFound = true
+				break
+			}
+		}
+	}
+
+	if diskSize <= 0 {
+		return nil, fmt.Errorf("Requested device not found")
+	}
+
+	if !bitmapFound {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Bitmap %q not found", bitmapName)
+	}
+
+	// Create the target image with the same virtual size as the source disk.
+	_, err = subprocess.RunCommand("qemu-img", "create", "-f", "qcow2", targetPath, fmt.Sprintf("%d", diskSize))
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating bitmap export image %q: %w", targetPath, err)
+	}
+
+	// Pass the target file to the running QEMU process.
+	targetFile, err := os.OpenFile(targetPath, unix.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("Failed opening bitmap export image %q: %w", targetPath, err)
+	}
+
+	defer func() { _ = targetFile.Close() }()
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	targetNodeName := d.blockNodeName(escapedDeviceName + "_export")
+
+	info, err := monitor.SendFileWithFDSet(targetNodeName, targetFile, false)
+	if err != nil {
+		return nil, fmt.Errorf("Failed sending file descriptor of %q for bitmap export: %w", targetPath, err)
+	}
+
+	reverter.Add(func() { _ = monitor.RemoveFDFromFDSet(targetNodeName) })
+
+	_ = targetFile.Close()
+
+	// Add the target image as a block device (not visible to the guest OS).
+	err = monitor.AddBlockDevice(map[string]any{
+		"driver":    "qcow2",
+		"node-name": targetNodeName,
+		"read-only": false,
+		"file": map[string]any{
+			"driver":   "file",
+			"filename": fmt.Sprintf("/dev/fdset/%d", info.ID),
+		},
+	}, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("Failed adding bitmap export block device: %w", err)
+	}
+
+	reverter.Add(func() {
+		err := monitor.RemoveBlockDevice(targetNodeName)
+		if err != nil {
+			d.logger.Error("Failed removing bitmap export block device", logger.Ctx{"err": err})
+		}
+	})
+
+	// Remove any pending bitmap left behind by an interrupted export.
+	pendingBitmapName := bitmapName + qemuPendingBitmapSuffix
+	_ = monitor.RemoveDirtyBitmap(blockName, pendingBitmapName)
+
+	waitJob, err := monitor.BlockDevBackup(blockName, targetNodeName, bitmapName, pendingBitmapName)
+	if err != nil {
+		_ = monitor.RemoveDirtyBitmap(blockName, pendingBitmapName)
+		return nil, fmt.Errorf("Failed exporting bitmap %q: %w", bitmapName, err)
+	}
+
+	cleanup := reverter.Clone().Fail
+	reverter.Success()
+
+	wait := sync.OnceValue(func() error {
+		defer cleanup()
+
+		err := waitJob()
+		if err != nil {
+			_ = monitor.RemoveDirtyBitmap(blockName, pendingBitmapName)
+			return fmt.Errorf("Failed exporting bitmap %q: %w", bitmapName, err)
+		}
+
+		return nil
+	})
+
+	return wait, nil
+}
+
+// bitmapBlockName returns the name of the block node holding the bitmaps of a disk device.
+func (d *qemu) bitmapBlockName(monitor *qmp.Monitor, deviceName string) (string, error) {
+	escapedDeviceName := linux.PathNameEncode(deviceName)
+	nodeName := d.blockNodeName(escapedDeviceName)
+
+	blockDevs, err := d.fetchBlockDeviceChain(monitor, nodeName)
+	if err != nil {
+		return "", fmt.Errorf("Failed fetching disk chain: %w", err)
+	}
+
+	return blockDevs[len(blockDevs)-1], nil
+}
+
+// CommitBitmapExport replaces the content of an exported bitmap with the writes recorded since the
+// start of its last export.
+func (d *qemu) CommitBitmapExport(deviceName string, bitmapName string) error {
+	monitor, err := d.qmpConnect()
+	if err != nil {
+		return err
+	}
+
+	blockName, err := d.bitmapBlockName(monitor, deviceName)
+	if err != nil {
+		return err
+	}
+
+	err = monitor.ResetDirtyBitmap(blockName, bitmapName, bitmapName+qemuPendingBitmapSuffix)
+	if err != nil {
+		return fmt.Errorf("Failed committing export of bitmap %q: %w", bitmapName, err)
+	}
+
+	return nil
+}
+
+// AbortBitmapExport discards the writes recorded since the start of the last export of a bitmap,
+// leaving the bitmap as it was before the export.
+func (d *qemu) AbortBitmapExport(deviceName string, bitmapName string) error {
+	monitor, err := d.qmpConnect()
+	if err != nil {
+		return err
+	}
+
+	blockName, err := d.bitmapBlockName(monitor, deviceName)
+	if err != nil {
+		return err
+	}
+
+	err = monitor.RemoveDirtyBitmap(blockName, bitmapName+qemuPendingBitmapSuffix)
+	if err != nil {
+		return fmt.Errorf("Failed aborting export of bitmap %q: %w", bitmapName, err)
+	}
+
+	return nil
+}
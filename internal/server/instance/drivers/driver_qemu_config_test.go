@@ -253,10 +253,12 @@ func TestQemuConfigTemplates(t *testing.T) {
 
 	t.Run("qemu_balloon", func(t *testing.T) {
 		testCases := []struct {
-			opts     qemuDevOpts
-			expected string
+			opts              qemuDevOpts
+			freePageReporting bool
+			expected          string
 		}{{
 			qemuDevOpts{"pcie", "qemu_pcie0", "00.0", true},
+			false,
 			`# Balloon driver
 			[device "qemu_balloon"]
 			addr = "00.0"
@@ -266,13 +268,25 @@ func TestQemuConfigTemplates(t *testing.T) {
 			`,
 		}, {
 			qemuDevOpts{"ccw", "qemu_pcie0", "00.0", false},
+			false,
 			`# Balloon driver
 			[device "qemu_balloon"]
 			driver = "virtio-balloon-ccw"
 			`,
+		}, {
+			qemuDevOpts{"pcie", "qemu_pcie0", "00.0", true},
+			true,
+			`# Balloon driver
+			[device "qemu_balloon"]
+			addr = "00.0"
+			bus = "qemu_pcie0"
+			driver = "virtio-balloon-pci"
+			free-page-reporting = "on"
+			multifunction = "on"
+			`,
 		}}
 		for _, tc := range testCases {
-			runTest(tc.expected, qemuBalloon(&tc.opts))
+			runTest(tc.expected, qemuBalloon(&tc.opts, tc.freePageReporting))
 		}
 	})
 
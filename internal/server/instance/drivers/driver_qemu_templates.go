@@ -329,17 +329,22 @@ func qemuSCSI(opts *qemuDevOpts, numQueues int) []cfg.Section {
 	}}
 }
 
-func qemuBalloon(opts *qemuDevOpts) []cfg.Section {
+func qemuBalloon(opts *qemuDevOpts, freePageReporting bool) []cfg.Section {
 	entriesOpts := qemuDevEntriesOpts{
 		dev:     *opts,
 		pciName: "virtio-balloon-pci",
 		ccwName: "virtio-balloon-ccw",
 	}
 
+	entries := qemuDeviceEntries(&entriesOpts)
+	if freePageReporting {
+		entries["free-page-reporting"] = "on"
+	}
+
 	return []cfg.Section{{
 		Name:    `device "qemu_balloon"`,
 		Comment: "Balloon driver",
-		Entries: qemuDeviceEntries(&entriesOpts),
+		Entries: entries,
 	}}
 }
 
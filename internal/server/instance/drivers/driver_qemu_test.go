@@ -0,0 +1,69 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
+)
+
+const testGiB = int64(1024 * 1024 * 1024)
+
+// Test AutoBalloonMin as used by BalanceMemory.
+func TestAutoBalloonMin(t *testing.T) {
+	value, err := internalInstance.AutoBalloonMin("", 4*testGiB)
+	assert.NoError(t, err)
+	assert.Equal(t, testGiB, value)
+
+	value, err = internalInstance.AutoBalloonMin("50%", 4*testGiB)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*testGiB, value)
+
+	value, err = internalInstance.AutoBalloonMin("100%", 4*testGiB)
+	assert.NoError(t, err)
+	assert.Equal(t, 4*testGiB, value)
+
+	value, err = internalInstance.AutoBalloonMin("512MiB", 4*testGiB)
+	assert.NoError(t, err)
+	assert.Equal(t, testGiB/2, value)
+
+	// Fixed values are capped to the memory limit.
+	value, err = internalInstance.AutoBalloonMin("8GiB", 4*testGiB)
+	assert.NoError(t, err)
+	assert.Equal(t, 4*testGiB, value)
+
+	for _, invalid := range []string{"0%", "101%", "-5%", "half%", "lots"} {
+		_, err = internalInstance.AutoBalloonMin(invalid, 4*testGiB)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// Test balloonTargetSize.
+func TestBalloonTargetSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		curSize      int64
+		used         int64
+		hostPressure bool
+		want         int64
+		wantResize   bool
+	}{
+		{name: "no pressure at full size", curSize: 4 * testGiB},
+		{name: "no pressure grows back", curSize: testGiB, want: 4 * testGiB, wantResize: true},
+		{name: "pressure shrinks with headroom", curSize: 4 * testGiB, used: 3 * testGiB / 2, hostPressure: true, want: 2 * testGiB, wantResize: true},
+		{name: "pressure never below the minimum", curSize: 4 * testGiB, used: testGiB / 4, hostPressure: true, want: testGiB, wantResize: true},
+		{name: "pressure never above the limit", curSize: 2 * testGiB, used: 4 * testGiB, hostPressure: true, want: 4 * testGiB, wantResize: true},
+		{name: "small change skipped", curSize: 2*testGiB + testGiB/10, used: 3 * testGiB / 2, hostPressure: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, resize := balloonTargetSize(4*testGiB, testGiB, tt.curSize, tt.used, tt.hostPressure)
+			assert.Equal(t, tt.wantResize, resize)
+			if tt.wantResize {
+				assert.Equal(t, tt.want, size)
+			}
+		})
+	}
+}
@@ -114,9 +114,9 @@ type MigrationStatus struct {
 		Duplicate               int64   `json:"duplicate"`
 		Normal                  int64   `json:"normal"`
 		NormalBytes             int64   `json:"normal-bytes"`
-		DirtyPagesRate          int64   `json:"diry-pages-rate"`
+		DirtyPagesRate          int64   `json:"dirty-pages-rate"`
 		MBps                    float64 `json:"mbps"`
-		DirtySyncCount          int64   `json:"diry-sync-count"`
+		DirtySyncCount          int64   `json:"dirty-sync-count"`
 		PostcopyRequests        int64   `json:"postcopy-requests"`
 		PageSize                int64   `json:"page-size"`
 		MultiFDBytes            int64   `json:"multifd-bytes"`
@@ -124,7 +124,7 @@ type MigrationStatus struct {
 		PrecopyBytes            int64   `json:"precopy-bytes"`
 		DowntimeBytes           int64   `json:"downtime-bytes"`
 		PostcopyBytes           int64   `json:"postcopy-bytes"`
-		DirtySyncMissedZeroCopy int64   `json:"diry-sync-missed-zero-copy"`
+		DirtySyncMissedZeroCopy int64   `json:"dirty-sync-missed-zero-copy"`
 	} `json:"ram"`
 	TotalTime                      int64   `json:"total-time"`
 	DownTime                       int64   `json:"down-time"`
@@ -154,10 +154,16 @@ type BlockDirtyInfo struct {
 	Inconsistent bool   `json:"inconsistent"`
 }
 
+// BlockImageInfo contains information about the image of a block device.
+type BlockImageInfo struct {
+	VirtualSize int64 `json:"virtual-size"`
+}
+
 // BlockDeviceInfo contains information about the backing device for a block device.
 type BlockDeviceInfo struct {
 	NodeName     string           `json:"node-name"`
 	DirtyBitmaps []BlockDirtyInfo `json:"dirty-bitmaps"`
+	Image        BlockImageInfo   `json:"image"`
 }
 
 // BlockInfo contains information about a virtual block device.
@@ -466,8 +472,27 @@ func (m *Monitor) MigrateSetParameters(parameters map[string]any) error {
 	return nil
 }
 
+// MigrationAddressFD returns the address of a migration channel using a file descriptor previously passed to QEMU.
+func MigrationAddressFD(name string) map[string]string {
+	return map[string]string{
+		"transport": "socket",
+		"type":      "fd",
+		"str":       name,
+	}
+}
+
+// MigrationAddressUnix returns the address of a migration channel using a unix socket.
+// Unlike file descriptors, this allows for multiple connections, as used by multifd.
+func MigrationAddressUnix(path string) map[string]string {
+	return map[string]string{
+		"transport": "socket",
+		"type":      "unix",
+		"path":      path,
+	}
+}
+
 // Migrate starts a migration stream.
-func (m *Monitor) Migrate(name string) error {
+func (m *Monitor) Migrate(address map[string]string) error {
 	// Query the status.
 
 	type migrateArgsChannel struct {
@@ -482,11 +507,7 @@ func (m *Monitor) Migrate(name string) error {
 	args := migrateArgs{}
 	args.Channels = []migrateArgsChannel{{
 		ChannelType: "main",
-		Address: map[string]string{
-			"transport": "socket",
-			"type":      "fd",
-			"str":       name,
-		},
+		Address:     address,
 	}}
 
 	err := m.Run("migrate", args, nil)
@@ -497,6 +518,16 @@ func (m *Monitor) Migrate(name string) error {
 	return nil
 }
 
+// MigrateStartPostcopy switches a running migration to post-copy mode.
+func (m *Monitor) MigrateStartPostcopy() error {
+	err := m.Run("migrate-start-postcopy", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // QueryMigrate gets the current migration status.
 func (m *Monitor) QueryMigrate() (*MigrationStatus, error) {
 	var resp struct {
@@ -537,6 +568,10 @@ func (m *Monitor) MigrateWait(ctx context.Context, state string) error {
 				return errors.New("Migrate call failed")
 			}
 
+			if resp.Return.Status == "postcopy-paused" {
+				return errors.New("Post-copy migration was interrupted")
+			}
+
 			if resp.Return.Status == state {
 				return nil
 			}
@@ -563,7 +598,7 @@ func (m *Monitor) MigrateContinue(fromState string) error {
 }
 
 // MigrateIncoming starts the receiver of a migration stream.
-func (m *Monitor) MigrateIncoming(ctx context.Context, name string) error {
+func (m *Monitor) MigrateIncoming(ctx context.Context, address map[string]string) error {
 	type migrateArgsChannel struct {
 		ChannelType string            `json:"channel-type"`
 		Address     map[string]string `json:"addr"`
@@ -576,11 +611,7 @@ func (m *Monitor) MigrateIncoming(ctx context.Context, name string) error {
 	args := migrateArgs{}
 	args.Channels = []migrateArgsChannel{{
 		ChannelType: "main",
-		Address: map[string]string{
-			"transport": "socket",
-			"type":      "fd",
-			"str":       name,
-		},
+		Address:     address,
 	}}
 
 	// Query the status.
@@ -607,7 +638,8 @@ func (m *Monitor) MigrateIncoming(ctx context.Context, name string) error {
 			return errors.New("Migrate incoming call failed")
 		}
 
-		if resp.Return.Status == "completed" {
+		// With post-copy, the guest can be started as soon as the switchover happened.
+		if resp.Return.Status == "completed" || resp.Return.Status == "postcopy-active" {
 			return nil
 		}
 
@@ -1473,6 +1505,103 @@ func (m *Monitor) BlockDevMirror(deviceNodeName string, targetNodeName string) e
 	return nil
 }
 
+// BlockDevBackup starts copying the blocks marked as dirty in the given bitmap to the target device and
+// returns a function waiting for the copy to complete.
+// The data is copied as it was when the job started, the guest can keep writing to the device meanwhile.
+// The bitmap is left untouched. Instead, a new pending bitmap is atomically added when the job starts so
+// that it records the writes made from that point on, see ResetDirtyBitmap.
+func (m *Monitor) BlockDevBackup(deviceNodeName string, targetNodeName string, bitmapName string, pendingBitmapName string) (func() error, error) {
+	jobID := targetNodeName
+
+	ch, err := m.CreateEventChannel(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []TransactionAction{
+		{
+			Type: "block-dirty-bitmap-add",
+			Data: map[string]any{
+				"node": deviceNodeName,
+				"name": pendingBitmapName,
+			},
+		},
+		{
+			Type: "blockdev-backup",
+			Data: map[string]any{
+				"device":      deviceNodeName,
+				"target":      targetNodeName,
+				"job-id":      jobID,
+				"sync":        "bitmap",
+				"bitmap":      bitmapName,
+				"bitmap-mode": "never",
+			},
+		},
+	}
+
+	err = m.RunTransaction(actions)
+	if err != nil {
+		m.CleanupEventChannel(jobID)
+		return nil, err
+	}
+
+	// Receive the job status right away so that other events aren't held up until the caller waits.
+	done := make(chan error, 1)
+	go func() {
+		event, ok := <-ch
+		if !ok {
+			done <- errors.New("Block backup job ended without reporting its status")
+			return
+		}
+
+		switch event.Name {
+		case EventBlockJobCompleted:
+			jobErr, _ := event.Data["error"].(string)
+			if jobErr != "" {
+				done <- fmt.Errorf("Failed block backup job: %s", jobErr)
+				return
+			}
+
+			done <- nil
+		case EventBlockJobError:
+			done <- errors.New("Error during block backup job")
+		default:
+			done <- fmt.Errorf("Not supported event: %q", event.Name)
+		}
+	}()
+
+	return func() error { return <-done }, nil
+}
+
+// ResetDirtyBitmap atomically replaces the content of a dirty bitmap with the one of the pending bitmap
+// added by BlockDevBackup, then removes the pending bitmap.
+func (m *Monitor) ResetDirtyBitmap(deviceName string, bitmapName string, pendingBitmapName string) error {
+	actions := []TransactionAction{
+		{
+			Type: "block-dirty-bitmap-clear",
+			Data: map[string]any{
+				"node": deviceName,
+				"name": bitmapName,
+			},
+		},
+		{
+			Type: "block-dirty-bitmap-merge",
+			Data: map[string]any{
+				"node":    deviceName,
+				"target":  bitmapName,
+				"bitmaps": []string{pendingBitmapName},
+			},
+		},
+	}
+
+	err := m.RunTransaction(actions)
+	if err != nil {
+		return err
+	}
+
+	return m.RemoveDirtyBitmap(deviceName, pendingBitmapName)
+}
+
 // BlockJobCancel cancels an ongoing block job.
 func (m *Monitor) BlockJobCancel(deviceNodeName string) error {
 	var args struct {
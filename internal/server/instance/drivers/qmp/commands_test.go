@@ -0,0 +1,152 @@
+package qmp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// mockExpectCommand returns a mock server handler expecting the given command and arguments,
+// replying with an empty successful response.
+func mockExpectCommand(execute string, arguments string) func(net.Conn) error {
+	return func(nc net.Conn) error {
+		var cmd qmpCommand
+		err := json.NewDecoder(nc).Decode(&cmd)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Execute != execute {
+			return fmt.Errorf("unexpected command:\n- want: %q\n-  got: %q", execute, cmd.Execute)
+		}
+
+		var want any
+		err = json.Unmarshal([]byte(arguments), &want)
+		if err != nil {
+			return err
+		}
+
+		wantJSON, _ := json.Marshal(want)
+		gotJSON, _ := json.Marshal(cmd.Arguments)
+		if string(wantJSON) != string(gotJSON) {
+			return fmt.Errorf("unexpected %q arguments:\n- want: %s\n-  got: %s", execute, wantJSON, gotJSON)
+		}
+
+		return json.NewEncoder(nc).Encode(qmpResponse{ID: cmd.ID})
+	}
+}
+
+func mockMonitor(t *testing.T, eg *errgroup.Group, hands ...func(net.Conn) error) *Monitor {
+	t.Helper()
+
+	m := &qemuMachineProtocol{}
+	mockMonitorServer(t, eg, m, hands...)
+
+	err := m.connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = m.disconnect() })
+
+	return &Monitor{qmp: m, eventMap: map[string]chan Event{}}
+}
+
+func TestBlockDevBackupKeepsBitmap(t *testing.T) {
+	eg := &errgroup.Group{}
+
+	var mon *Monitor
+	expectTransaction := mockExpectCommand("transaction", `{"actions": [
+		{"type": "block-dirty-bitmap-add", "data": {"node": "disk", "name": "daily.pending"}},
+		{"type": "blockdev-backup", "data": {"device": "disk", "target": "export", "job-id": "export", "sync": "bitmap", "bitmap": "daily", "bitmap-mode": "never"}}
+	]}`)
+
+	mon = mockMonitor(t, eg, func(nc net.Conn) error {
+		err := expectTransaction(nc)
+		if err != nil {
+			return err
+		}
+
+		mon.PushEvent(EventBlockJobCompleted, map[string]any{"device": "export"})
+		mon.CleanupEventChannel("export")
+		return nil
+	})
+
+	wait, err := mon.BlockDevBackup("disk", "export", "daily", "daily.pending")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The job status is received without waiting for the job.
+	err = eg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = wait()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestBlockDevBackupJobError(t *testing.T) {
+	eg := &errgroup.Group{}
+
+	var mon *Monitor
+	mon = mockMonitor(t, eg, func(nc net.Conn) error {
+		var cmd qmpCommand
+		err := json.NewDecoder(nc).Decode(&cmd)
+		if err != nil {
+			return err
+		}
+
+		err = json.NewEncoder(nc).Encode(qmpResponse{ID: cmd.ID})
+		if err != nil {
+			return err
+		}
+
+		mon.PushEvent(EventBlockJobCompleted, map[string]any{"device": "export", "error": "No space left on device"})
+		mon.CleanupEventChannel("export")
+		return nil
+	})
+
+	wait, err := mon.BlockDevBackup("disk", "export", "daily", "daily.pending")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = wait()
+	if err == nil {
+		t.Fatal("expected an error for a failed job")
+	}
+
+	err = eg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResetDirtyBitmap(t *testing.T) {
+	eg := &errgroup.Group{}
+
+	mon := mockMonitor(t, eg,
+		mockExpectCommand("transaction", `{"actions": [
+			{"type": "block-dirty-bitmap-clear", "data": {"node": "disk", "name": "daily"}},
+			{"type": "block-dirty-bitmap-merge", "data": {"node": "disk", "target": "daily", "bitmaps": ["daily.pending"]}}
+		]}`),
+		mockExpectCommand("block-dirty-bitmap-remove", `{"node": "disk", "name": "daily.pending"}`),
+	)
+
+	err := mon.ResetDirtyBitmap("disk", "daily", "daily.pending")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = eg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
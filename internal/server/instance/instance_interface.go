@@ -192,6 +192,9 @@ type Instance interface {
 	CreateBitmap(deviceNames []string, data api.StorageVolumeBitmapsPost) error
 	DeleteBitmap(deviceName string, bitmapName string) error
 	GetBitmaps(deviceName string) ([]api.StorageVolumeBitmap, error)
+	ExportBitmap(deviceName string, bitmapName string, targetPath string) (func() error, error)
+	CommitBitmapExport(deviceName string, bitmapName string) error
+	AbortBitmapExport(deviceName string, bitmapName string) error
 }
 
 // Container interface is for container specific functions.
@@ -215,6 +218,9 @@ type VM interface {
 	ConsoleLog() (string, error)
 	ConsoleScreenshot(screenshotFile *os.File) error
 	DumpGuestMemory(w *os.File, format string) error
+	FreezeFilesystems(maxDuration time.Duration) (func() error, error)
+	BalanceMemory(hostPressure bool) error
+	Inventory() (*api.InstanceInventory, error)
 }
 
 // CriuMigrationArgs arguments for CRIU migration.
@@ -244,6 +250,7 @@ type MigrateArgs struct {
 	ControlSend           func(m proto.Message) error
 	ControlReceive        func(m proto.Message, handshake bool) error
 	StateConn             func(ctx context.Context) (io.ReadWriteCloser, error)
+	MultifdConns          func(ctx context.Context) ([]io.ReadWriteCloser, error)
 	FilesystemConn        func(ctx context.Context) (io.ReadWriteCloser, error)
 	Snapshots             bool
 	Live                  bool
@@ -1322,3 +1322,101 @@ func ResourceUsage(instConfig map[string]string, instDevices map[string]map[stri
 
 	return cpuUsage, memoryUsage, diskUsage, nil
 }
+
+// PlacementGroups returns the placement affinity and anti-affinity groups from an instance's expanded config.
+func PlacementGroups(expandedConfig map[string]string) (affinity []string, antiAffinity []string) {
+	affinity = util.SplitNTrimSpace(expandedConfig["placement.affinity"], ",", -1, true)
+	antiAffinity = util.SplitNTrimSpace(expandedConfig["placement.anti_affinity"], ",", -1, true)
+
+	return affinity, antiAffinity
+}
+
+// PlacementFilterMembers filters the candidate cluster members for an instance based on its placement groups.
+//
+// Members running another instance of the project which shares an anti-affinity group with the instance are
+// always excluded. If some of the remaining members run another instance sharing an affinity group with it,
+// only those are returned. Otherwise, the remaining members are returned, unless strictAffinity is set
+// and such instances exist elsewhere, in which case no member is suitable.
+func PlacementFilterMembers(ctx context.Context, tx *db.ClusterTx, projectName string, instanceName string, expandedConfig map[string]string, candidates []db.NodeInfo, strictAffinity bool) ([]db.NodeInfo, error) {
+	affinity, antiAffinity := PlacementGroups(expandedConfig)
+	if len(affinity) == 0 && len(antiAffinity) == 0 {
+		return candidates, nil
+	}
+
+	instances := []db.InstanceArgs{}
+	err := tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+		instances = append(instances, inst)
+		return nil
+	}, cluster.InstanceFilter{Project: &projectName})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instances for placement groups: %w", err)
+	}
+
+	return placementFilterMembers(instanceName, expandedConfig, instances, candidates, strictAffinity), nil
+}
+
+// PlacementCheckMember checks that an explicitly targeted cluster member satisfies the anti-affinity rules of an instance.
+func PlacementCheckMember(ctx context.Context, tx *db.ClusterTx, projectName string, instanceName string, expandedConfig map[string]string, member db.NodeInfo) error {
+	members, err := PlacementFilterMembers(ctx, tx, projectName, instanceName, expandedConfig, []db.NodeInfo{member}, false)
+	if err != nil {
+		return err
+	}
+
+	if len(members) == 0 {
+		return api.StatusErrorf(http.StatusConflict, "Cluster member %q doesn't satisfy the placement anti-affinity rules of the instance", member.Name)
+	}
+
+	return nil
+}
+
+// placementFilterMembers filters the candidate cluster members based on the placement groups of the other instances of the project.
+func placementFilterMembers(instanceName string, expandedConfig map[string]string, instances []db.InstanceArgs, candidates []db.NodeInfo, strictAffinity bool) []db.NodeInfo {
+	affinity, antiAffinity := PlacementGroups(expandedConfig)
+
+	excludedMembers := map[string]bool{}
+	affinityMembers := map[string]bool{}
+
+	for _, inst := range instances {
+		if inst.Name == instanceName {
+			continue
+		}
+
+		instAffinity, instAntiAffinity := PlacementGroups(db.ExpandInstanceConfig(inst.Config, inst.Profiles))
+
+		for _, group := range antiAffinity {
+			if slices.Contains(instAntiAffinity, group) {
+				excludedMembers[inst.Node] = true
+			}
+		}
+
+		for _, group := range affinity {
+			if slices.Contains(instAffinity, group) {
+				affinityMembers[inst.Node] = true
+			}
+		}
+	}
+
+	allowed := make([]db.NodeInfo, 0, len(candidates))
+	preferred := make([]db.NodeInfo, 0, len(candidates))
+	for _, member := range candidates {
+		if excludedMembers[member.Name] {
+			continue
+		}
+
+		allowed = append(allowed, member)
+
+		if affinityMembers[member.Name] {
+			preferred = append(preferred, member)
+		}
+	}
+
+	if len(preferred) > 0 {
+		return preferred
+	}
+
+	if strictAffinity && len(affinityMembers) > 0 {
+		return []db.NodeInfo{}
+	}
+
+	return allowed
+}
@@ -0,0 +1,85 @@
+package instance
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/lxc/incus/v7/internal/server/db"
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+func TestPlacementGroups(t *testing.T) {
+	affinity, antiAffinity := PlacementGroups(map[string]string{
+		"placement.affinity":      "web, cache",
+		"placement.anti_affinity": "db",
+	})
+
+	if !slices.Equal(affinity, []string{"web", "cache"}) || !slices.Equal(antiAffinity, []string{"db"}) {
+		t.Fatalf("Unexpected placement groups: %v, %v", affinity, antiAffinity)
+	}
+
+	affinity, antiAffinity = PlacementGroups(nil)
+	if len(affinity) != 0 || len(antiAffinity) != 0 {
+		t.Fatalf("Unexpected placement groups without config: %v, %v", affinity, antiAffinity)
+	}
+}
+
+func TestPlacementFilterMembers(t *testing.T) {
+	members := []db.NodeInfo{{Name: "m1"}, {Name: "m2"}, {Name: "m3"}}
+
+	instances := []db.InstanceArgs{
+		{Name: "db1", Node: "m1", Config: map[string]string{"placement.anti_affinity": "db"}},
+		{Name: "web1", Node: "m2", Config: map[string]string{"placement.affinity": "web"}},
+		{Name: "other", Node: "m3", Config: map[string]string{}},
+
+		// Placement groups can come from profiles.
+		{Name: "db2", Node: "m2", Profiles: []api.Profile{{Name: "db", ProfilePut: api.ProfilePut{Config: map[string]string{"placement.anti_affinity": "db"}}}}},
+	}
+
+	names := func(members []db.NodeInfo) []string {
+		out := []string{}
+		for _, member := range members {
+			out = append(out, member.Name)
+		}
+
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		instance string
+		config   map[string]string
+		strict   bool
+		want     []string
+	}{
+		{name: "no groups", instance: "new", want: []string{"m1", "m2", "m3"}},
+		{name: "unused groups", instance: "new", config: map[string]string{"placement.affinity": "foo", "placement.anti_affinity": "bar"}, want: []string{"m1", "m2", "m3"}},
+		{name: "anti-affinity", instance: "new", config: map[string]string{"placement.anti_affinity": "db"}, want: []string{"m3"}},
+		{name: "anti-affinity ignores itself", instance: "db1", config: map[string]string{"placement.anti_affinity": "db"}, want: []string{"m1", "m3"}},
+		{name: "affinity", instance: "new", config: map[string]string{"placement.affinity": "web"}, want: []string{"m2"}},
+		{name: "strict affinity", instance: "new", config: map[string]string{"placement.affinity": "web"}, strict: true, want: []string{"m2"}},
+		{name: "affinity and anti-affinity", instance: "new", config: map[string]string{"placement.affinity": "web", "placement.anti_affinity": "db"}, want: []string{"m3"}},
+		{name: "strict affinity and anti-affinity", instance: "new", config: map[string]string{"placement.affinity": "web", "placement.anti_affinity": "db"}, strict: true, want: []string{}},
+		{name: "groups don't mix", instance: "new", config: map[string]string{"placement.affinity": "db"}, want: []string{"m1", "m2", "m3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := placementFilterMembers(tt.instance, tt.config, instances, members, tt.strict)
+			if !slices.Equal(names(got), tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, names(got))
+			}
+		})
+	}
+
+	// Only the candidate members are considered.
+	got := placementFilterMembers("new", map[string]string{"placement.affinity": "web"}, instances, []db.NodeInfo{{Name: "m1"}, {Name: "m3"}}, false)
+	if !slices.Equal(names(got), []string{"m1", "m3"}) {
+		t.Fatalf("Unexpected members outside of the affinity member: %v", names(got))
+	}
+
+	got = placementFilterMembers("new", map[string]string{"placement.affinity": "web"}, instances, []db.NodeInfo{{Name: "m1"}, {Name: "m3"}}, true)
+	if len(got) != 0 {
+		t.Fatalf("Unexpected members with strict affinity: %v", names(got))
+	}
+}
@@ -2,6 +2,7 @@ package logging
 
 import (
 	"encoding/json"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 
@@ -15,6 +16,19 @@ type Logger interface {
 	Start() error
 	Stop()
 	Validate() error
+	Stats() LoggerStats
+}
+
+// LoggerStats represents the delivery statistics of a logger.
+type LoggerStats struct {
+	// QueuedEvents is the number of events waiting in the on-disk spool.
+	QueuedEvents int64
+
+	// QueuedBytes is the size of the on-disk spool.
+	QueuedBytes int64
+
+	// DroppedEvents is the number of events which couldn't be delivered.
+	DroppedEvents uint64
 }
 
 // common embeds shared configuration fields for all logger types.
@@ -24,6 +38,12 @@ type common struct {
 	loggingLevel      string
 	name              string
 	types             []string
+
+	// spool is the optional on-disk queue of events waiting to be delivered.
+	spool *spool
+
+	// dropped counts the events dropped outside of the spool.
+	dropped atomic.Uint64
 }
 
 // newCommonLogger instantiates a new common logger.
@@ -39,6 +59,21 @@ func newCommonLogger(name string, cfg *clusterConfig.Config) common {
 	}
 }
 
+// Stats returns the delivery statistics of the logger.
+func (c *common) Stats() LoggerStats {
+	stats := LoggerStats{DroppedEvents: c.dropped.Load()}
+
+	if c.spool != nil {
+		queued, size, dropped := c.spool.stats()
+
+		stats.QueuedEvents = queued
+		stats.QueuedBytes = size
+		stats.DroppedEvents += dropped
+	}
+
+	return stats
+}
+
 // processEvent verifies whether the event should be processed for the specific logger.
 func (c *common) processEvent(event api.Event) bool {
 	switch event.Type {
@@ -2,8 +2,10 @@ package logging
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/lxc/incus/v7/internal/server/events"
+	"github.com/lxc/incus/v7/internal/server/metrics"
 	"github.com/lxc/incus/v7/internal/server/state"
 	"github.com/lxc/incus/v7/shared/logger"
 )
@@ -12,6 +14,7 @@ import (
 type Controller struct {
 	listener *events.InternalListener
 	loggers  map[string]Logger
+	mu       sync.Mutex
 }
 
 // NewLoggingController instantiates a new LoggerController object.
@@ -29,7 +32,10 @@ func (c *Controller) AddLogger(s *state.State, name string, loggerType string) e
 		return err
 	}
 
+	c.mu.Lock()
 	c.loggers[name] = loggerClient
+	c.mu.Unlock()
+
 	c.listener.AddHandler(name, loggerClient.HandleEvent)
 
 	return nil
@@ -37,11 +43,14 @@ func (c *Controller) AddLogger(s *state.State, name string, loggerType string) e
 
 // RemoveLogger removes a logger from the controller.
 func (c *Controller) RemoveLogger(name string) {
+	c.mu.Lock()
 	loggerClient, ok := c.loggers[name]
+	delete(c.loggers, name)
+	c.mu.Unlock()
+
 	if ok {
-		loggerClient.Stop()
-		delete(c.loggers, name)
 		c.listener.RemoveHandler(name)
+		loggerClient.Stop()
 	}
 }
 
@@ -52,6 +61,9 @@ func (c *Controller) Setup(s *state.State) error {
 		return err
 	}
 
+	// Clean up the spools of loggers deleted while the daemon wasn't running.
+	removeUnusedSpools(s, loggingConfig, nil)
+
 	for loggerName, loggerType := range loggingConfig {
 		err = c.AddLogger(s, loggerName, loggerType)
 		if err != nil {
@@ -72,6 +84,9 @@ func (c *Controller) Reconfigure(s *state.State, config map[string]struct{}) err
 	for loggerName := range config {
 		c.RemoveLogger(loggerName)
 
+		// Remove the spool of deleted loggers and of those which don't use spooling anymore.
+		removeUnusedSpools(s, loggingConfig, []string{loggerName})
+
 		loggerType, ok := loggingConfig[loggerName]
 		if !ok {
 			continue
@@ -88,11 +103,33 @@ func (c *Controller) Reconfigure(s *state.State, config map[string]struct{}) err
 
 // Shutdown cleans up loggers.
 func (c *Controller) Shutdown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	for _, loggerClient := range c.loggers {
 		loggerClient.Stop()
 	}
 }
 
+// Metrics returns the delivery metrics of all loggers.
+func (c *Controller) Metrics() *metrics.MetricSet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := metrics.NewMetricSet(nil)
+
+	for name, loggerClient := range c.loggers {
+		stats := loggerClient.Stats()
+		labels := map[string]string{"logger": name}
+
+		out.AddSamples(metrics.LoggingQueueEvents, metrics.Sample{Labels: labels, Value: float64(stats.QueuedEvents)})
+		out.AddSamples(metrics.LoggingQueueBytes, metrics.Sample{Labels: labels, Value: float64(stats.QueuedBytes)})
+		out.AddSamples(metrics.LoggingDroppedEventsTotal, metrics.Sample{Labels: labels, Value: float64(stats.DroppedEvents)})
+	}
+
+	return out
+}
+
 // LoggerFromType returns a new logger based on its type.
 func LoggerFromType(s *state.State, loggerName string, loggerType string) (Logger, error) {
 	if loggerType == "" {
@@ -107,6 +144,8 @@ func LoggerFromType(s *state.State, loggerName string, loggerType string) (Logge
 		loggerClient, err = NewSyslogLogger(s, loggerName)
 	case "loki":
 		loggerClient, err = NewLokiLogger(s, loggerName)
+	case "otlp":
+		loggerClient, err = NewOTLPLogger(s, loggerName)
 	case "webhook":
 		loggerClient, err = NewWebhookLogger(s, loggerName)
 	default:
@@ -129,6 +129,11 @@ func NewLokiLogger(s *state.State, name string) (*LokiLogger, error) {
 		loggerClient.client = http.DefaultClient
 	}
 
+	loggerClient.spool, err = newSpoolFromConfig(s, name)
+	if err != nil {
+		return nil, err
+	}
+
 	return &loggerClient, nil
 }
 
@@ -194,6 +199,7 @@ func (l *LokiLogger) sendBatch(batch *batch) {
 	for range l.cfg.retry {
 		select {
 		case <-l.quit:
+			l.dropped.Add(uint64(batch.entries()))
 			return
 		default:
 			// Try to send the message.
@@ -203,7 +209,8 @@ func (l *LokiLogger) sendBatch(batch *batch) {
 			}
 
 			// Only retry 429s, 500s and connection-level errors.
-			if status > 0 && status != 429 && status/100 != 5 {
+			if !lokiRetryable(status) {
+				l.dropped.Add(uint64(batch.entries()))
 				return
 			}
 
@@ -211,6 +218,42 @@ func (l *LokiLogger) sendBatch(batch *batch) {
 			time.Sleep(10 * time.Second)
 		}
 	}
+
+	l.dropped.Add(uint64(batch.entries()))
+}
+
+// sendSpooled sends a batch of events read from the spool.
+func (l *LokiLogger) sendSpooled(events []api.Event) (bool, error) {
+	batch := newBatch()
+
+	for _, event := range events {
+		entry, ok := l.eventToEntry(event)
+		if ok {
+			batch.add(entry)
+		}
+	}
+
+	if batch.empty() {
+		return false, nil
+	}
+
+	buf, _, err := batch.encode()
+	if err != nil {
+		return false, err
+	}
+
+	status, err := l.send(l.ctx, buf)
+	if err != nil {
+		return lokiRetryable(status), err
+	}
+
+	return false, nil
+}
+
+// lokiRetryable returns whether a push which failed with the given status is worth retrying.
+func lokiRetryable(status int) bool {
+	// Only retry 429s, 500s and connection-level errors.
+	return status <= 0 || status == 429 || status/100 == 5
 }
 
 func (l *LokiLogger) send(ctx context.Context, buf []byte) (int, error) {
@@ -251,6 +294,16 @@ func (l *LokiLogger) send(ctx context.Context, buf []byte) (int, error) {
 // Start starts the loki logger.
 func (l *LokiLogger) Start() error {
 	l.wg.Add(1)
+
+	if l.spool != nil {
+		go func() {
+			defer l.wg.Done()
+			l.spool.run(l.ctx, l.quit, 1000, l.sendSpooled)
+		}()
+
+		return nil
+	}
+
 	go l.run()
 
 	return nil
@@ -260,6 +313,10 @@ func (l *LokiLogger) Start() error {
 func (l *LokiLogger) Stop() {
 	l.once.Do(func() { close(l.quit) })
 	l.wg.Wait()
+
+	if l.spool != nil {
+		l.spool.close()
+	}
 }
 
 // Validate checks whether the logger configuration is correct.
@@ -277,6 +334,27 @@ func (l *LokiLogger) HandleEvent(event api.Event) {
 		return
 	}
 
+	// Queue the event on disk when spooling is enabled.
+	// Failures are accounted for in the dropped events, logging them would only generate more events.
+	if l.spool != nil {
+		err := l.spool.push(event)
+		if err != nil {
+			l.dropped.Add(1)
+		}
+
+		return
+	}
+
+	entry, ok := l.eventToEntry(event)
+	if !ok {
+		return
+	}
+
+	l.entries <- entry
+}
+
+// eventToEntry converts an event into a Loki log entry.
+func (l *LokiLogger) eventToEntry(event api.Event) (entry, bool) {
 	// Support overriding the location field (used on standalone systems).
 	location := event.Location
 	if l.cfg.location != "" {
@@ -303,7 +381,7 @@ func (l *LokiLogger) HandleEvent(event api.Event) {
 
 		err := json.Unmarshal(event.Metadata, &lifecycleEvent)
 		if err != nil {
-			return
+			return entry, false
 		}
 
 		if lifecycleEvent.Name != "" {
@@ -363,7 +441,7 @@ func (l *LokiLogger) HandleEvent(event api.Event) {
 
 		err := json.Unmarshal(event.Metadata, &logEvent)
 		if err != nil {
-			return
+			return entry, false
 		}
 
 		tmpContext := map[string]any{}
@@ -410,7 +488,7 @@ func (l *LokiLogger) HandleEvent(event api.Event) {
 		entry.Line = message.String()
 	}
 
-	l.entries <- entry
+	return entry, true
 }
 
 func buildNestedContext(prefix string, m map[string]any) map[string]string {
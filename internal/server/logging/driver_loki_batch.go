@@ -89,6 +89,16 @@ func (b *batch) createPushRequest() (*PushRequest, int) {
 	return &req, entriesCount
 }
 
+// entries returns the number of entries in the batch.
+func (b *batch) entries() int {
+	count := 0
+	for _, stream := range b.streams {
+		count += len(stream.Entries)
+	}
+
+	return count
+}
+
 // empty returns true if streams is empty.
 func (b *batch) empty() bool {
 	return len(b.streams) == 0
@@ -0,0 +1,440 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/server/state"
+	"github.com/lxc/incus/v7/shared/api"
+	localtls "github.com/lxc/incus/v7/shared/tls"
+)
+
+const (
+	otlpProtocolHTTP = "http"
+	otlpProtocolGRPC = "grpc"
+
+	otlpHTTPPath = "/v1/logs"
+	otlpGRPCPath = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+)
+
+// gRPC status codes which are worth retrying (UNAVAILABLE and RESOURCE_EXHAUSTED).
+var otlpGRPCRetryableCodes = []string{"14", "8"}
+
+type otlpConfig struct {
+	batchSize int
+	batchWait time.Duration
+
+	username string
+	password string
+	instance string
+	location string
+	protocol string
+	retry    int
+
+	timeout time.Duration
+	url     *url.URL
+}
+
+// OTLPLogger represents an OpenTelemetry (OTLP) logs client.
+type OTLPLogger struct {
+	common
+	cfg     otlpConfig
+	client  *http.Client
+	ctx     context.Context
+	quit    chan struct{}
+	once    sync.Once
+	records chan otlpRecord
+	wg      sync.WaitGroup
+}
+
+// NewOTLPLogger returns a logger of otlp type.
+func NewOTLPLogger(s *state.State, name string) (*OTLPLogger, error) {
+	urlStr, username, password, caCert, instance, protocol, retry := s.GlobalConfig.LoggingConfigForOTLP(name)
+
+	// Set defaults.
+	if retry == 0 {
+		retry = 3
+	}
+
+	if protocol == "" {
+		protocol = otlpProtocolHTTP
+	}
+
+	// Validate the URL.
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the default OTLP/HTTP path if none was provided.
+	if protocol == otlpProtocolHTTP && strings.TrimSuffix(u.Path, "/") == "" {
+		u.Path = otlpHTTPPath
+	}
+
+	// Handle standalone systems.
+	var location string
+	if !s.ServerClustered {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+
+		location = hostname
+		if instance == "" {
+			instance = hostname
+		}
+	} else if instance == "" {
+		instance = s.ServerName
+	}
+
+	transport := &http.Transport{}
+
+	if caCert != "" {
+		tlsConfig, err := localtls.GetTLSConfigMem("", "", caCert, "", false)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	// gRPC requires HTTP/2, including over plain text connections.
+	if protocol == otlpProtocolGRPC {
+		protocols := &http.Protocols{}
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+		transport.Protocols = protocols
+	}
+
+	loggerClient := OTLPLogger{
+		common: newCommonLogger(name, s.GlobalConfig),
+		cfg: otlpConfig{
+			batchSize: 512,
+			batchWait: 1 * time.Second,
+			username:  username,
+			password:  password,
+			instance:  instance,
+			location:  location,
+			protocol:  protocol,
+			retry:     retry,
+			timeout:   10 * time.Second,
+			url:       u,
+		},
+		client:  &http.Client{Transport: transport},
+		ctx:     s.ShutdownCtx,
+		records: make(chan otlpRecord),
+		quit:    make(chan struct{}),
+	}
+
+	return &loggerClient, nil
+}
+
+func (l *OTLPLogger) run() {
+	batch := []otlpRecord{}
+	batchStart := time.Now()
+
+	maxWaitCheck := time.NewTicker(max(l.cfg.batchWait/10, 10*time.Millisecond))
+	defer maxWaitCheck.Stop()
+
+	defer func() {
+		// Send all pending records.
+		l.sendBatch(batch)
+		l.wg.Done()
+	}()
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+
+		case <-l.quit:
+			return
+
+		case r := <-l.records:
+			if len(batch) == 0 {
+				batchStart = time.Now()
+			}
+
+			batch = append(batch, r)
+
+			// Send the batch once the max number of records is reached.
+			if len(batch) >= l.cfg.batchSize {
+				l.sendBatch(batch)
+				batch = []otlpRecord{}
+			}
+
+		case <-maxWaitCheck.C:
+			// Send batch if max wait time has been reached.
+			if len(batch) == 0 || time.Since(batchStart) < l.cfg.batchWait {
+				break
+			}
+
+			l.sendBatch(batch)
+			batch = []otlpRecord{}
+		}
+	}
+}
+
+func (l *OTLPLogger) sendBatch(batch []otlpRecord) {
+	if len(batch) == 0 {
+		return
+	}
+
+	buf := otlpEncodeRequest(batch)
+
+	for range l.cfg.retry {
+		select {
+		case <-l.quit:
+			return
+		default:
+			// Try to send the message.
+			retryable, err := l.send(l.ctx, buf)
+			if err == nil || !retryable {
+				return
+			}
+
+			// Retry every 10s.
+			time.Sleep(10 * time.Second)
+		}
+	}
+}
+
+// send pushes the encoded request to the collector and returns whether a failure is worth retrying.
+func (l *OTLPLogger) send(ctx context.Context, buf []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.cfg.timeout)
+	defer cancel()
+
+	var req *http.Request
+	var err error
+
+	if l.cfg.protocol == otlpProtocolGRPC {
+		// Prefix the message with the gRPC framing (uncompressed flag and message length).
+		frame := make([]byte, 5, 5+len(buf))
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(buf)))
+		frame = append(frame, buf...)
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, l.cfg.url.JoinPath(otlpGRPCPath).String(), bytes.NewReader(frame))
+		if err != nil {
+			return false, err
+		}
+
+		req.Header.Set("Content-Type", "application/grpc")
+		req.Header.Set("TE", "trailers")
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, l.cfg.url.String(), bytes.NewReader(buf))
+		if err != nil {
+			return false, err
+		}
+
+		req.Header.Set("Content-Type", "application/x-protobuf")
+	}
+
+	if l.cfg.username != "" && l.cfg.password != "" {
+		req.SetBasicAuth(l.cfg.username, l.cfg.password)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxErrMsgLen))
+		line := ""
+
+		if scanner.Scan() {
+			line = scanner.Text()
+		}
+
+		// Only retry 429s, 502s, 503s and 504s as per the OTLP specification.
+		retryable := resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode >= 502 && resp.StatusCode <= 504)
+
+		return retryable, fmt.Errorf("server returned HTTP status %s (%d): %s", resp.Status, resp.StatusCode, line)
+	}
+
+	if l.cfg.protocol != otlpProtocolGRPC {
+		return false, nil
+	}
+
+	// The gRPC status is only available once the body has been consumed.
+	_, err = io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return true, err
+	}
+
+	status := resp.Trailer.Get("grpc-status")
+	message := resp.Trailer.Get("grpc-message")
+	if status == "" {
+		// Trailers-only responses carry the status in the headers.
+		status = resp.Header.Get("grpc-status")
+		message = resp.Header.Get("grpc-message")
+	}
+
+	if status == "" {
+		return false, errors.New("server returned no gRPC status")
+	}
+
+	if status != "0" {
+		return contains(otlpGRPCRetryableCodes, status), fmt.Errorf("server returned gRPC status %s: %s", status, message)
+	}
+
+	return false, nil
+}
+
+// Start starts the otlp logger.
+func (l *OTLPLogger) Start() error {
+	l.wg.Add(1)
+	go l.run()
+
+	return nil
+}
+
+// Stop stops the client.
+func (l *OTLPLogger) Stop() {
+	l.once.Do(func() { close(l.quit) })
+	l.wg.Wait()
+}
+
+// Validate checks whether the logger configuration is correct.
+func (l *OTLPLogger) Validate() error {
+	if l.cfg.url.Host == "" {
+		return fmt.Errorf("%s: Address cannot be empty", l.name)
+	}
+
+	if l.cfg.url.Scheme != "http" && l.cfg.url.Scheme != "https" {
+		return fmt.Errorf("%s: Address must be an http:// or https:// URL", l.name)
+	}
+
+	if l.cfg.protocol != otlpProtocolHTTP && l.cfg.protocol != otlpProtocolGRPC {
+		return fmt.Errorf("%s: Invalid protocol %q", l.name, l.cfg.protocol)
+	}
+
+	return nil
+}
+
+// HandleEvent handles the event received from the internal event listener.
+func (l *OTLPLogger) HandleEvent(event api.Event) {
+	if !l.processEvent(event) {
+		return
+	}
+
+	// Support overriding the location field (used on standalone systems).
+	location := event.Location
+	if l.cfg.location != "" {
+		location = l.cfg.location
+	}
+
+	record := otlpRecord{
+		resource: map[string]string{
+			"service.name":        "incus",
+			"service.instance.id": l.cfg.instance,
+			"incus.location":      location,
+		},
+		timestamp:  event.Timestamp,
+		attributes: map[string]string{"incus.event.type": event.Type},
+	}
+
+	projectName := event.Project
+	instanceName := ""
+
+	switch event.Type {
+	case api.EventTypeLifecycle:
+		lifecycleEvent := api.EventLifecycle{}
+
+		err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+		if err != nil {
+			return
+		}
+
+		if lifecycleEvent.Project != "" {
+			projectName = lifecycleEvent.Project
+		}
+
+		if strings.HasPrefix(lifecycleEvent.Action, "instance-") {
+			instanceName = lifecycleEvent.Name
+		} else if lifecycleEvent.Name != "" {
+			record.attributes["name"] = lifecycleEvent.Name
+		}
+
+		record.attributes["action"] = lifecycleEvent.Action
+		record.attributes["source"] = lifecycleEvent.Source
+
+		maps.Copy(record.attributes, buildNestedContext("context", lifecycleEvent.Context))
+
+		if lifecycleEvent.Requestor != nil {
+			record.attributes["requester-address"] = lifecycleEvent.Requestor.Address
+			record.attributes["requester-protocol"] = lifecycleEvent.Requestor.Protocol
+			record.attributes["requester-username"] = lifecycleEvent.Requestor.Username
+		}
+
+		record.severity = otlpSeverityInfo
+		record.severityText = "info"
+		record.eventName = lifecycleEvent.Action
+		record.body = lifecycleEvent.Action
+	case api.EventTypeLogging, api.EventTypeNetworkACL:
+		logEvent := api.EventLogging{}
+
+		err := json.Unmarshal(event.Metadata, &logEvent)
+		if err != nil {
+			return
+		}
+
+		for k, v := range logEvent.Context {
+			switch k {
+			case "project":
+				projectName = v
+			case "instance":
+				instanceName = v
+			default:
+				record.attributes[fmt.Sprintf("context-%s", k)] = v
+			}
+		}
+
+		record.severity = otlpSeverityFromLevel(logEvent.Level)
+		record.severityText = logEvent.Level
+		record.body = logEvent.Message
+	}
+
+	if projectName != "" {
+		record.resource["incus.project"] = projectName
+	}
+
+	if instanceName != "" {
+		record.resource["incus.instance"] = instanceName
+	}
+
+	l.records <- record
+}
+
+// otlpSeverityFromLevel converts a log level into an OTLP severity number.
+func otlpSeverityFromLevel(level string) int {
+	switch level {
+	case "trace":
+		return otlpSeverityTrace
+	case "debug":
+		return otlpSeverityDebug
+	case "warn", "warning":
+		return otlpSeverityWarn
+	case "error":
+		return otlpSeverityError
+	case "fatal", "panic":
+		return otlpSeverityFatal
+	default:
+		return otlpSeverityInfo
+	}
+}
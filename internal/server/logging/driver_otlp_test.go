@@ -0,0 +1,346 @@
+package logging
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// otlpTestField describes a field of the test OTLP message descriptors.
+type otlpTestField struct {
+	name     string
+	number   int32
+	kind     descriptorpb.FieldDescriptorProto_Type
+	typeName string
+	repeated bool
+	oneof    bool
+}
+
+// otlpTestDescriptor returns an ExportLogsServiceRequest message descriptor, transcribed by hand from the OTLP
+// logs definitions. It lets the tests decode requests through the protobuf runtime rather than the encoder's own
+// code, but as it shares the encoder's reading of the definitions, it isn't a conformance check against them.
+func otlpTestDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	const (
+		typeString  = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		typeBytes   = descriptorpb.FieldDescriptorProto_TYPE_BYTES
+		typeBool    = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+		typeInt64   = descriptorpb.FieldDescriptorProto_TYPE_INT64
+		typeDouble  = descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+		typeUint32  = descriptorpb.FieldDescriptorProto_TYPE_UINT32
+		typeFixed32 = descriptorpb.FieldDescriptorProto_TYPE_FIXED32
+		typeFixed64 = descriptorpb.FieldDescriptorProto_TYPE_FIXED64
+		typeEnum    = descriptorpb.FieldDescriptorProto_TYPE_ENUM
+		typeMessage = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	)
+
+	messages := []struct {
+		name   string
+		fields []otlpTestField
+	}{
+		{"ExportLogsServiceRequest", []otlpTestField{
+			{name: "resource_logs", number: 1, kind: typeMessage, typeName: "ResourceLogs", repeated: true},
+		}},
+		{"ResourceLogs", []otlpTestField{
+			{name: "resource", number: 1, kind: typeMessage, typeName: "Resource"},
+			{name: "scope_logs", number: 2, kind: typeMessage, typeName: "ScopeLogs", repeated: true},
+			{name: "schema_url", number: 3, kind: typeString},
+		}},
+		{"ScopeLogs", []otlpTestField{
+			{name: "scope", number: 1, kind: typeMessage, typeName: "InstrumentationScope"},
+			{name: "log_records", number: 2, kind: typeMessage, typeName: "LogRecord", repeated: true},
+			{name: "schema_url", number: 3, kind: typeString},
+		}},
+		{"LogRecord", []otlpTestField{
+			{name: "time_unix_nano", number: 1, kind: typeFixed64},
+			{name: "observed_time_unix_nano", number: 11, kind: typeFixed64},
+			{name: "severity_number", number: 2, kind: typeEnum, typeName: "SeverityNumber"},
+			{name: "severity_text", number: 3, kind: typeString},
+			{name: "body", number: 5, kind: typeMessage, typeName: "AnyValue"},
+			{name: "attributes", number: 6, kind: typeMessage, typeName: "KeyValue", repeated: true},
+			{name: "dropped_attributes_count", number: 7, kind: typeUint32},
+			{name: "flags", number: 8, kind: typeFixed32},
+			{name: "trace_id", number: 9, kind: typeBytes},
+			{name: "span_id", number: 10, kind: typeBytes},
+			{name: "event_name", number: 12, kind: typeString},
+		}},
+		{"Resource", []otlpTestField{
+			{name: "attributes", number: 1, kind: typeMessage, typeName: "KeyValue", repeated: true},
+			{name: "dropped_attributes_count", number: 2, kind: typeUint32},
+		}},
+		{"InstrumentationScope", []otlpTestField{
+			{name: "name", number: 1, kind: typeString},
+			{name: "version", number: 2, kind: typeString},
+			{name: "attributes", number: 3, kind: typeMessage, typeName: "KeyValue", repeated: true},
+			{name: "dropped_attributes_count", number: 4, kind: typeUint32},
+		}},
+		{"KeyValue", []otlpTestField{
+			{name: "key", number: 1, kind: typeString},
+			{name: "value", number: 2, kind: typeMessage, typeName: "AnyValue"},
+		}},
+		{"AnyValue", []otlpTestField{
+			{name: "string_value", number: 1, kind: typeString, oneof: true},
+			{name: "bool_value", number: 2, kind: typeBool, oneof: true},
+			{name: "int_value", number: 3, kind: typeInt64, oneof: true},
+			{name: "double_value", number: 4, kind: typeDouble, oneof: true},
+			{name: "bytes_value", number: 7, kind: typeBytes, oneof: true},
+		}},
+	}
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("otlp_logs_test.proto"),
+		Package: proto.String("otlptest"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("SeverityNumber"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("SEVERITY_NUMBER_UNSPECIFIED"), Number: proto.Int32(0)},
+				{Name: proto.String("SEVERITY_NUMBER_TRACE"), Number: proto.Int32(otlpSeverityTrace)},
+				{Name: proto.String("SEVERITY_NUMBER_DEBUG"), Number: proto.Int32(otlpSeverityDebug)},
+				{Name: proto.String("SEVERITY_NUMBER_INFO"), Number: proto.Int32(otlpSeverityInfo)},
+				{Name: proto.String("SEVERITY_NUMBER_WARN"), Number: proto.Int32(otlpSeverityWarn)},
+				{Name: proto.String("SEVERITY_NUMBER_ERROR"), Number: proto.Int32(otlpSeverityError)},
+				{Name: proto.String("SEVERITY_NUMBER_FATAL"), Number: proto.Int32(otlpSeverityFatal)},
+			},
+		}},
+	}
+
+	for _, msg := range messages {
+		desc := &descriptorpb.DescriptorProto{Name: proto.String(msg.name)}
+
+		for _, f := range msg.fields {
+			label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+			if f.repeated {
+				label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+			}
+
+			field := &descriptorpb.FieldDescriptorProto{
+				Name:     proto.String(f.name),
+				JsonName: proto.String(f.name),
+				Number:   proto.Int32(f.number),
+				Label:    label.Enum(),
+				Type:     f.kind.Enum(),
+			}
+
+			if f.typeName != "" {
+				field.TypeName = proto.String(".otlptest." + f.typeName)
+			}
+
+			if f.oneof {
+				field.OneofIndex = proto.Int32(0)
+			}
+
+			desc.Field = append(desc.Field, field)
+		}
+
+		if msg.name == "AnyValue" {
+			desc.OneofDecl = []*descriptorpb.OneofDescriptorProto{{Name: proto.String("value")}}
+		}
+
+		file.MessageType = append(file.MessageType, desc)
+	}
+
+	fd, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		t.Fatalf("Failed building OTLP descriptors: %v", err)
+	}
+
+	return fd.Messages().ByName("ExportLogsServiceRequest")
+}
+
+// otlpTestCheckUnknown fails the test if the message or any of its sub-messages holds unknown fields.
+func otlpTestCheckUnknown(t *testing.T, msg protoreflect.Message) {
+	t.Helper()
+
+	if len(msg.GetUnknown()) > 0 {
+		t.Fatalf("Message %q has unknown fields", msg.Descriptor().FullName())
+	}
+
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.MessageKind {
+			return true
+		}
+
+		if fd.IsList() {
+			for i := 0; i < v.List().Len(); i++ {
+				otlpTestCheckUnknown(t, v.List().Get(i).Message())
+			}
+		} else {
+			otlpTestCheckUnknown(t, v.Message())
+		}
+
+		return true
+	})
+}
+
+type otlpTestKeyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"string_value"`
+	} `json:"value"`
+}
+
+type otlpTestRequest struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []otlpTestKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []struct {
+			Scope struct {
+				Name string `json:"name"`
+			} `json:"scope"`
+			LogRecords []struct {
+				TimeUnixNano         string `json:"time_unix_nano"`
+				ObservedTimeUnixNano string `json:"observed_time_unix_nano"`
+				SeverityNumber       string `json:"severity_number"`
+				SeverityText         string `json:"severity_text"`
+				EventName            string `json:"event_name"`
+				Body                 struct {
+					StringValue string `json:"string_value"`
+				} `json:"body"`
+				Attributes []otlpTestKeyValue `json:"attributes"`
+			} `json:"log_records"`
+		} `json:"scope_logs"`
+	} `json:"resource_logs"`
+}
+
+// otlpTestDecode decodes an encoded ExportLogsServiceRequest using the test message descriptor.
+func otlpTestDecode(t *testing.T, buf []byte) otlpTestRequest {
+	t.Helper()
+
+	msg := dynamicpb.NewMessage(otlpTestDescriptor(t))
+
+	err := proto.Unmarshal(buf, msg)
+	if err != nil {
+		t.Fatalf("Failed decoding OTLP request: %v", err)
+	}
+
+	otlpTestCheckUnknown(t, msg)
+
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var req otlpTestRequest
+	err = json.Unmarshal(data, &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return req
+}
+
+func TestOTLPEncodeRequest(t *testing.T) {
+	ts := time.Date(2025, 3, 14, 15, 9, 26, 535897932, time.UTC)
+
+	records := []otlpRecord{
+		{
+			resource:     map[string]string{"service.name": "incus", "host.name": "server01"},
+			timestamp:    ts,
+			severity:     otlpSeverityInfo,
+			severityText: "INFO",
+			eventName:    "instance-started",
+			body:         "Started instance",
+			attributes:   map[string]string{"project": "default", "instance": "c1"},
+		},
+		{
+			resource:     map[string]string{"service.name": "incus", "host.name": "server02"},
+			timestamp:    ts.Add(time.Second),
+			severity:     otlpSeverityError,
+			severityText: "ERROR",
+			body:         "Failed starting instance",
+		},
+		{
+			resource:     map[string]string{"host.name": "server01", "service.name": "incus"},
+			timestamp:    ts.Add(2 * time.Second),
+			severity:     otlpSeverityWarn,
+			severityText: "WARN",
+			body:         "Ünïcode body ✓",
+		},
+	}
+
+	req := otlpTestDecode(t, otlpEncodeRequest(records))
+
+	// Records are grouped by resource, in order of first appearance.
+	if len(req.ResourceLogs) != 2 {
+		t.Fatalf("Expected 2 resource logs, got %d", len(req.ResourceLogs))
+	}
+
+	first := req.ResourceLogs[0]
+	wantResource := []otlpTestKeyValue{{Key: "host.name"}, {Key: "service.name"}}
+	wantResource[0].Value.StringValue = "server01"
+	wantResource[1].Value.StringValue = "incus"
+
+	if len(first.Resource.Attributes) != len(wantResource) {
+		t.Fatalf("Unexpected resource attributes: %+v", first.Resource.Attributes)
+	}
+
+	for i, kv := range wantResource {
+		if first.Resource.Attributes[i] != kv {
+			t.Fatalf("Unexpected resource attribute %d: got %+v, want %+v", i, first.Resource.Attributes[i], kv)
+		}
+	}
+
+	if len(first.ScopeLogs) != 1 || first.ScopeLogs[0].Scope.Name != "incus" {
+		t.Fatalf("Unexpected scope logs: %+v", first.ScopeLogs)
+	}
+
+	logRecords := first.ScopeLogs[0].LogRecords
+	if len(logRecords) != 2 {
+		t.Fatalf("Expected 2 log records for the first resource, got %d", len(logRecords))
+	}
+
+	record := logRecords[0]
+	if record.TimeUnixNano != "1741964966535897932" {
+		t.Errorf("Unexpected timestamp %q", record.TimeUnixNano)
+	}
+
+	if record.ObservedTimeUnixNano == "" {
+		t.Error("Missing observed timestamp")
+	}
+
+	if record.SeverityNumber != "SEVERITY_NUMBER_INFO" || record.SeverityText != "INFO" {
+		t.Errorf("Unexpected severity %q (%q)", record.SeverityNumber, record.SeverityText)
+	}
+
+	if record.EventName != "instance-started" {
+		t.Errorf("Unexpected event name %q", record.EventName)
+	}
+
+	if record.Body.StringValue != "Started instance" {
+		t.Errorf("Unexpected body %q", record.Body.StringValue)
+	}
+
+	if len(record.Attributes) != 2 || record.Attributes[0].Key != "instance" || record.Attributes[0].Value.StringValue != "c1" || record.Attributes[1].Key != "project" || record.Attributes[1].Value.StringValue != "default" {
+		t.Errorf("Unexpected record attributes: %+v", record.Attributes)
+	}
+
+	if logRecords[1].Body.StringValue != "Ünïcode body ✓" || logRecords[1].SeverityNumber != "SEVERITY_NUMBER_WARN" {
+		t.Errorf("Unexpected second record: %+v", logRecords[1])
+	}
+
+	second := req.ResourceLogs[1]
+	if len(second.ScopeLogs) != 1 || len(second.ScopeLogs[0].LogRecords) != 1 {
+		t.Fatalf("Unexpected scope logs for the second resource: %+v", second.ScopeLogs)
+	}
+
+	if second.ScopeLogs[0].LogRecords[0].EventName != "" || second.ScopeLogs[0].LogRecords[0].SeverityNumber != "SEVERITY_NUMBER_ERROR" {
+		t.Errorf("Unexpected record for the second resource: %+v", second.ScopeLogs[0].LogRecords[0])
+	}
+}
+
+func TestOTLPEncodeRequestEmpty(t *testing.T) {
+	req := otlpTestDecode(t, otlpEncodeRequest(nil))
+	if len(req.ResourceLogs) != 0 {
+		t.Fatalf("Expected no resource logs, got %d", len(req.ResourceLogs))
+	}
+}
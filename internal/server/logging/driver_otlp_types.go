@@ -0,0 +1,166 @@
+package logging
+
+import (
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// This is a minimal encoder for the OTLP logs protocol.
+// See https://github.com/open-telemetry/opentelemetry-proto/blob/v1.5.0/opentelemetry/proto/logs/v1/logs.proto.
+
+// OTLP severity numbers.
+const (
+	otlpSeverityTrace = 1
+	otlpSeverityDebug = 5
+	otlpSeverityInfo  = 9
+	otlpSeverityWarn  = 13
+	otlpSeverityError = 17
+	otlpSeverityFatal = 21
+)
+
+// otlpRecord represents a single OTLP log record along with the attributes of the resource it belongs to.
+type otlpRecord struct {
+	resource     map[string]string
+	timestamp    time.Time
+	severity     int
+	severityText string
+	eventName    string
+	body         string
+	attributes   map[string]string
+}
+
+// otlpResourceKey returns a string uniquely identifying the resource attributes.
+func otlpResourceKey(attributes map[string]string) string {
+	return LabelSet(attributes).String()
+}
+
+// otlpEncodeRequest encodes the records as an ExportLogsServiceRequest message.
+// Records sharing the same resource attributes are grouped in a single ResourceLogs entry.
+func otlpEncodeRequest(records []otlpRecord) []byte {
+	keys := []string{}
+	resources := map[string][]otlpRecord{}
+
+	for _, record := range records {
+		key := otlpResourceKey(record.resource)
+
+		_, ok := resources[key]
+		if !ok {
+			keys = append(keys, key)
+		}
+
+		resources[key] = append(resources[key], record)
+	}
+
+	var buf []byte
+
+	for _, key := range keys {
+		// ExportLogsServiceRequest.resource_logs (1).
+		buf = otlpAppendMessage(buf, 1, otlpEncodeResourceLogs(resources[key]))
+	}
+
+	return buf
+}
+
+// otlpEncodeResourceLogs encodes a ResourceLogs message.
+func otlpEncodeResourceLogs(records []otlpRecord) []byte {
+	var resource []byte
+	for _, kv := range otlpEncodeAttributes(records[0].resource) {
+		// Resource.attributes (1).
+		resource = otlpAppendMessage(resource, 1, kv)
+	}
+
+	// InstrumentationScope.name (1).
+	scope := otlpAppendString(nil, 1, "incus")
+
+	// ScopeLogs.scope (1).
+	scopeLogs := otlpAppendMessage(nil, 1, scope)
+	for _, record := range records {
+		// ScopeLogs.log_records (2).
+		scopeLogs = otlpAppendMessage(scopeLogs, 2, otlpEncodeLogRecord(record))
+	}
+
+	// ResourceLogs.resource (1) and ResourceLogs.scope_logs (2).
+	buf := otlpAppendMessage(nil, 1, resource)
+	buf = otlpAppendMessage(buf, 2, scopeLogs)
+
+	return buf
+}
+
+// otlpEncodeLogRecord encodes a LogRecord message.
+func otlpEncodeLogRecord(record otlpRecord) []byte {
+	var buf []byte
+
+	// LogRecord.time_unix_nano (1).
+	buf = protowire.AppendTag(buf, 1, protowire.Fixed64Type)
+	buf = protowire.AppendFixed64(buf, uint64(record.timestamp.UnixNano()))
+
+	// LogRecord.severity_number (2).
+	buf = protowire.AppendTag(buf, 2, protowire.VarintType)
+	buf = protowire.AppendVarint(buf, uint64(record.severity))
+
+	// LogRecord.severity_text (3).
+	buf = otlpAppendString(buf, 3, record.severityText)
+
+	// LogRecord.body (5).
+	buf = otlpAppendMessage(buf, 5, otlpEncodeStringValue(record.body))
+
+	// LogRecord.attributes (6).
+	for _, kv := range otlpEncodeAttributes(record.attributes) {
+		buf = otlpAppendMessage(buf, 6, kv)
+	}
+
+	// LogRecord.observed_time_unix_nano (11).
+	buf = protowire.AppendTag(buf, 11, protowire.Fixed64Type)
+	buf = protowire.AppendFixed64(buf, uint64(time.Now().UnixNano()))
+
+	// LogRecord.event_name (12).
+	buf = otlpAppendString(buf, 12, record.eventName)
+
+	return buf
+}
+
+// otlpEncodeAttributes encodes the attributes as a sorted list of KeyValue messages.
+func otlpEncodeAttributes(attributes map[string]string) [][]byte {
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	result := make([][]byte, 0, len(keys))
+	for _, k := range keys {
+		// KeyValue.key (1) and KeyValue.value (2).
+		kv := otlpAppendString(nil, 1, k)
+		kv = otlpAppendMessage(kv, 2, otlpEncodeStringValue(attributes[k]))
+
+		result = append(result, kv)
+	}
+
+	return result
+}
+
+// otlpEncodeStringValue encodes an AnyValue message holding a string.
+func otlpEncodeStringValue(value string) []byte {
+	// AnyValue.string_value (1).
+	buf := protowire.AppendTag(nil, 1, protowire.BytesType)
+	return protowire.AppendString(buf, value)
+}
+
+// otlpAppendString appends a non-empty string field.
+func otlpAppendString(buf []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return buf
+	}
+
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendString(buf, value)
+}
+
+// otlpAppendMessage appends an embedded message field.
+func otlpAppendMessage(buf []byte, num protowire.Number, value []byte) []byte {
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendBytes(buf, value)
+}
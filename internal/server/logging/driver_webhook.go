@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/lxc/incus/v7/internal/server/state"
@@ -26,6 +27,11 @@ type WebhookLogger struct {
 	username string
 	password string
 	retry    int
+
+	ctx  context.Context
+	quit chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
 }
 
 // NewWebhookLogger instantiates a new webhook logger.
@@ -64,52 +70,127 @@ func NewWebhookLogger(s *state.State, name string) (*WebhookLogger, error) {
 		client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
 	}
 
-	return &WebhookLogger{
+	spool, err := newSpoolFromConfig(s, name)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookLogger := &WebhookLogger{
 		common:   newCommonLogger(name, s.GlobalConfig),
 		client:   client,
 		address:  address,
 		username: username,
 		password: password,
 		retry:    retry,
-	}, nil
+		ctx:      s.ShutdownCtx,
+		quit:     make(chan struct{}),
+	}
+
+	webhookLogger.spool = spool
+
+	return webhookLogger, nil
 }
 
 // HandleEvent handles the event received from the internal event listener.
 func (c *WebhookLogger) HandleEvent(event api.Event) {
+	// Queue the event on disk when spooling is enabled.
+	// Failures are accounted for in the dropped events, logging them would only generate more events.
+	if c.spool != nil {
+		err := c.spool.push(event)
+		if err != nil {
+			c.dropped.Add(1)
+		}
+
+		return
+	}
+
+	for range c.retry {
+		retry, err := c.send(event)
+		if err == nil {
+			return
+		}
+
+		if !retry {
+			break
+		}
+
+		// Wait 10s and try again.
+		time.Sleep(10 * time.Second)
+	}
+
+	c.dropped.Add(1)
+}
+
+// send delivers the event to the webhook and returns whether a failure is worth retrying.
+func (c *WebhookLogger) send(event api.Event) (bool, error) {
 	// JSON data.
 	data, err := json.Marshal(event)
 	if err != nil {
-		return
+		return false, err
 	}
 
 	// Prepare the request.
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, c.address, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, c.address, bytes.NewReader(data))
 	if err != nil {
-		return
+		return false, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	for range c.retry {
-		resp, err := c.client.Do(req)
-		if err != nil {
-			// Wait 10s and try again.
-			time.Sleep(10 * time.Second)
+	if c.username != "" && c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
 
-			continue
-		}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+
+	_ = resp.Body.Close()
 
-		_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		// Only retry 429s and 500s.
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5, fmt.Errorf("Server returned HTTP status %s", resp.Status)
 	}
+
+	return false, nil
+}
+
+// sendSpooled delivers the events read from the spool, in order.
+func (c *WebhookLogger) sendSpooled(events []api.Event) (bool, error) {
+	for _, event := range events {
+		retry, err := c.send(event)
+		if err != nil {
+			return retry, err
+		}
+	}
+
+	return false, nil
 }
 
 // Start starts the webhook logger.
 func (c *WebhookLogger) Start() error {
+	if c.spool == nil {
+		return nil
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.spool.run(c.ctx, c.quit, 1, c.sendSpooled)
+	}()
+
 	return nil
 }
 
 // Stop cleans up the webhook logger.
 func (c *WebhookLogger) Stop() {
+	c.once.Do(func() { close(c.quit) })
+	c.wg.Wait()
+
+	if c.spool != nil {
+		c.spool.close()
+	}
 }
 
 // Validate checks whether the logger configuration is correct.
@@ -0,0 +1,521 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/server/state"
+	internalUtil "github.com/lxc/incus/v7/internal/util"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/units"
+	"github.com/lxc/incus/v7/shared/util"
+)
+
+const (
+	// spoolSegmentSize is the maximum size after which a new spool segment is started.
+	spoolSegmentSize = 1024 * 1024
+
+	// spoolSegments is the minimum number of segments the spool is split into, so that only a fraction
+	// of the events get dropped when the spool is full.
+	spoolSegments = 10
+
+	// spoolSegmentSuffix is the file name suffix of spool segments.
+	spoolSegmentSuffix = ".spool"
+
+	// spoolRetryInterval is the delay between delivery attempts of spooled events.
+	spoolRetryInterval = 10 * time.Second
+)
+
+// spoolSender delivers a batch of spooled events.
+// It returns whether a failed delivery is worth retrying.
+type spoolSender func(events []api.Event) (bool, error)
+
+// spoolCursor records the position in the spool reached by a read.
+type spoolCursor struct {
+	segment uint64
+	offset  int64
+	count   int64
+	expired int64
+}
+
+// spool is a persistent on-disk queue of events, bounded by size and age.
+//
+// Events are stored as JSON lines in a sequence of segment files which are removed once fully delivered.
+// Delivery is at-least-once, events delivered right before a restart may be sent again.
+type spool struct {
+	path    string
+	maxSize int64
+	maxAge  time.Duration
+
+	mu       sync.Mutex
+	segments []uint64
+	size     int64
+	queued   int64
+	dropped  uint64
+
+	readOffset  int64
+	writeFile   *os.File
+	writeSize   int64
+	segmentSize int64
+
+	notify chan struct{}
+}
+
+// newSpoolFromConfig returns the on-disk spool of the logger or nil if spooling isn't enabled.
+func newSpoolFromConfig(s *state.State, name string) (*spool, error) {
+	sizeStr, maxAgeStr := s.GlobalConfig.LoggingConfigForSpool(name)
+	if sizeStr == "" {
+		return nil, nil
+	}
+
+	maxSize, err := units.ParseByteSizeString(sizeStr)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid spool size %q: %w", sizeStr, err)
+	}
+
+	maxAge, err := time.ParseDuration(maxAgeStr)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid spool maximum age %q: %w", maxAgeStr, err)
+	}
+
+	return newSpool(spoolPath(name), maxSize, maxAge)
+}
+
+// spoolPath returns the directory holding the spool of the logger.
+func spoolPath(name string) string {
+	return internalUtil.VarPath("logging", name)
+}
+
+// removeUnusedSpools removes the on-disk spools of the loggers which aren't configured with spooling anymore.
+// The names are those of the loggers whose spool may have changed, or all of them when nil.
+func removeUnusedSpools(s *state.State, loggers map[string]string, names []string) {
+	if names == nil {
+		entries, err := os.ReadDir(internalUtil.VarPath("logging"))
+		if err != nil {
+			return
+		}
+
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+	}
+
+	for _, name := range names {
+		_, ok := loggers[name]
+		sizeStr, _ := s.GlobalConfig.LoggingConfigForSpool(name)
+		if ok && sizeStr != "" {
+			continue
+		}
+
+		if !util.PathExists(spoolPath(name)) {
+			continue
+		}
+
+		err := os.RemoveAll(spoolPath(name))
+		if err != nil {
+			logger.Warn("Failed removing logging spool", logger.Ctx{"logger": name, "err": err})
+		}
+	}
+}
+
+// newSpool opens (or creates) the spool stored in the given directory.
+func newSpool(path string, maxSize int64, maxAge time.Duration) (*spool, error) {
+	err := os.MkdirAll(path, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating spool directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading spool directory: %w", err)
+	}
+
+	sp := &spool{
+		path:        path,
+		maxSize:     maxSize,
+		maxAge:      maxAge,
+		segmentSize: min(spoolSegmentSize, max(maxSize/spoolSegments, 1)),
+		notify:      make(chan struct{}, 1),
+	}
+
+	// Load the existing segments, left over from a previous run.
+	for _, entry := range entries {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(entry.Name(), spoolSegmentSuffix), 10, 64)
+		if err != nil || !strings.HasSuffix(entry.Name(), spoolSegmentSuffix) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		count, err := sp.countEvents(seq, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		sp.segments = append(sp.segments, seq)
+		sp.size += info.Size()
+		sp.queued += count
+	}
+
+	slices.Sort(sp.segments)
+
+	return sp, nil
+}
+
+// segmentPath returns the path of the given segment.
+func (sp *spool) segmentPath(seq uint64) string {
+	return filepath.Join(sp.path, fmt.Sprintf("%020d%s", seq, spoolSegmentSuffix))
+}
+
+// countEvents returns the number of events in a segment after the given offset.
+func (sp *spool) countEvents(seq uint64, offset int64) (int64, error) {
+	f, err := os.Open(sp.segmentPath(seq))
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.Read(buf)
+		count += int64(bytes.Count(buf[:n], []byte{'\n'}))
+
+		if err == io.EOF {
+			return count, nil
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// isWriteSegment returns whether the given segment is the one being written to.
+// Must be called with the lock held.
+func (sp *spool) isWriteSegment(seq uint64) bool {
+	return sp.writeFile != nil && seq == sp.segments[len(sp.segments)-1]
+}
+
+// removeSegment removes the oldest segment once fully read.
+// Must be called with the lock held.
+func (sp *spool) removeSegment() error {
+	info, err := os.Stat(sp.segmentPath(sp.segments[0]))
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(sp.segmentPath(sp.segments[0]))
+	if err != nil {
+		return err
+	}
+
+	sp.segments = sp.segments[1:]
+	sp.size -= info.Size()
+	sp.readOffset = 0
+
+	return nil
+}
+
+// dropOldestSegment removes the oldest segment, accounting for the events it still held as dropped.
+// Must be called with the lock held.
+func (sp *spool) dropOldestSegment() error {
+	seq := sp.segments[0]
+
+	count, err := sp.countEvents(seq, sp.readOffset)
+	if err != nil {
+		return err
+	}
+
+	err = sp.removeSegment()
+	if err != nil {
+		return err
+	}
+
+	sp.queued -= count
+	sp.dropped += uint64(count)
+
+	return nil
+}
+
+// push appends an event to the spool, dropping the oldest events if the spool is full.
+// The event itself isn't accounted for as dropped if it can't be added.
+func (sp *spool) push(event api.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	data = append(data, '\n')
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	// Make room for the new event by dropping the oldest segments (but never the one being written).
+	for sp.size+int64(len(data)) > sp.maxSize && len(sp.segments) > 0 && !sp.isWriteSegment(sp.segments[0]) {
+		err = sp.dropOldestSegment()
+		if err != nil {
+			return fmt.Errorf("Failed dropping spool segment: %w", err)
+		}
+	}
+
+	if sp.size+int64(len(data)) > sp.maxSize {
+		return fmt.Errorf("Spool is full (%d bytes)", sp.maxSize)
+	}
+
+	// Start a new segment if needed.
+	if sp.writeFile == nil || sp.writeSize >= sp.segmentSize {
+		if sp.writeFile != nil {
+			_ = sp.writeFile.Close()
+		}
+
+		var seq uint64
+		if len(sp.segments) > 0 {
+			seq = sp.segments[len(sp.segments)-1] + 1
+		}
+
+		f, err := os.OpenFile(sp.segmentPath(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_EXCL, 0o600)
+		if err != nil {
+			return fmt.Errorf("Failed creating spool segment: %w", err)
+		}
+
+		sp.segments = append(sp.segments, seq)
+		sp.writeFile = f
+		sp.writeSize = 0
+	}
+
+	n, err := sp.writeFile.Write(data)
+	sp.writeSize += int64(n)
+	sp.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("Failed writing to spool segment: %w", err)
+	}
+
+	sp.queued++
+
+	// Wake up the sender.
+	select {
+	case sp.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// peek returns up to maxEvents of the oldest events, skipping over those older than the maximum age.
+// The returned cursor must be passed to commit once the events have been delivered.
+func (sp *spool) peek(maxEvents int) ([]api.Event, spoolCursor, error) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	for len(sp.segments) > 0 {
+		events, cursor, err := sp.readSegment(maxEvents)
+		if err != nil {
+			return nil, cursor, err
+		}
+
+		// Move on to the next segment once done with this one.
+		if cursor.count == 0 && !sp.isWriteSegment(cursor.segment) {
+			err = sp.removeSegment()
+			if err != nil {
+				return nil, cursor, err
+			}
+
+			continue
+		}
+
+		return events, cursor, nil
+	}
+
+	return nil, spoolCursor{}, nil
+}
+
+// readSegment reads up to maxEvents from the oldest segment.
+// Must be called with the lock held.
+func (sp *spool) readSegment(maxEvents int) ([]api.Event, spoolCursor, error) {
+	cursor := spoolCursor{segment: sp.segments[0], offset: sp.readOffset}
+
+	f, err := os.Open(sp.segmentPath(cursor.segment))
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	_, err = f.Seek(sp.readOffset, io.SeekStart)
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	events := []api.Event{}
+	reader := bufio.NewReader(f)
+
+	for len(events) < maxEvents {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// Ignore partially written lines.
+			break
+		}
+
+		if err != nil {
+			return nil, cursor, err
+		}
+
+		cursor.offset += int64(len(line))
+		cursor.count++
+
+		event := api.Event{}
+
+		err = json.Unmarshal(line, &event)
+		if err != nil || time.Since(event.Timestamp) > sp.maxAge {
+			cursor.expired++
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	return events, cursor, nil
+}
+
+// commit marks the events up to the cursor as processed, with any undelivered ones counted as dropped.
+func (sp *spool) commit(cursor spoolCursor, undelivered int) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	sp.dropped += uint64(cursor.expired) + uint64(undelivered)
+
+	// Skip if the segment was dropped in the meantime.
+	if len(sp.segments) == 0 || sp.segments[0] != cursor.segment {
+		return
+	}
+
+	sp.readOffset = cursor.offset
+	sp.queued -= cursor.count
+
+	// Remove the segment once fully read, unless it's still being written to.
+	if sp.isWriteSegment(cursor.segment) {
+		return
+	}
+
+	info, err := os.Stat(sp.segmentPath(cursor.segment))
+	if err != nil || info.Size() > sp.readOffset {
+		return
+	}
+
+	err = sp.removeSegment()
+	if err != nil {
+		logger.Warn("Failed removing logging spool segment", logger.Ctx{"path": sp.segmentPath(cursor.segment), "err": err})
+	}
+}
+
+// run delivers the spooled events in order using the sender until the context is cancelled or quit is closed.
+func (sp *spool) run(ctx context.Context, quit chan struct{}, maxEvents int, send spoolSender) {
+	wait := func(d time.Duration) bool {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-quit:
+			return false
+		case <-sp.notify:
+			return true
+		case <-timer.C:
+			return true
+		}
+	}
+
+	for {
+		events, cursor, err := sp.peek(maxEvents)
+		if err != nil {
+			logger.Warn("Failed reading logging spool", logger.Ctx{"path": sp.path, "err": err})
+
+			if !wait(spoolRetryInterval) {
+				return
+			}
+
+			continue
+		}
+
+		// Nothing to deliver, wait for new events.
+		if cursor.count == 0 {
+			if !wait(spoolRetryInterval) {
+				return
+			}
+
+			continue
+		}
+
+		if len(events) == 0 {
+			sp.commit(cursor, 0)
+			continue
+		}
+
+		retry, err := send(events)
+		if err == nil {
+			sp.commit(cursor, 0)
+			continue
+		}
+
+		if !retry {
+			sp.commit(cursor, len(events))
+			continue
+		}
+
+		// Keep the events and retry later.
+		timer := time.NewTimer(spoolRetryInterval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-quit:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// close closes the segment being written to.
+func (sp *spool) close() {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if sp.writeFile != nil {
+		_ = sp.writeFile.Close()
+		sp.writeFile = nil
+	}
+}
+
+// stats returns the number of queued events, the size of the spool and the number of dropped events.
+func (sp *spool) stats() (int64, int64, uint64) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	return sp.queued, sp.size, sp.dropped
+}
@@ -0,0 +1,289 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+func spoolTestEvent(t *testing.T, id int, timestamp time.Time) api.Event {
+	t.Helper()
+
+	metadata, err := json.Marshal(map[string]int{"id": id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return api.Event{Type: "logging", Timestamp: timestamp, Metadata: metadata}
+}
+
+func spoolTestEventID(t *testing.T, event api.Event) int {
+	t.Helper()
+
+	var metadata map[string]int
+	err := json.Unmarshal(event.Metadata, &metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return metadata["id"]
+}
+
+func TestSpoolPushPeekCommit(t *testing.T) {
+	sp, err := newSpool(t.TempDir(), 1024*1024, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sp.close()
+
+	for i := range 5 {
+		err = sp.push(spoolTestEvent(t, i, time.Now()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events, cursor, err := sp.peek(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 3 || cursor.count != 3 {
+		t.Fatalf("Expected 3 events, got %d (cursor count %d)", len(events), cursor.count)
+	}
+
+	// Peeking again without committing returns the same events.
+	again, _, err := sp.peek(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if spoolTestEventID(t, again[0]) != spoolTestEventID(t, events[0]) {
+		t.Fatal("Uncommitted events were skipped")
+	}
+
+	sp.commit(cursor, 0)
+
+	events, cursor, err = sp.peek(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 || spoolTestEventID(t, events[0]) != 3 || spoolTestEventID(t, events[1]) != 4 {
+		t.Fatalf("Unexpected remaining events: %+v", events)
+	}
+
+	sp.commit(cursor, 1)
+
+	queued, _, dropped := sp.stats()
+	if queued != 0 || dropped != 1 {
+		t.Fatalf("Unexpected stats: queued %d, dropped %d", queued, dropped)
+	}
+}
+
+func TestSpoolMaxSize(t *testing.T) {
+	event := spoolTestEvent(t, 0, time.Now())
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Room for 20 events, split in segments of 2 events.
+	eventSize := int64(len(data) + 1)
+	sp, err := newSpool(t.TempDir(), 20*eventSize, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sp.close()
+
+	for i := range 30 {
+		err = sp.push(spoolTestEvent(t, i, time.Now()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	queued, size, dropped := sp.stats()
+	if size > 20*eventSize {
+		t.Fatalf("Spool grew over its maximum size: %d > %d", size, 20*eventSize)
+	}
+
+	if queued+int64(dropped) != 30 {
+		t.Fatalf("Events went missing: queued %d, dropped %d", queued, dropped)
+	}
+
+	// The oldest events are the ones dropped.
+	events, _, err := sp.peek(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if spoolTestEventID(t, events[0]) != int(dropped) {
+		t.Fatalf("Expected oldest remaining event to be %d, got %d", dropped, spoolTestEventID(t, events[0]))
+	}
+
+	// An event larger than the whole spool is rejected.
+	sp.maxSize = eventSize - 1
+	err = sp.push(spoolTestEvent(t, 99, time.Now()))
+	if err == nil {
+		t.Fatal("Expected an error when the spool is full")
+	}
+}
+
+func TestSpoolMaxAge(t *testing.T) {
+	sp, err := newSpool(t.TempDir(), 1024*1024, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sp.close()
+
+	err = sp.push(spoolTestEvent(t, 0, time.Now().Add(-time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sp.push(spoolTestEvent(t, 1, time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, cursor, err := sp.peek(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 1 || spoolTestEventID(t, events[0]) != 1 || cursor.expired != 1 {
+		t.Fatalf("Expected only the recent event, got %d events (%d expired)", len(events), cursor.expired)
+	}
+
+	sp.commit(cursor, 0)
+
+	_, _, dropped := sp.stats()
+	if dropped != 1 {
+		t.Fatalf("Expected the expired event to be counted as dropped, got %d", dropped)
+	}
+}
+
+func TestSpoolReload(t *testing.T) {
+	path := t.TempDir()
+
+	sp, err := newSpool(path, 1024*1024, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 3 {
+		err = sp.push(spoolTestEvent(t, i, time.Now()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sp.close()
+
+	// Simulate a partially written event.
+	f, err := os.OpenFile(sp.segmentPath(sp.segments[len(sp.segments)-1]), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.WriteString(`{"type":"logg`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = f.Close()
+
+	sp, err = newSpool(path, 1024*1024, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sp.close()
+
+	queued, _, _ := sp.stats()
+	if queued != 3 {
+		t.Fatalf("Expected 3 queued events after reload, got %d", queued)
+	}
+
+	events, _, err := sp.peek(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events after reload, got %d", len(events))
+	}
+
+	// New events go to a new segment after the existing ones.
+	err = sp.push(spoolTestEvent(t, 3, time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sp.segments) != 2 {
+		t.Fatalf("Expected 2 segments, got %d", len(sp.segments))
+	}
+}
+
+func TestSpoolRun(t *testing.T) {
+	sp, err := newSpool(t.TempDir(), 1024*1024, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sp.close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	quit := make(chan struct{})
+	received := make(chan int, 10)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		sp.run(ctx, quit, 2, func(events []api.Event) (bool, error) {
+			for _, event := range events {
+				received <- spoolTestEventID(t, event)
+			}
+
+			return false, nil
+		})
+	}()
+
+	for i := range 5 {
+		err = sp.push(spoolTestEvent(t, i, time.Now()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for want := range 5 {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Fatalf("Events delivered out of order: expected %d, got %d", want, got)
+			}
+
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for event %d", want)
+		}
+	}
+
+	close(quit)
+	<-done
+
+	queued, _, dropped := sp.stats()
+	if queued != 0 || dropped != 0 {
+		t.Fatalf("Unexpected stats after delivery: queued %d, dropped %d", queued, dropped)
+	}
+}
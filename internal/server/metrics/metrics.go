@@ -12,13 +12,21 @@ import (
 
 // ProcsTotal is a gauge according to the OpenMetrics spec as its value can decrease.
 var metricTypeGauges = []MetricType{
+	BGPPeerAdvertisedPrefixes,
+	BGPPeerBFDUp,
+	BGPPeerReceivedPrefixes,
+	BGPPeerUp,
+	BGPPeerUptimeSeconds,
 	CPUs,
 	GoGoroutines,
 	GoHeapObjects,
+	LoggingQueueBytes,
+	LoggingQueueEvents,
 	ProcsTotal,
 	ProjectLimit,
 	ProjectResourcesTotal,
 	ProjectUsage,
+	StorageVolumeSnapshots,
 }
 
 // NewMetricSet returns a new MetricSet.
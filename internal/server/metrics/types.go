@@ -155,10 +155,47 @@ const (
 	GoOtherSysBytes
 	// GoNextGCBytes represents the number of heap bytes when next garbage collection will take place.
 	GoNextGCBytes
+	// LoggingQueueEvents represents the number of events waiting in a logger spool.
+	LoggingQueueEvents
+	// LoggingQueueBytes represents the size of a logger spool.
+	LoggingQueueBytes
+	// LoggingDroppedEventsTotal represents the number of events a logger couldn't deliver.
+	LoggingDroppedEventsTotal
+	// StoragePoolSizeBytes represents the total capacity of a storage pool.
+	StoragePoolSizeBytes
+	// StoragePoolUsedBytes represents the used space of a storage pool.
+	StoragePoolUsedBytes
+	// StoragePoolAllocatedBytes represents the sum of the quotas of the volumes in a storage pool.
+	StoragePoolAllocatedBytes
+	// StorageVolumeUsageBytes represents the used space of a custom storage volume.
+	StorageVolumeUsageBytes
+	// StorageVolumeQuotaBytes represents the quota of a custom storage volume.
+	StorageVolumeQuotaBytes
+	// StorageVolumeSnapshots represents the number of snapshots of a custom storage volume.
+	StorageVolumeSnapshots
+	// StorageBucketUsageBytes represents the used space of a storage bucket.
+	StorageBucketUsageBytes
+	// StorageBucketQuotaBytes represents the quota of a storage bucket.
+	StorageBucketQuotaBytes
+	// BGPPeerUp represents whether the BGP session with a peer is established.
+	BGPPeerUp
+	// BGPPeerUptimeSeconds represents the time since the BGP session with a peer was established.
+	BGPPeerUptimeSeconds
+	// BGPPeerReceivedPrefixes represents the number of prefixes received from a BGP peer.
+	BGPPeerReceivedPrefixes
+	// BGPPeerAdvertisedPrefixes represents the number of prefixes advertised to a BGP peer.
+	BGPPeerAdvertisedPrefixes
+	// BGPPeerBFDUp represents whether the BFD session with a BGP peer is up.
+	BGPPeerBFDUp
 )
 
 // MetricNames associates a metric type to its name.
 var MetricNames = map[MetricType]string{
+	BGPPeerAdvertisedPrefixes:   "incus_bgp_peer_advertised_prefixes",
+	BGPPeerBFDUp:                "incus_bgp_peer_bfd_up",
+	BGPPeerReceivedPrefixes:     "incus_bgp_peer_received_prefixes",
+	BGPPeerUp:                   "incus_bgp_peer_up",
+	BGPPeerUptimeSeconds:        "incus_bgp_peer_uptime_seconds",
 	BootTimeSeconds:             "incus_boot_time_seconds",
 	CPUSecondsTotal:             "incus_cpu_seconds_total",
 	CPUs:                        "incus_cpu_effective_total",
@@ -192,6 +229,9 @@ var MetricNames = map[MetricType]string{
 	GoStackInuseBytes:           "incus_go_stack_inuse_bytes",
 	GoStackSysBytes:             "incus_go_stack_sys_bytes",
 	GoSysBytes:                  "incus_go_sys_bytes",
+	LoggingDroppedEventsTotal:   "incus_logging_dropped_events_total",
+	LoggingQueueBytes:           "incus_logging_queue_bytes",
+	LoggingQueueEvents:          "incus_logging_queue_events",
 	MemoryActiveAnonBytes:       "incus_memory_Active_anon_bytes",
 	MemoryActiveFileBytes:       "incus_memory_Active_file_bytes",
 	MemoryActiveBytes:           "incus_memory_Active_bytes",
@@ -225,6 +265,14 @@ var MetricNames = map[MetricType]string{
 	ProjectLimit:                "incus_project_limit",
 	ProjectResourcesTotal:       "incus_project_resources_total",
 	ProjectUsage:                "incus_project_usage",
+	StorageBucketQuotaBytes:     "incus_storage_bucket_quota_bytes",
+	StorageBucketUsageBytes:     "incus_storage_bucket_usage_bytes",
+	StoragePoolAllocatedBytes:   "incus_storage_pool_allocated_bytes",
+	StoragePoolSizeBytes:        "incus_storage_pool_size_bytes",
+	StoragePoolUsedBytes:        "incus_storage_pool_used_bytes",
+	StorageVolumeQuotaBytes:     "incus_storage_volume_quota_bytes",
+	StorageVolumeSnapshots:      "incus_storage_volume_snapshots",
+	StorageVolumeUsageBytes:     "incus_storage_volume_usage_bytes",
 	TimeSeconds:                 "incus_time_seconds",
 	UptimeSeconds:               "incus_uptime_seconds",
 	WarningsTotal:               "incus_warnings_total",
@@ -232,6 +280,11 @@ var MetricNames = map[MetricType]string{
 
 // MetricHeaders represents the metric headers which contain help messages as specified by OpenMetrics.
 var MetricHeaders = map[MetricType]string{
+	BGPPeerAdvertisedPrefixes:   "# HELP incus_bgp_peer_advertised_prefixes The number of prefixes advertised to the BGP peer.",
+	BGPPeerBFDUp:                "# HELP incus_bgp_peer_bfd_up Whether the BFD session with the BGP peer is up.",
+	BGPPeerReceivedPrefixes:     "# HELP incus_bgp_peer_received_prefixes The number of prefixes received from the BGP peer.",
+	BGPPeerUp:                   "# HELP incus_bgp_peer_up Whether the BGP session with the peer is established.",
+	BGPPeerUptimeSeconds:        "# HELP incus_bgp_peer_uptime_seconds The time since the BGP session with the peer was established.",
 	BootTimeSeconds:             "# HELP incus_boot_time_seconds The unix epoch at the time of the instance start.",
 	CPUSecondsTotal:             "# HELP incus_cpu_seconds_total The total number of CPU time used in seconds.",
 	CPUs:                        "# HELP incus_cpu_effective_total The total number of effective CPUs.",
@@ -265,6 +318,9 @@ var MetricHeaders = map[MetricType]string{
 	GoStackInuseBytes:           "# HELP incus_go_stack_inuse_bytes Number of bytes in use by the stack allocator.",
 	GoStackSysBytes:             "# HELP incus_go_stack_sys_bytes Number of bytes obtained from system for stack allocator.",
 	GoSysBytes:                  "# HELP incus_go_sys_bytes Number of bytes obtained from system.",
+	LoggingDroppedEventsTotal:   "# HELP incus_logging_dropped_events_total The number of events a logger couldn't deliver.",
+	LoggingQueueBytes:           "# HELP incus_logging_queue_bytes The size of the logger spool in bytes.",
+	LoggingQueueEvents:          "# HELP incus_logging_queue_events The number of events waiting in the logger spool.",
 	MemoryActiveAnonBytes:       "# HELP incus_memory_Active_anon_bytes The amount of anonymous memory on active LRU list.",
 	MemoryActiveFileBytes:       "# HELP incus_memory_Active_file_bytes The amount of file-backed memory on active LRU list.",
 	MemoryActiveBytes:           "# HELP incus_memory_Active_bytes The amount of memory on active LRU list.",
@@ -298,6 +354,14 @@ var MetricHeaders = map[MetricType]string{
 	ProjectLimit:                "# HELP incus_project_limit Current project resource limit.",
 	ProjectResourcesTotal:       "# HELP incus_project_resources_total Current resource count in a project.",
 	ProjectUsage:                "# HELP incus_project_usage Current project resource usage.",
+	StorageBucketQuotaBytes:     "# HELP incus_storage_bucket_quota_bytes The quota of the storage bucket in bytes.",
+	StorageBucketUsageBytes:     "# HELP incus_storage_bucket_usage_bytes The used space of the storage bucket in bytes.",
+	StoragePoolAllocatedBytes:   "# HELP incus_storage_pool_allocated_bytes The sum of the quotas of the volumes in the storage pool in bytes.",
+	StoragePoolSizeBytes:        "# HELP incus_storage_pool_size_bytes The total capacity of the storage pool in bytes.",
+	StoragePoolUsedBytes:        "# HELP incus_storage_pool_used_bytes The used space of the storage pool in bytes.",
+	StorageVolumeQuotaBytes:     "# HELP incus_storage_volume_quota_bytes The quota of the custom storage volume in bytes.",
+	StorageVolumeSnapshots:      "# HELP incus_storage_volume_snapshots The number of snapshots of the custom storage volume.",
+	StorageVolumeUsageBytes:     "# HELP incus_storage_volume_usage_bytes The used space of the custom storage volume in bytes.",
 	TimeSeconds:                 "# HELP incus_time_seconds The current unix epoch.",
 	UptimeSeconds:               "# HELP incus_uptime_seconds The daemon uptime in seconds.",
 	WarningsTotal:               "# HELP incus_warnings_total The number of active warnings.",
@@ -47,6 +47,9 @@ func FirewallACLRules(s *state.State, aclDeviceName string, aclProjectName strin
 				DestinationPort: rule.DestinationPort,
 				ICMPType:        rule.ICMPType,
 				ICMPCode:        rule.ICMPCode,
+				ConnectionState: rule.ConnectionState,
+				RateLimit:       rule.RateLimit,
+				ConnectionLimit: rule.ConnectionLimit,
 			}
 
 			if rule.State == "logged" {
@@ -124,6 +124,35 @@ func Exists(s *state.State, projectName string, name ...string) error {
 	return nil
 }
 
+// ValidateOVN checks the ACL(s) provided only contain rules which can be applied to OVN networks.
+func ValidateOVN(s *state.State, projectName string, name ...string) error {
+	var acls []cluster.NetworkACL
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		acls, err = cluster.GetNetworkACLs(ctx, tx.Tx(), cluster.NetworkACLFilter{Project: &projectName})
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, acl := range acls {
+		if !slices.Contains(name, acl.Name) {
+			continue
+		}
+
+		err = validateOVNRules(acl.Ingress, acl.Egress)
+		if err != nil {
+			return fmt.Errorf("Network ACL %q can't be used on OVN networks: %w", acl.Name, err)
+		}
+	}
+
+	return nil
+}
+
 // UsedBy finds all networks, profiles and instance NICs that use any of the specified ACLs and executes usageFunc
 // once for each resource using one or more of the ACLs with info about the resource and matched ACLs being used.
 func UsedBy(s *state.State, aclProjectName string, usageFunc func(ctx context.Context, tx *db.ClusterTx, matchedACLNames []string, usageType any, nicName string, nicConfig map[string]string) error, matchACLNames ...string) error {
@@ -776,12 +776,40 @@ func ovnRuleCriteriaToOVNACLRule(s *state.State, direction string, rule *api.Net
 		}
 	}
 
+	// Add connection tracking state filters.
+	if rule.ConnectionState != "" {
+		stateMatches := []string{}
+		for _, connectionState := range util.SplitNTrimSpace(rule.ConnectionState, ",", -1, false) {
+			stateMatch, found := ovnConnectionStateMatches[connectionState]
+			if !found {
+				return ovn.OVNACLRule{}, false, false, nil, fmt.Errorf("Unsupported connection state %q", connectionState)
+			}
+
+			stateMatches = append(stateMatches, stateMatch)
+		}
+
+		matchParts = append(matchParts, strings.Join(stateMatches, " || "))
+	}
+
+	// OVN ACLs don't keep per-source state so limits can't be implemented.
+	if rule.RateLimit != "" || rule.ConnectionLimit != "" {
+		return ovn.OVNACLRule{}, false, false, nil, errors.New("Rate and connection limits aren't supported on OVN networks")
+	}
+
 	// Populate the Match field with the generated match parts.
 	portGroupRule.Match = fmt.Sprintf("(%s)", strings.Join(matchParts, ") && ("))
 
 	return portGroupRule, isAllRule, networkSpecific, networkPeersNeeded, nil
 }
 
+// ovnConnectionStateMatches maps the ACL rule connection states to OVN connection tracking match fields.
+var ovnConnectionStateMatches = map[string]string{
+	"new":         "ct.new",
+	"established": "ct.est",
+	"related":     "ct.rel",
+	"invalid":     "ct.inv",
+}
+
 // ovnRulePortToOVNACLMatch converts protocol (tcp/udp), direction (src/dst) and port criteria list into an OVN
 // match statement.
 func ovnRulePortToOVNACLMatch(protocol string, direction string, portCriteria ...string) string {
@@ -1,8 +1,13 @@
 package acl
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/lxc/incus/v7/shared/api"
 	"github.com/lxc/incus/v7/shared/util"
 	"github.com/lxc/incus/v7/shared/validate"
 )
@@ -28,3 +33,75 @@ func ValidName(name string) error {
 
 	return nil
 }
+
+// ParseRateLimit parses a rate limit in the "<packets>/<unit>" format and returns the packet count and unit.
+func ParseRateLimit(value string) (uint64, string, error) {
+	count, unit, found := strings.Cut(value, "/")
+	if !found {
+		return 0, "", errors.New(`Rate limit must be in the "<packets>/<unit>" format`)
+	}
+
+	packets, err := strconv.ParseUint(count, 10, 32)
+	if err != nil || packets == 0 {
+		return 0, "", fmt.Errorf("Invalid packet count %q", count)
+	}
+
+	validUnits := []string{"second", "minute", "hour", "day"}
+	if !slices.Contains(validUnits, unit) {
+		return 0, "", fmt.Errorf("Rate limit unit must be one of: %s", strings.Join(validUnits, ", "))
+	}
+
+	return packets, unit, nil
+}
+
+// validateRuleLimits checks the rate and connection limits of a rule.
+func validateRuleLimits(rule api.NetworkACLRule) error {
+	if rule.RateLimit == "" && rule.ConnectionLimit == "" {
+		return nil
+	}
+
+	if !slices.Contains([]string{"drop", "reject"}, rule.Action) {
+		return errors.New("Rate and connection limits can only be used with drop or reject actions")
+	}
+
+	if rule.RateLimit != "" && rule.ConnectionLimit != "" {
+		return errors.New("Rate limit and connection limit cannot be used in the same rule")
+	}
+
+	if rule.RateLimit != "" {
+		_, _, err := ParseRateLimit(rule.RateLimit)
+		if err != nil {
+			return fmt.Errorf("Invalid rate limit: %w", err)
+		}
+	}
+
+	if rule.ConnectionLimit != "" {
+		err := validate.IsUint32(rule.ConnectionLimit)
+		if err != nil {
+			return fmt.Errorf("Invalid connection limit: %w", err)
+		}
+
+		if rule.ConnectionLimit == "0" {
+			return errors.New("Invalid connection limit: Must be greater than 0")
+		}
+	}
+
+	return nil
+}
+
+// validateOVNRules checks that the rules can be applied to OVN networks.
+func validateOVNRules(ingress []api.NetworkACLRule, egress []api.NetworkACLRule) error {
+	for i, rule := range ingress {
+		if rule.RateLimit != "" || rule.ConnectionLimit != "" {
+			return fmt.Errorf("Invalid ingress rule %d: Rate and connection limits aren't supported on OVN networks", i)
+		}
+	}
+
+	for i, rule := range egress {
+		if rule.RateLimit != "" || rule.ConnectionLimit != "" {
+			return fmt.Errorf("Invalid egress rule %d: Rate and connection limits aren't supported on OVN networks", i)
+		}
+	}
+
+	return nil
+}
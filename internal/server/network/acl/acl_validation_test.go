@@ -0,0 +1,102 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		packets uint64
+		unit    string
+		wantErr bool
+	}{
+		{value: "10/second", packets: 10, unit: "second"},
+		{value: "1/day", packets: 1, unit: "day"},
+		{value: "10", wantErr: true},
+		{value: "0/second", wantErr: true},
+		{value: "-1/second", wantErr: true},
+		{value: "ten/second", wantErr: true},
+		{value: "10/week", wantErr: true},
+		{value: "4294967296/second", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			packets, unit, err := ParseRateLimit(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if packets != tt.packets || unit != tt.unit {
+				t.Fatalf("Expected %d/%s, got %d/%s", tt.packets, tt.unit, packets, unit)
+			}
+		})
+	}
+}
+
+func TestValidateRuleLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    api.NetworkACLRule
+		wantErr bool
+	}{
+		{name: "no limits", rule: api.NetworkACLRule{Action: "allow"}},
+		{name: "rate limit", rule: api.NetworkACLRule{Action: "drop", RateLimit: "10/second"}},
+		{name: "connection limit", rule: api.NetworkACLRule{Action: "reject", ConnectionLimit: "5"}},
+		{name: "allow action", rule: api.NetworkACLRule{Action: "allow", RateLimit: "10/second"}, wantErr: true},
+		{name: "both limits", rule: api.NetworkACLRule{Action: "drop", RateLimit: "10/second", ConnectionLimit: "5"}, wantErr: true},
+		{name: "invalid rate limit", rule: api.NetworkACLRule{Action: "drop", RateLimit: "10/fortnight"}, wantErr: true},
+		{name: "invalid connection limit", rule: api.NetworkACLRule{Action: "drop", ConnectionLimit: "many"}, wantErr: true},
+		{name: "zero connection limit", rule: api.NetworkACLRule{Action: "drop", ConnectionLimit: "0"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRuleLimits(tt.rule)
+			if tt.wantErr && err == nil {
+				t.Fatal("Expected an error")
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateOVNRules(t *testing.T) {
+	plain := api.NetworkACLRule{Action: "drop", Protocol: "tcp", DestinationPort: "22"}
+	rateLimited := api.NetworkACLRule{Action: "drop", RateLimit: "10/second"}
+	connectionLimited := api.NetworkACLRule{Action: "reject", ConnectionLimit: "5"}
+
+	err := validateOVNRules([]api.NetworkACLRule{plain}, []api.NetworkACLRule{plain})
+	if err != nil {
+		t.Fatalf("Unexpected error for rules without limits: %v", err)
+	}
+
+	err = validateOVNRules(nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error without rules: %v", err)
+	}
+
+	err = validateOVNRules([]api.NetworkACLRule{plain, rateLimited}, nil)
+	if err == nil || err.Error() != "Invalid ingress rule 1: Rate and connection limits aren't supported on OVN networks" {
+		t.Fatalf("Unexpected error for a rate limited ingress rule: %v", err)
+	}
+
+	err = validateOVNRules(nil, []api.NetworkACLRule{connectionLimited})
+	if err == nil || err.Error() != "Invalid egress rule 0: Rate and connection limits aren't supported on OVN networks" {
+		t.Fatalf("Unexpected error for a connection limited egress rule: %v", err)
+	}
+}
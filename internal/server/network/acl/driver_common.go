@@ -462,6 +462,31 @@ func (d *common) validateRule(direction ruleDirection, rule api.NetworkACLRule)
 		}
 	}
 
+	// Validate ConnectionState field.
+	if rule.ConnectionState != "" {
+		if rule.Action == "allow-stateless" {
+			return fmt.Errorf("Connection state cannot be used with %q action", rule.Action)
+		}
+
+		validConnectionStates := []string{"new", "established", "related", "invalid"}
+		connectionStates := util.SplitNTrimSpace(rule.ConnectionState, ",", -1, false)
+		for i, connectionState := range connectionStates {
+			if !slices.Contains(validConnectionStates, connectionState) {
+				return fmt.Errorf("Connection state must be one of: %s", strings.Join(validConnectionStates, ", "))
+			}
+
+			if slices.Contains(connectionStates[:i], connectionState) {
+				return fmt.Errorf("Duplicate connection state %q", connectionState)
+			}
+		}
+	}
+
+	// Validate RateLimit and ConnectionLimit fields.
+	err = validateRuleLimits(rule)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -621,6 +646,22 @@ func (d *common) Update(config *api.NetworkACLPut, clientType request.ClientType
 		return err
 	}
 
+	// Check that rules with limits won't end up applied to OVN networks.
+	ovnErr := validateOVNRules(config.Ingress, config.Egress)
+	if ovnErr != nil {
+		aclNets := map[string]NetworkACLUsage{}
+		err = NetworkUsage(d.state, d.projectName, []string{d.info.Name}, aclNets)
+		if err != nil {
+			return fmt.Errorf("Failed getting ACL network usage: %w", err)
+		}
+
+		for _, aclNet := range aclNets {
+			if aclNet.Type == "ovn" {
+				return fmt.Errorf("Network ACL is used by OVN network %q: %w", aclNet.Name, ovnErr)
+			}
+		}
+	}
+
 	reverter := revert.New()
 	defer reverter.Fail()
 
@@ -61,6 +61,7 @@ func (n *bridge) DBType() db.NetworkType {
 func (n *bridge) Info() Info {
 	info := n.common.Info()
 	info.AddressForwards = true
+	info.LoadBalancers = true
 
 	return info
 }
@@ -714,6 +715,70 @@ func (n *bridge) Validate(config map[string]string, clientType request.ClientTyp
 	// defaultdesc: `180`
 	// shortdesc: Peer session hold time (in seconds; optional)
 
+	// gendoc:generate(entity=network_bridge, group=bgp, key=bgp.peers.NAME.bfd)
+	//
+	// ---
+	// type: bool
+	// condition: BGP server
+	// defaultdesc: `false`
+	// shortdesc: Whether to use BFD to detect peer failures
+
+	// gendoc:generate(entity=network_bridge, group=bgp, key=bgp.peers.NAME.bfd_interval)
+	//
+	// ---
+	// type: integer
+	// condition: BGP server
+	// defaultdesc: `300`
+	// shortdesc: BFD transmit and receive interval (in milliseconds)
+
+	// gendoc:generate(entity=network_bridge, group=bgp, key=bgp.peers.NAME.bfd_multiplier)
+	//
+	// ---
+	// type: integer
+	// condition: BGP server
+	// defaultdesc: `3`
+	// shortdesc: Number of missed BFD packets before the peer is considered down
+
+	// gendoc:generate(entity=network_bridge, group=bgp, key=bgp.peers.NAME.graceful_restart)
+	//
+	// ---
+	// type: bool
+	// condition: BGP server
+	// defaultdesc: `true`
+	// shortdesc: Whether to use BGP graceful restart so the peer retains routes while the server restarts
+
+	// gendoc:generate(entity=network_bridge, group=bgp, key=bgp.peers.NAME.graceful_restart_time)
+	//
+	// ---
+	// type: integer
+	// condition: BGP server
+	// defaultdesc: `3600`
+	// shortdesc: Time the peer retains routes while the server restarts (in seconds)
+
+	// gendoc:generate(entity=network_bridge, group=bgp, key=bgp.peers.NAME.communities)
+	//
+	// ---
+	// type: string
+	// condition: BGP server
+	// defaultdesc: -
+	// shortdesc: Comma-separated list of BGP communities (`ASN:VALUE` or `ASN:VALUE:VALUE`) added to exported routes
+
+	// gendoc:generate(entity=network_bridge, group=bgp, key=bgp.peers.NAME.med)
+	//
+	// ---
+	// type: integer
+	// condition: BGP server
+	// defaultdesc: -
+	// shortdesc: Multi-exit discriminator set on exported routes
+
+	// gendoc:generate(entity=network_bridge, group=bgp, key=bgp.peers.NAME.import_routes)
+	//
+	// ---
+	// type: bool
+	// condition: BGP server
+	// defaultdesc: `true`
+	// shortdesc: Whether to accept routes received from the peer
+
 	// Add the BGP validation rules.
 	bgpRules, err := n.bgpValidationRules(config)
 	if err != nil {
@@ -2029,6 +2094,12 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
+	// Setup network load balancers.
+	err = n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
 	// Setup BGP.
 	err = n.bgpSetup(oldConfig)
 	if err != nil {
@@ -2048,6 +2119,9 @@ func (n *bridge) Stop() error {
 		return nil
 	}
 
+	// Stop the load balancer health checks.
+	lbHealthCheckersStop(n.id)
+
 	// Clear BGP.
 	err := n.bgpClear(n.config)
 	if err != nil {
@@ -2809,102 +2883,114 @@ func (n *bridge) ForwardCreate(forward api.NetworkForwardsPost, clientType reque
 		return err
 	}
 
-	// Check if hairpin mode needs to be enabled on active NIC bridge ports.
-	if n.config["bridge.driver"] != "openvswitch" {
-		brNetfilterEnabled := false
-		for _, ipVersion := range []uint{4, 6} {
-			if BridgeNetfilterEnabled(ipVersion) == nil {
-				brNetfilterEnabled = true
-				break
+	// If we are the first forward on this bridge, enable hairpin mode on active NIC ports.
+	var listenAddresses map[int64]string
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networkID := n.ID()
+		dbRecords, err := dbCluster.GetNetworkForwards(ctx, tx.Tx(), dbCluster.NetworkForwardFilter{
+			NetworkID: &networkID,
+		})
+		if err != nil {
+			return err
+		}
+
+		listenAddresses = make(map[int64]string)
+		for _, dbRecord := range dbRecords {
+			if !dbRecord.NodeID.Valid || (dbRecord.NodeID.Int64 == tx.GetNodeID()) {
+				listenAddresses[dbRecord.ID] = dbRecord.ListenAddress
 			}
 		}
 
-		// If br_netfilter is enabled and bridge has forwards, we enable hairpin mode on each NIC's bridge
-		// port in case any of the forwards target the NIC and the instance attempts to connect to the
-		// forward's listener. Without hairpin mode on the target of the forward will not be able to
-		// connect to the listener.
-		if brNetfilterEnabled {
-			var listenAddresses map[int64]string
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network forwards: %w", err)
+	}
 
-			err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-				networkID := n.ID()
-				dbRecords, err := dbCluster.GetNetworkForwards(ctx, tx.Tx(), dbCluster.NetworkForwardFilter{
-					NetworkID: &networkID,
-				})
-				if err != nil {
-					return err
-				}
+	if len(listenAddresses) <= 1 {
+		err = n.enableNICHairpin()
+		if err != nil {
+			return err
+		}
+	}
 
-				listenAddresses = make(map[int64]string)
-				for _, dbRecord := range dbRecords {
-					if !dbRecord.NodeID.Valid || (dbRecord.NodeID.Int64 == tx.GetNodeID()) {
-						listenAddresses[dbRecord.ID] = dbRecord.ListenAddress
-					}
-				}
+	// Refresh exported BGP prefixes on local member.
+	err = n.forwardBGPSetupPrefixes()
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for address forwards: %w", err)
+	}
 
-				return err
-			})
-			if err != nil {
-				return fmt.Errorf("Failed loading network forwards: %w", err)
-			}
+	reverter.Success()
 
-			// If we are the first forward on this bridge, enable hairpin mode on active NIC ports.
-			if len(listenAddresses) <= 1 {
-				filter := dbCluster.InstanceFilter{Node: &n.state.ServerName}
+	return nil
+}
 
-				err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-					return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
-						// Get the instance's effective network project name.
-						instNetworkProject := project.NetworkProjectFromRecord(&p)
+// enableNICHairpin enables hairpin mode on the bridge ports of active NICs connected to the network.
+// If br_netfilter is enabled and the bridge has forwards or load balancers, hairpin mode is needed in case any
+// of them target the NIC and the instance attempts to connect to the listener. Without hairpin mode the target
+// will not be able to connect to the listener.
+func (n *bridge) enableNICHairpin() error {
+	if n.config["bridge.driver"] == "openvswitch" {
+		return nil
+	}
 
-						if instNetworkProject != api.ProjectDefaultName {
-							return nil // Managed bridge networks can only exist in default project.
-						}
+	brNetfilterEnabled := false
+	for _, ipVersion := range []uint{4, 6} {
+		if BridgeNetfilterEnabled(ipVersion) == nil {
+			brNetfilterEnabled = true
+			break
+		}
+	}
 
-						devices := db.ExpandInstanceDevices(inst.Devices.Clone(), inst.Profiles)
+	if !brNetfilterEnabled {
+		return nil
+	}
 
-						// Iterate through each of the instance's devices, looking for bridged NICs
-						// that are linked to this network.
-						for devName, devConfig := range devices {
-							if devConfig["type"] != "nic" {
-								continue
-							}
+	filter := dbCluster.InstanceFilter{Node: &n.state.ServerName}
 
-							// Check whether the NIC device references our network..
-							if !NICUsesNetwork(devConfig, &api.Network{Name: n.Name()}) {
-								continue
-							}
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			// Get the instance's effective network project name.
+			instNetworkProject := project.NetworkProjectFromRecord(&p)
 
-							hostName := inst.Config[fmt.Sprintf("volatile.%s.host_name", devName)]
-							if InterfaceExists(hostName) {
-								link := &ip.Link{Name: hostName}
-								err = link.BridgeLinkSetHairpin(true)
-								if err != nil {
-									return fmt.Errorf("Error enabling hairpin mode on bridge port %q: %w", link.Name, err)
-								}
+			if instNetworkProject != api.ProjectDefaultName {
+				return nil // Managed bridge networks can only exist in default project.
+			}
 
-								n.logger.Debug("Enabled hairpin mode on NIC bridge port", logger.Ctx{"inst": inst.Name, "project": inst.Project, "device": devName, "dev": link.Name})
-							}
-						}
+			devices := db.ExpandInstanceDevices(inst.Devices.Clone(), inst.Profiles)
 
-						return nil
-					}, filter)
-				})
-				if err != nil {
-					return err
+			// Iterate through each of the instance's devices, looking for bridged NICs
+			// that are linked to this network.
+			for devName, devConfig := range devices {
+				if devConfig["type"] != "nic" {
+					continue
+				}
+
+				// Check whether the NIC device references our network..
+				if !NICUsesNetwork(devConfig, &api.Network{Name: n.Name()}) {
+					continue
+				}
+
+				hostName := inst.Config[fmt.Sprintf("volatile.%s.host_name", devName)]
+				if InterfaceExists(hostName) {
+					link := &ip.Link{Name: hostName}
+					err := link.BridgeLinkSetHairpin(true)
+					if err != nil {
+						return fmt.Errorf("Error enabling hairpin mode on bridge port %q: %w", link.Name, err)
+					}
+
+					n.logger.Debug("Enabled hairpin mode on NIC bridge port", logger.Ctx{"inst": inst.Name, "project": inst.Project, "device": devName, "dev": link.Name})
 				}
 			}
-		}
-	}
 
-	// Refresh exported BGP prefixes on local member.
-	err = n.forwardBGPSetupPrefixes()
+			return nil
+		}, filter)
+	})
 	if err != nil {
-		return fmt.Errorf("Failed applying BGP prefixes for address forwards: %w", err)
+		return err
 	}
 
-	reverter.Success()
-
 	return nil
 }
 
@@ -3244,6 +3330,599 @@ func (n *bridge) forwardSetupFirewall() error {
 	return nil
 }
 
+// LoadBalancerCreate creates a network load balancer on the local member.
+// Load balancers are specific to the cluster member they are created on as their backends are only reachable
+// through that member's bridge.
+func (n *bridge) LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clientType request.ClientType) error {
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Check if there is an existing load balancer using the same listen address on any member.
+		_, err := dbCluster.GetNetworkLoadBalancer(ctx, tx.Tx(), n.ID(), loadBalancer.ListenAddress)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err == nil {
+		return api.StatusErrorf(http.StatusConflict, "A load balancer for that listen address already exists")
+	}
+
+	// Convert listen address to subnet so we can check its valid and can be used.
+	listenAddressNet, err := ParseIPToNet(loadBalancer.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("Failed parsing %q: %w", loadBalancer.ListenAddress, err)
+	}
+
+	_, err = n.loadBalancerValidate(listenAddressNet.IP, &loadBalancer.NetworkLoadBalancerPut)
+	if err != nil {
+		return err
+	}
+
+	err = n.loadBalancerValidateBackendsLocal(loadBalancer.Backends)
+	if err != nil {
+		return err
+	}
+
+	externalSubnetsInUse, err := n.getExternalSubnetInUse()
+	if err != nil {
+		return err
+	}
+
+	// Check the listen address subnet doesn't fall within any existing network external subnets.
+	for _, externalSubnetUser := range externalSubnetsInUse {
+		// Check if usage is from our own network.
+		if externalSubnetUser.networkProject == n.project && externalSubnetUser.networkName == n.name {
+			// Skip checking conflict with our own network's subnet or SNAT address.
+			// But do not allow other conflict with other usage types within our own network.
+			if externalSubnetUser.usageType == subnetUsageNetwork || externalSubnetUser.usageType == subnetUsageNetworkSNAT {
+				continue
+			}
+		}
+
+		if SubnetContains(&externalSubnetUser.subnet, listenAddressNet) || SubnetContains(listenAddressNet, &externalSubnetUser.subnet) {
+			// This error is purposefully vague so that it doesn't reveal any names of
+			// resources potentially outside of the network.
+			return fmt.Errorf("Load balancer listen address %q overlaps with another network or NIC", listenAddressNet.String())
+		}
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	var loadBalancerID int64
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Create load balancer DB record.
+		lb := dbCluster.NetworkLoadBalancer{
+			NetworkID: n.ID(),
+			NodeID: sql.NullInt64{
+				Valid: true,
+				Int64: tx.GetNodeID(),
+			},
+			ListenAddress: loadBalancer.ListenAddress,
+			Description:   loadBalancer.Description,
+			Backends:      loadBalancer.Backends,
+			Ports:         loadBalancer.Ports,
+		}
+
+		loadBalancerID, err = dbCluster.CreateNetworkLoadBalancer(ctx, tx.Tx(), lb)
+		if err != nil {
+			return err
+		}
+
+		// Save the load balancer configuration.
+		err = dbCluster.CreateNetworkLoadBalancerConfig(ctx, tx.Tx(), loadBalancerID, loadBalancer.Config)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(func() {
+		_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return dbCluster.DeleteNetworkLoadBalancer(ctx, tx.Tx(), n.ID(), loadBalancerID)
+		})
+
+		_ = n.loadBalancerSetupFirewall()
+	})
+
+	err = n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	// Enable hairpin mode on active NIC ports in case an instance is both a backend and a client.
+	err = n.enableNICHairpin()
+	if err != nil {
+		return err
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// loadBalancerGetLocal returns the database record of a load balancer of the local member.
+func (n *bridge) loadBalancerGetLocal(listenAddress string) (*dbCluster.NetworkLoadBalancer, *api.NetworkLoadBalancer, error) {
+	var dbLoadBalancer *dbCluster.NetworkLoadBalancer
+	var loadBalancer *api.NetworkLoadBalancer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networkID := n.ID()
+
+		dbLoadBalancers, err := dbCluster.GetNetworkLoadBalancers(ctx, tx.Tx(), dbCluster.NetworkLoadBalancerFilter{
+			NetworkID:     &networkID,
+			ListenAddress: &listenAddress,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(dbLoadBalancers) != 1 {
+			return api.StatusErrorf(http.StatusNotFound, "Network load balancer not found")
+		}
+
+		dbLoadBalancer = &dbLoadBalancers[0]
+		if dbLoadBalancer.NodeID.Valid && dbLoadBalancer.NodeID.Int64 != tx.GetNodeID() {
+			return api.StatusErrorf(http.StatusBadRequest, "Network load balancer is located on another cluster member")
+		}
+
+		loadBalancer, err = dbLoadBalancer.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dbLoadBalancer, loadBalancer, nil
+}
+
+// LoadBalancerUpdate updates a network load balancer of the local member.
+func (n *bridge) LoadBalancerUpdate(listenAddress string, req api.NetworkLoadBalancerPut, clientType request.ClientType) error {
+	curDBLoadBalancer, curLoadBalancer, err := n.loadBalancerGetLocal(listenAddress)
+	if err != nil {
+		return err
+	}
+
+	_, err = n.loadBalancerValidate(net.ParseIP(curLoadBalancer.ListenAddress), &req)
+	if err != nil {
+		return err
+	}
+
+	err = n.loadBalancerValidateBackendsLocal(req.Backends)
+	if err != nil {
+		return err
+	}
+
+	curEtagHash, err := localUtil.EtagHash(curLoadBalancer.Etag())
+	if err != nil {
+		return err
+	}
+
+	newLoadBalancer := api.NetworkLoadBalancer{
+		ListenAddress:          curLoadBalancer.ListenAddress,
+		NetworkLoadBalancerPut: req,
+	}
+
+	newLoadBalancerEtagHash, err := localUtil.EtagHash(newLoadBalancer.Etag())
+	if err != nil {
+		return err
+	}
+
+	if curEtagHash == newLoadBalancerEtagHash {
+		return nil // Nothing has changed.
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		lb := dbCluster.NetworkLoadBalancer{
+			NetworkID:     n.ID(),
+			NodeID:        curDBLoadBalancer.NodeID,
+			ListenAddress: listenAddress,
+			Description:   newLoadBalancer.Description,
+			Backends:      newLoadBalancer.Backends,
+			Ports:         newLoadBalancer.Ports,
+		}
+
+		err = dbCluster.UpdateNetworkLoadBalancer(ctx, tx.Tx(), n.ID(), listenAddress, lb)
+		if err != nil {
+			return err
+		}
+
+		err = dbCluster.UpdateNetworkLoadBalancerConfig(ctx, tx.Tx(), curDBLoadBalancer.ID, newLoadBalancer.Config)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(func() {
+		_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			lb := dbCluster.NetworkLoadBalancer{
+				NetworkID:     n.ID(),
+				NodeID:        curDBLoadBalancer.NodeID,
+				ListenAddress: listenAddress,
+				Description:   curLoadBalancer.Description,
+				Backends:      curLoadBalancer.Backends,
+				Ports:         curLoadBalancer.Ports,
+			}
+
+			err = dbCluster.UpdateNetworkLoadBalancer(ctx, tx.Tx(), n.ID(), listenAddress, lb)
+			if err != nil {
+				return err
+			}
+
+			err = dbCluster.UpdateNetworkLoadBalancerConfig(ctx, tx.Tx(), curDBLoadBalancer.ID, curLoadBalancer.Config)
+			if err != nil {
+				return err
+			}
+
+			return nil
+		})
+
+		_ = n.loadBalancerSetupFirewall()
+	})
+
+	err = n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// loadBalancerValidateBackendsLocal checks that the backends target addresses used on the local member's bridge.
+// Those are the addresses of the bridge itself, the static and EUI64 addresses of the local instances connected to
+// the network and the local DHCP leases.
+func (n *bridge) loadBalancerValidateBackendsLocal(backends []api.NetworkLoadBalancerBackend) error {
+	if !n.state.ServerClustered || len(backends) == 0 {
+		return nil
+	}
+
+	localAddresses := map[string]bool{}
+	for _, address := range []string{n.config["ipv4.address"], n.config["ipv6.address"]} {
+		ip, _, _ := net.ParseCIDR(address)
+		if ip != nil {
+			localAddresses[ip.String()] = true
+		}
+	}
+
+	_, netIP6, _ := net.ParseCIDR(n.config["ipv6.address"])
+
+	filter := dbCluster.InstanceFilter{Node: &n.state.ServerName}
+	err := UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		for _, key := range []string{"ipv4.address", "ipv6.address"} {
+			ip := net.ParseIP(nicConfig[key])
+			if ip != nil {
+				localAddresses[ip.String()] = true
+			}
+		}
+
+		hwAddr, _ := net.ParseMAC(nicConfig["hwaddr"])
+		if hwAddr == nil {
+			hwAddr, _ = net.ParseMAC(inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)])
+		}
+
+		if netIP6 != nil && hwAddr != nil && util.IsFalseOrEmpty(n.config["ipv6.dhcp.stateful"]) {
+			eui64IP6, err := eui64.ParseMAC(netIP6.IP, hwAddr)
+			if err == nil {
+				localAddresses[eui64IP6.String()] = true
+			}
+		}
+
+		return nil
+	}, filter)
+	if err != nil {
+		return err
+	}
+
+	// Get the dynamic leases of the local member only.
+	leases, err := n.Leases(n.project, request.ClientTypeInternal)
+	if err != nil {
+		return err
+	}
+
+	for _, lease := range leases {
+		ip := net.ParseIP(lease.Address)
+		if ip != nil {
+			localAddresses[ip.String()] = true
+		}
+	}
+
+	for _, backend := range backends {
+		ip := net.ParseIP(backend.TargetAddress)
+		if ip == nil || !localAddresses[ip.String()] {
+			return api.StatusErrorf(http.StatusBadRequest, "Load balancer backend %q target address %q isn't in use on this cluster member", backend.Name, backend.TargetAddress)
+		}
+	}
+
+	return nil
+}
+
+// LoadBalancerState returns the current state of the load balancer on this member.
+func (n *bridge) LoadBalancerState(lb api.NetworkLoadBalancer) (*api.NetworkLoadBalancerState, error) {
+	lbState := &api.NetworkLoadBalancerState{}
+
+	if !util.IsTrue(lb.Config["healthcheck"]) {
+		return lbState, nil
+	}
+
+	listenAddress := net.ParseIP(lb.ListenAddress)
+
+	portMaps, err := n.loadBalancerValidate(listenAddress, &lb.NetworkLoadBalancerPut)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := lbHealthCheckerGet(n.ID(), listenAddress.String())
+
+	lbState.BackendHealth = map[string]api.NetworkLoadBalancerStateBackendHealth{}
+	for _, backend := range lb.Backends {
+		lbState.BackendHealth[backend.Name] = api.NetworkLoadBalancerStateBackendHealth{
+			Address: backend.TargetAddress,
+			Ports:   []api.NetworkLoadBalancerStateBackendHealthPort{},
+		}
+	}
+
+	// The port maps are in the same order as the port specifications and their targets in the same order
+	// as the backends referenced by them.
+	for portSpecID, lbPort := range lb.Ports {
+		portMap := portMaps[portSpecID]
+
+		for i, backendName := range lbPort.TargetBackend {
+			backendHealth := lbState.BackendHealth[backendName]
+
+			for _, port := range loadBalancerTargetPorts(portMap, portMap.targets[i]) {
+				target := lbHealthTarget{address: backendHealth.Address, protocol: portMap.protocol, port: port}
+
+				backendHealth.Ports = append(backendHealth.Ports, api.NetworkLoadBalancerStateBackendHealthPort{
+					Protocol: portMap.protocol,
+					Port:     int(port),
+					Status:   checker.status(target),
+				})
+			}
+
+			lbState.BackendHealth[backendName] = backendHealth
+		}
+	}
+
+	return lbState, nil
+}
+
+// LoadBalancerDelete deletes a network load balancer of the local member.
+func (n *bridge) LoadBalancerDelete(listenAddress string, clientType request.ClientType) error {
+	lb, _, err := n.loadBalancerGetLocal(listenAddress)
+	if err != nil {
+		return err
+	}
+
+	// Delete the database records.
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return dbCluster.DeleteNetworkLoadBalancer(ctx, tx.Tx(), n.ID(), lb.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	return n.loadBalancerSetupFirewall()
+}
+
+// loadBalancerTargetPorts returns the target port used by a load balancer backend for each of the listen ports.
+func loadBalancerTargetPorts(portMap *loadBalancerPortMap, target forwardTarget) []uint64 {
+	switch len(target.ports) {
+	case 0:
+		// Default to using the same port as the listen port.
+		return portMap.listenPorts
+	case 1:
+		// If a single target port is specified, forward all listen ports to it.
+		return []uint64{target.ports[0]}
+	default:
+		// Otherwise the target ports are mapped one-to-one to the listen ports.
+		return target.ports
+	}
+}
+
+// loadBalancerSetupFirewall applies the network load balancers of the local member defined for this network.
+// It also starts or stops the health checks as needed and only directs traffic to backends which aren't offline.
+func (n *bridge) loadBalancerSetupFirewall() error {
+	var loadBalancers []*api.NetworkLoadBalancer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networkID := n.ID()
+
+		dbLoadBalancers, err := dbCluster.GetNetworkLoadBalancers(ctx, tx.Tx(), dbCluster.NetworkLoadBalancerFilter{
+			NetworkID: &networkID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, dbLoadBalancer := range dbLoadBalancers {
+			// Only apply the load balancers of the local member.
+			if dbLoadBalancer.NodeID.Valid && dbLoadBalancer.NodeID.Int64 != tx.GetNodeID() {
+				continue
+			}
+
+			lb, err := dbLoadBalancer.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			loadBalancers = append(loadBalancers, lb)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network load balancers: %w", err)
+	}
+
+	portMapsByAddress := make(map[string][]*loadBalancerPortMap, len(loadBalancers))
+	healthConfigs := map[string]map[string]string{}
+	healthTargets := map[string][]lbHealthTarget{}
+
+	for _, lb := range loadBalancers {
+		listenAddress := net.ParseIP(lb.ListenAddress)
+
+		portMaps, err := n.loadBalancerValidate(listenAddress, &lb.NetworkLoadBalancerPut)
+		if err != nil {
+			return fmt.Errorf("Failed validating load balancer for listen address %q: %w", lb.ListenAddress, err)
+		}
+
+		portMapsByAddress[listenAddress.String()] = portMaps
+
+		if !util.IsTrue(lb.Config["healthcheck"]) {
+			continue
+		}
+
+		healthConfigs[listenAddress.String()] = lb.Config
+		for _, portMap := range portMaps {
+			for _, target := range portMap.targets {
+				for _, port := range loadBalancerTargetPorts(portMap, target) {
+					healthTargets[listenAddress.String()] = append(healthTargets[listenAddress.String()], lbHealthTarget{
+						address:  target.address.String(),
+						protocol: portMap.protocol,
+						port:     port,
+					})
+				}
+			}
+		}
+	}
+
+	err = lbHealthCheckersSync(n.ID(), healthConfigs, healthTargets, n.loadBalancerHealthChanged)
+	if err != nil {
+		return err
+	}
+
+	var fwLoadBalancers []firewallDrivers.LoadBalancer
+	onlineAddresses := make([]net.IP, 0, len(portMapsByAddress))
+
+	for listenAddress, portMaps := range portMapsByAddress {
+		checker := lbHealthCheckerGet(n.ID(), listenAddress)
+		online := false
+
+		for _, portMap := range portMaps {
+			fwLoadBalancer := firewallDrivers.LoadBalancer{
+				ListenAddress: net.ParseIP(listenAddress),
+				Protocol:      portMap.protocol,
+				ListenPorts:   portMap.listenPorts,
+			}
+
+			for _, target := range portMap.targets {
+				// Skip backends with any of their ports offline.
+				offline := slices.ContainsFunc(loadBalancerTargetPorts(portMap, target), func(port uint64) bool {
+					return checker.status(lbHealthTarget{address: target.address.String(), protocol: portMap.protocol, port: port}) == lbBackendStatusOffline
+				})
+
+				if offline {
+					continue
+				}
+
+				fwLoadBalancer.Backends = append(fwLoadBalancer.Backends, firewallDrivers.LoadBalancerBackend{
+					TargetAddress: target.address,
+					TargetPorts:   target.ports,
+				})
+			}
+
+			if len(fwLoadBalancer.Backends) == 0 {
+				continue
+			}
+
+			online = true
+			fwLoadBalancers = append(fwLoadBalancers, fwLoadBalancer)
+		}
+
+		if online {
+			onlineAddresses = append(onlineAddresses, net.ParseIP(listenAddress))
+		}
+	}
+
+	err = n.state.Firewall.NetworkApplyLoadBalancers(n.name, fwLoadBalancers)
+	if err != nil {
+		return fmt.Errorf("Failed applying firewall load balancers: %w", err)
+	}
+
+	// Refresh exported BGP prefixes on local member.
+	err = n.loadBalancerBGPSetupPrefixes(onlineAddresses)
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
+	}
+
+	return nil
+}
+
+// loadBalancerHealthChanged reapplies the load balancers after the health of a backend changed.
+func (n *bridge) loadBalancerHealthChanged() {
+	// Reload the network in case its configuration changed since the health checks were started.
+	netw, err := LoadByName(n.state, n.project, n.name)
+	if err != nil {
+		n.logger.Warn("Failed loading network to apply load balancer health changes", logger.Ctx{"err": err})
+		return
+	}
+
+	b, ok := netw.(*bridge)
+	if !ok || !b.isRunning() {
+		return
+	}
+
+	err = b.loadBalancerSetupFirewall()
+	if err != nil {
+		n.logger.Warn("Failed applying load balancer health changes", logger.Ctx{"err": err})
+	}
+}
+
+// loadBalancerBGPSetupPrefixes exports the listen addresses of the load balancers with usable backends.
+func (n *bridge) loadBalancerBGPSetupPrefixes(listenAddresses []net.IP) error {
+	// Use load balancer specific owner string (different from the network prefixes) so that these can be
+	// reapplied independently of the network's own prefixes.
+	bgpOwner := fmt.Sprintf("network_%d_load_balancer", n.id)
+
+	// Clear existing load balancer prefixes for network.
+	err := n.state.BGP.RemovePrefixByOwner(bgpOwner)
+	if err != nil {
+		return err
+	}
+
+	for _, listenAddress := range listenAddresses {
+		ipVersion := uint(4)
+		routeSubnetSize := 32
+		if listenAddress.To4() == nil {
+			ipVersion = 6
+			routeSubnetSize = 128
+		}
+
+		// Don't export internal load balancers (those inside the NAT enabled network's subnet).
+		natEnabled := util.IsTrue(n.config[fmt.Sprintf("ipv%d.nat", ipVersion)])
+		_, netSubnet, _ := net.ParseCIDR(n.config[fmt.Sprintf("ipv%d.address", ipVersion)])
+		if natEnabled && netSubnet != nil && netSubnet.Contains(listenAddress) {
+			continue
+		}
+
+		err = n.state.BGP.AddPrefix(net.IPNet{IP: listenAddress, Mask: net.CIDRMask(routeSubnetSize, routeSubnetSize)}, n.bgpNextHopAddress(ipVersion), bgpOwner)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Leases returns a list of leases for the bridged network. It will reach out to other cluster members as needed.
 // The projectName passed here refers to the initial project from the API request which may differ from the network's project.
 func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
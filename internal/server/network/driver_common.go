@@ -10,8 +10,10 @@ import (
 	"net"
 	"os"
 	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	incus "github.com/lxc/incus/v7/client"
@@ -628,12 +630,53 @@ func (n *common) bgpValidationRules(config map[string]string) (map[string]func(v
 			rules[k] = validate.Optional(validate.IsAny)
 		case "holdtime":
 			rules[k] = validate.Optional(validate.IsInRange(9, 65535))
+		case "bfd":
+			rules[k] = validate.Optional(validate.IsBool)
+		case "bfd_interval":
+			rules[k] = validate.Optional(validate.IsInRange(10, 60000))
+		case "bfd_multiplier":
+			rules[k] = validate.Optional(validate.IsInRange(1, 255))
+		case "graceful_restart":
+			rules[k] = validate.Optional(validate.IsBool)
+		case "graceful_restart_time":
+			rules[k] = validate.Optional(validate.IsInRange(1, 4095))
+		case "communities":
+			rules[k] = validate.Optional(bgpValidateCommunities)
+		case "med":
+			rules[k] = validate.Optional(validate.IsUint32)
+		case "import_routes":
+			rules[k] = validate.Optional(validate.IsBool)
 		}
 	}
 
 	return rules, nil
 }
 
+// bgpValidateCommunities validates a comma separated list of standard (ASN:VALUE) or large (ASN:VALUE:VALUE) BGP communities.
+func bgpValidateCommunities(value string) error {
+	for _, community := range util.SplitNTrimSpace(value, ",", -1, true) {
+		fields := strings.Split(community, ":")
+		if len(fields) != 2 && len(fields) != 3 {
+			return fmt.Errorf("Invalid BGP community %q", community)
+		}
+
+		// Standard communities are made of two 16-bit values, large communities of three 32-bit values.
+		bitSize := 16
+		if len(fields) == 3 {
+			bitSize = 32
+		}
+
+		for _, field := range fields {
+			_, err := strconv.ParseUint(field, 10, bitSize)
+			if err != nil {
+				return fmt.Errorf("Invalid BGP community %q: %w", community, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // bgpSetup initializes BGP peers and prefixes.
 func (n *common) bgpSetup(oldConfig map[string]string) error {
 	currentPeers := n.bgpGetPeers(n.config)
@@ -688,6 +731,12 @@ func (n *common) bgpClear(config map[string]string) error {
 		return err
 	}
 
+	// Clear existing load balancer prefixes for network.
+	err = n.state.BGP.RemovePrefixByOwner(fmt.Sprintf("network_%d_load_balancer", n.id))
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -696,8 +745,7 @@ func (n *common) bgpClearPeers(config map[string]string) error {
 	peers := n.bgpGetPeers(config)
 	for _, peer := range peers {
 		// Remove the peer.
-		fields := strings.Split(peer, ",")
-		err := n.state.BGP.RemovePeer(net.ParseIP(fields[0]))
+		err := n.state.BGP.RemovePeer(net.ParseIP(peer.address))
 		if err != nil && !errors.Is(err, bgp.ErrPeerNotFound) {
 			return err
 		}
@@ -719,8 +767,7 @@ func (n *common) bgpSetupPeers(oldConfig map[string]string) error {
 		}
 
 		// Remove old peer.
-		fields := strings.Split(peer, ",")
-		err := n.state.BGP.RemovePeer(net.ParseIP(fields[0]))
+		err := n.state.BGP.RemovePeer(net.ParseIP(peer.address))
 		if err != nil {
 			return err
 		}
@@ -733,21 +780,17 @@ func (n *common) bgpSetupPeers(oldConfig map[string]string) error {
 		}
 
 		// Add new peer.
-		fields := strings.Split(peer, ",")
-		asn, err := strconv.ParseUint(fields[1], 10, 32)
+		asn, err := strconv.ParseUint(peer.asn, 10, 32)
 		if err != nil {
 			return err
 		}
 
-		var holdTime uint64
-		if fields[3] != "" {
-			holdTime, err = strconv.ParseUint(fields[3], 10, 32)
-			if err != nil {
-				return err
-			}
+		options, err := peer.options()
+		if err != nil {
+			return err
 		}
 
-		err = n.state.BGP.AddPeer(net.ParseIP(fields[0]), uint32(asn), fields[2], holdTime)
+		err = n.state.BGP.AddPeer(net.ParseIP(peer.address), uint32(asn), options)
 		if err != nil {
 			return err
 		}
@@ -827,8 +870,20 @@ func (n *common) bgpSetupPrefixes(oldConfig map[string]string) error {
 }
 
 // bgpGetPeers returns a list of strings representing the BGP peers.
-func (n *common) bgpGetPeers(config map[string]string) []string {
-	// Get a list of peer names.
+func (n *common) bgpGetPeers(config map[string]string) []bgpPeerConfig {
+	peers := []bgpPeerConfig{}
+	for _, peerName := range n.bgpGetPeerNames(config) {
+		peer := n.bgpGetPeer(config, peerName)
+		if peer.address != "" && peer.asn != "" {
+			peers = append(peers, peer)
+		}
+	}
+
+	return peers
+}
+
+// bgpGetPeerNames returns the names of the BGP peers in the config.
+func (n *common) bgpGetPeerNames(config map[string]string) []string {
 	peerNames := []string{}
 	for k := range config {
 		if !strings.HasPrefix(k, "bgp.peers.") {
@@ -841,20 +896,112 @@ func (n *common) bgpGetPeers(config map[string]string) []string {
 		}
 	}
 
-	// Build up a list of peer strings.
-	peers := []string{}
-	for _, peerName := range peerNames {
-		peerAddress := config[fmt.Sprintf("bgp.peers.%s.address", peerName)]
-		peerASN := config[fmt.Sprintf("bgp.peers.%s.asn", peerName)]
-		peerPassword := config[fmt.Sprintf("bgp.peers.%s.password", peerName)]
-		peerHoldTime := config[fmt.Sprintf("bgp.peers.%s.holdtime", peerName)]
+	sort.Strings(peerNames)
+
+	return peerNames
+}
 
-		if peerAddress != "" && peerASN != "" {
-			peers = append(peers, fmt.Sprintf("%s,%s,%s,%s", peerAddress, peerASN, peerPassword, peerHoldTime))
+// bgpGetPeer returns the configuration of a BGP peer.
+func (n *common) bgpGetPeer(config map[string]string, peerName string) bgpPeerConfig {
+	peerKey := func(key string) string {
+		return config[fmt.Sprintf("bgp.peers.%s.%s", peerName, key)]
+	}
+
+	return bgpPeerConfig{
+		address:             peerKey("address"),
+		asn:                 peerKey("asn"),
+		password:            peerKey("password"),
+		holdTime:            peerKey("holdtime"),
+		bfd:                 peerKey("bfd"),
+		bfdInterval:         peerKey("bfd_interval"),
+		bfdMultiplier:       peerKey("bfd_multiplier"),
+		gracefulRestart:     peerKey("graceful_restart"),
+		gracefulRestartTime: peerKey("graceful_restart_time"),
+		communities:         peerKey("communities"),
+		med:                 peerKey("med"),
+		importRoutes:        peerKey("import_routes"),
+	}
+}
+
+// bgpPeerConfig represents the raw configuration of a BGP peer.
+type bgpPeerConfig struct {
+	address             string
+	asn                 string
+	password            string
+	holdTime            string
+	bfd                 string
+	bfdInterval         string
+	bfdMultiplier       string
+	gracefulRestart     string
+	gracefulRestartTime string
+	communities         string
+	med                 string
+	importRoutes        string
+}
+
+// options converts the peer configuration into BGP server options.
+func (p bgpPeerConfig) options() (bgp.PeerOptions, error) {
+	options := bgp.PeerOptions{
+		Password:            p.password,
+		GracefulRestartTime: 3600,
+		Communities:         util.SplitNTrimSpace(p.communities, ",", -1, true),
+		RejectImport:        util.IsFalse(p.importRoutes),
+	}
+
+	if p.holdTime != "" {
+		holdTime, err := strconv.ParseUint(p.holdTime, 10, 32)
+		if err != nil {
+			return bgp.PeerOptions{}, err
 		}
+
+		options.HoldTime = holdTime
 	}
 
-	return peers
+	if util.IsTrue(p.bfd) {
+		options.BFDInterval = 300 * time.Millisecond
+		options.BFDMultiplier = 3
+
+		if p.bfdInterval != "" {
+			interval, err := strconv.ParseUint(p.bfdInterval, 10, 32)
+			if err != nil {
+				return bgp.PeerOptions{}, err
+			}
+
+			options.BFDInterval = time.Duration(interval) * time.Millisecond
+		}
+
+		if p.bfdMultiplier != "" {
+			multiplier, err := strconv.ParseUint(p.bfdMultiplier, 10, 8)
+			if err != nil {
+				return bgp.PeerOptions{}, err
+			}
+
+			options.BFDMultiplier = uint8(multiplier)
+		}
+	}
+
+	if util.IsFalse(p.gracefulRestart) {
+		options.GracefulRestartTime = 0
+	} else if p.gracefulRestartTime != "" {
+		restartTime, err := strconv.ParseUint(p.gracefulRestartTime, 10, 32)
+		if err != nil {
+			return bgp.PeerOptions{}, err
+		}
+
+		options.GracefulRestartTime = uint32(restartTime)
+	}
+
+	if p.med != "" {
+		med, err := strconv.ParseUint(p.med, 10, 32)
+		if err != nil {
+			return bgp.PeerOptions{}, err
+		}
+
+		medValue := uint32(med)
+		options.MED = &medValue
+	}
+
+	return options, nil
 }
 
 // forwardValidate validates the forward request.
@@ -1205,11 +1352,13 @@ func (n *common) getExternalSubnetInUse(ctx context.Context, tx *db.ClusterTx, u
 		}
 
 		for _, lb := range loadBalancers {
-			if projectNetworksLoadBalancersOnUplink[relatedNetwork.Project] == nil {
-				projectNetworksLoadBalancersOnUplink[relatedNetwork.Project] = map[string][]string{}
-			}
+			if !memberSpecific || (!lb.NodeID.Valid || (lb.NodeID.Int64 == tx.GetNodeID())) {
+				if projectNetworksLoadBalancersOnUplink[relatedNetwork.Project] == nil {
+					projectNetworksLoadBalancersOnUplink[relatedNetwork.Project] = map[string][]string{}
+				}
 
-			projectNetworksLoadBalancersOnUplink[relatedNetwork.Project][relatedNetwork.Name] = append(projectNetworksLoadBalancersOnUplink[relatedNetwork.Project][relatedNetwork.Name], lb.ListenAddress)
+				projectNetworksLoadBalancersOnUplink[relatedNetwork.Project][relatedNetwork.Name] = append(projectNetworksLoadBalancersOnUplink[relatedNetwork.Project][relatedNetwork.Name], lb.ListenAddress)
+			}
 		}
 
 		// Get all network forwards associated with this network.
@@ -1666,6 +1815,96 @@ func (n *common) State() (*api.NetworkState, error) {
 	return resources.GetNetworkState(n.name)
 }
 
+// BGPState returns the state of the BGP peers used by the network and of the prefixes advertised for it.
+func (n *common) BGPState() (*api.NetworkBGPState, error) {
+	state := &api.NetworkBGPState{
+		Peers:    []api.NetworkBGPPeerState{},
+		Prefixes: []api.NetworkBGPPrefix{},
+	}
+
+	// OVN networks use the peers of their uplink network.
+	peerConfig := n.config
+	if n.netType == "ovn" && n.config["network"] != "" {
+		uplinkNet, err := LoadByName(n.state, api.ProjectDefaultName, n.config["network"])
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading uplink network %q: %w", n.config["network"], err)
+		}
+
+		peerConfig = uplinkNet.Config()
+	}
+
+	for _, peerName := range n.bgpGetPeerNames(peerConfig) {
+		peer := n.bgpGetPeer(peerConfig, peerName)
+		if peer.address == "" || peer.asn == "" {
+			continue
+		}
+
+		peerState, err := n.state.BGP.PeerState(net.ParseIP(peer.address))
+		if err != nil {
+			if !errors.Is(err, bgp.ErrPeerNotFound) {
+				return nil, fmt.Errorf("Failed getting state of BGP peer %q: %w", peerName, err)
+			}
+
+			// The peer isn't set up on this server (network not started or no BGP server configured).
+			state.Peers = append(state.Peers, api.NetworkBGPPeerState{Name: peerName, Address: peer.address, State: "unconfigured"})
+			continue
+		}
+
+		entry := api.NetworkBGPPeerState{
+			Name:               peerName,
+			Address:            peerState.Address.String(),
+			ASN:                peerState.ASN,
+			State:              peerState.State,
+			Uptime:             int64(peerState.Uptime.Seconds()),
+			ReceivedPrefixes:   peerState.ReceivedPrefixes,
+			AcceptedPrefixes:   peerState.AcceptedPrefixes,
+			AdvertisedPrefixes: peerState.AdvertisedPrefixes,
+			BFDState:           peerState.BFDState,
+			LastError:          peerState.LastError,
+		}
+
+		if !peerState.LastErrorTime.IsZero() {
+			entry.LastErrorAt = &peerState.LastErrorTime
+		}
+
+		state.Peers = append(state.Peers, entry)
+	}
+
+	// Build the list of prefix owners related to this network.
+	owners := map[string]string{
+		fmt.Sprintf("network_%d", n.id):               "network",
+		fmt.Sprintf("network_%d_forward", n.id):       "forward",
+		fmt.Sprintf("network_%d_load_balancer", n.id): "load-balancer",
+	}
+
+	instanceNames := map[string]string{}
+	err := UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		owner := fmt.Sprintf("instance_%d_%s", inst.ID, nicName)
+		owners[owner] = "instance"
+		instanceNames[owner] = inst.Name
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting instances using the network: %w", err)
+	}
+
+	for _, prefix := range n.state.BGP.Prefixes(slices.Collect(maps.Keys(owners))...) {
+		state.Prefixes = append(state.Prefixes, api.NetworkBGPPrefix{
+			Prefix:   prefix.Prefix.String(),
+			Nexthop:  prefix.Nexthop.String(),
+			Source:   owners[prefix.Owner],
+			Instance: instanceNames[prefix.Owner],
+		})
+	}
+
+	sort.Slice(state.Prefixes, func(i, j int) bool {
+		return state.Prefixes[i].Prefix < state.Prefixes[j].Prefix
+	})
+
+	return state, nil
+}
+
 func (n *common) setUnavailable() {
 	pn := ProjectNetwork{
 		ProjectName: n.Project(),
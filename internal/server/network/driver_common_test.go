@@ -0,0 +1,117 @@
+package network
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBGPValidateCommunities(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: ""},
+		{value: "65000:100"},
+		{value: "65000:100, 65535:65535"},
+		{value: "4200000000:1:2"},
+		{value: "65000:100,4200000000:1:2"},
+		{value: "65000", wantErr: true},
+		{value: "65000:1:2:3", wantErr: true},
+		{value: "65536:100", wantErr: true},
+		{value: "65000:-1", wantErr: true},
+		{value: "65000:no-export", wantErr: true},
+		{value: "4294967296:1:2", wantErr: true},
+		{value: "65000:100,foo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			err := bgpValidateCommunities(tt.value)
+			if tt.wantErr && err == nil {
+				t.Fatal("Expected an error")
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestBGPPeerConfigOptions(t *testing.T) {
+	// Defaults.
+	options, err := bgpPeerConfig{address: "192.0.2.1", asn: "65001"}.options()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if options.GracefulRestartTime != 3600 || options.HoldTime != 0 || options.BFDInterval != 0 || options.MED != nil || options.RejectImport || len(options.Communities) != 0 {
+		t.Fatalf("Unexpected default options: %+v", options)
+	}
+
+	// All options set.
+	options, err = bgpPeerConfig{
+		password:            "secret",
+		holdTime:            "90",
+		bfd:                 "true",
+		bfdInterval:         "100",
+		bfdMultiplier:       "5",
+		gracefulRestartTime: "120",
+		communities:         "65000:100, 65000:1:2",
+		med:                 "50",
+		importRoutes:        "false",
+	}.options()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if options.Password != "secret" || options.HoldTime != 90 || options.GracefulRestartTime != 120 || !options.RejectImport {
+		t.Fatalf("Unexpected options: %+v", options)
+	}
+
+	if options.BFDInterval != 100*time.Millisecond || options.BFDMultiplier != 5 {
+		t.Fatalf("Unexpected BFD options: %s x%d", options.BFDInterval, options.BFDMultiplier)
+	}
+
+	if !slices.Equal(options.Communities, []string{"65000:100", "65000:1:2"}) {
+		t.Fatalf("Unexpected communities: %v", options.Communities)
+	}
+
+	if options.MED == nil || *options.MED != 50 {
+		t.Fatalf("Unexpected MED: %v", options.MED)
+	}
+
+	// BFD defaults, BFD timers without BFD and disabled graceful restart.
+	options, err = bgpPeerConfig{bfd: "true", gracefulRestart: "false", gracefulRestartTime: "120"}.options()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if options.BFDInterval != 300*time.Millisecond || options.BFDMultiplier != 3 || options.GracefulRestartTime != 0 {
+		t.Fatalf("Unexpected options: %+v", options)
+	}
+
+	options, err = bgpPeerConfig{bfdInterval: "100", bfdMultiplier: "5"}.options()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if options.BFDInterval != 0 || options.BFDMultiplier != 0 {
+		t.Fatalf("BFD enabled without being requested: %+v", options)
+	}
+
+	// Invalid values.
+	for _, config := range []bgpPeerConfig{
+		{holdTime: "forever"},
+		{bfd: "true", bfdInterval: "fast"},
+		{bfd: "true", bfdMultiplier: "256"},
+		{gracefulRestartTime: "-1"},
+		{med: "4294967296"},
+	} {
+		_, err = config.options()
+		if err == nil {
+			t.Fatalf("Expected an error for %+v", config)
+		}
+	}
+}
@@ -956,6 +956,11 @@ func (n *ovn) Validate(config map[string]string, clientType request.ClientType)
 		if err != nil {
 			return err
 		}
+
+		err = acl.ValidateOVN(n.state, n.project, util.SplitNTrimSpace(config["security.acls"], ",", -1, true)...)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Check that ipv6.l3only mode is used with ipvp.dhcp.stateful.
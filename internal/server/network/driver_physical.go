@@ -220,6 +220,70 @@ func (n *physical) Validate(config map[string]string, clientType request.ClientT
 	// defaultdesc: `180`
 	// shortdesc: Peer session hold time (in seconds; optional)
 
+	// gendoc:generate(entity=network_physical, group=bgp, key=bgp.peers.NAME.bfd)
+	//
+	// ---
+	// type: bool
+	// condition: BGP server
+	// defaultdesc: `false`
+	// shortdesc: Whether to use BFD to detect peer failures
+
+	// gendoc:generate(entity=network_physical, group=bgp, key=bgp.peers.NAME.bfd_interval)
+	//
+	// ---
+	// type: integer
+	// condition: BGP server
+	// defaultdesc: `300`
+	// shortdesc: BFD transmit and receive interval (in milliseconds)
+
+	// gendoc:generate(entity=network_physical, group=bgp, key=bgp.peers.NAME.bfd_multiplier)
+	//
+	// ---
+	// type: integer
+	// condition: BGP server
+	// defaultdesc: `3`
+	// shortdesc: Number of missed BFD packets before the peer is considered down
+
+	// gendoc:generate(entity=network_physical, group=bgp, key=bgp.peers.NAME.graceful_restart)
+	//
+	// ---
+	// type: bool
+	// condition: BGP server
+	// defaultdesc: `true`
+	// shortdesc: Whether to use BGP graceful restart so the peer retains routes while the server restarts
+
+	// gendoc:generate(entity=network_physical, group=bgp, key=bgp.peers.NAME.graceful_restart_time)
+	//
+	// ---
+	// type: integer
+	// condition: BGP server
+	// defaultdesc: `3600`
+	// shortdesc: Time the peer retains routes while the server restarts (in seconds)
+
+	// gendoc:generate(entity=network_physical, group=bgp, key=bgp.peers.NAME.communities)
+	//
+	// ---
+	// type: string
+	// condition: BGP server
+	// defaultdesc: -
+	// shortdesc: Comma-separated list of BGP communities (`ASN:VALUE` or `ASN:VALUE:VALUE`) added to exported routes
+
+	// gendoc:generate(entity=network_physical, group=bgp, key=bgp.peers.NAME.med)
+	//
+	// ---
+	// type: integer
+	// condition: BGP server
+	// defaultdesc: -
+	// shortdesc: Multi-exit discriminator set on exported routes
+
+	// gendoc:generate(entity=network_physical, group=bgp, key=bgp.peers.NAME.import_routes)
+	//
+	// ---
+	// type: bool
+	// condition: BGP server
+	// defaultdesc: `true`
+	// shortdesc: Whether to accept routes received from the peer
+
 	// Add the BGP validation rules.
 	bgpRules, err := n.bgpValidationRules(config)
 	if err != nil {
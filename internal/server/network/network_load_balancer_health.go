@@ -0,0 +1,331 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// Load balancer health check defaults.
+const (
+	lbHealthCheckDefaultInterval     = 10
+	lbHealthCheckDefaultTimeout      = 30
+	lbHealthCheckDefaultSuccessCount = 3
+	lbHealthCheckDefaultFailureCount = 3
+)
+
+// Load balancer backend health status.
+const (
+	lbBackendStatusUnknown = "unknown"
+	lbBackendStatusOnline  = "online"
+	lbBackendStatusOffline = "offline"
+)
+
+// lbHealthCheckers tracks the health checkers running on this server, indexed by network ID and listen address.
+var (
+	lbHealthCheckers   = map[string]*lbHealthChecker{}
+	lbHealthCheckersMu sync.Mutex
+)
+
+// lbHealthTarget represents a single backend port being checked.
+type lbHealthTarget struct {
+	address  string
+	protocol string
+	port     uint64
+}
+
+// lbHealthTargetState represents the health of a single backend port.
+type lbHealthTargetState struct {
+	status    string
+	successes uint32
+	failures  uint32
+}
+
+// lbHealthChecker periodically checks the backends of a load balancer.
+type lbHealthChecker struct {
+	interval     time.Duration
+	timeout      time.Duration
+	successCount uint32
+	failureCount uint32
+
+	fingerprint string
+	onChange    func()
+	cancel      context.CancelFunc
+
+	states map[lbHealthTarget]*lbHealthTargetState
+	mu     sync.Mutex
+}
+
+// lbHealthCheckerKey returns the key used to track the health checker of a load balancer.
+func lbHealthCheckerKey(networkID int64, listenAddress string) string {
+	return fmt.Sprintf("%d/%s", networkID, listenAddress)
+}
+
+// lbHealthCheckerGet returns the health checker of a load balancer or nil if none is running.
+func lbHealthCheckerGet(networkID int64, listenAddress string) *lbHealthChecker {
+	lbHealthCheckersMu.Lock()
+	defer lbHealthCheckersMu.Unlock()
+
+	return lbHealthCheckers[lbHealthCheckerKey(networkID, listenAddress)]
+}
+
+// lbHealthCheckersSync starts, restarts or stops the health checkers of a network's load balancers.
+// The targets map is indexed by listen address and only contains load balancers with health checks enabled.
+// The onChange function is called whenever the health of a backend changes.
+func lbHealthCheckersSync(networkID int64, configs map[string]map[string]string, targets map[string][]lbHealthTarget, onChange func()) error {
+	lbHealthCheckersMu.Lock()
+	defer lbHealthCheckersMu.Unlock()
+
+	wanted := map[string]struct{}{}
+
+	for listenAddress, lbTargets := range targets {
+		key := lbHealthCheckerKey(networkID, listenAddress)
+		wanted[key] = struct{}{}
+
+		checker, err := newLBHealthChecker(configs[listenAddress], lbTargets, onChange)
+		if err != nil {
+			return fmt.Errorf("Invalid health check configuration for load balancer %q: %w", listenAddress, err)
+		}
+
+		// Keep existing checkers with the same configuration (and their current state).
+		existing := lbHealthCheckers[key]
+		if existing != nil {
+			if existing.fingerprint == checker.fingerprint {
+				continue
+			}
+
+			existing.stop()
+		}
+
+		lbHealthCheckers[key] = checker
+		checker.start()
+	}
+
+	// Stop the checkers which are no longer needed.
+	prefix := fmt.Sprintf("%d/", networkID)
+	for key, checker := range lbHealthCheckers {
+		_, found := wanted[key]
+		if found || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		checker.stop()
+		delete(lbHealthCheckers, key)
+	}
+
+	return nil
+}
+
+// lbHealthCheckersStop stops all the health checkers of a network.
+func lbHealthCheckersStop(networkID int64) {
+	_ = lbHealthCheckersSync(networkID, nil, nil, nil)
+}
+
+// newLBHealthChecker returns a new (stopped) health checker.
+func newLBHealthChecker(config map[string]string, targets []lbHealthTarget, onChange func()) (*lbHealthChecker, error) {
+	getValue := func(key string, defaultValue uint32) (uint32, error) {
+		if config[key] == "" {
+			return defaultValue, nil
+		}
+
+		value, err := strconv.ParseUint(config[key], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid value for %q: %w", key, err)
+		}
+
+		return uint32(value), nil
+	}
+
+	interval, err := getValue("healthcheck.interval", lbHealthCheckDefaultInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := getValue("healthcheck.timeout", lbHealthCheckDefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	successCount, err := getValue("healthcheck.success_count", lbHealthCheckDefaultSuccessCount)
+	if err != nil {
+		return nil, err
+	}
+
+	failureCount, err := getValue("healthcheck.failure_count", lbHealthCheckDefaultFailureCount)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := &lbHealthChecker{
+		interval:     time.Duration(max(interval, 1)) * time.Second,
+		timeout:      time.Duration(max(timeout, 1)) * time.Second,
+		successCount: max(successCount, 1),
+		failureCount: max(failureCount, 1),
+		onChange:     onChange,
+		states:       make(map[lbHealthTarget]*lbHealthTargetState, len(targets)),
+	}
+
+	fingerprint := []string{fmt.Sprintf("%d,%d,%d,%d", interval, timeout, successCount, failureCount)}
+	for _, target := range targets {
+		checker.states[target] = &lbHealthTargetState{status: lbBackendStatusUnknown}
+		fingerprint = append(fingerprint, fmt.Sprintf("%s,%s,%d", target.address, target.protocol, target.port))
+	}
+
+	slices.Sort(fingerprint[1:])
+	checker.fingerprint = strings.Join(fingerprint, ";")
+
+	return checker, nil
+}
+
+// start runs the health checks in the background.
+func (c *lbHealthChecker) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			c.checkAll(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stop stops the health checks.
+func (c *lbHealthChecker) stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// checkAll checks all the targets, calling the onChange function if the status of any of them changed.
+func (c *lbHealthChecker) checkAll(ctx context.Context) {
+	c.mu.Lock()
+	targets := make([]lbHealthTarget, 0, len(c.states))
+	for target := range c.states {
+		targets = append(targets, target)
+	}
+
+	c.mu.Unlock()
+
+	results := make([]error, len(targets))
+
+	wg := sync.WaitGroup{}
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = lbHealthCheck(ctx, target, c.timeout)
+		}()
+	}
+
+	wg.Wait()
+
+	// Don't record anything if the checker was stopped in the meantime.
+	if ctx.Err() != nil {
+		return
+	}
+
+	changed := false
+
+	c.mu.Lock()
+	for i, target := range targets {
+		state := c.states[target]
+
+		if results[i] == nil {
+			state.successes++
+			state.failures = 0
+
+			if state.status != lbBackendStatusOnline && state.successes >= c.successCount {
+				state.status = lbBackendStatusOnline
+				changed = true
+			}
+		} else {
+			state.failures++
+			state.successes = 0
+
+			if state.status != lbBackendStatusOffline && state.failures >= c.failureCount {
+				logger.Warn("Load balancer backend is offline", logger.Ctx{"address": target.address, "protocol": target.protocol, "port": target.port, "err": results[i]})
+				state.status = lbBackendStatusOffline
+				changed = true
+			}
+		}
+	}
+
+	c.mu.Unlock()
+
+	if changed && c.onChange != nil {
+		c.onChange()
+	}
+}
+
+// status returns the current health status of a backend port.
+func (c *lbHealthChecker) status(target lbHealthTarget) string {
+	if c == nil {
+		return lbBackendStatusUnknown
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, ok := c.states[target]
+	if !ok {
+		return lbBackendStatusUnknown
+	}
+
+	return state.status
+}
+
+// lbHealthCheck checks whether a backend port is reachable.
+//
+// For TCP, a connection must be established within the timeout.
+// For UDP, an empty datagram is sent and the port is considered down only if it's rejected.
+func lbHealthCheck(ctx context.Context, target lbHealthTarget, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	address := net.JoinHostPort(target.address, strconv.FormatUint(target.port, 10))
+	dialer := net.Dialer{}
+
+	conn, err := dialer.DialContext(ctx, target.protocol, address)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	if target.protocol != "udp" {
+		return nil
+	}
+
+	_, err = conn.Write([]byte{})
+	if err != nil {
+		return err
+	}
+
+	// Rejections come back quickly as ICMP port unreachable, so there's no point in waiting for the full timeout.
+	_ = conn.SetReadDeadline(time.Now().Add(min(timeout, 2*time.Second)))
+
+	_, err = conn.Read(make([]byte, 1))
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return err
+	}
+
+	return nil
+}
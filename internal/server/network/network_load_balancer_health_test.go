@@ -0,0 +1,353 @@
+package network
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// lbTestTCPTarget returns a target for a local TCP listener along with a function closing it.
+func lbTestTCPTarget(t *testing.T) (lbHealthTarget, func()) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			_ = conn.Close()
+		}
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	return lbHealthTarget{address: "127.0.0.1", protocol: "tcp", port: uint64(port)}, func() { _ = listener.Close() }
+}
+
+// lbTestUnusedPort returns a local port with nothing listening on it.
+func lbTestUnusedPort(t *testing.T, protocol string) uint64 {
+	t.Helper()
+
+	var address string
+	switch protocol {
+	case "tcp":
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		address = listener.Addr().String()
+		_ = listener.Close()
+	case "udp":
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		address = conn.LocalAddr().String()
+		_ = conn.Close()
+	}
+
+	_, portStr, _ := net.SplitHostPort(address)
+	port, _ := strconv.ParseUint(portStr, 10, 64)
+
+	return port
+}
+
+func TestNewLBHealthChecker(t *testing.T) {
+	checker, err := newLBHealthChecker(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if checker.interval != lbHealthCheckDefaultInterval*time.Second || checker.timeout != lbHealthCheckDefaultTimeout*time.Second {
+		t.Fatalf("Unexpected default timings: interval %s, timeout %s", checker.interval, checker.timeout)
+	}
+
+	if checker.successCount != lbHealthCheckDefaultSuccessCount || checker.failureCount != lbHealthCheckDefaultFailureCount {
+		t.Fatalf("Unexpected default counts: success %d, failure %d", checker.successCount, checker.failureCount)
+	}
+
+	// Zero values are raised to the minimum.
+	checker, err = newLBHealthChecker(map[string]string{
+		"healthcheck.interval":      "0",
+		"healthcheck.timeout":       "0",
+		"healthcheck.success_count": "0",
+		"healthcheck.failure_count": "0",
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if checker.interval != time.Second || checker.timeout != time.Second || checker.successCount != 1 || checker.failureCount != 1 {
+		t.Fatalf("Zero values weren't raised to the minimum: %+v", checker)
+	}
+
+	for _, key := range []string{"healthcheck.interval", "healthcheck.timeout", "healthcheck.success_count", "healthcheck.failure_count"} {
+		_, err = newLBHealthChecker(map[string]string{key: "invalid"}, nil, nil)
+		if err == nil {
+			t.Fatalf("Expected an error for an invalid %q", key)
+		}
+	}
+}
+
+func TestLBHealthCheckerFingerprint(t *testing.T) {
+	targetA := lbHealthTarget{address: "192.0.2.1", protocol: "tcp", port: 80}
+	targetB := lbHealthTarget{address: "192.0.2.2", protocol: "udp", port: 53}
+
+	checkerA, err := newLBHealthChecker(nil, []lbHealthTarget{targetA, targetB}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkerB, err := newLBHealthChecker(nil, []lbHealthTarget{targetB, targetA}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if checkerA.fingerprint != checkerB.fingerprint {
+		t.Fatal("Fingerprint depends on the target order")
+	}
+
+	checkerC, err := newLBHealthChecker(map[string]string{"healthcheck.interval": "5"}, []lbHealthTarget{targetA, targetB}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if checkerA.fingerprint == checkerC.fingerprint {
+		t.Fatal("Fingerprint doesn't depend on the configuration")
+	}
+
+	checkerD, err := newLBHealthChecker(nil, []lbHealthTarget{targetA}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if checkerA.fingerprint == checkerD.fingerprint {
+		t.Fatal("Fingerprint doesn't depend on the targets")
+	}
+}
+
+func TestLBHealthCheckerStateMachine(t *testing.T) {
+	online, closeOnline := lbTestTCPTarget(t)
+	offline := lbHealthTarget{address: "127.0.0.1", protocol: "tcp", port: lbTestUnusedPort(t, "tcp")}
+
+	changes := 0
+	checker, err := newLBHealthChecker(map[string]string{
+		"healthcheck.timeout":       "1",
+		"healthcheck.success_count": "2",
+		"healthcheck.failure_count": "3",
+	}, []lbHealthTarget{online, offline}, func() { changes++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkStatus := func(step string, target lbHealthTarget, want string) {
+		t.Helper()
+
+		got := checker.status(target)
+		if got != want {
+			t.Fatalf("%s: expected %s:%d to be %q, got %q", step, target.protocol, target.port, want, got)
+		}
+	}
+
+	ctx := context.Background()
+
+	// Both start unknown until enough checks agree.
+	checkStatus("initial", online, lbBackendStatusUnknown)
+	checkStatus("initial", offline, lbBackendStatusUnknown)
+
+	checker.checkAll(ctx)
+	checkStatus("first check", online, lbBackendStatusUnknown)
+	checkStatus("first check", offline, lbBackendStatusUnknown)
+
+	if changes != 0 {
+		t.Fatalf("Unexpected change notification after the first check")
+	}
+
+	checker.checkAll(ctx)
+	checkStatus("second check", online, lbBackendStatusOnline)
+	checkStatus("second check", offline, lbBackendStatusUnknown)
+
+	checker.checkAll(ctx)
+	checkStatus("third check", offline, lbBackendStatusOffline)
+
+	if changes != 2 {
+		t.Fatalf("Expected 2 change notifications, got %d", changes)
+	}
+
+	// Further checks without a status change don't notify.
+	checker.checkAll(ctx)
+	if changes != 2 {
+		t.Fatalf("Unexpected change notification without a status change")
+	}
+
+	// A backend going down needs consecutive failures to go offline.
+	closeOnline()
+
+	checker.checkAll(ctx)
+	checker.checkAll(ctx)
+	checkStatus("two failures", online, lbBackendStatusOnline)
+
+	checker.checkAll(ctx)
+	checkStatus("three failures", online, lbBackendStatusOffline)
+
+	if changes != 3 {
+		t.Fatalf("Expected 3 change notifications, got %d", changes)
+	}
+
+	// Unknown targets and missing checkers are reported as unknown.
+	checkStatus("unknown target", lbHealthTarget{address: "127.0.0.1", protocol: "tcp", port: 1}, lbBackendStatusUnknown)
+
+	var missing *lbHealthChecker
+	if missing.status(online) != lbBackendStatusUnknown {
+		t.Fatal("Expected a missing checker to report unknown status")
+	}
+}
+
+func TestLBHealthCheckerStopped(t *testing.T) {
+	online, _ := lbTestTCPTarget(t)
+
+	changes := 0
+	checker, err := newLBHealthChecker(map[string]string{"healthcheck.success_count": "1"}, []lbHealthTarget{online}, func() { changes++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Results of checks running when the checker gets stopped are dropped.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	checker.checkAll(ctx)
+	if checker.status(online) != lbBackendStatusUnknown || changes != 0 {
+		t.Fatal("Results recorded after the checker was stopped")
+	}
+}
+
+func TestLBHealthCheck(t *testing.T) {
+	ctx := context.Background()
+
+	online, _ := lbTestTCPTarget(t)
+	err := lbHealthCheck(ctx, online, time.Second)
+	if err != nil {
+		t.Fatalf("Expected listening TCP port to be healthy: %v", err)
+	}
+
+	err = lbHealthCheck(ctx, lbHealthTarget{address: "127.0.0.1", protocol: "tcp", port: lbTestUnusedPort(t, "tcp")}, time.Second)
+	if err == nil {
+		t.Fatal("Expected closed TCP port to be unhealthy")
+	}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	err = lbHealthCheck(ctx, lbHealthTarget{address: "127.0.0.1", protocol: "udp", port: uint64(conn.LocalAddr().(*net.UDPAddr).Port)}, time.Second)
+	if err != nil {
+		t.Fatalf("Expected listening UDP port to be healthy: %v", err)
+	}
+
+	err = lbHealthCheck(ctx, lbHealthTarget{address: "127.0.0.1", protocol: "udp", port: lbTestUnusedPort(t, "udp")}, time.Second)
+	if err == nil {
+		t.Fatal("Expected closed UDP port to be unhealthy")
+	}
+}
+
+func TestLBHealthCheckersSync(t *testing.T) {
+	networkID := int64(-1)
+	defer lbHealthCheckersStop(networkID)
+
+	targetA := lbHealthTarget{address: "127.0.0.1", protocol: "tcp", port: lbTestUnusedPort(t, "tcp")}
+	targetB := lbHealthTarget{address: "127.0.0.1", protocol: "tcp", port: lbTestUnusedPort(t, "tcp")}
+
+	configs := map[string]map[string]string{
+		"192.0.2.1": {"healthcheck.interval": "60"},
+		"192.0.2.2": {"healthcheck.interval": "60"},
+	}
+
+	targets := map[string][]lbHealthTarget{
+		"192.0.2.1": {targetA},
+		"192.0.2.2": {targetB},
+	}
+
+	err := lbHealthCheckersSync(networkID, configs, targets, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkerA := lbHealthCheckerGet(networkID, "192.0.2.1")
+	checkerB := lbHealthCheckerGet(networkID, "192.0.2.2")
+	if checkerA == nil || checkerB == nil {
+		t.Fatal("Expected both health checkers to be running")
+	}
+
+	// Unchanged load balancers keep their checker, changed ones get a new one and removed ones are stopped.
+	configs["192.0.2.2"] = map[string]string{"healthcheck.interval": "30"}
+	delete(targets, "192.0.2.1")
+	targets["192.0.2.2"] = []lbHealthTarget{targetB}
+	targets["192.0.2.3"] = []lbHealthTarget{targetA}
+
+	err = lbHealthCheckersSync(networkID, configs, targets, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lbHealthCheckerGet(networkID, "192.0.2.1") != nil {
+		t.Fatal("Expected the removed health checker to be gone")
+	}
+
+	newB := lbHealthCheckerGet(networkID, "192.0.2.2")
+	if newB == nil || newB == checkerB || newB.interval != 30*time.Second {
+		t.Fatal("Expected the changed health checker to be replaced")
+	}
+
+	checkerC := lbHealthCheckerGet(networkID, "192.0.2.3")
+	if checkerC == nil {
+		t.Fatal("Expected the new health checker to be running")
+	}
+
+	err = lbHealthCheckersSync(networkID, configs, targets, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lbHealthCheckerGet(networkID, "192.0.2.2") != newB || lbHealthCheckerGet(networkID, "192.0.2.3") != checkerC {
+		t.Fatal("Expected unchanged health checkers to be kept")
+	}
+
+	// Invalid configurations are rejected.
+	configs["192.0.2.3"] = map[string]string{"healthcheck.timeout": "invalid"}
+	err = lbHealthCheckersSync(networkID, configs, targets, nil)
+	if err == nil {
+		t.Fatal("Expected an error for an invalid configuration")
+	}
+
+	// Other networks are left alone.
+	err = lbHealthCheckersSync(networkID-1, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lbHealthCheckerGet(networkID, "192.0.2.2") == nil {
+		t.Fatal("Health checker of another network was stopped")
+	}
+
+	lbHealthCheckersStop(networkID)
+	if lbHealthCheckerGet(networkID, "192.0.2.2") != nil || lbHealthCheckerGet(networkID, "192.0.2.3") != nil {
+		t.Fatal("Expected all health checkers of the network to be stopped")
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lxc/incus/v7/shared/api"
 	"github.com/lxc/incus/v7/shared/idmap"
 )
 
@@ -75,3 +76,23 @@ func TestParseHostIDMapRange(t *testing.T) {
 		assert.Equal(t, idmaps, expected)
 	}
 }
+
+func TestCheckVolumeReplicationRestriction(t *testing.T) {
+	unrestricted := &api.Project{Name: "p1"}
+	restricted := &api.Project{Name: "p1", ProjectPut: api.ProjectPut{Config: map[string]string{"restricted": "true"}}}
+	allowed := &api.Project{Name: "p1", ProjectPut: api.ProjectPut{Config: map[string]string{"restricted": "true", "restricted.storage.replication": "allow"}}}
+
+	config := map[string]string{"replication.target": "https://10.0.0.1:8443/default", "replication.schedule": "@hourly"}
+
+	assert.NoError(t, checkVolumeReplicationRestriction(unrestricted, config, nil))
+	assert.NoError(t, checkVolumeReplicationRestriction(allowed, config, nil))
+	assert.EqualError(t, checkVolumeReplicationRestriction(restricted, config, nil), `Project "p1" doesn't allow for storage volume replication`)
+
+	// Unchanged or removed targets are allowed.
+	assert.NoError(t, checkVolumeReplicationRestriction(restricted, config, config))
+	assert.NoError(t, checkVolumeReplicationRestriction(restricted, map[string]string{"replication.target": ""}, config))
+
+	// Setting any of the target keys is blocked.
+	assert.Error(t, checkVolumeReplicationRestriction(restricted, map[string]string{"replication.target.token": "foo"}, nil))
+	assert.NoError(t, checkVolumeReplicationRestriction(restricted, map[string]string{"replication.retention": "5"}, nil))
+}
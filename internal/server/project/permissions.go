@@ -313,6 +313,11 @@ func AllowVolumeCreation(tx *db.ClusterTx, projectName string, poolName string,
 		return errors.New("Restricted projects aren't allowed to use pull mode migration")
 	}
 
+	err = checkVolumeReplicationRestriction(&info.Project, req.Config, nil)
+	if err != nil {
+		return err
+	}
+
 	// Add the volume being created.
 	info.Volumes = append(info.Volumes, db.StorageVolumeArgs{
 		Name:     req.Name,
@@ -963,9 +968,11 @@ var allRestrictions = map[string]string{
 	"restricted.idmap.uid":                 "",
 	"restricted.idmap.gid":                 "",
 	"restricted.images.servers":            "",
+	"restricted.instances.credentials":     "block",
 	"restricted.networks.access":           "",
 	"restricted.snapshots":                 "block",
 	"restricted.storage-pools.access":      "",
+	"restricted.storage.replication":       "block",
 }
 
 // allowableIntercept lists all syscall interception keys which may be allowed.
@@ -1087,6 +1094,11 @@ func AllowVolumeUpdate(tx *db.ClusterTx, projectName, volumeName string, req api
 		return nil
 	}
 
+	err = checkVolumeReplicationRestriction(&info.Project, req.Config, currentConfig)
+	if err != nil {
+		return err
+	}
+
 	// If "limits.disk" is not set, there's nothing to do.
 	if info.Project.Config["limits.disk"] == "" {
 		return nil
@@ -1761,6 +1773,43 @@ func AllowSnapshotCreation(p *api.Project) error {
 	return nil
 }
 
+// AllowVolumeReplication returns an error if any project-specific restriction is violated
+// when replicating a custom volume to a remote server.
+func AllowVolumeReplication(p *api.Project) error {
+	if projectHasRestriction(p, "restricted.storage.replication", "block") {
+		return fmt.Errorf("Project %q doesn't allow for storage volume replication", p.Name)
+	}
+
+	return nil
+}
+
+// checkVolumeReplicationRestriction returns an error if the replication target of a custom volume is set
+// or changed in a project which doesn't allow for storage volume replication.
+func checkVolumeReplicationRestriction(p *api.Project, config map[string]string, currentConfig map[string]string) error {
+	for key, value := range config {
+		if !strings.HasPrefix(key, "replication.target") || value == "" || value == currentConfig[key] {
+			continue
+		}
+
+		err := AllowVolumeReplication(p)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AllowInstanceCredentials returns an error if any project-specific restriction is violated
+// when setting the credentials of a user inside of an instance.
+func AllowInstanceCredentials(p *api.Project) error {
+	if projectHasRestriction(p, "restricted.instances.credentials", "block") {
+		return fmt.Errorf("Project %q doesn't allow for setting instance credentials", p.Name)
+	}
+
+	return nil
+}
+
 // GetRestrictedClusterGroups returns a slice of restricted cluster groups for the given project.
 func GetRestrictedClusterGroups(p *api.Project) []string {
 	return util.SplitNTrimSpace(p.Config["restricted.cluster.groups"], ",", -1, true)
@@ -0,0 +1,111 @@
+package scriptlet
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.starlark.net/starlark"
+
+	scriptletLoad "github.com/lxc/incus/v7/internal/server/scriptlet/load"
+	"github.com/lxc/incus/v7/internal/server/scriptlet/log"
+	"github.com/lxc/incus/v7/shared/api"
+	apiScriptlet "github.com/lxc/incus/v7/shared/api/scriptlet"
+	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/scriptlet"
+)
+
+// InstanceValidationRun runs the instance validation scriptlet.
+// It returns the configuration changes requested by the scriptlet (an empty value meaning the key should be unset)
+// or an error with a bad request status if the scriptlet rejected the request.
+func InstanceValidationRun(l logger.Logger, req *apiScriptlet.InstanceValidation) (map[string]string, error) {
+	logFunc := log.CreateLogger(l, "Instance validation scriptlet")
+
+	changes := map[string]string{}
+	var rejections []string
+
+	setConfigFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var key string
+		var value string
+
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "key", &key, "value", &value)
+		if err != nil {
+			return nil, err
+		}
+
+		if key == "" || strings.HasPrefix(key, "volatile.") {
+			return nil, fmt.Errorf("Invalid config key: %q", key)
+		}
+
+		l.Info("Instance validation scriptlet changed config key", logger.Ctx{"project": req.Project, "name": req.Name, "key": key, "value": value})
+		changes[key] = value
+
+		return starlark.None, nil
+	}
+
+	rejectFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var message string
+
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "message", &message)
+		if err != nil {
+			return nil, err
+		}
+
+		rejections = append(rejections, message)
+
+		return starlark.None, nil
+	}
+
+	// Remember to match the entries in scriptletLoad.InstanceValidationCompile() with this list so Starlark can
+	// perform compile time validation of functions used.
+	env := starlark.StringDict{
+		"log_info":   starlark.NewBuiltin("log_info", logFunc),
+		"log_warn":   starlark.NewBuiltin("log_warn", logFunc),
+		"log_error":  starlark.NewBuiltin("log_error", logFunc),
+		"set_config": starlark.NewBuiltin("set_config", setConfigFunc),
+		"reject":     starlark.NewBuiltin("reject", rejectFunc),
+	}
+
+	prog, thread, err := scriptletLoad.InstanceValidationProgram()
+	if err != nil {
+		return nil, err
+	}
+
+	globals, err := prog.Init(thread, env)
+	if err != nil {
+		return nil, fmt.Errorf("Failed initializing: %w", err)
+	}
+
+	globals.Freeze()
+
+	// Retrieve a global variable from starlark environment.
+	instanceValidation := globals["instance_validation"]
+	if instanceValidation == nil {
+		return nil, errors.New("Scriptlet missing instance_validation function")
+	}
+
+	rv, err := scriptlet.StarlarkMarshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("Marshalling request failed: %w", err)
+	}
+
+	// Call starlark function from Go.
+	v, err := starlark.Call(thread, instanceValidation, nil, []starlark.Tuple{{
+		starlark.String("request"),
+		rv,
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to run: %w", err)
+	}
+
+	if v.Type() != "NoneType" {
+		return nil, fmt.Errorf("Failed with unexpected return value: %v", v)
+	}
+
+	if len(rejections) > 0 {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Rejected by instance validation scriptlet: %s", strings.Join(rejections, ", "))
+	}
+
+	return changes, nil
+}
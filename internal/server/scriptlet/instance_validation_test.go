@@ -0,0 +1,158 @@
+package scriptlet
+
+import (
+	"net/http"
+	"testing"
+
+	scriptletLoad "github.com/lxc/incus/v7/internal/server/scriptlet/load"
+	"github.com/lxc/incus/v7/shared/api"
+	apiScriptlet "github.com/lxc/incus/v7/shared/api/scriptlet"
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+func instanceValidationTestRun(t *testing.T, src string) (map[string]string, error) {
+	t.Helper()
+
+	err := scriptletLoad.InstanceValidationSet(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = scriptletLoad.InstanceValidationSet("") })
+
+	req := &apiScriptlet.InstanceValidation{
+		Reason:   apiScriptlet.InstanceValidationReasonCreate,
+		Project:  "default",
+		Name:     "c1",
+		Type:     "container",
+		Profiles: []string{"default"},
+		Config:   map[string]string{"limits.cpu": "2"},
+		Devices:  map[string]map[string]string{"root": {"type": "disk", "path": "/", "pool": "default"}},
+	}
+
+	return InstanceValidationRun(logger.Log, req)
+}
+
+func TestInstanceValidationRunAccept(t *testing.T) {
+	changes, err := instanceValidationTestRun(t, `
+def instance_validation(request):
+    if request.name != "c1" or request.config["limits.cpu"] != "2" or request.devices["root"]["pool"] != "default":
+        reject("unexpected request")
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(changes) != 0 {
+		t.Fatalf("Unexpected changes: %v", changes)
+	}
+}
+
+func TestInstanceValidationRunSetConfig(t *testing.T) {
+	changes, err := instanceValidationTestRun(t, `
+def instance_validation(request):
+    set_config("limits.memory", "1GiB")
+    set_config("limits.cpu", "")
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(changes) != 2 || changes["limits.memory"] != "1GiB" || changes["limits.cpu"] != "" {
+		t.Fatalf("Unexpected changes: %v", changes)
+	}
+}
+
+func TestInstanceValidationRunReject(t *testing.T) {
+	_, err := instanceValidationTestRun(t, `
+def instance_validation(request):
+    reject("too small")
+    reject("wrong project")
+`)
+	if err == nil {
+		t.Fatal("Expected the request to be rejected")
+	}
+
+	if !api.StatusErrorCheck(err, http.StatusBadRequest) {
+		t.Fatalf("Expected a bad request error, got: %v", err)
+	}
+
+	if err.Error() != "Rejected by instance validation scriptlet: too small, wrong project" {
+		t.Fatalf("Unexpected error message: %v", err)
+	}
+}
+
+func TestInstanceValidationRunErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{
+			name: "volatile key",
+			src: `
+def instance_validation(request):
+    set_config("volatile.uuid", "foo")
+`,
+		},
+		{
+			name: "empty key",
+			src: `
+def instance_validation(request):
+    set_config("", "foo")
+`,
+		},
+		{
+			name: "return value",
+			src: `
+def instance_validation(request):
+    return True
+`,
+		},
+		{
+			name: "runtime error",
+			src: `
+def instance_validation(request):
+    fail("broken")
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := instanceValidationTestRun(t, tt.src)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+
+			if api.StatusErrorCheck(err, http.StatusBadRequest) {
+				t.Fatalf("Scriptlet failures shouldn't be reported as rejections: %v", err)
+			}
+		})
+	}
+}
+
+func TestInstanceValidationValidate(t *testing.T) {
+	err := scriptletLoad.InstanceValidationValidate(`
+def instance_validation(request):
+    pass
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = scriptletLoad.InstanceValidationValidate(`
+def instance_placement(request, candidate_members):
+    pass
+`)
+	if err == nil {
+		t.Fatal("Expected an error for a scriptlet without instance_validation")
+	}
+
+	err = scriptletLoad.InstanceValidationValidate(`
+def instance_validation(request):
+    set_target("foo")
+`)
+	if err == nil {
+		t.Fatal("Expected an error for a scriptlet using an unknown function")
+	}
+}
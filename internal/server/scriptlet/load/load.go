@@ -9,6 +9,9 @@ import (
 // nameInstancePlacement is the name used in Starlark for the instance placement scriptlet.
 const nameInstancePlacement = "instance_placement"
 
+// nameInstanceValidation is the name used in Starlark for the instance validation scriptlet.
+const nameInstanceValidation = "instance_validation"
+
 // prefixQEMU is the prefix used in Starlark for the QEMU scriptlet.
 const prefixQEMU = "qemu"
 
@@ -52,6 +55,35 @@ func InstancePlacementProgram() (*starlark.Program, *starlark.Thread, error) {
 	return loader.Program("Instance placement", nameInstancePlacement)
 }
 
+// InstanceValidationCompile compiles the instance validation scriptlet.
+func InstanceValidationCompile(name string, src string) (*starlark.Program, error) {
+	return scriptlet.Compile(name, src, []string{
+		"log_info",
+		"log_warn",
+		"log_error",
+		"set_config",
+		"reject",
+	})
+}
+
+// InstanceValidationValidate validates the instance validation scriptlet.
+func InstanceValidationValidate(src string) error {
+	return scriptlet.Validate(InstanceValidationCompile, nameInstanceValidation, src, scriptlet.Declaration{
+		scriptlet.Required("instance_validation"): {"request"},
+	})
+}
+
+// InstanceValidationSet compiles the instance validation scriptlet into memory for use with InstanceValidationRun.
+// If empty src is provided the current program is deleted.
+func InstanceValidationSet(src string) error {
+	return loader.Set(InstanceValidationCompile, nameInstanceValidation, src)
+}
+
+// InstanceValidationProgram returns the precompiled instance validation scriptlet program.
+func InstanceValidationProgram() (*starlark.Program, *starlark.Thread, error) {
+	return loader.Program("Instance validation", nameInstanceValidation)
+}
+
 // QEMUCompile compiles the QEMU scriptlet.
 func QEMUCompile(name string, src string) (*starlark.Program, error) {
 	return scriptlet.Compile(name, src, []string{
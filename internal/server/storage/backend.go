@@ -23,6 +23,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	"go.yaml.in/yaml/v4"
 	"golang.org/x/sync/errgroup"
 
@@ -62,6 +63,10 @@ import (
 	"github.com/lxc/incus/v7/shared/util"
 )
 
+// backupFreezeDuration is the maximum time the guest file systems of a virtual machine are kept frozen while
+// taking the temporary snapshot a backup is made from.
+const backupFreezeDuration = 10 * time.Minute
+
 var (
 	unavailablePools   = make(map[string]struct{})
 	unavailablePoolsMu = sync.Mutex{}
@@ -2824,11 +2829,33 @@ func (b *backend) BackupInstance(inst instance.Instance, tarWriter *instancewrit
 		}
 	}
 
+	// The guest file systems of running virtual machines are only frozen while taking a temporary snapshot
+	// of the volume, which is then copied instead of the live volume.
+	vm, freeze := inst.(instance.VM)
+	freezeMode := inst.ExpandedConfig()["snapshots.freeze"]
+	if freeze && inst.IsRunning() && (freezeMode == "auto" || freezeMode == "required") {
+		if optimized || dbVol.Config["block.type"] == drivers.BlockVolumeTypeQcow2 {
+			if freezeMode == "required" {
+				return errors.New("Freezing guest file systems isn't supported for optimized backups or qcow2 volumes")
+			}
+
+			l.Warn("Freezing guest file systems isn't supported for optimized backups or qcow2 volumes, falling back to crash-consistent backup")
+			freeze = false
+		}
+	} else {
+		freeze = false
+	}
+
 	if dbVol.Config["block.type"] == drivers.BlockVolumeTypeQcow2 {
 		err = b.qcow2BackupVolume(vol, dbVol, inst.Project().Name, tarWriter, backup.DefaultBackupPrefix, snapNames, op)
 		if err != nil {
 			return err
 		}
+	} else if freeze {
+		err = b.frozenBackupVolume(vm, vol, tarWriter, backup.DefaultBackupPrefix, snapNames, op)
+		if err != nil {
+			return err
+		}
 	} else {
 		err = b.driver.BackupVolume(vol, tarWriter, backup.DefaultBackupPrefix, optimized, snapNames, op)
 		if err != nil {
@@ -2859,6 +2886,67 @@ func (b *backend) BackupInstance(inst instance.Instance, tarWriter *instancewrit
 	return nil
 }
 
+// frozenBackupVolume writes a non-optimized backup of the volume of a running virtual machine.
+// The guest file systems are frozen while taking a temporary snapshot of the volume, and the main volume is then
+// copied from that snapshot.
+func (b *backend) frozenBackupVolume(vm instance.VM, vol drivers.Volume, writer instancewriter.InstanceWriter, basePrefix string, snapshots []string, op *operations.Operation) error {
+	if len(snapshots) > 0 {
+		// Check requested snapshot match those in storage.
+		err := vol.SnapshotsMatch(snapshots, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmpVol, err := vol.NewSnapshot("backup-" + uuid.New().String())
+	if err != nil {
+		return err
+	}
+
+	thaw, err := vm.FreezeFilesystems(backupFreezeDuration)
+	if err != nil {
+		return err
+	}
+
+	err = b.driver.CreateVolumeSnapshot(tmpVol, op)
+	thawErr := thaw()
+	if err != nil {
+		return fmt.Errorf("Failed creating temporary backup snapshot: %w", err)
+	}
+
+	defer func() { _ = b.driver.DeleteVolumeSnapshot(tmpVol, op) }()
+
+	if thawErr != nil {
+		return thawErr
+	}
+
+	backupVolume := func(v drivers.Volume, prefix string) error {
+		return v.MountTask(func(mountPath string, op *operations.Operation) error {
+			diskPath, err := b.driver.GetVolumeDiskPath(v)
+			if err != nil {
+				return fmt.Errorf("Error getting VM block volume disk path: %w", err)
+			}
+
+			return drivers.BackupVolume(b.driver, v, writer, mountPath, diskPath, prefix)
+		}, op)
+	}
+
+	for _, snapName := range snapshots {
+		snapVol, err := vol.NewSnapshot(snapName)
+		if err != nil {
+			return err
+		}
+
+		err = backupVolume(snapVol, filepath.Join(basePrefix, drivers.BackupSnapshotPrefix(vol), snapName))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Copy the main volume from the temporary snapshot.
+	return backupVolume(tmpVol, filepath.Join(basePrefix, drivers.BackupPrefix(vol)))
+}
+
 // GetInstanceUsage returns the disk usage of the instance's root volume.
 func (b *backend) GetInstanceUsage(inst instance.Instance) (*VolumeUsage, error) {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
@@ -4860,6 +4948,57 @@ func (b *backend) MountLocalBucket(projectName string, bucketName string, op *op
 	return bucketVol.MountPath(), unmount, nil
 }
 
+// GetBucketUsage returns the disk usage of a storage bucket.
+func (b *backend) GetBucketUsage(projectName string, bucketName string) (*VolumeUsage, error) {
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	if !b.Driver().Info().Buckets {
+		return nil, errors.New("Storage pool does not support buckets")
+	}
+
+	memberSpecific := !b.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
+	var bucket *db.StorageBucket
+	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, memberSpecific, bucketName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	val := VolumeUsage{}
+
+	bucketVolName := project.StorageVolume(projectName, bucket.Name)
+	bucketVol := b.GetVolume(drivers.VolumeTypeBucket, drivers.ContentTypeFS, bucketVolName, bucket.Config)
+
+	// Get the usage.
+	size, err := b.driver.GetVolumeUsage(bucketVol)
+	if err != nil {
+		return nil, err
+	}
+
+	val.Used = size
+
+	// Get the total size.
+	sizeStr, ok := bucket.Config["size"]
+	if ok {
+		total, err := units.ParseByteSizeString(sizeStr)
+		if err != nil {
+			return nil, err
+		}
+
+		if total >= 0 {
+			val.Total = total
+		}
+	}
+
+	return &val, nil
+}
+
 // GetBucketURL returns S3 URL for bucket.
 func (b *backend) GetBucketURL(bucketName string) *url.URL {
 	err := b.isStatusReady()
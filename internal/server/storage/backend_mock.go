@@ -283,6 +283,10 @@ func (b *mockBackend) GetBucketURL(bucketName string) *url.URL {
 	return nil
 }
 
+func (b *mockBackend) GetBucketUsage(projectName string, bucketName string) (*VolumeUsage, error) {
+	return nil, nil
+}
+
 func (b *mockBackend) CreateCustomVolume(projectName string, volName string, desc string, config map[string]string, contentType drivers.ContentType, op *operations.Operation) error {
 	return nil
 }
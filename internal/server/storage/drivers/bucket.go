@@ -1,7 +1,73 @@
 package drivers
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v7/shared/util"
+)
+
 // S3Credentials represents the credentials to access a bucket.
 type S3Credentials struct {
 	AccessKey string `json:"access_key"`
 	SecretKey string `json:"secret_key"`
 }
+
+// BucketPublicPrefixes returns the object key prefixes of a bucket which can be read without authentication.
+// An empty prefix means that the whole bucket is public.
+func BucketPublicPrefixes(config map[string]string) []string {
+	if util.IsTrue(config["public_read"]) {
+		return []string{""}
+	}
+
+	return util.SplitNTrimSpace(config["public_read.prefixes"], ",", -1, true)
+}
+
+// BucketLifecycleRule represents a rule expiring the objects of a bucket.
+type BucketLifecycleRule struct {
+	// Prefix of the object keys the rule applies to.
+	Prefix string
+
+	// Days after which the objects expire.
+	Days int
+}
+
+// BucketLifecycleRules parses the value of the lifecycle.expiry bucket config key.
+// It's a comma-separated list of "[<prefix>=]<days>" entries, without prefix the rule applies to the whole bucket.
+func BucketLifecycleRules(value string) ([]BucketLifecycleRule, error) {
+	rules := []BucketLifecycleRule{}
+
+	for _, entry := range util.SplitNTrimSpace(value, ",", -1, true) {
+		var prefix string
+
+		days := entry
+		idx := strings.LastIndex(entry, "=")
+		if idx >= 0 {
+			prefix = entry[:idx]
+			days = entry[idx+1:]
+
+			if prefix == "" {
+				return nil, fmt.Errorf("Empty prefix in lifecycle rule %q", entry)
+			}
+		}
+
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("Invalid number of days in lifecycle rule %q", entry)
+		}
+
+		rules = append(rules, BucketLifecycleRule{Prefix: prefix, Days: n})
+	}
+
+	return rules, nil
+}
+
+// BucketVersionRetention returns for how many versions and days the previous versions of the objects of a bucket are kept.
+// Zero means no limit.
+func BucketVersionRetention(config map[string]string) (int, int) {
+	versions, _ := strconv.Atoi(config["versioning.retention.versions"])
+	days, _ := strconv.Atoi(config["versioning.retention.days"])
+
+	return versions, days
+}
@@ -435,6 +435,16 @@ func (d *ceph) Unmount() (bool, error) {
 	return true, nil
 }
 
+// FenceMember blocks the Ceph clients of a cluster member from accessing the Ceph cluster.
+func (d *ceph) FenceMember(name string, addresses []string) error {
+	return cephBlocklistAdd(d.config["ceph.cluster_name"], d.config["ceph.user.name"], addresses)
+}
+
+// UnfenceMember removes the Ceph clients of a cluster member from the OSD blocklist.
+func (d *ceph) UnfenceMember(name string, addresses []string) error {
+	return cephBlocklistRemove(d.config["ceph.cluster_name"], d.config["ceph.user.name"], addresses)
+}
+
 // GetResources returns the pool resource usage information.
 func (d *ceph) GetResources() (*api.ResourcesStoragePool, error) {
 	var stdout bytes.Buffer
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
@@ -49,6 +50,82 @@ var cephVolTypePrefixes = map[VolumeType]string{
 	VolumeTypeCustom:    db.StoragePoolVolumeTypeNameCustom,
 }
 
+// cephBlocklistExpiry is how long a fenced cluster member is kept on the Ceph OSD blocklist.
+// The entry is normally removed well before that, once the member rejoins the cluster.
+const cephBlocklistExpiry = 365 * 24 * time.Hour
+
+// cephBlocklistArgs returns the arguments of the ceph command adding (or removing) an address or subnet to the OSD blocklist.
+// A plain IP address blocks all the Ceph clients using it.
+func cephBlocklistArgs(clusterName string, userName string, address string, add bool) ([]string, error) {
+	args := []string{
+		"--name", fmt.Sprintf("client.%s", userName),
+		"--cluster", clusterName,
+		"osd",
+		"blocklist",
+	}
+
+	action := "rm"
+	if add {
+		action = "add"
+	}
+
+	ip := net.ParseIP(address)
+	if ip != nil {
+		args = append(args, action, net.JoinHostPort(ip.String(), "0")+"/0")
+	} else {
+		_, subnet, err := net.ParseCIDR(address)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid Ceph client address %q", address)
+		}
+
+		args = append(args, "range", action, subnet.String())
+	}
+
+	if add {
+		args = append(args, strconv.FormatInt(int64(cephBlocklistExpiry/time.Second), 10))
+	}
+
+	return args, nil
+}
+
+// cephBlocklistAdd adds all Ceph clients at the given addresses to the OSD blocklist.
+func cephBlocklistAdd(clusterName string, userName string, addresses []string) error {
+	if len(addresses) == 0 {
+		return errors.New("No Ceph client address to blocklist")
+	}
+
+	for _, address := range addresses {
+		args, err := cephBlocklistArgs(clusterName, userName, address, true)
+		if err != nil {
+			return err
+		}
+
+		_, err = subprocess.RunCommand("ceph", args...)
+		if err != nil {
+			return fmt.Errorf("Failed adding %q to the Ceph OSD blocklist: %w", address, err)
+		}
+	}
+
+	return nil
+}
+
+// cephBlocklistRemove removes the given addresses from the OSD blocklist.
+func cephBlocklistRemove(clusterName string, userName string, addresses []string) error {
+	for _, address := range addresses {
+		args, err := cephBlocklistArgs(clusterName, userName, address, false)
+		if err != nil {
+			return err
+		}
+
+		_, err = subprocess.RunCommand("ceph", args...)
+		if err != nil {
+			return fmt.Errorf("Failed removing %q from the Ceph OSD blocklist: %w", address, err)
+		}
+	}
+
+	return nil
+}
+
 // osdPoolExists checks whether a given OSD pool exists.
 func (d *ceph) osdPoolExists() (bool, error) {
 	_, err := subprocess.RunCommand(
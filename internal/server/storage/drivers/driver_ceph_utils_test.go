@@ -2,6 +2,7 @@ package drivers
 
 import (
 	"fmt"
+	"slices"
 	"testing"
 )
 
@@ -226,3 +227,49 @@ func Example_ceph_parseParent() {
 	//   contentType: filesystem
 	//   config: map[]
 }
+
+func Test_cephBlocklistArgs(t *testing.T) {
+	prefix := []string{"--name", "client.admin", "--cluster", "ceph", "osd", "blocklist"}
+
+	tests := []struct {
+		address string
+		add     bool
+		want    []string
+		wantErr bool
+	}{
+		{address: "192.0.2.1", add: true, want: []string{"add", "192.0.2.1:0/0", "31536000"}},
+		{address: "192.0.2.1", want: []string{"rm", "192.0.2.1:0/0"}},
+		{address: "2001:db8::1", add: true, want: []string{"add", "[2001:db8::1]:0/0", "31536000"}},
+		{address: "192.0.2.0/24", add: true, want: []string{"range", "add", "192.0.2.0/24", "31536000"}},
+		{address: "2001:db8::/64", want: []string{"range", "rm", "2001:db8::/64"}},
+		{address: "ceph1.example.net", add: true, wantErr: true},
+		{address: "192.0.2.1:6789", add: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %v", tt.address, tt.add), func(t *testing.T) {
+			args, err := cephBlocklistArgs("ceph", "admin", tt.address, tt.add)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			want := append(slices.Clone(prefix), tt.want...)
+			if !slices.Equal(args, want) {
+				t.Fatalf("Expected %v, got %v", want, args)
+			}
+		})
+	}
+
+	err := cephBlocklistAdd("ceph", "admin", nil)
+	if err == nil {
+		t.Fatal("Expected an error without any address")
+	}
+}
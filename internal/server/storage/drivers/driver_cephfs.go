@@ -567,6 +567,16 @@ func (d *cephfs) Unmount() (bool, error) {
 	return forceUnmount(GetPoolMountPath(d.name))
 }
 
+// FenceMember blocks the Ceph clients of a cluster member from accessing the Ceph cluster.
+func (d *cephfs) FenceMember(name string, addresses []string) error {
+	return cephBlocklistAdd(d.config["cephfs.cluster_name"], d.config["cephfs.user.name"], addresses)
+}
+
+// UnfenceMember removes the Ceph clients of a cluster member from the OSD blocklist.
+func (d *cephfs) UnfenceMember(name string, addresses []string) error {
+	return cephBlocklistRemove(d.config["cephfs.cluster_name"], d.config["cephfs.user.name"], addresses)
+}
+
 // GetResources returns the pool resource usage information.
 func (d *cephfs) GetResources() (*api.ResourcesStoragePool, error) {
 	return genericVFSGetResources(d)
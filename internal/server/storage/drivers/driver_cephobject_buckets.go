@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
@@ -15,6 +16,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
 
 	"github.com/lxc/incus/v7/internal/server/operations"
@@ -23,6 +25,7 @@ import (
 	"github.com/lxc/incus/v7/shared/api"
 	"github.com/lxc/incus/v7/shared/revert"
 	"github.com/lxc/incus/v7/shared/units"
+	"github.com/lxc/incus/v7/shared/util"
 )
 
 // ValidateVolume validates the supplied volume config.
@@ -34,6 +37,48 @@ func (d *cephobject) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	//  default: -
 	//  shortdesc: Quota of the storage bucket
 
+	// gendoc:generate(entity=storage_bucket_cephobject, group=common, key=public_read)
+	//
+	// ---
+	//  type: bool
+	//  default: `false`
+	//  shortdesc: Whether all objects can be downloaded without authentication
+
+	// gendoc:generate(entity=storage_bucket_cephobject, group=common, key=public_read.prefixes)
+	//
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Comma-separated list of object key prefixes that can be downloaded without authentication
+
+	// gendoc:generate(entity=storage_bucket_cephobject, group=common, key=versioning)
+	//
+	// ---
+	//  type: bool
+	//  default: `false`
+	//  shortdesc: Whether previous versions of overwritten and deleted objects are kept
+
+	// gendoc:generate(entity=storage_bucket_cephobject, group=common, key=versioning.retention.versions)
+	//
+	// ---
+	//  type: integer
+	//  default: `0` (no limit)
+	//  shortdesc: Number of previous versions kept for each object
+
+	// gendoc:generate(entity=storage_bucket_cephobject, group=common, key=versioning.retention.days)
+	//
+	// ---
+	//  type: integer
+	//  default: `0` (no limit)
+	//  shortdesc: Number of days previous versions are kept for
+
+	// gendoc:generate(entity=storage_bucket_cephobject, group=common, key=lifecycle.expiry)
+	//
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Comma-separated list of `[<prefix>=]<days>` rules after which objects expire
+
 	return d.validateVolume(vol, nil, removeUnknownKeys)
 }
 
@@ -161,6 +206,30 @@ func (d *cephobject) CreateBucket(bucket Volume, op *operations.Operation) error
 		}
 	}
 
+	// Allow anonymous access if requested.
+	if len(BucketPublicPrefixes(bucket.config)) > 0 {
+		err = d.setBucketPublicPolicy(bucket, bucket.config)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Enable versioning if requested.
+	if util.IsTrue(bucket.config["versioning"]) {
+		err = d.setBucketVersioning(bucket, bucket.config)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Apply the lifecycle rules if specified.
+	if bucketHasLifecycle(bucket.config) {
+		err = d.setBucketLifecycle(bucket, bucket.config)
+		if err != nil {
+			return err
+		}
+	}
+
 	reverter.Success()
 	return nil
 }
@@ -183,6 +252,163 @@ func (d *cephobject) setBucketQuota(bucket Volume, quotaSize string) error {
 	return nil
 }
 
+// bucketOwnerS3Client returns an S3 client authenticated as the user owning the bucket.
+// The bucket policy, versioning and lifecycle settings can only be changed by the owner.
+func (d *cephobject) bucketOwnerS3Client(ctx context.Context, storageBucketName string) (*s3.Client, error) {
+	bucketUserInfo, _, err := d.radosgwadminGetUser(ctx, storageBucketName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting bucket user: %w", err)
+	}
+
+	return d.s3Client(*bucketUserInfo)
+}
+
+// setBucketPublicPolicy applies the bucket policy granting anonymous read access according to config.
+func (d *cephobject) setBucketPublicPolicy(bucket Volume, config map[string]string) error {
+	_, bucketName := project.StorageVolumeParts(bucket.name)
+	storageBucketName := d.radosgwBucketName(bucketName)
+
+	ctx, ctxCancel := context.WithTimeout(context.TODO(), time.Duration(time.Second*30))
+	defer ctxCancel()
+
+	s3Client, err := d.bucketOwnerS3Client(ctx, storageBucketName)
+	if err != nil {
+		return err
+	}
+
+	prefixes := BucketPublicPrefixes(config)
+	if len(prefixes) == 0 {
+		_, err = s3Client.DeleteBucketPolicy(ctx, &s3.DeleteBucketPolicyInput{
+			Bucket: aws.String(storageBucketName),
+		})
+		if err != nil {
+			return fmt.Errorf("Failed removing bucket policy: %w", err)
+		}
+
+		return nil
+	}
+
+	policy, err := s3util.PublicReadPolicy(storageBucketName, prefixes)
+	if err != nil {
+		return err
+	}
+
+	_, err = s3Client.PutBucketPolicy(ctx, &s3.PutBucketPolicyInput{
+		Bucket: aws.String(storageBucketName),
+		Policy: aws.String(string(policy)),
+	})
+	if err != nil {
+		return fmt.Errorf("Failed setting bucket policy: %w", err)
+	}
+
+	return nil
+}
+
+// setBucketVersioning enables or suspends the versioning of the bucket according to config.
+// Once enabled, versioning can only be suspended, the existing versions are kept.
+func (d *cephobject) setBucketVersioning(bucket Volume, config map[string]string) error {
+	_, bucketName := project.StorageVolumeParts(bucket.name)
+	storageBucketName := d.radosgwBucketName(bucketName)
+
+	ctx, ctxCancel := context.WithTimeout(context.TODO(), time.Duration(time.Second*30))
+	defer ctxCancel()
+
+	s3Client, err := d.bucketOwnerS3Client(ctx, storageBucketName)
+	if err != nil {
+		return err
+	}
+
+	status := types.BucketVersioningStatusSuspended
+	if util.IsTrue(config["versioning"]) {
+		status = types.BucketVersioningStatusEnabled
+	}
+
+	_, err = s3Client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+		Bucket:                  aws.String(storageBucketName),
+		VersioningConfiguration: &types.VersioningConfiguration{Status: status},
+	})
+	if err != nil {
+		return fmt.Errorf("Failed setting bucket versioning: %w", err)
+	}
+
+	return nil
+}
+
+// bucketHasLifecycle returns whether config defines lifecycle rules or a retention for previous versions of objects.
+func bucketHasLifecycle(config map[string]string) bool {
+	versions, days := BucketVersionRetention(config)
+
+	return config["lifecycle.expiry"] != "" || versions > 0 || days > 0
+}
+
+// setBucketLifecycle applies the lifecycle configuration of the bucket according to config.
+// The expiration of objects and the retention of their previous versions are both handled by
+// the radosgw lifecycle processing.
+func (d *cephobject) setBucketLifecycle(bucket Volume, config map[string]string) error {
+	_, bucketName := project.StorageVolumeParts(bucket.name)
+	storageBucketName := d.radosgwBucketName(bucketName)
+
+	ctx, ctxCancel := context.WithTimeout(context.TODO(), time.Duration(time.Second*30))
+	defer ctxCancel()
+
+	s3Client, err := d.bucketOwnerS3Client(ctx, storageBucketName)
+	if err != nil {
+		return err
+	}
+
+	if !bucketHasLifecycle(config) {
+		_, err = s3Client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{
+			Bucket: aws.String(storageBucketName),
+		})
+		if err != nil {
+			return fmt.Errorf("Failed removing bucket lifecycle: %w", err)
+		}
+
+		return nil
+	}
+
+	expiryRules, err := BucketLifecycleRules(config["lifecycle.expiry"])
+	if err != nil {
+		return err
+	}
+
+	rules := []types.LifecycleRule{}
+	for i, rule := range expiryRules {
+		rules = append(rules, types.LifecycleRule{
+			ID:         aws.String(fmt.Sprintf("incus-expiry-%d", i)),
+			Status:     types.ExpirationStatusEnabled,
+			Filter:     &types.LifecycleRuleFilter{Prefix: aws.String(rule.Prefix)},
+			Expiration: &types.LifecycleExpiration{Days: aws.Int32(int32(rule.Days))},
+		})
+	}
+
+	versions, days := BucketVersionRetention(config)
+	if versions > 0 || days > 0 {
+		// Noncurrent versions can't be expired in less than a day.
+		expiration := &types.NoncurrentVersionExpiration{NoncurrentDays: aws.Int32(int32(max(days, 1)))}
+		if versions > 0 {
+			expiration.NewerNoncurrentVersions = aws.Int32(int32(versions))
+		}
+
+		rules = append(rules, types.LifecycleRule{
+			ID:                          aws.String("incus-versions"),
+			Status:                      types.ExpirationStatusEnabled,
+			Filter:                      &types.LifecycleRuleFilter{Prefix: aws.String("")},
+			NoncurrentVersionExpiration: expiration,
+		})
+	}
+
+	_, err = s3Client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(storageBucketName),
+		LifecycleConfiguration: &types.BucketLifecycleConfiguration{Rules: rules},
+	})
+	if err != nil {
+		return fmt.Errorf("Failed setting bucket lifecycle: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteBucket deletes an existing bucket.
 func (d *cephobject) DeleteBucket(bucket Volume, op *operations.Operation) error {
 	_, bucketName := project.StorageVolumeParts(bucket.name)
@@ -211,6 +437,36 @@ func (d *cephobject) UpdateBucket(bucket Volume, changedConfig map[string]string
 		}
 	}
 
+	newConfig := maps.Clone(bucket.config)
+	maps.Copy(newConfig, changedConfig)
+
+	_, publicReadChanged := changedConfig["public_read"]
+	_, publicPrefixesChanged := changedConfig["public_read.prefixes"]
+	if publicReadChanged || publicPrefixesChanged {
+		err := d.setBucketPublicPolicy(bucket, newConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, versioningChanged := changedConfig["versioning"]
+	if versioningChanged {
+		err := d.setBucketVersioning(bucket, newConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, retentionVersionsChanged := changedConfig["versioning.retention.versions"]
+	_, retentionDaysChanged := changedConfig["versioning.retention.days"]
+	_, expiryChanged := changedConfig["lifecycle.expiry"]
+	if retentionVersionsChanged || retentionDaysChanged || expiryChanged {
+		err := d.setBucketLifecycle(bucket, newConfig)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -304,6 +560,17 @@ func (d *cephobject) DeleteBucketKey(bucket Volume, keyName string, op *operatio
 	return nil
 }
 
+// GetVolumeUsage returns the disk space used by a bucket.
+func (d *cephobject) GetVolumeUsage(vol Volume) (int64, error) {
+	if vol.volType != VolumeTypeBucket {
+		return -1, ErrNotSupported
+	}
+
+	_, bucketName := project.StorageVolumeParts(vol.name)
+
+	return d.radosgwadminBucketUsage(context.TODO(), d.radosgwBucketName(bucketName))
+}
+
 // GetBucketURL returns the URL of the specified bucket.
 func (d *cephobject) GetBucketURL(bucketName string) *url.URL {
 	u, err := url.ParseRequestURI(d.config["cephobject.radosgw.endpoint"])
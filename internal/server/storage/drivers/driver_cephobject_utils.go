@@ -251,6 +251,32 @@ func (d *cephobject) radosgwadminBucketList(ctx context.Context) ([]string, erro
 	return buckets, nil
 }
 
+// radosgwadminBucketUsage returns the space used by a bucket.
+func (d *cephobject) radosgwadminBucketUsage(ctx context.Context, bucket string) (int64, error) {
+	out, err := d.radosgwadmin(ctx, "bucket", "stats", "--bucket", bucket)
+	if err != nil {
+		return -1, err
+	}
+
+	stats := struct {
+		Usage map[string]struct {
+			SizeActual int64 `json:"size_actual"`
+		} `json:"usage"`
+	}{}
+
+	err = json.Unmarshal([]byte(out), &stats)
+	if err != nil {
+		return -1, err
+	}
+
+	var size int64
+	for _, usage := range stats.Usage {
+		size += usage.SizeActual
+	}
+
+	return size, nil
+}
+
 // radosgwBucketName returns the bucket name to use for the actual radosgw bucket.
 func (d *cephobject) radosgwBucketName(bucketName string) string {
 	return fmt.Sprintf("%s%s", d.config["cephobject.bucket.name_prefix"], bucketName)
@@ -23,6 +23,7 @@ import (
 	"github.com/lxc/incus/v7/shared/revert"
 	"github.com/lxc/incus/v7/shared/subprocess"
 	"github.com/lxc/incus/v7/shared/util"
+	"github.com/lxc/incus/v7/shared/validate"
 )
 
 type common struct {
@@ -172,6 +173,16 @@ func (d *common) validateVolume(vol Volume, driverRules map[string]func(value st
 			continue
 		}
 
+		// Backup checkpoints of dirty bitmaps are keyed by bitmap name.
+		if vol.volType == VolumeTypeCustom && strings.HasPrefix(k, "volatile.bitmap.") && strings.HasSuffix(k, ".checkpoint") {
+			err := validate.IsUUID(vol.config[k])
+			if err != nil {
+				return fmt.Errorf("Invalid value for volume %q option %q: %w", vol.name, k, err)
+			}
+
+			continue
+		}
+
 		if removeUnknownKeys {
 			delete(vol.config, k)
 		} else {
@@ -327,6 +338,17 @@ func (d *common) moveGPTAltHeader(devPath string) error {
 	return err
 }
 
+// FenceMember prevents a cluster member from accessing the storage pool.
+// The addresses are those the member uses to access the storage.
+func (d *common) FenceMember(name string, addresses []string) error {
+	return ErrNotSupported
+}
+
+// UnfenceMember allows a previously fenced cluster member to access the storage pool again.
+func (d *common) UnfenceMember(name string, addresses []string) error {
+	return ErrNotSupported
+}
+
 // CreateVolume creates a new storage volume on disk.
 func (d *common) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Operation) error {
 	return ErrNotSupported
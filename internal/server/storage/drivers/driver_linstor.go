@@ -3,9 +3,12 @@ package drivers
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"time"
 
+	linstorClient "github.com/LINBIT/golinstor/client"
+
 	"github.com/lxc/incus/v7/internal/migration"
 	deviceConfig "github.com/lxc/incus/v7/internal/server/device/config"
 	localMigration "github.com/lxc/incus/v7/internal/server/migration"
@@ -355,6 +358,66 @@ func (d *linstor) Update(changedConfig map[string]string) error {
 	return nil
 }
 
+// FenceMember evicts the LINSTOR satellite of an offline cluster member.
+// Evicted satellites aren't allowed to reconnect to the LINSTOR controller until restored, while DRBD quorum
+// prevents the member from writing to the volumes in the mean time.
+func (d *linstor) FenceMember(name string, addresses []string) error {
+	if d.config[DrbdOnNoQuorumConfigKey] == "" {
+		return fmt.Errorf("Fencing requires %q to be set on the pool", DrbdOnNoQuorumConfigKey)
+	}
+
+	linstor, err := d.state.Linstor()
+	if err != nil {
+		return err
+	}
+
+	node, err := linstor.Client.Nodes.Get(context.TODO(), name)
+	if err != nil {
+		return fmt.Errorf("Failed to get LINSTOR satellite %q: %w", name, err)
+	}
+
+	if slices.Contains(node.Flags, "EVICTED") {
+		return nil
+	}
+
+	// Only satellites which lost contact with the controller can be evicted, a member which is still
+	// connected to the storage network may still be writing to its volumes.
+	if node.ConnectionStatus == "ONLINE" {
+		return fmt.Errorf("LINSTOR satellite %q is still online", name)
+	}
+
+	err = linstor.Client.Nodes.Evict(context.TODO(), name)
+	if err != nil {
+		return fmt.Errorf("Failed to evict LINSTOR satellite %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// UnfenceMember restores the evicted LINSTOR satellite of a cluster member, keeping its resources.
+func (d *linstor) UnfenceMember(name string, addresses []string) error {
+	linstor, err := d.state.Linstor()
+	if err != nil {
+		return err
+	}
+
+	node, err := linstor.Client.Nodes.Get(context.TODO(), name)
+	if err != nil {
+		return fmt.Errorf("Failed to get LINSTOR satellite %q: %w", name, err)
+	}
+
+	if !slices.Contains(node.Flags, "EVICTED") {
+		return nil
+	}
+
+	err = linstor.Client.Nodes.Restore(context.TODO(), name, linstorClient.NodeRestore{})
+	if err != nil {
+		return fmt.Errorf("Failed to restore LINSTOR satellite %q: %w", name, err)
+	}
+
+	return nil
+}
+
 // GetResources returns utilisation and space info about the pool.
 func (d *linstor) GetResources() (*api.ResourcesStoragePool, error) {
 	freeCapacity, totalCapacity, err := d.getResourceGroupSize()
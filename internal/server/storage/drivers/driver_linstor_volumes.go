@@ -216,6 +216,46 @@ func (d *linstor) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	//  default: same as `volume.snapshot.schedule`
 	//  shortdesc: {{snapshot_schedule_format}}
 
+	// gendoc:generate(entity=storage_volume_linstor, group=common, key=replication.retention)
+	//
+	// ---
+	//  type: integer
+	//  condition: custom volume
+	//  default: `1`
+	//  shortdesc: Number of replication snapshots to keep
+
+	// gendoc:generate(entity=storage_volume_linstor, group=common, key=replication.schedule)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Schedule on which to replicate the volume (same format as `snapshots.schedule`)
+
+	// gendoc:generate(entity=storage_volume_linstor, group=common, key=replication.target)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: URL of the server and storage pool to replicate the volume to
+
+	// gendoc:generate(entity=storage_volume_linstor, group=common, key=replication.target.certificate)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Certificate of the replication target server
+
+	// gendoc:generate(entity=storage_volume_linstor, group=common, key=replication.target.token)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Trust token used to add the volume client certificate to the replication target server
+
 	commonRules := d.commonVolumeRules()
 
 	// Disallow block.* settings for regular custom block volumes. These settings only make sense
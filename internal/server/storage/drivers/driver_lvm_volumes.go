@@ -511,6 +511,46 @@ func (d *lvm) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	//  default: same as `volume.snapshot.schedule`
 	//  shortdesc: {{snapshot_schedule_format}}
 
+	// gendoc:generate(entity=storage_volume_lvm, group=common, key=replication.retention)
+	//
+	// ---
+	//  type: integer
+	//  condition: custom volume
+	//  default: `1`
+	//  shortdesc: Number of replication snapshots to keep
+
+	// gendoc:generate(entity=storage_volume_lvm, group=common, key=replication.schedule)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Schedule on which to replicate the volume (same format as `snapshots.schedule`)
+
+	// gendoc:generate(entity=storage_volume_lvm, group=common, key=replication.target)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: URL of the server and storage pool to replicate the volume to
+
+	// gendoc:generate(entity=storage_volume_lvm, group=common, key=replication.target.certificate)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Certificate of the replication target server
+
+	// gendoc:generate(entity=storage_volume_lvm, group=common, key=replication.target.token)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Trust token used to add the volume client certificate to the replication target server
+
 	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=size)
 	//
 	// ---
@@ -519,6 +559,48 @@ func (d *lvm) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	//  default: same as `volume.size`
 	//  shortdesc: Size/quota of the storage bucket
 
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=public_read)
+	//
+	// ---
+	//  type: bool
+	//  default: `false`
+	//  shortdesc: Whether all objects can be downloaded without authentication
+
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=public_read.prefixes)
+	//
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Comma-separated list of object key prefixes that can be downloaded without authentication
+
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=versioning)
+	//
+	// ---
+	//  type: bool
+	//  default: `false`
+	//  shortdesc: Whether previous versions of overwritten and deleted objects are kept
+
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=versioning.retention.versions)
+	//
+	// ---
+	//  type: integer
+	//  default: `0` (no limit)
+	//  shortdesc: Number of previous versions kept for each object
+
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=versioning.retention.days)
+	//
+	// ---
+	//  type: integer
+	//  default: `0` (no limit)
+	//  shortdesc: Number of days previous versions are kept for
+
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=lifecycle.expiry)
+	//
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Comma-separated list of `[<prefix>=]<days>` rules after which objects expire
+
 	commonRules := d.commonVolumeRules()
 
 	// Disallow block.* settings for regular custom block volumes. These settings only make sense
@@ -46,6 +46,8 @@ type Driver interface {
 	// Unmount unmounts a storage pool if needed, returns true if unmounted, false if was not mounted.
 	Unmount() (bool, error)
 	GetResources() (*api.ResourcesStoragePool, error)
+	FenceMember(name string, addresses []string) error
+	UnfenceMember(name string, addresses []string) error
 	Validate(config map[string]string) error
 	Update(changedConfig map[string]string) error
 	ApplyPatch(name string) error
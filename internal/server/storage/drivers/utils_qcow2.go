@@ -114,6 +114,36 @@ func Qcow2Commit(path string) error {
 	return nil
 }
 
+// Qcow2ApplyDelta writes the allocated clusters of a qcow2 delta image onto the target disk.
+// The target can be either a raw or qcow2 disk image or block device.
+func Qcow2ApplyDelta(deltaPath string, targetPath string) error {
+	deltaInfo, err := Qcow2Info(deltaPath)
+	if err != nil {
+		return err
+	}
+
+	if deltaInfo.Format != BlockVolumeTypeQcow2 {
+		return fmt.Errorf("Delta image %q isn't in qcow2 format", deltaPath)
+	}
+
+	targetInfo, err := Qcow2Info(targetPath)
+	if err != nil {
+		return err
+	}
+
+	if deltaInfo.VirtualSize > targetInfo.VirtualSize {
+		return fmt.Errorf("Delta image size (%d) exceeds the target disk size (%d)", deltaInfo.VirtualSize, targetInfo.VirtualSize)
+	}
+
+	// Point the delta at the target disk and commit its content into it.
+	_, err = subprocess.RunCommand("qemu-img", "rebase", "-u", "-b", targetPath, "-F", targetInfo.Format, deltaPath)
+	if err != nil {
+		return err
+	}
+
+	return Qcow2Commit(deltaPath)
+}
+
 // Qcow2Info returns information about a qcow2 image.
 func Qcow2Info(path string) (*ImageInfo, error) {
 	imgJSON, err := subprocess.RunCommand("qemu-img", "info", "-U", "--output=json", path)
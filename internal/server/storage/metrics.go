@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
+	"github.com/lxc/incus/v7/internal/server/db"
+	"github.com/lxc/incus/v7/internal/server/metrics"
+	"github.com/lxc/incus/v7/internal/server/response"
+	"github.com/lxc/incus/v7/internal/server/state"
+	"github.com/lxc/incus/v7/internal/server/storage/drivers"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/units"
+)
+
+// metricsCacheDuration is how long the metrics of a storage pool are cached for.
+// Getting the usage of every volume can be expensive and wake up idle disks, so this is kept fairly long.
+const metricsCacheDuration = 5 * time.Minute
+
+type metricsCacheEntry struct {
+	metrics    *metrics.MetricSet
+	expiry     time.Time
+	refreshing bool
+}
+
+var (
+	metricsCache     = map[string]metricsCacheEntry{}
+	metricsCacheLock sync.Mutex
+)
+
+// Metrics returns the metrics of the storage pools along with their custom volumes and buckets.
+// The metrics of each pool are cached for metricsCacheDuration.
+// In a cluster, the pools on shared storage are only reported by the leader so they aren't counted multiple times.
+func Metrics(s *state.State) *metrics.MetricSet {
+	out := metrics.NewMetricSet(nil)
+
+	var pools map[int64]api.StoragePool
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		poolState := db.StoragePoolCreated
+		pools, _, err = tx.GetStoragePools(ctx, &poolState)
+
+		return err
+	})
+	if err != nil && !response.IsNotFoundError(err) {
+		logger.Warn("Failed to get storage pools", logger.Ctx{"err": err})
+		return out
+	}
+
+	reportShared := metricsReportShared(s)
+	remoteDrivers := drivers.RemoteDriverNames()
+
+	poolNames := make([]string, 0, len(pools))
+	for _, pool := range pools {
+		if !reportShared && slices.Contains(remoteDrivers, pool.Driver) {
+			continue
+		}
+
+		poolNames = append(poolNames, pool.Name)
+	}
+
+	// Find the pools which need refreshing, skipping those already being refreshed.
+	var refresh []string
+
+	metricsCacheLock.Lock()
+
+	// Forget about the pools which were deleted or which aren't reported anymore.
+	for poolName := range metricsCache {
+		if !slices.Contains(poolNames, poolName) {
+			delete(metricsCache, poolName)
+		}
+	}
+
+	for _, poolName := range poolNames {
+		entry := metricsCache[poolName]
+		if entry.refreshing || entry.expiry.After(time.Now()) {
+			continue
+		}
+
+		entry.refreshing = true
+		metricsCache[poolName] = entry
+		refresh = append(refresh, poolName)
+	}
+
+	metricsCacheLock.Unlock()
+
+	// Gather the metrics without holding the lock as this can be slow.
+	refreshed := make(map[string]*metrics.MetricSet, len(refresh))
+	for _, poolName := range refresh {
+		refreshed[poolName] = poolMetrics(s, poolName)
+	}
+
+	metricsCacheLock.Lock()
+	defer metricsCacheLock.Unlock()
+
+	for poolName, poolSet := range refreshed {
+		metricsCache[poolName] = metricsCacheEntry{
+			metrics: poolSet,
+			expiry:  time.Now().Add(metricsCacheDuration),
+		}
+	}
+
+	// Pools being refreshed by another request are reported with their previous metrics (if any).
+	for _, poolName := range poolNames {
+		entry := metricsCache[poolName]
+		if entry.metrics != nil {
+			out.Merge(entry.metrics)
+		}
+	}
+
+	return out
+}
+
+// metricsReportShared returns whether this server reports the metrics of the storage pools shared by all
+// cluster members.
+func metricsReportShared(s *state.State) bool {
+	if !s.ServerClustered {
+		return true
+	}
+
+	leaderAddress, err := s.Cluster.LeaderAddress()
+	if err != nil {
+		return false
+	}
+
+	return leaderAddress == s.LocalConfig.ClusterAddress()
+}
+
+// poolMetrics gathers the metrics of a single storage pool.
+func poolMetrics(s *state.State, poolName string) *metrics.MetricSet {
+	out := metrics.NewMetricSet(map[string]string{"pool": poolName})
+	l := logger.AddContext(logger.Ctx{"pool": poolName})
+
+	pool, err := LoadByName(s, poolName)
+	if err != nil {
+		l.Warn("Failed to load storage pool", logger.Ctx{"err": err})
+		return out
+	}
+
+	res, err := pool.GetResources()
+	if err != nil {
+		l.Debug("Failed to get storage pool resources", logger.Ctx{"err": err})
+	} else {
+		out.AddSamples(metrics.StoragePoolSizeBytes, metrics.Sample{Value: float64(res.Space.Total)})
+		out.AddSamples(metrics.StoragePoolUsedBytes, metrics.Sample{Value: float64(res.Space.Used)})
+	}
+
+	var volumes []*db.StorageVolume
+	var buckets []*db.StorageBucket
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		volumes, err = tx.GetStoragePoolVolumes(ctx, pool.ID(), true)
+		if err != nil {
+			return err
+		}
+
+		if !pool.Driver().Info().Buckets {
+			return nil
+		}
+
+		poolID := pool.ID()
+		buckets, err = tx.GetStoragePoolBuckets(ctx, !pool.Driver().Info().Remote, db.StorageBucketFilter{PoolID: &poolID})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		l.Warn("Failed to get storage pool volumes", logger.Ctx{"err": err})
+		return out
+	}
+
+	// getQuota returns the quota set in the config or 0 if none.
+	getQuota := func(config map[string]string) int64 {
+		if config["size"] == "" {
+			return 0
+		}
+
+		size, err := units.ParseByteSizeString(config["size"])
+		if err != nil || size < 0 {
+			return 0
+		}
+
+		return size
+	}
+
+	var allocated int64
+	snapshots := map[[2]string]int{}
+
+	for _, vol := range volumes {
+		parentName, _, isSnapshot := strings.Cut(vol.Name, internalInstance.SnapshotDelimiter)
+		if isSnapshot {
+			if vol.Type == db.StoragePoolVolumeTypeNameCustom {
+				snapshots[[2]string{vol.Project, parentName}]++
+			}
+
+			continue
+		}
+
+		allocated += getQuota(vol.Config)
+	}
+
+	out.AddSamples(metrics.StoragePoolAllocatedBytes, metrics.Sample{Value: float64(allocated)})
+
+	for _, vol := range volumes {
+		if vol.Type != db.StoragePoolVolumeTypeNameCustom || internalInstance.IsSnapshot(vol.Name) {
+			continue
+		}
+
+		labels := map[string]string{"project": vol.Project, "volume": vol.Name}
+
+		out.AddSamples(metrics.StorageVolumeSnapshots, metrics.Sample{Labels: labels, Value: float64(snapshots[[2]string{vol.Project, vol.Name}])})
+
+		quota := getQuota(vol.Config)
+		if quota > 0 {
+			out.AddSamples(metrics.StorageVolumeQuotaBytes, metrics.Sample{Labels: labels, Value: float64(quota)})
+		}
+
+		usage, err := pool.GetCustomVolumeUsage(vol.Project, vol.Name)
+		if err != nil {
+			if !errors.Is(err, drivers.ErrNotSupported) {
+				l.Debug("Failed to get storage volume usage", logger.Ctx{"project": vol.Project, "volume": vol.Name, "err": err})
+			}
+
+			continue
+		}
+
+		out.AddSamples(metrics.StorageVolumeUsageBytes, metrics.Sample{Labels: labels, Value: float64(usage.Used)})
+	}
+
+	for _, bucket := range buckets {
+		labels := map[string]string{"project": bucket.Project, "bucket": bucket.Name}
+
+		quota := getQuota(bucket.Config)
+		if quota > 0 {
+			out.AddSamples(metrics.StorageBucketQuotaBytes, metrics.Sample{Labels: labels, Value: float64(quota)})
+		}
+
+		usage, err := pool.GetBucketUsage(bucket.Project, bucket.Name)
+		if err != nil {
+			if !errors.Is(err, drivers.ErrNotSupported) {
+				l.Debug("Failed to get storage bucket usage", logger.Ctx{"project": bucket.Project, "bucket": bucket.Name, "err": err})
+			}
+
+			continue
+		}
+
+		out.AddSamples(metrics.StorageBucketUsageBytes, metrics.Sample{Labels: labels, Value: float64(usage.Used)})
+	}
+
+	return out
+}
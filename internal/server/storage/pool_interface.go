@@ -115,6 +115,7 @@ type Pool interface {
 	DeleteBucketKey(projectName string, bucketName string, keyName string, op *operations.Operation) error
 	MountLocalBucket(projectName string, bucketName string, op *operations.Operation) (string, func() error, error)
 	GetBucketURL(bucketName string) *url.URL
+	GetBucketUsage(projectName string, bucketName string) (*VolumeUsage, error)
 	GenerateBucketBackupConfig(projectName string, bucketName string, op *operations.Operation) (*backupConfig.Config, error)
 	BackupBucket(projectName string, bucketName string, tarWriter *instancewriter.InstanceTarWriter, op *operations.Operation) error
 	CreateBucketFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) error
@@ -31,9 +31,20 @@ const (
 // On success, r.Body is replaced with a buffered copy if the body's hash had
 // to be computed for verification. The caller must use r.Body, not the
 // original.
+//
+// Requests carrying neither an Authorization header nor a presigned URL
+// signature are let through as anonymous if the bucket has public prefixes.
 func (s *Server) authenticate(r *http.Request) (Role, *s3.Error) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
+		if r.URL.Query().Has("X-Amz-Signature") {
+			return s.authenticatePresigned(r)
+		}
+
+		if len(s.PublicPrefixes) > 0 {
+			return roleAnonymous, nil
+		}
+
 		return "", &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Missing Authorization header."}
 	}
 
@@ -42,16 +53,7 @@ func (s *Server) authenticate(r *http.Request) (Role, *s3.Error) {
 		return "", &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Could not extract access key."}
 	}
 
-	var secret string
-	var role Role
-	for _, c := range s.creds {
-		if c.AccessKey == accessKey {
-			secret = c.SecretKey
-			role = c.Role
-			break
-		}
-	}
-
+	secret, role := s.credential(accessKey)
 	if secret == "" {
 		return "", &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Unknown access key."}
 	}
@@ -101,7 +103,7 @@ func (s *Server) authenticate(r *http.Request) (Role, *s3.Error) {
 		bodyHash = unsignedPayload
 	}
 
-	canonical := canonicalRequest(r, parsed.signedHeaders, bodyHash)
+	canonical := canonicalRequest(r, r.URL.Query(), parsed.signedHeaders, bodyHash)
 
 	stringToSign := strings.Join([]string{
 		"AWS4-HMAC-SHA256",
@@ -127,6 +129,91 @@ func (s *Server) authenticate(r *http.Request) (Role, *s3.Error) {
 	return role, nil
 }
 
+// maxPresignedExpiry is the longest validity of a presigned URL allowed by SigV4.
+const maxPresignedExpiry = 7 * 24 * time.Hour
+
+// authenticatePresigned verifies the SigV4 query string signature of a
+// presigned URL and returns the matching credential's role on success.
+//
+// The payload of presigned requests isn't signed.
+func (s *Server) authenticatePresigned(r *http.Request) (Role, *s3.Error) {
+	q := r.URL.Query()
+
+	if q.Get("X-Amz-Algorithm") != "AWS4-HMAC-SHA256" {
+		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Unsupported X-Amz-Algorithm."}
+	}
+
+	parsed := &parsedAuthorization{
+		amzDate:   q.Get("X-Amz-Date"),
+		signature: q.Get("X-Amz-Signature"),
+	}
+
+	err := parsed.parseCredential(q.Get("X-Amz-Credential"))
+	if err != nil {
+		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}
+	}
+
+	secret, role := s.credential(parsed.accessKey)
+	if secret == "" {
+		return "", &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Unknown access key."}
+	}
+
+	signedHeaders := q.Get("X-Amz-SignedHeaders")
+	if signedHeaders == "" {
+		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing X-Amz-SignedHeaders."}
+	}
+
+	parsed.signedHeaders = strings.Split(signedHeaders, ";")
+	sort.Strings(parsed.signedHeaders)
+
+	// Check the validity period of the URL.
+	date, err := time.Parse("20060102T150405Z", parsed.amzDate)
+	if err != nil || !strings.HasPrefix(parsed.amzDate, parsed.scopeDate) {
+		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid X-Amz-Date."}
+	}
+
+	expires, err := strconv.ParseInt(q.Get("X-Amz-Expires"), 10, 64)
+	if err != nil || expires < 1 || time.Duration(expires)*time.Second > maxPresignedExpiry {
+		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid X-Amz-Expires."}
+	}
+
+	now := time.Now()
+	if now.Before(date.Add(-15*time.Minute)) || now.After(date.Add(time.Duration(expires)*time.Second)) {
+		return "", &s3.Error{Code: s3.ErrorCodeAccessDenied, Message: "Request has expired."}
+	}
+
+	// The signature itself isn't part of the canonical query string.
+	q.Del("X-Amz-Signature")
+	canonical := canonicalRequest(r, q, parsed.signedHeaders, unsignedPayload)
+
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		parsed.amzDate,
+		parsed.scope,
+		sha256Hex([]byte(canonical)),
+	}, "\n")
+
+	signingKey := deriveSigningKey(secret, parsed.scopeDate, parsed.scopeRegion, parsed.scopeService)
+	expected := hmacSHA256Hex(signingKey, stringToSign)
+
+	if !hmac.Equal([]byte(expected), []byte(parsed.signature)) {
+		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Signature mismatch."}
+	}
+
+	return role, nil
+}
+
+// credential returns the secret key and role of the given access key, or an empty secret key if it's unknown.
+func (s *Server) credential(accessKey string) (string, Role) {
+	for _, c := range s.creds {
+		if c.AccessKey == accessKey {
+			return c.SecretKey, c.Role
+		}
+	}
+
+	return "", ""
+}
+
 // hasAWSChunkedEncoding returns true if the request advertises an
 // aws-chunked Content-Encoding. Several layers of clients (notably
 // aws-sdk-go-v2 with checksum middleware disabled, or callers that
@@ -247,19 +334,11 @@ func parseAuthorizationHeader(h string) (*parsedAuthorization, error) {
 
 		switch k {
 		case "Credential":
-			// <accessKey>/<date>/<region>/<service>/aws4_request
-			//
-			// Access keys may contain "/" so do a reverse split.
-			fields := strings.Split(v, "/")
-			if len(fields) < 5 {
-				return nil, fmt.Errorf("malformed Credential field")
+			err := out.parseCredential(v)
+			if err != nil {
+				return nil, err
 			}
 
-			out.accessKey = strings.Join(fields[:len(fields)-4], "/")
-			out.scopeDate = fields[len(fields)-4]
-			out.scopeRegion = fields[len(fields)-3]
-			out.scopeService = fields[len(fields)-2]
-			out.scope = strings.Join(fields[len(fields)-4:], "/")
 		case "SignedHeaders":
 			out.signedHeaders = strings.Split(v, ";")
 			sort.Strings(out.signedHeaders)
@@ -275,8 +354,31 @@ func parseAuthorizationHeader(h string) (*parsedAuthorization, error) {
 	return out, nil
 }
 
+// parseCredential parses the credential of an Authorization header or presigned URL.
+func (p *parsedAuthorization) parseCredential(v string) error {
+	// <accessKey>/<date>/<region>/<service>/aws4_request
+	//
+	// Access keys may contain "/" so do a reverse split.
+	fields := strings.Split(v, "/")
+	if len(fields) < 5 {
+		return fmt.Errorf("malformed Credential field")
+	}
+
+	p.accessKey = strings.Join(fields[:len(fields)-4], "/")
+	p.scopeDate = fields[len(fields)-4]
+	p.scopeRegion = fields[len(fields)-3]
+	p.scopeService = fields[len(fields)-2]
+	p.scope = strings.Join(fields[len(fields)-4:], "/")
+
+	if p.accessKey == "" {
+		return fmt.Errorf("malformed Credential field")
+	}
+
+	return nil
+}
+
 // canonicalRequest builds the canonical request string defined by SigV4.
-func canonicalRequest(r *http.Request, signedHeaders []string, bodyHash string) string {
+func canonicalRequest(r *http.Request, query url.Values, signedHeaders []string, bodyHash string) string {
 	var sb strings.Builder
 
 	sb.WriteString(r.Method)
@@ -285,7 +387,7 @@ func canonicalRequest(r *http.Request, signedHeaders []string, bodyHash string)
 	sb.WriteString(canonicalURI(r.URL.Path))
 	sb.WriteByte('\n')
 
-	sb.WriteString(canonicalQueryString(r.URL.Query()))
+	sb.WriteString(canonicalQueryString(query))
 	sb.WriteByte('\n')
 
 	for _, name := range signedHeaders {
@@ -434,7 +536,7 @@ func SignRequest(r *http.Request, accessKey, secretKey, region, service string,
 	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
 	sort.Strings(signed)
 
-	canonical := canonicalRequest(r, signed, bodyHash)
+	canonical := canonicalRequest(r, r.URL.Query(), signed, bodyHash)
 	scope := strings.Join([]string{scopeDate, region, service, "aws4_request"}, "/")
 	stringToSign := strings.Join([]string{
 		"AWS4-HMAC-SHA256",
@@ -453,3 +555,39 @@ func SignRequest(r *http.Request, accessKey, secretKey, region, service string,
 
 	return nil
 }
+
+// PresignRequest adds a SigV4 query string signature valid for expiry to the
+// URL of r using the given credential. It is exposed for tests and not used
+// by the server.
+func PresignRequest(r *http.Request, accessKey, secretKey, region, service string, expiry time.Duration, now time.Time) error {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	scopeDate := now.UTC().Format("20060102")
+	scope := strings.Join([]string{scopeDate, region, service, "aws4_request"}, "/")
+
+	if r.Header.Get("Host") == "" {
+		r.Host = r.URL.Host
+	}
+
+	signed := []string{"host"}
+
+	q := r.URL.Query()
+	q.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	q.Set("X-Amz-Credential", accessKey+"/"+scope)
+	q.Set("X-Amz-Date", amzDate)
+	q.Set("X-Amz-Expires", strconv.FormatInt(int64(expiry/time.Second), 10))
+	q.Set("X-Amz-SignedHeaders", strings.Join(signed, ";"))
+
+	canonical := canonicalRequest(r, q, signed, unsignedPayload)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonical)),
+	}, "\n")
+
+	key := deriveSigningKey(secretKey, scopeDate, region, service)
+	q.Set("X-Amz-Signature", hmacSHA256Hex(key, stringToSign))
+	r.URL.RawQuery = q.Encode()
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package local
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// presigned returns a presigned URL for the given request target.
+func presigned(t *testing.T, accessKey string, method string, target string, expiry time.Duration, now time.Time) *http.Request {
+	r := httptest.NewRequest(method, "http://localhost"+target, nil)
+
+	err := PresignRequest(r, accessKey, testSecretKey, "us-east-1", "s3", expiry, now)
+	require.NoError(t, err)
+
+	return httptest.NewRequest(method, r.URL.String(), nil)
+}
+
+// Test presigned URL authentication.
+func TestPresignedURL(t *testing.T) {
+	s := newTestServer(t)
+	putTestObject(t, s, "dir/obj", "hello", nil)
+
+	// Valid URL.
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, presigned(t, testReadOnlyKey, http.MethodGet, "/bucket/dir/obj", time.Hour, time.Now()))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+
+	// Expired URL.
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, presigned(t, testReadOnlyKey, http.MethodGet, "/bucket/dir/obj", time.Hour, time.Now().Add(-2*time.Hour)))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// URL valid in the future.
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, presigned(t, testReadOnlyKey, http.MethodGet, "/bucket/dir/obj", time.Hour, time.Now().Add(time.Hour)))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Validity exceeding seven days.
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, presigned(t, testReadOnlyKey, http.MethodGet, "/bucket/dir/obj", 8*24*time.Hour, time.Now()))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// URL used for another object.
+	r := presigned(t, testReadOnlyKey, http.MethodGet, "/bucket/dir/obj", time.Hour, time.Now())
+	r.URL.Path = "/bucket/dir/other"
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// URL used with another method.
+	r = presigned(t, testReadOnlyKey, http.MethodGet, "/bucket/dir/obj", time.Hour, time.Now())
+	r.Method = http.MethodDelete
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Tampered expiry.
+	r = presigned(t, testReadOnlyKey, http.MethodGet, "/bucket/dir/obj", time.Hour, time.Now())
+	q := r.URL.Query()
+	q.Set("X-Amz-Expires", "7200")
+	r.URL.RawQuery = q.Encode()
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Unknown access key.
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, presigned(t, "unknown", http.MethodGet, "/bucket/dir/obj", time.Hour, time.Now()))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Role restrictions still apply.
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, presigned(t, testReadOnlyKey, http.MethodDelete, "/bucket/dir/obj", time.Hour, time.Now()))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, presigned(t, testAccessKey, http.MethodDelete, "/bucket/dir/obj", time.Hour, time.Now()))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+// Test anonymous access to public prefixes.
+func TestPublicPrefixes(t *testing.T) {
+	s := newTestServer(t)
+	putTestObject(t, s, "public/obj", "public", nil)
+	putTestObject(t, s, "private/obj", "private", nil)
+
+	anonymous := func(method string, target string) int {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(method, "http://localhost"+target, nil))
+		return w.Code
+	}
+
+	// Private bucket.
+	assert.Equal(t, http.StatusForbidden, anonymous(http.MethodGet, "/bucket/public/obj"))
+
+	// Public prefix.
+	s.PublicPrefixes = []string{"public/"}
+	assert.Equal(t, http.StatusOK, anonymous(http.MethodGet, "/bucket/public/obj"))
+	assert.Equal(t, http.StatusOK, anonymous(http.MethodHead, "/bucket/public/obj"))
+	assert.Equal(t, http.StatusNotFound, anonymous(http.MethodGet, "/bucket/public/missing"))
+	assert.Equal(t, http.StatusForbidden, anonymous(http.MethodGet, "/bucket/private/obj"))
+	assert.Equal(t, http.StatusForbidden, anonymous(http.MethodGet, "/bucket?prefix=public/"))
+	assert.Equal(t, http.StatusForbidden, anonymous(http.MethodPut, "/bucket/public/new"))
+	assert.Equal(t, http.StatusForbidden, anonymous(http.MethodDelete, "/bucket/public/obj"))
+	assert.Equal(t, http.StatusForbidden, anonymous(http.MethodPost, "/bucket/public/obj?uploads"))
+
+	// Whole bucket.
+	s.PublicPrefixes = []string{""}
+	assert.Equal(t, http.StatusOK, anonymous(http.MethodGet, "/bucket/private/obj"))
+	assert.Equal(t, http.StatusForbidden, anonymous(http.MethodGet, "/bucket"))
+
+	// Invalid credentials aren't treated as anonymous.
+	r := httptest.NewRequest(http.MethodGet, "http://localhost/bucket/public/obj", nil)
+	err := SignRequest(r, testAccessKey, "wrong", "us-east-1", "s3", nil, time.Now())
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,327 @@
+package local
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/server/storage/s3"
+)
+
+// copySource resolves the x-amz-copy-source header of r to an object key and version ID.
+//
+// The header has the form "[/]<bucket>/<key>[?versionId=<id>]" with the key
+// URL-encoded. As the server is scoped to a single bucket, only objects from
+// the bucket the request is addressed to can be used as the source.
+func copySource(r *http.Request) (string, string, error) {
+	source, query, _ := strings.Cut(r.Header.Get("X-Amz-Copy-Source"), "?")
+
+	source, err := url.PathUnescape(source)
+	if err != nil {
+		return "", "", fmt.Errorf("Invalid copy source: %w", err)
+	}
+
+	bucketName, key, ok := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+	if !ok || key == "" {
+		return "", "", errors.New("Invalid copy source")
+	}
+
+	requestBucketName, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucketName != requestBucketName {
+		return "", "", errors.New("Copy source must be in the same bucket")
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", "", fmt.Errorf("Invalid copy source: %w", err)
+	}
+
+	return key, values.Get("versionId"), nil
+}
+
+// loadCopySource returns the data path and metadata of the source object of a copy request.
+func (s *Server) loadCopySource(r *http.Request) (string, *objectMeta, *s3.Error) {
+	srcKey, srcVersionID, err := copySource(r)
+	if err != nil {
+		return "", nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}
+	}
+
+	srcPath, err := s.objectPath(srcKey)
+	if err != nil {
+		return "", nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}
+	}
+
+	srcPath, srcMeta, err := s.loadObjectVersion(srcKey, srcPath, srcVersionID)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil, &s3.Error{Code: s3.ErrorCodeNoSuchKey, Message: "Copy source not found."}
+		}
+
+		return "", nil, &s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}
+	}
+
+	return srcPath, srcMeta, nil
+}
+
+// copyObject implements CopyObject (PUT with x-amz-copy-source).
+func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, key string) {
+	dataPath, err := s.objectPath(key)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}).Response(w)
+		return
+	}
+
+	srcPath, srcMeta, s3Err := s.loadCopySource(r)
+	if s3Err != nil {
+		s3Err.Response(w)
+		return
+	}
+
+	meta := &objectMeta{
+		ETag:      srcMeta.ETag,
+		Size:      srcMeta.Size,
+		LastMod:   time.Now().UTC(),
+		VersionID: s.newVersionID(),
+	}
+
+	directive := r.Header.Get("X-Amz-Metadata-Directive")
+	switch directive {
+	case "", "COPY":
+		meta.ContentType = srcMeta.ContentType
+		meta.UserMeta = srcMeta.UserMeta
+	case "REPLACE":
+		meta.ContentType = r.Header.Get("Content-Type")
+		meta.UserMeta = extractUserMeta(r.Header)
+	default:
+		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid metadata directive."}).Response(w)
+		return
+	}
+
+	// Copying an object onto itself is only allowed to update its metadata.
+	if srcPath == dataPath && directive != "REPLACE" {
+		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: "Copying an object to itself requires the REPLACE metadata directive."}).Response(w)
+		return
+	}
+
+	// Unless the current version is kept, updating the metadata of an object doesn't need to copy its data.
+	copied := srcPath != dataPath || s.keepsCurrentVersion(dataPath)
+	if copied {
+		tmp, err := copyToTemp(srcPath, dataPath)
+		if err != nil {
+			(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+			return
+		}
+
+		err = s.replaceObject(key, dataPath, tmp)
+		if err != nil {
+			_ = os.Remove(tmp)
+			(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+			return
+		}
+	}
+
+	err = writeMeta(metaPathFor(dataPath), meta)
+	if err != nil {
+		if copied {
+			_ = os.Remove(dataPath)
+		}
+
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	type copyObjectResult struct {
+		XMLName      xml.Name `xml:"CopyObjectResult"`
+		LastModified string   `xml:"LastModified"`
+		ETag         string   `xml:"ETag"`
+	}
+
+	resp, err := xml.Marshal(&copyObjectResult{
+		LastModified: meta.LastMod.Format("2006-01-02T15:04:05.000Z"),
+		ETag:         `"` + meta.ETag + `"`,
+	})
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	if meta.VersionID != "" {
+		w.Header().Set("X-Amz-Version-Id", meta.VersionID)
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>`))
+	_, _ = w.Write(resp)
+}
+
+// uploadPartCopy implements UploadPartCopy (PUT ?partNumber&uploadId with x-amz-copy-source).
+func (s *Server) uploadPartCopy(w http.ResponseWriter, r *http.Request, key, uploadID string) {
+	partPath, s3Err := s.uploadPartPath(r, uploadID)
+	if s3Err != nil {
+		s3Err.Response(w)
+		return
+	}
+
+	srcPath, srcMeta, s3Err := s.loadCopySource(r)
+	if s3Err != nil {
+		s3Err.Response(w)
+		return
+	}
+
+	start, end := int64(0), srcMeta.Size-1
+
+	rangeHeader := r.Header.Get("X-Amz-Copy-Source-Range")
+	if rangeHeader != "" {
+		var ok bool
+		start, end, ok = parseCopySourceRange(rangeHeader, srcMeta.Size)
+		if !ok {
+			(&s3.Error{Code: s3.ErrorCodeInvalidRange, Message: "Invalid x-amz-copy-source-range header."}).Response(w)
+			return
+		}
+	}
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	defer func() { _ = src.Close() }()
+
+	_, err = src.Seek(start, io.SeekStart)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	tmp := partPath + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	length := end - start + 1
+	hasher := md5.New()
+	written, err := io.CopyN(io.MultiWriter(f, hasher), src, length)
+	closeErr := f.Close()
+	if err == nil && written != length {
+		err = io.ErrUnexpectedEOF
+	}
+
+	if err != nil || closeErr != nil {
+		_ = os.Remove(tmp)
+		msg := err
+		if msg == nil {
+			msg = closeErr
+		}
+
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: msg.Error()}).Response(w)
+		return
+	}
+
+	err = os.Rename(tmp, partPath)
+	if err != nil {
+		_ = os.Remove(tmp)
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	type copyPartResult struct {
+		XMLName      xml.Name `xml:"CopyPartResult"`
+		LastModified string   `xml:"LastModified"`
+		ETag         string   `xml:"ETag"`
+	}
+
+	resp, err := xml.Marshal(&copyPartResult{
+		LastModified: time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+		ETag:         `"` + hex.EncodeToString(hasher.Sum(nil)) + `"`,
+	})
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>`))
+	_, _ = w.Write(resp)
+}
+
+// copyToTemp copies the file at srcPath to a temporary file next to dstPath
+// and returns its path, for the caller to move it in place.
+//
+// The data is copied between the two files directly so that the kernel can
+// use copy_file_range, which lets file systems supporting reflinks (btrfs,
+// xfs) share the extents rather than duplicating them.
+func copyToTemp(srcPath string, dstPath string) (string, error) {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = src.Close() }()
+
+	err = os.MkdirAll(filepath.Dir(dstPath), 0o700)
+	if err != nil {
+		return "", err
+	}
+
+	tmp := dstPath + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(dst, src)
+	closeErr := dst.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+
+	return tmp, nil
+}
+
+// parseCopySourceRange parses an x-amz-copy-source-range header. Unlike the
+// Range header, both offsets are required and must fall within the object.
+// Returns inclusive start and end offsets.
+func parseCopySourceRange(h string, size int64) (int64, int64, bool) {
+	rest, ok := strings.CutPrefix(h, "bytes=")
+	if !ok {
+		return 0, 0, false
+	}
+
+	startStr, endStr, ok := strings.Cut(rest, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false
+	}
+
+	end, err := strconv.ParseInt(endStr, 10, 64)
+	if err != nil || end < start || end >= size {
+		return 0, 0, false
+	}
+
+	return start, end, true
+}
@@ -21,6 +21,7 @@ type objectMeta struct {
 	Size        int64             `json:"size"`
 	LastMod     time.Time         `json:"last_modified"`
 	UserMeta    map[string]string `json:"user_meta,omitempty"`
+	VersionID   string            `json:"version_id,omitempty"`
 }
 
 func readMeta(metaPath string) (*objectMeta, error) {
@@ -38,7 +39,7 @@ func readMeta(metaPath string) (*objectMeta, error) {
 	return m, nil
 }
 
-func writeMeta(metaPath string, m *objectMeta) error {
+func writeMeta(metaPath string, m any) error {
 	b, err := json.Marshal(m)
 	if err != nil {
 		return err
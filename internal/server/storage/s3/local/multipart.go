@@ -81,27 +81,34 @@ func (s *Server) initiateMultipartUpload(w http.ResponseWriter, r *http.Request,
 	_, _ = w.Write(resp)
 }
 
-func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, key, uploadID string) {
+// uploadPartPath returns the path of the part targeted by an UploadPart or UploadPartCopy request.
+func (s *Server) uploadPartPath(r *http.Request, uploadID string) (string, *s3.Error) {
 	dir := s.uploadDir(uploadID)
 	_, err := os.Stat(dir)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			(&s3.Error{Code: s3.ErrorInvalidRequest, Message: "Upload not found."}).Response(w)
-			return
+			return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Upload not found."}
 		}
 
-		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
-		return
+		return "", &s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}
 	}
 
 	partStr := r.URL.Query().Get("partNumber")
 	partNumber, err := strconv.Atoi(partStr)
 	if err != nil || partNumber < 1 || partNumber > 10000 {
-		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid partNumber."}).Response(w)
+		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid partNumber."}
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("part-%05d", partNumber)), nil
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, key, uploadID string) {
+	partPath, s3Err := s.uploadPartPath(r, uploadID)
+	if s3Err != nil {
+		s3Err.Response(w)
 		return
 	}
 
-	partPath := filepath.Join(dir, fmt.Sprintf("part-%05d", partNumber))
 	tmp := partPath + ".tmp"
 
 	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
@@ -234,7 +241,7 @@ func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	err = os.Rename(tmp, dataPath)
+	err = s.replaceObject(key, dataPath, tmp)
 	if err != nil {
 		_ = os.Remove(tmp)
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
@@ -248,6 +255,7 @@ func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request,
 		Size:        size,
 		LastMod:     time.Now().UTC(),
 		UserMeta:    info.UserMeta,
+		VersionID:   s.newVersionID(),
 	}
 
 	err = writeMeta(metaPathFor(dataPath), meta)
@@ -272,6 +280,10 @@ func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	if meta.VersionID != "" {
+		w.Header().Set("X-Amz-Version-Id", meta.VersionID)
+	}
+
 	w.Header().Set("Content-Type", "application/xml")
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>`))
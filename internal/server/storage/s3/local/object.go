@@ -3,6 +3,7 @@ package local
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
@@ -29,7 +30,7 @@ func (s *Server) objectPath(key string) (string, error) {
 	}
 
 	first, _, _ := strings.Cut(key, "/")
-	if first == uploadsSubdir || strings.HasSuffix(key, metaSuffix) {
+	if first == uploadsSubdir || first == versionsSubdir || strings.HasSuffix(key, metaSuffix) {
 		return "", errors.New("Reserved object key")
 	}
 
@@ -43,10 +44,10 @@ func (s *Server) headObject(w http.ResponseWriter, r *http.Request, key string)
 		return
 	}
 
-	meta, err := loadOrInferMeta(dataPath)
+	dataPath, meta, err := s.loadObjectVersion(key, dataPath, r.URL.Query().Get("versionId"))
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			(&s3.Error{Code: s3.ErrorCodeNoSuchBucket, Message: "Object not found."}).Response(w)
+			(&s3.Error{Code: s3.ErrorCodeNoSuchKey, Message: "Object not found."}).Response(w)
 			return
 		}
 
@@ -65,10 +66,10 @@ func (s *Server) getObject(w http.ResponseWriter, r *http.Request, key string) {
 		return
 	}
 
-	meta, err := loadOrInferMeta(dataPath)
+	dataPath, meta, err := s.loadObjectVersion(key, dataPath, r.URL.Query().Get("versionId"))
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			(&s3.Error{Code: s3.ErrorCodeNoSuchBucket, Message: "Object not found."}).Response(w)
+			(&s3.Error{Code: s3.ErrorCodeNoSuchKey, Message: "Object not found."}).Response(w)
 			return
 		}
 
@@ -95,7 +96,7 @@ func (s *Server) getObject(w http.ResponseWriter, r *http.Request, key string) {
 	start, end, ok := parseSingleRange(rangeHeader, meta.Size)
 	if !ok {
 		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", meta.Size))
-		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid Range header."}).Response(w)
+		(&s3.Error{Code: s3.ErrorCodeInvalidRange, Message: "The requested range is not satisfiable."}).Response(w)
 		return
 	}
 
@@ -147,7 +148,7 @@ func (s *Server) putObject(w http.ResponseWriter, r *http.Request, key string) {
 		return
 	}
 
-	err = os.Rename(tmp, dataPath)
+	err = s.replaceObject(key, dataPath, tmp)
 	if err != nil {
 		_ = os.Remove(tmp)
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
@@ -162,6 +163,7 @@ func (s *Server) putObject(w http.ResponseWriter, r *http.Request, key string) {
 		Size:        written,
 		LastMod:     time.Now().UTC(),
 		UserMeta:    extractUserMeta(r.Header),
+		VersionID:   s.newVersionID(),
 	}
 
 	err = writeMeta(metaPathFor(dataPath), meta)
@@ -171,30 +173,131 @@ func (s *Server) putObject(w http.ResponseWriter, r *http.Request, key string) {
 		return
 	}
 
+	if meta.VersionID != "" {
+		w.Header().Set("X-Amz-Version-Id", meta.VersionID)
+	}
+
 	w.Header().Set("ETag", `"`+etag+`"`)
 	w.WriteHeader(http.StatusOK)
 }
 
-func (s *Server) deleteObject(w http.ResponseWriter, key string) {
+func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request, key string) {
 	dataPath, err := s.objectPath(key)
 	if err != nil {
 		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}).Response(w)
 		return
 	}
 
-	err = os.Remove(dataPath)
-	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+	versionID := r.URL.Query().Get("versionId")
+	err = s.deleteObjectVersion(key, dataPath, versionID)
+	if err != nil {
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
 		return
 	}
 
-	err = removeMeta(metaPathFor(dataPath))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// maxDeleteRequestSize is the largest DeleteObjects body accepted, enough for 1000 keys of the maximum length.
+const maxDeleteRequestSize = 2 * 1024 * 1024
+
+// deleteRequest models the body of DeleteObjects.
+type deleteRequest struct {
+	XMLName xml.Name              `xml:"Delete"`
+	Quiet   bool                  `xml:"Quiet"`
+	Objects []deleteRequestObject `xml:"Object"`
+}
+
+type deleteRequestObject struct {
+	Key       string `xml:"Key"`
+	VersionID string `xml:"VersionId"`
+}
+
+// deleteObjects implements DeleteObjects (POST ?delete on the bucket).
+func (s *Server) deleteObjects(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxDeleteRequestSize))
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			(&s3.Error{Code: s3.ErrorInvalidRequest, Message: "The request body is too large."}).Response(w)
+			return
+		}
+
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	req := &deleteRequest{}
+	err = xml.Unmarshal(body, req)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}).Response(w)
+		return
+	}
+
+	if len(req.Objects) > 1000 {
+		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: "At most 1000 objects can be deleted per request."}).Response(w)
+		return
+	}
+
+	type deleted struct {
+		Key       string `xml:"Key"`
+		VersionID string `xml:"VersionId,omitempty"`
+	}
+
+	type deleteError struct {
+		Key       string `xml:"Key"`
+		VersionID string `xml:"VersionId,omitempty"`
+		Code      string `xml:"Code"`
+		Message   string `xml:"Message"`
+	}
+
+	type result struct {
+		XMLName xml.Name      `xml:"DeleteResult"`
+		Deleted []deleted     `xml:"Deleted"`
+		Errors  []deleteError `xml:"Error"`
+	}
+
+	out := &result{}
+
+	for _, obj := range req.Objects {
+		dataPath, err := s.objectPath(obj.Key)
+		if err != nil {
+			out.Errors = append(out.Errors, deleteError{Key: obj.Key, VersionID: obj.VersionID, Code: s3.ErrorInvalidRequest, Message: err.Error()})
+			continue
+		}
+
+		err = s.deleteObjectVersion(obj.Key, dataPath, obj.VersionID)
+		if err != nil {
+			out.Errors = append(out.Errors, deleteError{Key: obj.Key, VersionID: obj.VersionID, Code: s3.ErrorCodeInternalError, Message: err.Error()})
+			continue
+		}
+
+		// Quiet mode only reports failures.
+		if !req.Quiet {
+			out.Deleted = append(out.Deleted, deleted{Key: obj.Key, VersionID: obj.VersionID})
+		}
+	}
+
+	resp, err := xml.Marshal(out)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>`))
+	_, _ = w.Write(resp)
+}
+
+// removeObject removes the object data and its metadata. Removing a missing object isn't an error.
+func removeObject(dataPath string) error {
+	err := os.Remove(dataPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return removeMeta(metaPathFor(dataPath))
 }
 
 func writeObjectHeaders(w http.ResponseWriter, meta *objectMeta) {
@@ -202,6 +305,11 @@ func writeObjectHeaders(w http.ResponseWriter, meta *objectMeta) {
 		w.Header().Set("Content-Type", meta.ContentType)
 	}
 
+	if meta.VersionID != "" {
+		w.Header().Set("X-Amz-Version-Id", meta.VersionID)
+	}
+
+	w.Header().Set("Accept-Ranges", "bytes")
 	w.Header().Set("ETag", `"`+meta.ETag+`"`)
 	w.Header().Set("Content-Length", strconv.FormatInt(meta.Size, 10))
 	w.Header().Set("Last-Modified", meta.LastMod.UTC().Format(http.TimeFormat))
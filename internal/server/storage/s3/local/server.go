@@ -9,6 +9,12 @@
 //	data/<key>           object data
 //	data/<key>.meta      object metadata (JSON)
 //	data/.uploads/<id>/  in-flight multipart upload state
+//	data/.versions/<h>/  noncurrent versions of the object whose key hashes to <h>
+//
+// Supported operations are the subset of S3 used by common backup and data
+// tools: object GET (including Range requests), HEAD, PUT, DELETE and
+// server-side copy, DeleteObjects, ListObjectsV2, ListObjectVersions and
+// multipart uploads (including UploadPartCopy).
 package local
 
 import (
@@ -21,8 +27,9 @@ import (
 )
 
 const (
-	dataSubdir    = "data"
-	uploadsSubdir = ".uploads"
+	dataSubdir     = "data"
+	uploadsSubdir  = ".uploads"
+	versionsSubdir = ".versions"
 )
 
 // Role describes what operations a Credential is permitted to perform.
@@ -34,6 +41,9 @@ const (
 
 	// RoleReadOnly allows only read operations (GET/HEAD on objects and bucket listing).
 	RoleReadOnly Role = "read-only"
+
+	// roleAnonymous is used for unauthenticated requests, which may only read public objects.
+	roleAnonymous Role = "anonymous"
 )
 
 // Credential is an S3 access-key / secret-key pair authorised against the bucket.
@@ -48,6 +58,18 @@ type Server struct {
 	bucketDir string
 	creds     []Credential
 
+	// PublicPrefixes lists the object key prefixes which can be read
+	// (GET/HEAD) without authentication. An empty prefix makes all objects
+	// public. Listing the bucket always requires authentication.
+	PublicPrefixes []string
+
+	// Versioning configures whether and for how long previous versions of
+	// objects are kept.
+	Versioning Versioning
+
+	// Lifecycle lists the rules applied by ApplyLifecycle to expire objects.
+	Lifecycle []LifecycleRule
+
 	// OnAuthenticated, if set, is invoked once the request has been
 	// authenticated and authorised, before any data on disk is touched.
 	// Errors are returned to the client as an internal-error response and
@@ -78,6 +100,10 @@ func (s *Server) uploadsDir() string {
 	return filepath.Join(s.dataDir(), uploadsSubdir)
 }
 
+func (s *Server) versionsDir() string {
+	return filepath.Join(s.dataDir(), versionsSubdir)
+}
+
 // ServeHTTP implements http.Handler.
 //
 // The bucket name component of the URL is ignored: this handler is scoped to
@@ -99,7 +125,15 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		objectKey = ""
 	}
 
-	if !methodAllowedForRole(r.Method, role, objectKey, r.URL.Query()) {
+	if role == roleAnonymous {
+		if !publicReadAllowed(r.Method, objectKey, r.URL.Query(), s.PublicPrefixes) {
+			(&s3.Error{
+				Code:    s3.ErrorCodeAccessDenied,
+				Message: "Anonymous access is not allowed.",
+			}).Response(w)
+			return
+		}
+	} else if !methodAllowedForRole(r.Method, role, objectKey, r.URL.Query()) {
 		(&s3.Error{
 			Code:    s3.ErrorInvalidRequest,
 			Message: "Operation not permitted by credential role.",
@@ -153,6 +187,32 @@ func methodAllowedForRole(method string, role Role, objectKey string, q url.Valu
 	return false
 }
 
+// publicReadAllowed returns whether an anonymous request can be served.
+// Only plain GET and HEAD requests on the current version of objects under
+// one of the public prefixes are allowed.
+func publicReadAllowed(method string, objectKey string, q url.Values, prefixes []string) bool {
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+
+	if objectKey == "" {
+		return false
+	}
+
+	// Multipart sub-resources and object versions.
+	if q.Has("uploads") || q.Has("uploadId") || q.Has("versionId") {
+		return false
+	}
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(objectKey, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s *Server) handleBucket(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -163,10 +223,25 @@ func (s *Server) handleBucket(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		_, ok = r.URL.Query()["versions"]
+		if ok {
+			s.listObjectVersions(w, r)
+			return
+		}
+
 		s.listObjects(w, r)
 	case http.MethodHead:
 		// Bucket exist if we made it this far.
 		w.WriteHeader(http.StatusOK)
+	case http.MethodPost:
+		// DeleteObjects is the only bucket-level write.
+		_, ok := r.URL.Query()["delete"]
+		if ok {
+			s.deleteObjects(w, r)
+			return
+		}
+
+		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: "Unsupported method."}).Response(w)
 	default:
 		// We don't allow bucket creation/deletion.
 		(&s3.Error{
@@ -188,6 +263,11 @@ func (s *Server) handleObject(w http.ResponseWriter, r *http.Request, objectKey
 	if uploadID != "" {
 		switch r.Method {
 		case http.MethodPut:
+			if r.Header.Get("X-Amz-Copy-Source") != "" {
+				s.uploadPartCopy(w, r, objectKey, uploadID)
+				return
+			}
+
 			s.uploadPart(w, r, objectKey, uploadID)
 		case http.MethodPost:
 			s.completeMultipartUpload(w, r, objectKey, uploadID)
@@ -206,9 +286,14 @@ func (s *Server) handleObject(w http.ResponseWriter, r *http.Request, objectKey
 	case http.MethodHead:
 		s.headObject(w, r, objectKey)
 	case http.MethodPut:
+		if r.Header.Get("X-Amz-Copy-Source") != "" {
+			s.copyObject(w, r, objectKey)
+			return
+		}
+
 		s.putObject(w, r, objectKey)
 	case http.MethodDelete:
-		s.deleteObject(w, objectKey)
+		s.deleteObject(w, r, objectKey)
 	default:
 		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: "Unsupported method."}).Response(w)
 	}
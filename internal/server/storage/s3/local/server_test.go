@@ -0,0 +1,272 @@
+package local
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAccessKey   = "admin"
+	testSecretKey   = "secret"
+	testReadOnlyKey = "reader"
+)
+
+func newTestServer(t *testing.T) *Server {
+	return NewServer(t.TempDir(), []Credential{
+		{AccessKey: testAccessKey, SecretKey: testSecretKey, Role: RoleAdmin},
+		{AccessKey: testReadOnlyKey, SecretKey: testSecretKey, Role: RoleReadOnly},
+	})
+}
+
+// do sends a request signed with the given access key to the server.
+func do(t *testing.T, s *Server, accessKey string, method string, target string, headers map[string]string, body []byte) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "http://localhost"+target, nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+
+	err := SignRequest(r, accessKey, testSecretKey, "us-east-1", "s3", body, time.Now())
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	return w
+}
+
+func putTestObject(t *testing.T, s *Server, key string, content string, headers map[string]string) {
+	w := do(t, s, testAccessKey, http.MethodPut, "/bucket/"+key, headers, []byte(content))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func getTestObject(t *testing.T, s *Server, key string) *httptest.ResponseRecorder {
+	return do(t, s, testAccessKey, http.MethodGet, "/bucket/"+key, nil, nil)
+}
+
+// Test Range requests on objects.
+func TestGetObjectRange(t *testing.T) {
+	s := newTestServer(t)
+	putTestObject(t, s, "obj", "0123456789", nil)
+
+	tests := []struct {
+		rangeHeader  string
+		status       int
+		body         string
+		contentRange string
+	}{
+		{"bytes=0-3", http.StatusPartialContent, "0123", "bytes 0-3/10"},
+		{"bytes=5-", http.StatusPartialContent, "56789", "bytes 5-9/10"},
+		{"bytes=-3", http.StatusPartialContent, "789", "bytes 7-9/10"},
+		{"bytes=8-100", http.StatusPartialContent, "89", "bytes 8-9/10"},
+		{"bytes=10-", http.StatusRequestedRangeNotSatisfiable, "", "bytes */10"},
+		{"bytes=5-2", http.StatusRequestedRangeNotSatisfiable, "", "bytes */10"},
+		{"bytes=0-1,4-5", http.StatusRequestedRangeNotSatisfiable, "", "bytes */10"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.rangeHeader, func(t *testing.T) {
+			w := do(t, s, testAccessKey, http.MethodGet, "/bucket/obj", map[string]string{"Range": test.rangeHeader}, nil)
+			assert.Equal(t, test.status, w.Code)
+			assert.Equal(t, test.contentRange, w.Header().Get("Content-Range"))
+			if test.status == http.StatusPartialContent {
+				assert.Equal(t, test.body, w.Body.String())
+			}
+		})
+	}
+
+	w := do(t, s, testAccessKey, http.MethodHead, "/bucket/obj", nil, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+}
+
+// Test server-side copy of objects.
+func TestCopyObject(t *testing.T) {
+	s := newTestServer(t)
+	putTestObject(t, s, "src/obj", "hello world", map[string]string{"Content-Type": "text/plain", "X-Amz-Meta-Color": "blue"})
+
+	// Copy with the default (COPY) metadata directive.
+	w := do(t, s, testAccessKey, http.MethodPut, "/bucket/dst/obj", map[string]string{"X-Amz-Copy-Source": "/bucket/src%2Fobj"}, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	result := struct {
+		XMLName xml.Name `xml:"CopyObjectResult"`
+		ETag    string   `xml:"ETag"`
+	}{}
+
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, `"5eb63bbbe01eeed093cb22bb8f5acdc3"`, result.ETag)
+
+	w = getTestObject(t, s, "dst/obj")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello world", w.Body.String())
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "blue", w.Header().Get("X-Amz-Meta-Color"))
+
+	// Copy with the REPLACE metadata directive.
+	w = do(t, s, testAccessKey, http.MethodPut, "/bucket/dst/replaced", map[string]string{
+		"X-Amz-Copy-Source":        "bucket/src/obj",
+		"X-Amz-Metadata-Directive": "REPLACE",
+		"Content-Type":             "application/octet-stream",
+		"X-Amz-Meta-Shape":         "square",
+	}, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = getTestObject(t, s, "dst/replaced")
+	assert.Equal(t, "hello world", w.Body.String())
+	assert.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "", w.Header().Get("X-Amz-Meta-Color"))
+	assert.Equal(t, "square", w.Header().Get("X-Amz-Meta-Shape"))
+
+	// Copying an object onto itself only works when replacing its metadata.
+	w = do(t, s, testAccessKey, http.MethodPut, "/bucket/src/obj", map[string]string{"X-Amz-Copy-Source": "/bucket/src/obj"}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(t, s, testAccessKey, http.MethodPut, "/bucket/src/obj", map[string]string{
+		"X-Amz-Copy-Source":        "/bucket/src/obj",
+		"X-Amz-Metadata-Directive": "REPLACE",
+		"X-Amz-Meta-Color":         "red",
+	}, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = getTestObject(t, s, "src/obj")
+	assert.Equal(t, "hello world", w.Body.String())
+	assert.Equal(t, "red", w.Header().Get("X-Amz-Meta-Color"))
+
+	// Invalid sources.
+	w = do(t, s, testAccessKey, http.MethodPut, "/bucket/dst/obj", map[string]string{"X-Amz-Copy-Source": "/bucket/missing"}, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(t, s, testAccessKey, http.MethodPut, "/bucket/dst/obj", map[string]string{"X-Amz-Copy-Source": "/other/src/obj"}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(t, s, testAccessKey, http.MethodPut, "/bucket/dst/obj", map[string]string{"X-Amz-Copy-Source": "/bucket/src/../../obj"}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Read-only credentials can't copy.
+	w = do(t, s, testReadOnlyKey, http.MethodPut, "/bucket/dst/other", map[string]string{"X-Amz-Copy-Source": "/bucket/src/obj"}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = getTestObject(t, s, "dst/other")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// Test multipart uploads using UploadPartCopy.
+func TestUploadPartCopy(t *testing.T) {
+	s := newTestServer(t)
+	putTestObject(t, s, "src", "0123456789", nil)
+
+	w := do(t, s, testAccessKey, http.MethodPost, "/bucket/dst?uploads", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	initiate := struct {
+		UploadID string `xml:"UploadId"`
+	}{}
+
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &initiate))
+
+	w = do(t, s, testAccessKey, http.MethodPut, "/bucket/dst?partNumber=1&uploadId="+initiate.UploadID, map[string]string{
+		"X-Amz-Copy-Source":       "/bucket/src",
+		"X-Amz-Copy-Source-Range": "bytes=5-9",
+	}, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	part := struct {
+		XMLName xml.Name `xml:"CopyPartResult"`
+		ETag    string   `xml:"ETag"`
+	}{}
+
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &part))
+	assert.Equal(t, `"099ebea48ea9666a7da2177267983138"`, part.ETag)
+
+	w = do(t, s, testAccessKey, http.MethodPut, "/bucket/dst?partNumber=2&uploadId="+initiate.UploadID, map[string]string{"X-Amz-Copy-Source": "/bucket/src"}, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	for _, rangeHeader := range []string{"bytes=5-10", "bytes=5-", "bytes=-5", "5-9"} {
+		w = do(t, s, testAccessKey, http.MethodPut, "/bucket/dst?partNumber=3&uploadId="+initiate.UploadID, map[string]string{
+			"X-Amz-Copy-Source":       "/bucket/src",
+			"X-Amz-Copy-Source-Range": rangeHeader,
+		}, nil)
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code, rangeHeader)
+	}
+
+	complete := []byte(`<CompleteMultipartUpload><Part><PartNumber>1</PartNumber></Part><Part><PartNumber>2</PartNumber></Part></CompleteMultipartUpload>`)
+	w = do(t, s, testAccessKey, http.MethodPost, "/bucket/dst?uploadId="+initiate.UploadID, nil, complete)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = getTestObject(t, s, "dst")
+	assert.Equal(t, "567890123456789", w.Body.String())
+}
+
+// Test multi-object deletion.
+func TestDeleteObjects(t *testing.T) {
+	s := newTestServer(t)
+	for _, key := range []string{"a", "b", "dir/c", "d"} {
+		putTestObject(t, s, key, key, nil)
+	}
+
+	body := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Delete xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Object><Key>a</Key></Object>
+  <Object><Key>dir/c</Key></Object>
+  <Object><Key>missing</Key></Object>
+  <Object><Key>../escape</Key></Object>
+</Delete>`)
+
+	// Read-only credentials can't delete.
+	w := do(t, s, testReadOnlyKey, http.MethodPost, "/bucket?delete", nil, body)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(t, s, testAccessKey, http.MethodPost, "/bucket?delete", nil, body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	result := struct {
+		Deleted []struct {
+			Key string `xml:"Key"`
+		} `xml:"Deleted"`
+		Errors []struct {
+			Key  string `xml:"Key"`
+			Code string `xml:"Code"`
+		} `xml:"Error"`
+	}{}
+
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &result))
+	require.Len(t, result.Deleted, 3)
+	assert.Equal(t, "a", result.Deleted[0].Key)
+	assert.Equal(t, "dir/c", result.Deleted[1].Key)
+	assert.Equal(t, "missing", result.Deleted[2].Key)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "../escape", result.Errors[0].Key)
+
+	for key, status := range map[string]int{"a": http.StatusNotFound, "b": http.StatusOK, "dir/c": http.StatusNotFound, "d": http.StatusOK} {
+		w = getTestObject(t, s, key)
+		assert.Equal(t, status, w.Code, key)
+	}
+
+	// Quiet mode only reports errors.
+	w = do(t, s, testAccessKey, http.MethodPost, "/bucket?delete", nil, []byte(`<Delete><Quiet>true</Quiet><Object><Key>b</Key></Object></Delete>`))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	b, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(b, []byte("<Deleted>")))
+
+	w = getTestObject(t, s, "b")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Oversized requests are refused without being processed.
+	large := bytes.Repeat([]byte(`<Object><Key>d</Key></Object>`), maxDeleteRequestSize/20)
+	w = do(t, s, testAccessKey, http.MethodPost, "/bucket?delete", nil, append(append([]byte(`<Delete>`), large...), []byte(`</Delete>`)...))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "too large")
+
+	w = getTestObject(t, s, "d")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
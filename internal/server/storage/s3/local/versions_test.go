@@ -0,0 +1,201 @@
+package local
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testVersion struct {
+	Key       string `xml:"Key"`
+	VersionID string `xml:"VersionId"`
+	IsLatest  bool   `xml:"IsLatest"`
+}
+
+// listTestVersions returns the versions of the objects starting with prefix.
+func listTestVersions(t *testing.T, s *Server, prefix string) []testVersion {
+	w := do(t, s, testAccessKey, http.MethodGet, "/bucket?versions&prefix="+prefix, nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result struct {
+		Versions []testVersion `xml:"Version"`
+	}
+
+	err := xml.Unmarshal(w.Body.Bytes(), &result)
+	require.NoError(t, err)
+
+	return result.Versions
+}
+
+// Test that overwritten and deleted objects are kept as noncurrent versions.
+func TestVersioning(t *testing.T) {
+	s := newTestServer(t)
+
+	// Objects written before versioning is enabled get the null version.
+	putTestObject(t, s, "obj", "v0", nil)
+
+	s.Versioning.Enabled = true
+	putTestObject(t, s, "obj", "v1", nil)
+
+	w := do(t, s, testAccessKey, http.MethodPut, "/bucket/obj", nil, []byte("v2"))
+	require.Equal(t, http.StatusOK, w.Code)
+	v2 := w.Header().Get("X-Amz-Version-Id")
+	assert.NotEmpty(t, v2)
+
+	w = getTestObject(t, s, "obj")
+	assert.Equal(t, "v2", w.Body.String())
+	assert.Equal(t, v2, w.Header().Get("X-Amz-Version-Id"))
+
+	versions := listTestVersions(t, s, "obj")
+	require.Len(t, versions, 3)
+	assert.Equal(t, testVersion{Key: "obj", VersionID: v2, IsLatest: true}, versions[0])
+	assert.Equal(t, "null", versions[2].VersionID)
+	v1 := versions[1].VersionID
+
+	// Get previous versions.
+	w = do(t, s, testAccessKey, http.MethodGet, "/bucket/obj?versionId="+v1, nil, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "v1", w.Body.String())
+
+	w = do(t, s, testAccessKey, http.MethodGet, "/bucket/obj?versionId=null", nil, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "v0", w.Body.String())
+
+	w = do(t, s, testAccessKey, http.MethodGet, "/bucket/obj?versionId=../../obj", nil, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Restore a previous version.
+	w = do(t, s, testAccessKey, http.MethodPut, "/bucket/obj", map[string]string{"X-Amz-Copy-Source": "/bucket/obj?versionId=" + v1}, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "v1", getTestObject(t, s, "obj").Body.String())
+	assert.Len(t, listTestVersions(t, s, "obj"), 4)
+
+	// Deleting the object keeps it as a noncurrent version.
+	w = do(t, s, testAccessKey, http.MethodDelete, "/bucket/obj", nil, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, http.StatusNotFound, getTestObject(t, s, "obj").Code)
+
+	versions = listTestVersions(t, s, "obj")
+	require.Len(t, versions, 4)
+	for _, v := range versions {
+		assert.False(t, v.IsLatest)
+	}
+
+	// Deleting a version removes it permanently.
+	w = do(t, s, testAccessKey, http.MethodDelete, "/bucket/obj?versionId="+v1, nil, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Len(t, listTestVersions(t, s, "obj"), 3)
+
+	w = do(t, s, testAccessKey, http.MethodGet, "/bucket/obj?versionId="+v1, nil, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Noncurrent versions aren't listed as objects.
+	w = do(t, s, testAccessKey, http.MethodGet, "/bucket?list-type=2", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "<Key>")
+}
+
+// Test the retention of noncurrent versions.
+func TestVersioningRetention(t *testing.T) {
+	s := newTestServer(t)
+	s.Versioning = Versioning{Enabled: true, KeepVersions: 2}
+
+	for _, content := range []string{"v1", "v2", "v3", "v4"} {
+		putTestObject(t, s, "obj", content, nil)
+	}
+
+	versions := listTestVersions(t, s, "obj")
+	require.Len(t, versions, 3)
+
+	w := do(t, s, testAccessKey, http.MethodGet, "/bucket/obj?versionId="+versions[2].VersionID, nil, nil)
+	assert.Equal(t, "v2", w.Body.String())
+
+	// Versions beyond the count are only removed once old enough.
+	s.Versioning.KeepVersions = 1
+	s.Versioning.KeepFor = time.Hour
+
+	err := s.ApplyLifecycle(time.Now())
+	require.NoError(t, err)
+	assert.Len(t, listTestVersions(t, s, "obj"), 3)
+
+	err = s.ApplyLifecycle(time.Now().Add(2 * time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, listTestVersions(t, s, "obj"), 2)
+}
+
+// Test the expiration of objects by lifecycle rules.
+func TestApplyLifecycle(t *testing.T) {
+	s := newTestServer(t)
+	s.Lifecycle = []LifecycleRule{{Prefix: "logs/", Age: 24 * time.Hour}}
+
+	putTestObject(t, s, "logs/a", "a", nil)
+	putTestObject(t, s, "data/b", "b", nil)
+
+	err := s.ApplyLifecycle(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, getTestObject(t, s, "logs/a").Code)
+
+	err = s.ApplyLifecycle(time.Now().Add(48 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, getTestObject(t, s, "logs/a").Code)
+	assert.Equal(t, http.StatusOK, getTestObject(t, s, "data/b").Code)
+
+	// Expired objects are kept as noncurrent versions.
+	s.Versioning.Enabled = true
+	putTestObject(t, s, "logs/c", "c", nil)
+
+	err = s.ApplyLifecycle(time.Now().Add(48 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, getTestObject(t, s, "logs/c").Code)
+
+	versions := listTestVersions(t, s, "logs/")
+	require.Len(t, versions, 1)
+	assert.Equal(t, "logs/c", versions[0].Key)
+}
+
+// Test that anonymous reads only ever get the current version of public objects.
+func TestVersioningPublicRead(t *testing.T) {
+	s := newTestServer(t)
+	s.Versioning.Enabled = true
+	s.PublicPrefixes = []string{"public/"}
+
+	putTestObject(t, s, "public/obj", "secret", nil)
+	putTestObject(t, s, "public/obj", "redacted", nil)
+
+	versions := listTestVersions(t, s, "public/obj")
+	require.Len(t, versions, 2)
+	previous := versions[1].VersionID
+
+	anonymous := func(method string, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(method, "http://localhost"+target, nil))
+		return w
+	}
+
+	w := anonymous(http.MethodGet, "/bucket/public/obj")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "redacted", w.Body.String())
+
+	for _, target := range []string{"/bucket/public/obj?versionId=" + previous, "/bucket/public/obj?versionId=" + versions[0].VersionID, "/bucket/public/obj?versionId=", "/bucket?versions&prefix=public/"} {
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			w = anonymous(method, target)
+			assert.Equal(t, http.StatusForbidden, w.Code, method+" "+target)
+			assert.NotContains(t, w.Body.String(), "secret", method+" "+target)
+		}
+	}
+
+	// Deleted public objects can't be read anonymously anymore.
+	w = do(t, s, testAccessKey, http.MethodDelete, "/bucket/public/obj", nil, nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = anonymous(http.MethodGet, "/bucket/public/obj")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = anonymous(http.MethodGet, "/bucket/public/obj?versionId="+previous)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -9,19 +9,31 @@ import (
 // ErrorCodeNoSuchBucket means the specified bucket does not exist.
 const ErrorCodeNoSuchBucket = "NoSuchBucket"
 
+// ErrorCodeNoSuchKey means the specified object does not exist.
+const ErrorCodeNoSuchKey = "NoSuchKey"
+
+// ErrorCodeInvalidRange means the requested range cannot be satisfied.
+const ErrorCodeInvalidRange = "InvalidRange"
+
 // ErrorCodeInternalError means there was an internal error.
 const ErrorCodeInternalError = "InternalError"
 
 // ErrorCodeInvalidAccessKeyID means there was an invalid access key provided.
 const ErrorCodeInvalidAccessKeyID = "InvalidAccessKeyId"
 
+// ErrorCodeAccessDenied means the request isn't allowed.
+const ErrorCodeAccessDenied = "AccessDenied"
+
 // ErrorInvalidRequest means there was an invalid request.
 const ErrorInvalidRequest = "InvalidRequest"
 
 var errorHTTPStatusCodes = map[string]int{
 	ErrorCodeNoSuchBucket:       http.StatusNotFound,
+	ErrorCodeNoSuchKey:          http.StatusNotFound,
+	ErrorCodeInvalidRange:       http.StatusRequestedRangeNotSatisfiable,
 	ErrorCodeInternalError:      http.StatusInternalServerError,
 	ErrorCodeInvalidAccessKeyID: http.StatusForbidden,
+	ErrorCodeAccessDenied:       http.StatusForbidden,
 	ErrorInvalidRequest:         http.StatusBadRequest,
 }
 
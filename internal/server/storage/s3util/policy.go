@@ -0,0 +1,37 @@
+package s3util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PublicReadPolicy generates an S3 bucket policy allowing anyone to read the objects under the given prefixes.
+// An empty prefix matches all objects of the bucket.
+func PublicReadPolicy(bucketName string, prefixes []string) (json.RawMessage, error) {
+	type statement struct {
+		Effect    string
+		Principal map[string][]string
+		Action    []string
+		Resource  []string
+	}
+
+	if len(prefixes) == 0 {
+		return nil, errors.New("No public prefixes")
+	}
+
+	publicRead := statement{
+		Effect:    "Allow",
+		Principal: map[string][]string{"AWS": {"*"}},
+		Action:    []string{"s3:GetObject"},
+	}
+
+	for _, prefix := range prefixes {
+		publicRead.Resource = append(publicRead.Resource, fmt.Sprintf("arn:aws:s3:::%s/%s*", bucketName, prefix))
+	}
+
+	return json.Marshal(map[string]any{
+		"Version":   "2012-10-17",
+		"Statement": []statement{publicRead},
+	})
+}
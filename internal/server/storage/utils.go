@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
 	"slices"
@@ -37,6 +38,7 @@ import (
 	"github.com/lxc/incus/v7/shared/archive"
 	"github.com/lxc/incus/v7/shared/ioprogress"
 	"github.com/lxc/incus/v7/shared/logger"
+	localtls "github.com/lxc/incus/v7/shared/tls"
 	"github.com/lxc/incus/v7/shared/util"
 	"github.com/lxc/incus/v7/shared/validate"
 )
@@ -563,11 +565,82 @@ func validateVolumeCommonRules(vol drivers.Volume) map[string]func(string) error
 
 	if vol.Type() == drivers.VolumeTypeCustom {
 		rules["dependent"] = validate.Optional(validate.IsBool)
+
+		// Replication settings.
+		rules["replication.target"] = validate.Optional(func(value string) error {
+			_, _, err := ParseReplicationTarget(value)
+			return err
+		})
+
+		rules["replication.target.certificate"] = validate.Optional(func(value string) error {
+			_, err := localtls.CertFingerprintStr(value)
+			if err != nil {
+				return fmt.Errorf("Invalid certificate: %w", err)
+			}
+
+			return nil
+		})
+
+		rules["replication.target.token"] = validate.Optional(func(value string) error {
+			_, err := localtls.CertificateTokenDecode(value)
+			if err != nil {
+				return fmt.Errorf("Invalid trust token: %w", err)
+			}
+
+			return nil
+		})
+
+		rules["replication.schedule"] = validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"}))
+		rules["replication.retention"] = validate.Optional(validate.IsInRange(1, 1024))
+		rules["volatile.replication.certificate"] = validate.IsAny
+		rules["volatile.replication.key"] = validate.IsAny
+		rules["volatile.replication.last_error"] = validate.IsAny
+		rules["volatile.replication.last_snapshot"] = validate.IsAny
+		rules["volatile.replication.last_success"] = validate.IsAny
+	}
+
+	if vol.Type() == drivers.VolumeTypeBucket {
+		// Anonymous access settings.
+		rules["public_read"] = validate.Optional(validate.IsBool)
+		rules["public_read.prefixes"] = validate.Optional(validate.IsListOf(validate.IsNotEmpty))
+
+		// Versioning and lifecycle settings.
+		rules["versioning"] = validate.Optional(validate.IsBool)
+		rules["versioning.retention.versions"] = validate.Optional(validate.IsUint32)
+		rules["versioning.retention.days"] = validate.Optional(validate.IsUint32)
+		rules["lifecycle.expiry"] = validate.Optional(func(value string) error {
+			_, err := drivers.BucketLifecycleRules(value)
+			return err
+		})
 	}
 
 	return rules
 }
 
+// ParseReplicationTarget parses a replication target of the form "https://<address>[:<port>]/<pool>".
+// It returns the URL of the target server and the name of the target storage pool.
+func ParseReplicationTarget(value string) (string, string, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", "", fmt.Errorf("Invalid replication target: %w", err)
+	}
+
+	if u.Scheme != "https" || u.Host == "" {
+		return "", "", errors.New("Replication target must be an HTTPS URL")
+	}
+
+	poolName := strings.Trim(u.Path, "/")
+	if poolName == "" || strings.Contains(poolName, "/") {
+		return "", "", errors.New("Replication target must include the name of the target storage pool as its path")
+	}
+
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", "", errors.New("Replication target must not include credentials, query or fragment")
+	}
+
+	return fmt.Sprintf("https://%s", u.Host), poolName, nil
+}
+
 // ImageUnpack unpacks a filesystem image into the destination path.
 // There are several formats that images can come in:
 // Container Format A: Separate metadata tarball and root squashfs file.
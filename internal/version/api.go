@@ -535,6 +535,30 @@ var APIExtensions = []string{
 	"projects_restricted_storage_pool_access",
 	"server_shutdown_action",
 	"instances_placement_scriptlet_rebalance",
+	"backup_incremental",
+	"logging_otlp",
+	"logging_spool",
+	"network_zones_dns_queries",
+	"network_zones_ixfr_notify",
+	"network_load_balancer_bridge",
+	"network_acl_stateful",
+	"instances_validation_scriptlet",
+	"metrics_storage",
+	"storage_volume_replication",
+	"network_bgp_peer_options",
+	"network_bgp_state",
+	"instance_placement_groups",
+	"cluster_maintenance_windows",
+	"cluster_healing_fencing",
+	"instance_snapshot_freeze",
+	"instance_memory_auto_balloon",
+	"instance_migration_tuning",
+	"instance_inventory",
+	"instance_access_credentials",
+	"storage_buckets_local_copy_delete",
+	"storage_buckets_public_read",
+	"storage_buckets_versioning",
+	"instance_publish_oci",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
@@ -25,3 +25,22 @@ type AccessEntry struct {
 	// Example: tls, openfga
 	Provider string `json:"provider" yaml:"provider"`
 }
+
+// InstanceAccessCredentialsPost represents the credentials to set for a user of an instance.
+//
+// swagger:model
+//
+// API extension: instance_access_credentials.
+type InstanceAccessCredentialsPost struct {
+	// Name of the user inside the instance
+	// Example: root
+	User string `json:"user" yaml:"user"`
+
+	// New password for the user (empty to leave unchanged)
+	// Example: my-new-password
+	Password string `json:"password" yaml:"password"`
+
+	// SSH public keys to add to the user's authorized keys
+	// Example: ["ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIFoo support@example.com"]
+	SSHKeys []string `json:"ssh_keys" yaml:"ssh_keys"`
+}
@@ -18,3 +18,10 @@ type API10Put struct {
 	// Example: true
 	DevIncus bool `json:"dev_incus" yaml:"dev_incus"`
 }
+
+// FreezePost contains the fields used to freeze the guest file systems.
+type FreezePost struct {
+	// Time in seconds after which the file systems are automatically thawed (0 to use the default)
+	// Example: 300
+	Timeout int64 `json:"timeout" yaml:"timeout"`
+}
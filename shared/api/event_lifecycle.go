@@ -15,6 +15,8 @@ const (
 	EventLifecycleClusterMemberAdded                = "cluster-member-added"
 	EventLifecycleClusterMemberEvacuated            = "cluster-member-evacuated"
 	EventLifecycleClusterMemberHealed               = "cluster-member-healed"
+	EventLifecycleClusterMemberMaintenanceEnded     = "cluster-member-maintenance-ended"
+	EventLifecycleClusterMemberMaintenanceStarted   = "cluster-member-maintenance-started"
 	EventLifecycleClusterMemberRemoved              = "cluster-member-removed"
 	EventLifecycleClusterMemberRenamed              = "cluster-member-renamed"
 	EventLifecycleClusterMemberRestored             = "cluster-member-restored"
@@ -39,6 +41,7 @@ const (
 	EventLifecycleInstanceConsoleReset              = "instance-console-reset"
 	EventLifecycleInstanceConsoleRetrieved          = "instance-console-retrieved"
 	EventLifecycleInstanceCreated                   = "instance-created"
+	EventLifecycleInstanceCredentialsUpdated        = "instance-credentials-updated"
 	EventLifecycleInstanceDeleted                   = "instance-deleted"
 	EventLifecycleInstanceExec                      = "instance-exec"
 	EventLifecycleInstanceFileDeleted               = "instance-file-deleted"
@@ -57,7 +57,7 @@ type ImagesPost struct {
 	// API extension: image_compression_algorithm
 	CompressionAlgorithm string `json:"compression_algorithm" yaml:"compression_algorithm"`
 
-	// Type of image format
+	// Type of image format (unified, split or oci)
 	// Example: split
 	//
 	// API extension: instance_publish_split
@@ -68,6 +68,34 @@ type ImagesPost struct {
 	//
 	// API extension: image_create_aliases
 	Aliases []ImageAlias `json:"aliases" yaml:"aliases"`
+
+	// Registry to push the image to (for the "oci" format)
+	//
+	// API extension: instance_publish_oci
+	Target *ImagesPostTarget `json:"target" yaml:"target"`
+}
+
+// ImagesPostTarget represents the OCI registry an instance is published to
+//
+// swagger:model
+//
+// API extension: instance_publish_oci.
+type ImagesPostTarget struct {
+	// URL of the registry
+	// Example: https://ghcr.io
+	Server string `json:"server" yaml:"server"`
+
+	// Certificate of the registry (if not trusted by the system CA)
+	// Example: X509 PEM certificate
+	Certificate string `json:"certificate" yaml:"certificate"`
+
+	// User name on the registry (if authentication is needed)
+	// Example: user
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+
+	// Password or token of the user on the registry
+	// Example: RANDOM-STRING
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
 }
 
 // ImagesPostSource represents the source of a new image
@@ -268,6 +268,11 @@ type InstanceFull struct {
 
 	// List of snapshots.
 	Snapshots []InstanceSnapshot `json:"snapshots" yaml:"snapshots"`
+
+	// Software inventory (only set when requested, for running virtual machines)
+	//
+	// API extension: instance_inventory.
+	Inventory *InstanceInventory `json:"inventory,omitempty" yaml:"inventory,omitempty"`
 }
 
 // Writable converts a full Instance struct into a InstancePut struct (filters read-only fields).
@@ -72,6 +72,13 @@ type InstanceBackupsPost struct {
 	//
 	// API extension: backup_s3_upload
 	Target *BackupTarget `json:"target" yaml:"target"`
+
+	// Name of the dirty bitmap to use as the base for an incremental backup
+	// Only the blocks changed since the bitmap was created (or last exported) are included.
+	// Example: bitmap0
+	//
+	// API extension: backup_incremental
+	Since string `json:"since" yaml:"since"`
 }
 
 // InstanceBackup represents an instance backup.
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"time"
+)
+
+// InstanceInventory represents the software inventory of an instance, as reported by its guest agent.
+//
+// swagger:model
+//
+// API extension: instance_inventory.
+type InstanceInventory struct {
+	// Content of the operating system release file (os-release on Linux)
+	// Example: {"ID": "debian", "VERSION_ID": "12", "VERSION_CODENAME": "bookworm"}
+	OSRelease map[string]string `json:"os_release" yaml:"os_release"`
+
+	// Installed packages
+	Packages []InstanceInventoryPackage `json:"packages" yaml:"packages"`
+
+	// Logged-in users
+	Users []InstanceInventoryUser `json:"users" yaml:"users"`
+
+	// Services
+	Services []InstanceInventoryService `json:"services" yaml:"services"`
+}
+
+// InstanceInventoryPackage represents a package installed in an instance.
+//
+// swagger:model
+//
+// API extension: instance_inventory.
+type InstanceInventoryPackage struct {
+	// Name of the package
+	// Example: openssl
+	Name string `json:"name" yaml:"name"`
+
+	// Version of the package
+	// Example: 3.0.14-1~deb12u2
+	Version string `json:"version" yaml:"version"`
+
+	// Architecture of the package
+	// Example: amd64
+	Architecture string `json:"architecture" yaml:"architecture"`
+
+	// Package manager the package was installed with
+	// Example: dpkg
+	Source string `json:"source" yaml:"source"`
+}
+
+// InstanceInventoryUser represents a user logged into an instance.
+//
+// swagger:model
+//
+// API extension: instance_inventory.
+type InstanceInventoryUser struct {
+	// Name of the user
+	// Example: root
+	Name string `json:"name" yaml:"name"`
+
+	// Terminal the user is logged into
+	// Example: pts/0
+	Terminal string `json:"terminal" yaml:"terminal"`
+
+	// Remote host the user is connected from (if any)
+	// Example: 192.0.2.10
+	Host string `json:"host" yaml:"host"`
+
+	// Time at which the session was started
+	// Example: 2021-03-23T20:00:00-04:00
+	StartedAt time.Time `json:"started_at" yaml:"started_at"`
+}
+
+// InstanceInventoryService represents a service of an instance.
+//
+// swagger:model
+//
+// API extension: instance_inventory.
+type InstanceInventoryService struct {
+	// Name of the service
+	// Example: ssh.service
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the service
+	// Example: OpenBSD Secure Shell server
+	Description string `json:"description" yaml:"description"`
+
+	// Current state of the service
+	// Example: running
+	State string `json:"state" yaml:"state"`
+}
@@ -8,3 +8,6 @@ const SecretNameFilesystem = "fs"
 
 // SecretNameState is the secret name used for the migration state connection.
 const SecretNameState = "criu" // Legacy value used for backward compatibility for clients.
+
+// SecretNameMultifd is the prefix of the secret names used for the migration multifd connections.
+const SecretNameMultifd = "multifd"
@@ -43,6 +43,24 @@ type NetworkACLRule struct {
 	// Example: 0
 	ICMPCode string `json:"icmp_code,omitempty" yaml:"icmp_code,omitempty"`
 
+	// Connection tracking states to match (comma separated list of new, established, related or invalid)
+	// Example: new
+	//
+	// API extension: network_acl_stateful
+	ConnectionState string `json:"connection_state,omitempty" yaml:"connection_state,omitempty"`
+
+	// Maximum packet rate per source address (only the traffic exceeding it matches the rule)
+	// Example: 10/second
+	//
+	// API extension: network_acl_stateful
+	RateLimit string `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+
+	// Maximum number of concurrent connections per source address (only the connections exceeding it match the rule)
+	// Example: 20
+	//
+	// API extension: network_acl_stateful
+	ConnectionLimit string `json:"connection_limit,omitempty" yaml:"connection_limit,omitempty"`
+
 	// Description of the rule
 	// Example: Allow DNS queries to Google DNS
 	Description string `json:"description,omitempty" yaml:"description,omitempty"`
@@ -58,6 +76,8 @@ func (r *NetworkACLRule) Normalise() {
 	r.Protocol = strings.TrimSpace(r.Protocol)
 	r.ICMPType = strings.TrimSpace(r.ICMPType)
 	r.ICMPCode = strings.TrimSpace(r.ICMPCode)
+	r.RateLimit = strings.TrimSpace(r.RateLimit)
+	r.ConnectionLimit = strings.TrimSpace(r.ConnectionLimit)
 	r.Description = strings.TrimSpace(r.Description)
 	r.State = strings.TrimSpace(r.State)
 
@@ -92,6 +112,14 @@ func (r *NetworkACLRule) Normalise() {
 	}
 
 	r.DestinationPort = strings.Join(ports, ",")
+
+	// Remove space from ConnectionState list.
+	states := strings.Split(r.ConnectionState, ",")
+	for i, s := range states {
+		states[i] = strings.TrimSpace(s)
+	}
+
+	r.ConnectionState = strings.Join(states, ",")
 }
 
 // NetworkACLPost used for renaming an ACL.
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"time"
+)
+
+// NetworkBGPState represents the BGP state of a network on a server
+//
+// swagger:model
+//
+// API extension: network_bgp_state.
+type NetworkBGPState struct {
+	// List of BGP peers configured on the network (or its uplink network)
+	Peers []NetworkBGPPeerState `json:"peers" yaml:"peers"`
+
+	// List of prefixes advertised for the network
+	Prefixes []NetworkBGPPrefix `json:"prefixes" yaml:"prefixes"`
+}
+
+// NetworkBGPPeerState represents the state of a BGP peer
+//
+// swagger:model
+//
+// API extension: network_bgp_state.
+type NetworkBGPPeerState struct {
+	// Name of the peer in the network configuration
+	// Example: router1
+	Name string `json:"name" yaml:"name"`
+
+	// Address of the peer
+	// Example: 192.0.2.1
+	Address string `json:"address" yaml:"address"`
+
+	// ASN of the peer
+	// Example: 65000
+	ASN uint32 `json:"asn" yaml:"asn"`
+
+	// Session state (such as "established", "active", "idle" or "restarting")
+	// Example: established
+	State string `json:"state" yaml:"state"`
+
+	// Time since the session was established in seconds
+	// Example: 3600
+	Uptime int64 `json:"uptime" yaml:"uptime"`
+
+	// Number of prefixes received from the peer
+	// Example: 2
+	ReceivedPrefixes uint64 `json:"received_prefixes" yaml:"received_prefixes"`
+
+	// Number of received prefixes which were accepted
+	// Example: 2
+	AcceptedPrefixes uint64 `json:"accepted_prefixes" yaml:"accepted_prefixes"`
+
+	// Number of prefixes advertised to the peer
+	// Example: 4
+	AdvertisedPrefixes uint64 `json:"advertised_prefixes" yaml:"advertised_prefixes"`
+
+	// State of the BFD session (empty if BFD isn't enabled)
+	// Example: up
+	BFDState string `json:"bfd_state" yaml:"bfd_state"`
+
+	// Last error seen on the session
+	// Example: BFD session down
+	LastError string `json:"last_error" yaml:"last_error"`
+
+	// Time of the last error
+	// Example: 2021-03-23T20:00:00-04:00
+	LastErrorAt *time.Time `json:"last_error_at" yaml:"last_error_at"`
+}
+
+// NetworkBGPPrefix represents a prefix advertised over BGP
+//
+// swagger:model
+//
+// API extension: network_bgp_state.
+type NetworkBGPPrefix struct {
+	// The advertised prefix
+	// Example: 198.51.100.0/24
+	Prefix string `json:"prefix" yaml:"prefix"`
+
+	// The next-hop of the prefix
+	// Example: 192.0.2.10
+	Nexthop string `json:"nexthop" yaml:"nexthop"`
+
+	// What the prefix is advertised for (network, forward, load-balancer or instance)
+	// Example: network
+	Source string `json:"source" yaml:"source"`
+
+	// Instance the prefix is routed to (for instance routes)
+	// Example: c1
+	Instance string `json:"instance" yaml:"instance"`
+}
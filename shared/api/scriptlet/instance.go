@@ -34,3 +34,31 @@ type InstancePlacement struct {
 	Reason  string `json:"reason" yaml:"reason"`
 	Project string `json:"project" yaml:"project"`
 }
+
+// InstanceValidationReasonCreate is when a new instance is being created.
+const InstanceValidationReasonCreate = "create"
+
+// InstanceValidationReasonUpdate is when an existing instance is being updated.
+const InstanceValidationReasonUpdate = "update"
+
+// InstanceValidationReasonProfile is when a profile is being created or updated.
+const InstanceValidationReasonProfile = "profile"
+
+// InstanceValidation represents the instance validation request.
+//
+// API extension: instances_validation_scriptlet.
+type InstanceValidation struct {
+	Reason  string `json:"reason" yaml:"reason"`
+	Project string `json:"project" yaml:"project"`
+	Name    string `json:"name" yaml:"name"`
+
+	// Instance type (empty for profiles)
+	Type string `json:"type" yaml:"type"`
+
+	// List of profiles applied to the instance (empty for profiles)
+	Profiles []string `json:"profiles" yaml:"profiles"`
+
+	// Expanded configuration and devices
+	Config  map[string]string            `json:"config" yaml:"config"`
+	Devices map[string]map[string]string `json:"devices" yaml:"devices"`
+}
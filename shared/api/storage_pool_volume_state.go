@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // StorageVolumeState represents the live state of the volume
 //
 // swagger:model
@@ -8,6 +12,11 @@ package api
 type StorageVolumeState struct {
 	// Volume usage
 	Usage *StorageVolumeStateUsage `json:"usage" yaml:"usage"`
+
+	// Volume replication state (only set when replication is configured)
+	//
+	// API extension: storage_volume_replication
+	Replication *StorageVolumeStateReplication `json:"replication,omitempty" yaml:"replication,omitempty"`
 }
 
 // StorageVolumeStateUsage represents the disk usage of a volume
@@ -26,3 +35,30 @@ type StorageVolumeStateUsage struct {
 	// API extension: storage_volume_state_total
 	Total int64 `json:"total" yaml:"total"`
 }
+
+// StorageVolumeStateReplication represents the replication state of a custom volume
+//
+// swagger:model
+//
+// API extension: storage_volume_replication.
+type StorageVolumeStateReplication struct {
+	// Replication target
+	// Example: https://backup.example.net:8443/default
+	Target string `json:"target" yaml:"target"`
+
+	// Name of the last successfully replicated snapshot
+	// Example: replication-12
+	LastSnapshot string `json:"last_snapshot" yaml:"last_snapshot"`
+
+	// Time of the last successful replication
+	// Example: 2021-03-23T20:00:00-04:00
+	LastSuccess *time.Time `json:"last_success" yaml:"last_success"`
+
+	// Age of the data on the target in seconds (time since the last replicated snapshot was taken)
+	// Example: 3600
+	Lag int64 `json:"lag" yaml:"lag"`
+
+	// Error of the last replication attempt (empty if it succeeded)
+	// Example: Failed connecting to replication target
+	LastError string `json:"last_error" yaml:"last_error"`
+}
@@ -96,6 +96,24 @@ var architectureSupportedPersonalities = map[int][]int{
 	ARCH_64BIT_LOONGARCH:             {},
 }
 
+var architectureOCIPlatforms = map[int][]string{
+	ARCH_32BIT_INTEL_X86:             {"386"},
+	ARCH_64BIT_INTEL_X86:             {"amd64"},
+	ARCH_32BIT_ARMV6_LITTLE_ENDIAN:   {"arm", "v6"},
+	ARCH_32BIT_ARMV7_LITTLE_ENDIAN:   {"arm", "v7"},
+	ARCH_32BIT_ARMV8_LITTLE_ENDIAN:   {"arm", "v8"},
+	ARCH_64BIT_ARMV8_LITTLE_ENDIAN:   {"arm64"},
+	ARCH_32BIT_POWERPC_BIG_ENDIAN:    {"ppc"},
+	ARCH_64BIT_POWERPC_BIG_ENDIAN:    {"ppc64"},
+	ARCH_64BIT_POWERPC_LITTLE_ENDIAN: {"ppc64le"},
+	ARCH_64BIT_S390_BIG_ENDIAN:       {"s390x"},
+	ARCH_32BIT_MIPS:                  {"mipsle"},
+	ARCH_64BIT_MIPS:                  {"mips64le"},
+	ARCH_32BIT_RISCV_LITTLE_ENDIAN:   {"riscv32"},
+	ARCH_64BIT_RISCV_LITTLE_ENDIAN:   {"riscv64"},
+	ARCH_64BIT_LOONGARCH:             {"loong64"},
+}
+
 // ArchitectureDefault is the fallback architecture when the local architecture can't be properly detected.
 const ArchitectureDefault = "x86_64"
 
@@ -146,6 +164,20 @@ func ArchitecturePersonalities(arch int) ([]int, error) {
 	return []int{}, fmt.Errorf("Architecture isn't supported: %d", arch)
 }
 
+// ArchitectureOCIPlatform returns the OCI architecture and variant (if any) for the architecture.
+func ArchitectureOCIPlatform(arch int) (string, string, error) {
+	platform, exists := architectureOCIPlatforms[arch]
+	if !exists {
+		return "", "", fmt.Errorf("Architecture isn't supported: %d", arch)
+	}
+
+	if len(platform) == 1 {
+		return platform[0], "", nil
+	}
+
+	return platform[0], platform[1], nil
+}
+
 // ArchitectureGetLocalID returns the local hardware architecture ID.
 func ArchitectureGetLocalID() (int, error) {
 	name, err := ArchitectureGetLocal()